@@ -0,0 +1,5 @@
+.PHONY: bench
+## bench: runs the load test benchmarks of the collectors
+bench:
+	@echo "====> $@"
+	go test -run '^$$' -bench . -benchmem ./pkg/loadtest/
@@ -0,0 +1,134 @@
+package client
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/pkg/audit"
+)
+
+var (
+	auditWriteFailuresCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "azure_operator",
+		Subsystem: "audit",
+		Name:      "write_failures_total",
+		Help:      "Number of audit records of requests sent to the Azure APIs which failed to be written.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(auditWriteFailuresCounter)
+}
+
+// auditSender writes an audit record of every request sent to the Azure APIs
+// when auditing is enabled. It sends the requests, so requests refused by the
+// call budget and responses served from the conditional cache are recorded
+// as they were actually sent.
+type auditSender struct {
+	sender  autorest.Sender
+	service string
+}
+
+func (s *auditSender) Do(req *http.Request) (*http.Response, error) {
+	if !audit.Enabled() {
+		return s.sender.Do(req)
+	}
+
+	start := time.Now()
+	resp, err := s.sender.Do(req)
+
+	status := "error"
+	if err == nil && resp != nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+
+	// The query is not recorded, as it may contain SAS tokens.
+	r := audit.Record{
+		Time:            start.UTC(),
+		Subscription:    subscriptionFromPath(req.URL.Path),
+		Service:         s.service,
+		Operation:       operationFromRequest(req.Method, req.URL.Path),
+		Method:          req.Method,
+		Host:            req.URL.Host,
+		Path:            req.URL.Path,
+		Status:          status,
+		Duration:        time.Since(start).Seconds(),
+		ClientRequestID: req.Header.Get(clientRequestIDHeader),
+	}
+
+	// Failing to write the record must not fail the collection. It is
+	// counted instead, so missing records can be alerted on.
+	if audit.Write(r) != nil {
+		auditWriteFailuresCounter.Inc()
+	}
+
+	return resp, err
+}
+
+// Operation returns the Azure resource provider operation of the given
+// request, e.g. "Microsoft.Compute/virtualMachineScaleSets/read", or its
+// method and host for requests to other APIs, e.g. "GET graph.microsoft.com".
+func Operation(req *http.Request) string {
+	operation := operationFromRequest(req.Method, req.URL.Path)
+	if operation == "" {
+		operation = req.Method + " " + req.URL.Host
+	}
+
+	return operation
+}
+
+// operationFromRequest returns the Azure resource provider operation of the
+// given ARM request in the form used by role definitions, e.g.
+// "Microsoft.Compute/virtualMachineScaleSets/read". It is empty for requests
+// to other APIs.
+func operationFromRequest(method string, path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) < 2 || !strings.EqualFold(segments[0], "subscriptions") {
+		return ""
+	}
+
+	// Resources of resource providers, including extension resources of
+	// other resources, are the ones after the last providers segment.
+	// Subscriptions and resource groups are the ones of
+	// Microsoft.Resources.
+	namespace := "Microsoft.Resources"
+	types := []string{"subscriptions"}
+	rest := segments[2:]
+	for i := len(segments) - 2; i >= 0; i-- {
+		if strings.EqualFold(segments[i], "providers") {
+			namespace = segments[i+1]
+			types = nil
+			rest = segments[i+2:]
+			break
+		}
+	}
+
+	var action string
+	if method == http.MethodPost && len(rest)%2 == 1 {
+		action = rest[len(rest)-1]
+		rest = rest[:len(rest)-1]
+	}
+	for i := 0; i < len(rest); i += 2 {
+		types = append(types, rest[i])
+	}
+
+	operation := strings.Join(append([]string{namespace}, types...), "/")
+
+	switch {
+	case action != "":
+		return operation + "/" + action + "/action"
+	case method == http.MethodGet || method == http.MethodHead:
+		return operation + "/read"
+	case method == http.MethodPut || method == http.MethodPatch:
+		return operation + "/write"
+	case method == http.MethodDelete:
+		return operation + "/delete"
+	default:
+		return operation + "/action"
+	}
+}
@@ -0,0 +1,58 @@
+package client
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func Test_operationFromRequest(t *testing.T) {
+	testCases := []struct {
+		name              string
+		method            string
+		path              string
+		expectedOperation string
+	}{
+		{
+			name:              "case 0: resource group",
+			method:            http.MethodGet,
+			path:              "/subscriptions/1/resourceGroups/abc12",
+			expectedOperation: "Microsoft.Resources/subscriptions/resourceGroups/read",
+		},
+		{
+			name:              "case 1: nested resource",
+			method:            http.MethodGet,
+			path:              "/subscriptions/1/resourceGroups/abc12/providers/Microsoft.Compute/virtualMachineScaleSets/abc12-worker/virtualMachines",
+			expectedOperation: "Microsoft.Compute/virtualMachineScaleSets/virtualMachines/read",
+		},
+		{
+			name:              "case 2: extension resource",
+			method:            http.MethodGet,
+			path:              "/subscriptions/1/resourceGroups/abc12/providers/Microsoft.Network/loadBalancers/abc12-lb/providers/microsoft.insights/metrics",
+			expectedOperation: "microsoft.insights/metrics/read",
+		},
+		{
+			name:              "case 3: action",
+			method:            http.MethodPost,
+			path:              "/subscriptions/1/resourceGroups/abc12/providers/Microsoft.Storage/storageAccounts/abc12sa/listKeys",
+			expectedOperation: "Microsoft.Storage/storageAccounts/listKeys/action",
+		},
+		{
+			name:              "case 4: request to another API",
+			method:            http.MethodPost,
+			path:              "/tenant/oauth2/token",
+			expectedOperation: "",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			operation := operationFromRequest(tc.method, tc.path)
+			if operation != tc.expectedOperation {
+				t.Fatalf("operation = %#q, want %#q", operation, tc.expectedOperation)
+			}
+		})
+	}
+}
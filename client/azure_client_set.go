@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"net/http"
 
-	"github.com/Azure/azure-sdk-for-go/profiles/latest/graphrbac/graphrbac"
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/containerregistry/mgmt/2019-05-01/containerregistry"
+	"github.com/Azure/azure-sdk-for-go/services/keyvault/v7.0/keyvault"
 	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-11-01/network"
+	"github.com/Azure/azure-sdk-for-go/services/preview/monitor/mgmt/2019-06-01/insights"
 	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2019-05-01/resources"
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2019-06-01/storage"
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/azure"
 	"github.com/Azure/go-autorest/autorest/azure/auth"
@@ -15,6 +18,9 @@ import (
 )
 
 type AzureClientSetConfig struct {
+	// Factory creates the clients of the client set, which share its HTTP
+	// transport, endpoints, call budgets and caches.
+	Factory        *Factory
 	Authorizer     autorest.Authorizer
 	ClientID       string
 	ClientSecret   string
@@ -30,7 +36,8 @@ const (
 
 // AzureClientSet is the collection of Azure API clients.
 type AzureClientSet struct {
-	ApplicationsClient *graphrbac.ApplicationsClient
+	// GraphClient sends requests to Microsoft Graph in the GiantSwarm tenant.
+	GraphClient *GraphClient
 	// DeploymentsClient manages deployments of ARM templates.
 	DeploymentsClient *resources.DeploymentsClient
 	// GroupsClient manages ARM resource groups.
@@ -41,6 +48,50 @@ type AzureClientSet struct {
 	VirtualNetworkGatewayConnectionsClient *network.VirtualNetworkGatewayConnectionsClient
 	// VirtualMachineScaleSetVMsClient manages virtual machine scale set VMs.
 	VirtualMachineScaleSetVMsClient *compute.VirtualMachineScaleSetVMsClient
+	// VirtualNetworksClient manages virtual networks.
+	VirtualNetworksClient *network.VirtualNetworksClient
+	// FlowLogsClient manages network watcher flow logs.
+	FlowLogsClient *network.FlowLogsClient
+	// WatchersClient manages network watchers.
+	WatchersClient *network.WatchersClient
+	// ConnectionMonitorsClient manages network watcher connection monitors.
+	ConnectionMonitorsClient *network.ConnectionMonitorsClient
+	// MetricsClient queries Azure Monitor metrics.
+	MetricsClient *insights.MetricsClient
+	// ResourceSkusClient lists the SKUs available for compute resources.
+	ResourceSkusClient *compute.ResourceSkusClient
+	// VirtualMachineScaleSetsClient manages virtual machine scale sets.
+	VirtualMachineScaleSetsClient *compute.VirtualMachineScaleSetsClient
+	// RESTClient sends requests using API versions the SDK does not support yet.
+	RESTClient *RESTClient
+	// ResourcesClient manages generic ARM resources.
+	ResourcesClient *resources.Client
+	// StorageAccountsClient manages storage accounts.
+	StorageAccountsClient *storage.AccountsClient
+	// BlobServicesClient manages the blob service properties of storage accounts.
+	BlobServicesClient *storage.BlobServicesClient
+	// ManagementPoliciesClient manages the lifecycle management policies of storage accounts.
+	ManagementPoliciesClient *storage.ManagementPoliciesClient
+	// FileSharesClient manages the file shares of storage accounts.
+	FileSharesClient *storage.FileSharesClient
+	// StorageUsagesClient fetches the storage account usage and limits per location.
+	StorageUsagesClient *storage.UsagesClient
+	// KeyVaultClient manages the secrets, keys and certificates stored in key vaults.
+	KeyVaultClient *keyvault.BaseClient
+	// DiskEncryptionSetsClient manages disk encryption sets.
+	DiskEncryptionSetsClient *compute.DiskEncryptionSetsClient
+	// RegistriesClient manages container registries.
+	RegistriesClient *containerregistry.RegistriesClient
+	// WebhooksClient manages the webhooks of container registries.
+	WebhooksClient *containerregistry.WebhooksClient
+	// ReplicationsClient manages the geo-replications of container registries.
+	ReplicationsClient *containerregistry.ReplicationsClient
+	// DiagnosticSettingsClient manages the diagnostic settings of resources.
+	DiagnosticSettingsClient *insights.DiagnosticSettingsClient
+	// ActivityLogsClient queries the Activity Log of the subscription.
+	ActivityLogsClient *insights.ActivityLogsClient
+	// NetworkUsagesClient fetches the network resource usage and limits per location.
+	NetworkUsagesClient *network.UsagesClient
 }
 
 func init() {
@@ -49,7 +100,7 @@ func init() {
 }
 
 // NewAzureClientSetConfig creates a new azure client set config and applies defaults.
-func NewAzureClientSetConfig(authorizer autorest.Authorizer, clientid, clientsecret, subscriptionID, partnerID, tenantID, gsTenantID string) (AzureClientSetConfig, error) {
+func NewAzureClientSetConfig(factory *Factory, authorizer autorest.Authorizer, clientid, clientsecret, subscriptionID, partnerID, tenantID, gsTenantID string) (AzureClientSetConfig, error) {
 	// No having partnerID in the secret means that customer has not
 	// upgraded yet to use the Azure Partner Program. In that case we set a
 	// constant random generated GUID that we haven't registered with Azure.
@@ -59,6 +110,7 @@ func NewAzureClientSetConfig(authorizer autorest.Authorizer, clientid, clientsec
 	}
 
 	return AzureClientSetConfig{
+		Factory:        factory,
 		Authorizer:     authorizer,
 		ClientID:       clientid,
 		ClientSecret:   clientsecret,
@@ -71,100 +123,383 @@ func NewAzureClientSetConfig(authorizer autorest.Authorizer, clientid, clientsec
 
 // NewAzureClientSet returns the Azure API clients.
 func NewAzureClientSet(config AzureClientSetConfig) (*AzureClientSet, error) {
-	applicationsClient, err := newApplicationsClient(config.ClientID, config.ClientSecret, config.GSTenantID, config.PartnerID)
+	if config.Factory == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Factory must not be empty", config)
+	}
+
+	f := config.Factory
+
+	graphClient, err := f.newGraphClient(config.ClientID, config.ClientSecret, config.GSTenantID, config.PartnerID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	deploymentsClient, err := f.newDeploymentsClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	groupsClient, err := f.newGroupsClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	usageClient, err := f.newUsageClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	virtualNetworkGatewayConnectionsClient, err := f.newVirtualNetworkGatewayConnectionsClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	virtualMachineScaleSetVMsClient, err := f.newVirtualMachineScaleSetVMsClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	virtualNetworksClient, err := f.newVirtualNetworksClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	flowLogsClient, err := f.newFlowLogsClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	watchersClient, err := f.newWatchersClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	connectionMonitorsClient, err := f.newConnectionMonitorsClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	metricsClient, err := f.newMetricsClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	resourceSkusClient, err := f.newResourceSkusClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	virtualMachineScaleSetsClient, err := f.newVirtualMachineScaleSetsClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	restClient, err := f.newRESTClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	resourcesClient, err := f.newResourcesClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
-	deploymentsClient, err := newDeploymentsClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
+	storageAccountsClient, err := f.newStorageAccountsClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
-	groupsClient, err := newGroupsClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
+	blobServicesClient, err := f.newBlobServicesClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
-	usageClient, err := newUsageClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
+	managementPoliciesClient, err := f.newManagementPoliciesClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
-	virtualNetworkGatewayConnectionsClient, err := newVirtualNetworkGatewayConnectionsClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
+	fileSharesClient, err := f.newFileSharesClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
-	virtualMachineScaleSetVMsClient, err := newVirtualMachineScaleSetVMsClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
+	storageUsagesClient, err := f.newStorageUsagesClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	keyVaultClient, err := f.newKeyVaultClient(config.ClientID, config.ClientSecret, config.TenantID, config.PartnerID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	diskEncryptionSetsClient, err := f.newDiskEncryptionSetsClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	registriesClient, err := f.newRegistriesClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	webhooksClient, err := f.newWebhooksClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	replicationsClient, err := f.newReplicationsClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	diagnosticSettingsClient, err := f.newDiagnosticSettingsClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	activityLogsClient, err := f.newActivityLogsClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	networkUsagesClient, err := f.newNetworkUsagesClient(config.Authorizer, config.SubscriptionID, config.PartnerID)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 
 	clientSet := &AzureClientSet{
-		ApplicationsClient:                     applicationsClient,
+		GraphClient:                            graphClient,
 		DeploymentsClient:                      deploymentsClient,
 		GroupsClient:                           groupsClient,
 		UsageClient:                            usageClient,
 		VirtualNetworkGatewayConnectionsClient: virtualNetworkGatewayConnectionsClient,
 		VirtualMachineScaleSetVMsClient:        virtualMachineScaleSetVMsClient,
+		VirtualNetworksClient:                  virtualNetworksClient,
+		FlowLogsClient:                         flowLogsClient,
+		WatchersClient:                         watchersClient,
+		ConnectionMonitorsClient:               connectionMonitorsClient,
+		MetricsClient:                          metricsClient,
+		ResourceSkusClient:                     resourceSkusClient,
+		VirtualMachineScaleSetsClient:          virtualMachineScaleSetsClient,
+		RESTClient:                             restClient,
+		ResourcesClient:                        resourcesClient,
+		StorageAccountsClient:                  storageAccountsClient,
+		BlobServicesClient:                     blobServicesClient,
+		ManagementPoliciesClient:               managementPoliciesClient,
+		FileSharesClient:                       fileSharesClient,
+		StorageUsagesClient:                    storageUsagesClient,
+		KeyVaultClient:                         keyVaultClient,
+		DiskEncryptionSetsClient:               diskEncryptionSetsClient,
+		RegistriesClient:                       registriesClient,
+		WebhooksClient:                         webhooksClient,
+		ReplicationsClient:                     replicationsClient,
+		DiagnosticSettingsClient:               diagnosticSettingsClient,
+		ActivityLogsClient:                     activityLogsClient,
+		NetworkUsagesClient:                    networkUsagesClient,
 	}
 
 	return clientSet, nil
 }
 
-func prepareClient(client *autorest.Client, authorizer autorest.Authorizer, partnerID string) *autorest.Client {
+func (f *Factory) prepareClient(client *autorest.Client, authorizer autorest.Authorizer, partnerID, service string) *autorest.Client {
 	client.Authorizer = authorizer
+	client.Sender = f.newSender(service)
 	_ = client.AddToUserAgent(partnerID)
 
 	return client
 }
 
-func newDeploymentsClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*resources.DeploymentsClient, error) {
-	client := resources.NewDeploymentsClient(subscriptionID)
-	prepareClient(&client.Client, authorizer, partnerID)
+func (f *Factory) newDeploymentsClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*resources.DeploymentsClient, error) {
+	client := resources.NewDeploymentsClientWithBaseURI(f.resourceManagerBaseURI(resources.DefaultBaseURI), subscriptionID)
+	f.prepareClient(&client.Client, authorizer, partnerID, ServiceResources)
+
+	return &client, nil
+}
+
+func (f *Factory) newGroupsClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*resources.GroupsClient, error) {
+	client := resources.NewGroupsClientWithBaseURI(f.resourceManagerBaseURI(resources.DefaultBaseURI), subscriptionID)
+	f.prepareClient(&client.Client, authorizer, partnerID, ServiceResources)
+
+	return &client, nil
+}
+
+func (f *Factory) newUsageClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*compute.UsageClient, error) {
+	client := compute.NewUsageClientWithBaseURI(f.resourceManagerBaseURI(compute.DefaultBaseURI), subscriptionID)
+	f.prepareClient(&client.Client, authorizer, partnerID, ServiceCompute)
+
+	return &client, nil
+}
+
+func (f *Factory) newVirtualNetworkGatewayConnectionsClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*network.VirtualNetworkGatewayConnectionsClient, error) {
+	client := network.NewVirtualNetworkGatewayConnectionsClientWithBaseURI(f.resourceManagerBaseURI(network.DefaultBaseURI), subscriptionID)
+	f.prepareClient(&client.Client, authorizer, partnerID, ServiceNetwork)
+
+	return &client, nil
+}
+
+func (f *Factory) newVirtualMachineScaleSetVMsClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*compute.VirtualMachineScaleSetVMsClient, error) {
+	client := compute.NewVirtualMachineScaleSetVMsClientWithBaseURI(f.resourceManagerBaseURI(compute.DefaultBaseURI), subscriptionID)
+	f.prepareClient(&client.Client, authorizer, partnerID, ServiceCompute)
+
+	return &client, nil
+}
+
+func (f *Factory) newVirtualNetworksClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*network.VirtualNetworksClient, error) {
+	client := network.NewVirtualNetworksClientWithBaseURI(f.resourceManagerBaseURI(network.DefaultBaseURI), subscriptionID)
+	f.prepareClient(&client.Client, authorizer, partnerID, ServiceNetwork)
+
+	return &client, nil
+}
+
+func (f *Factory) newFlowLogsClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*network.FlowLogsClient, error) {
+	client := network.NewFlowLogsClientWithBaseURI(f.resourceManagerBaseURI(network.DefaultBaseURI), subscriptionID)
+	f.prepareClient(&client.Client, authorizer, partnerID, ServiceNetwork)
+
+	return &client, nil
+}
+
+func (f *Factory) newWatchersClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*network.WatchersClient, error) {
+	client := network.NewWatchersClientWithBaseURI(f.resourceManagerBaseURI(network.DefaultBaseURI), subscriptionID)
+	f.prepareClient(&client.Client, authorizer, partnerID, ServiceNetwork)
+
+	return &client, nil
+}
+
+func (f *Factory) newConnectionMonitorsClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*network.ConnectionMonitorsClient, error) {
+	client := network.NewConnectionMonitorsClientWithBaseURI(f.resourceManagerBaseURI(network.DefaultBaseURI), subscriptionID)
+	f.prepareClient(&client.Client, authorizer, partnerID, ServiceNetwork)
+
+	return &client, nil
+}
+
+func (f *Factory) newMetricsClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*insights.MetricsClient, error) {
+	client := insights.NewMetricsClientWithBaseURI(f.resourceManagerBaseURI(insights.DefaultBaseURI), subscriptionID)
+	f.prepareClient(&client.Client, authorizer, partnerID, ServiceMonitor)
+
+	return &client, nil
+}
+
+func (f *Factory) newResourceSkusClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*compute.ResourceSkusClient, error) {
+	client := compute.NewResourceSkusClientWithBaseURI(f.resourceManagerBaseURI(compute.DefaultBaseURI), subscriptionID)
+	f.prepareClient(&client.Client, authorizer, partnerID, ServiceCompute)
+
+	return &client, nil
+}
+
+func (f *Factory) newVirtualMachineScaleSetsClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*compute.VirtualMachineScaleSetsClient, error) {
+	client := compute.NewVirtualMachineScaleSetsClientWithBaseURI(f.resourceManagerBaseURI(compute.DefaultBaseURI), subscriptionID)
+	f.prepareClient(&client.Client, authorizer, partnerID, ServiceCompute)
+
+	return &client, nil
+}
+
+func (f *Factory) newRESTClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*RESTClient, error) {
+	client := NewRESTClient(f.resourceManagerBaseURI(azure.PublicCloud.ResourceManagerEndpoint), subscriptionID)
+	f.prepareClient(&client.Client, authorizer, partnerID, ServiceARM)
+
+	return &client, nil
+}
+
+func (f *Factory) newResourcesClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*resources.Client, error) {
+	client := resources.NewClientWithBaseURI(f.resourceManagerBaseURI(resources.DefaultBaseURI), subscriptionID)
+	f.prepareClient(&client.Client, authorizer, partnerID, ServiceResources)
+
+	return &client, nil
+}
+
+func (f *Factory) newStorageAccountsClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*storage.AccountsClient, error) {
+	client := storage.NewAccountsClientWithBaseURI(f.resourceManagerBaseURI(storage.DefaultBaseURI), subscriptionID)
+	f.prepareClient(&client.Client, authorizer, partnerID, ServiceStorage)
+
+	return &client, nil
+}
+
+func (f *Factory) newBlobServicesClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*storage.BlobServicesClient, error) {
+	client := storage.NewBlobServicesClientWithBaseURI(f.resourceManagerBaseURI(storage.DefaultBaseURI), subscriptionID)
+	f.prepareClient(&client.Client, authorizer, partnerID, ServiceStorage)
 
 	return &client, nil
 }
 
-func newGroupsClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*resources.GroupsClient, error) {
-	client := resources.NewGroupsClient(subscriptionID)
-	prepareClient(&client.Client, authorizer, partnerID)
+func (f *Factory) newManagementPoliciesClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*storage.ManagementPoliciesClient, error) {
+	client := storage.NewManagementPoliciesClientWithBaseURI(f.resourceManagerBaseURI(storage.DefaultBaseURI), subscriptionID)
+	f.prepareClient(&client.Client, authorizer, partnerID, ServiceStorage)
 
 	return &client, nil
 }
 
-func newUsageClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*compute.UsageClient, error) {
-	client := compute.NewUsageClient(subscriptionID)
-	prepareClient(&client.Client, authorizer, partnerID)
+func (f *Factory) newFileSharesClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*storage.FileSharesClient, error) {
+	client := storage.NewFileSharesClientWithBaseURI(f.resourceManagerBaseURI(storage.DefaultBaseURI), subscriptionID)
+	f.prepareClient(&client.Client, authorizer, partnerID, ServiceStorage)
 
 	return &client, nil
 }
 
-func newVirtualNetworkGatewayConnectionsClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*network.VirtualNetworkGatewayConnectionsClient, error) {
-	client := network.NewVirtualNetworkGatewayConnectionsClient(subscriptionID)
-	prepareClient(&client.Client, authorizer, partnerID)
+func (f *Factory) newStorageUsagesClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*storage.UsagesClient, error) {
+	client := storage.NewUsagesClientWithBaseURI(f.resourceManagerBaseURI(storage.DefaultBaseURI), subscriptionID)
+	f.prepareClient(&client.Client, authorizer, partnerID, ServiceStorage)
 
 	return &client, nil
 }
 
-func newVirtualMachineScaleSetVMsClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*compute.VirtualMachineScaleSetVMsClient, error) {
-	client := compute.NewVirtualMachineScaleSetVMsClient(subscriptionID)
-	prepareClient(&client.Client, authorizer, partnerID)
+func (f *Factory) newDiskEncryptionSetsClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*compute.DiskEncryptionSetsClient, error) {
+	client := compute.NewDiskEncryptionSetsClientWithBaseURI(f.resourceManagerBaseURI(compute.DefaultBaseURI), subscriptionID)
+	f.prepareClient(&client.Client, authorizer, partnerID, ServiceCompute)
 
 	return &client, nil
 }
 
-func newApplicationsClient(clientID, clientSecret, gsTenantID, partnerID string) (*graphrbac.ApplicationsClient, error) {
+func (f *Factory) newRegistriesClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*containerregistry.RegistriesClient, error) {
+	client := containerregistry.NewRegistriesClientWithBaseURI(f.resourceManagerBaseURI(containerregistry.DefaultBaseURI), subscriptionID)
+	f.prepareClient(&client.Client, authorizer, partnerID, ServiceContainerRegistry)
+
+	return &client, nil
+}
+
+func (f *Factory) newWebhooksClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*containerregistry.WebhooksClient, error) {
+	client := containerregistry.NewWebhooksClientWithBaseURI(f.resourceManagerBaseURI(containerregistry.DefaultBaseURI), subscriptionID)
+	f.prepareClient(&client.Client, authorizer, partnerID, ServiceContainerRegistry)
+
+	return &client, nil
+}
+
+func (f *Factory) newReplicationsClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*containerregistry.ReplicationsClient, error) {
+	client := containerregistry.NewReplicationsClientWithBaseURI(f.resourceManagerBaseURI(containerregistry.DefaultBaseURI), subscriptionID)
+	f.prepareClient(&client.Client, authorizer, partnerID, ServiceContainerRegistry)
+
+	return &client, nil
+}
+
+func (f *Factory) newDiagnosticSettingsClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*insights.DiagnosticSettingsClient, error) {
+	client := insights.NewDiagnosticSettingsClientWithBaseURI(f.resourceManagerBaseURI(insights.DefaultBaseURI), subscriptionID)
+	f.prepareClient(&client.Client, authorizer, partnerID, ServiceMonitor)
+
+	return &client, nil
+}
+
+func (f *Factory) newActivityLogsClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*insights.ActivityLogsClient, error) {
+	client := insights.NewActivityLogsClientWithBaseURI(f.resourceManagerBaseURI(insights.DefaultBaseURI), subscriptionID)
+	f.prepareClient(&client.Client, authorizer, partnerID, ServiceMonitor)
+
+	return &client, nil
+}
+
+func (f *Factory) newNetworkUsagesClient(authorizer autorest.Authorizer, subscriptionID, partnerID string) (*network.UsagesClient, error) {
+	client := network.NewUsagesClientWithBaseURI(f.resourceManagerBaseURI(network.DefaultBaseURI), subscriptionID)
+	f.prepareClient(&client.Client, authorizer, partnerID, ServiceNetwork)
+
+	return &client, nil
+}
+
+func (f *Factory) newGraphClient(clientID, clientSecret, gsTenantID, partnerID string) (*GraphClient, error) {
 	credentials := auth.ClientCredentialsConfig{
 		ClientID:     clientID,
 		ClientSecret: clientSecret,
 		TenantID:     gsTenantID,
-		Resource:     azure.PublicCloud.GraphEndpoint, // This Endpoint is different than using regular ClientCredentialsConfig
-		AADEndpoint:  azure.PublicCloud.ActiveDirectoryEndpoint,
+		Resource:     f.microsoftGraphEndpoint(), // This Endpoint is different than using regular ClientCredentialsConfig
+		AADEndpoint:  f.activeDirectoryEndpoint(),
+	}
+	authorizer, err := f.NewAuthorizer(credentials)
+	if err != nil {
+		return &GraphClient{}, microerror.Mask(err)
+	}
+
+	client := NewGraphClient(f.microsoftGraphEndpoint())
+	f.prepareClient(&client.Client, authorizer, partnerID, ServiceGraph)
+
+	return &client, nil
+}
+
+func (f *Factory) newKeyVaultClient(clientID, clientSecret, tenantID, partnerID string) (*keyvault.BaseClient, error) {
+	credentials := auth.ClientCredentialsConfig{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		TenantID:     tenantID,
+		Resource:     f.keyVaultResource(), // The key vault data plane requires its own token audience.
+		AADEndpoint:  f.activeDirectoryEndpoint(),
 	}
-	authorizer, err := credentials.Authorizer()
+	authorizer, err := f.NewAuthorizer(credentials)
 	if err != nil {
-		return &graphrbac.ApplicationsClient{}, microerror.Mask(err)
+		return &keyvault.BaseClient{}, microerror.Mask(err)
 	}
 
-	client := graphrbac.NewApplicationsClient(gsTenantID)
-	prepareClient(&client.Client, authorizer, partnerID)
+	client := keyvault.New()
+	f.prepareClient(&client.Client, authorizer, partnerID, ServiceKeyVault)
 
 	return &client, nil
 }
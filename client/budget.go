@@ -0,0 +1,233 @@
+package client
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/giantswarm/microerror"
+)
+
+const (
+	// budgetWindowMinutes is the length of the sliding window ARM calls are
+	// counted in.
+	budgetWindowMinutes = 60
+	// defaultThrottleBackoff is how long calls to a throttled subscription
+	// are refused when Azure does not tell when to retry.
+	defaultThrottleBackoff = time.Minute
+	// maxThrottleBackoff is the longest calls to a throttled subscription are
+	// refused, whatever Azure tells.
+	maxThrottleBackoff = time.Hour
+)
+
+var (
+	subscriptionPathRegexp = regexp.MustCompile(`(?i)^/subscriptions/([^/]+)`)
+)
+
+// budget holds the ARM calls counted per subscription and the subscriptions
+// Azure throttled, which all clients of a factory share.
+type budget struct {
+	mutex sync.Mutex
+	// limit is the maximum number of ARM calls per hour and subscription.
+	// There is no limit when it is zero.
+	limit int
+	// calls are the ARM calls made keyed by the lower cased subscription ID.
+	calls map[string]*callWindow
+	// throttledAt is the time Azure last throttled a call to a subscription
+	// keyed by the lower cased subscription ID.
+	throttledAt map[string]time.Time
+	// throttledUntil is the time calls to a throttled subscription are
+	// refused until keyed by the lower cased subscription ID.
+	throttledUntil map[string]time.Time
+	// throttleRecorded is notified whenever a throttled call is recorded.
+	// Notifications not received yet are merged.
+	throttleRecorded chan struct{}
+}
+
+func newBudget(limit int) *budget {
+	return &budget{
+		limit:            limit,
+		calls:            map[string]*callWindow{},
+		throttledAt:      map[string]time.Time{},
+		throttledUntil:   map[string]time.Time{},
+		throttleRecorded: make(chan struct{}, 1),
+	}
+}
+
+// callWindow counts calls in a sliding window of an hour with a resolution
+// of a minute.
+type callWindow struct {
+	counts  [budgetWindowMinutes]int
+	minutes [budgetWindowMinutes]int64
+}
+
+func (w *callWindow) add(now time.Time) {
+	minute := now.Unix() / 60
+	i := minute % budgetWindowMinutes
+
+	if w.minutes[i] != minute {
+		w.minutes[i] = minute
+		w.counts[i] = 0
+	}
+	w.counts[i]++
+}
+
+func (w *callWindow) count(now time.Time) int {
+	minute := now.Unix() / 60
+
+	var count int
+	for i := range w.counts {
+		if w.minutes[i] > minute-budgetWindowMinutes {
+			count += w.counts[i]
+		}
+	}
+
+	return count
+}
+
+// budgetSender counts the ARM calls sent per subscription and records when
+// they are throttled. Calls to a subscription are refused until Azure allows
+// them to be retried, so throttled subscriptions are not hammered, or for the
+// backoff of their context, see WithThrottleBackoff. Calls to a subscription
+// whose budget is exhausted are refused unless their context is exempt, see
+// WithBudgetExempt.
+type budgetSender struct {
+	sender autorest.Sender
+	budget *budget
+}
+
+func (s *budgetSender) Do(req *http.Request) (*http.Response, error) {
+	subscriptionID := subscriptionFromPath(req.URL.Path)
+	if subscriptionID != "" {
+		now := time.Now()
+
+		b := s.budget
+		b.mutex.Lock()
+		until := b.throttledUntil[subscriptionID]
+		if now.Before(until) {
+			b.mutex.Unlock()
+			return nil, microerror.Maskf(throttledError, "subscription %#q is throttled until %s", subscriptionID, until.UTC().Format(time.RFC3339))
+		}
+		if backoff, ok := throttleBackoffFromContext(req.Context()); ok && now.Sub(b.throttledAt[subscriptionID]) < backoff {
+			at := b.throttledAt[subscriptionID]
+			b.mutex.Unlock()
+			return nil, microerror.Maskf(throttledError, "subscription %#q was throttled at %s", subscriptionID, at.UTC().Format(time.RFC3339))
+		}
+
+		w, ok := b.calls[subscriptionID]
+		if !ok {
+			w = &callWindow{}
+			b.calls[subscriptionID] = w
+		}
+		if b.limit > 0 && w.count(now) >= b.limit && !budgetExemptFromContext(req.Context()) {
+			b.mutex.Unlock()
+			return nil, microerror.Maskf(budgetExhaustedError, "ARM call budget of subscription %#q is exhausted", subscriptionID)
+		}
+		w.add(now)
+		b.mutex.Unlock()
+	}
+
+	resp, err := s.sender.Do(req)
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		now := time.Now()
+
+		b := s.budget
+		b.mutex.Lock()
+		if subscriptionID != "" {
+			b.throttledAt[subscriptionID] = now
+			b.throttledUntil[subscriptionID] = now.Add(retryAfter(resp.Header.Get("Retry-After"), now))
+		}
+		b.mutex.Unlock()
+
+		select {
+		case b.throttleRecorded <- struct{}{}:
+		default:
+		}
+	}
+
+	return resp, err
+}
+
+// retryAfter returns how long to wait before retrying a throttled call given
+// the value of its Retry-After header, either in seconds or as HTTP date.
+func retryAfter(value string, now time.Time) time.Duration {
+	var d time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		d = time.Duration(seconds) * time.Second
+	} else if t, err := http.ParseTime(value); err == nil {
+		d = t.Sub(now)
+	}
+
+	switch {
+	case d <= 0:
+		return defaultThrottleBackoff
+	case d > maxThrottleBackoff:
+		return maxThrottleBackoff
+	default:
+		return d
+	}
+}
+
+// BudgetLimit returns the maximum number of ARM calls per hour and
+// subscription, or zero when there is no limit.
+func (f *Factory) BudgetLimit() int {
+	return f.budget.limit
+}
+
+// BudgetCalls returns the number of ARM calls made in the last hour keyed by
+// the lower cased subscription ID.
+func (f *Factory) BudgetCalls() map[string]int {
+	b := f.budget
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	now := time.Now()
+
+	calls := map[string]int{}
+	for subscriptionID, w := range b.calls {
+		calls[subscriptionID] = w.count(now)
+	}
+
+	return calls
+}
+
+// ThrottleRecorded returns a channel which is notified whenever a throttled
+// call is recorded, e.g. to persist the budget state right away.
+func (f *Factory) ThrottleRecorded() <-chan struct{} {
+	return f.budget.throttleRecorded
+}
+
+// ThrottledSubscriptions returns the time calls are refused until keyed by the
+// lower cased IDs of the subscriptions which are currently throttled.
+func (f *Factory) ThrottledSubscriptions() map[string]time.Time {
+	b := f.budget
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	now := time.Now()
+
+	throttled := map[string]time.Time{}
+	for subscriptionID, until := range b.throttledUntil {
+		if now.Before(until) {
+			throttled[subscriptionID] = until
+		}
+	}
+
+	return throttled
+}
+
+// subscriptionFromPath returns the lower cased subscription ID of the given
+// ARM request path, or an empty string when the request is not scoped to a
+// subscription, e.g. Microsoft Graph or token requests.
+func subscriptionFromPath(path string) string {
+	matches := subscriptionPathRegexp.FindStringSubmatch(path)
+	if matches == nil {
+		return ""
+	}
+
+	return strings.ToLower(matches[1])
+}
@@ -0,0 +1,277 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_callWindow(t *testing.T) {
+	start := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		calls         []time.Time
+		now           time.Time
+		expectedCount int
+	}{
+		{
+			name:          "case 0: no calls",
+			calls:         nil,
+			now:           start,
+			expectedCount: 0,
+		},
+		{
+			name: "case 1: calls within the last hour",
+			calls: []time.Time{
+				start,
+				start,
+				start.Add(30 * time.Minute),
+				start.Add(59 * time.Minute),
+			},
+			now:           start.Add(59 * time.Minute),
+			expectedCount: 4,
+		},
+		{
+			name: "case 2: calls older than an hour are not counted",
+			calls: []time.Time{
+				start,
+				start.Add(30 * time.Minute),
+				start.Add(61 * time.Minute),
+			},
+			now:           start.Add(61 * time.Minute),
+			expectedCount: 2,
+		},
+		{
+			name: "case 3: reused minute slots are reset",
+			calls: []time.Time{
+				start,
+				start.Add(time.Hour),
+			},
+			now:           start.Add(time.Hour),
+			expectedCount: 1,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			w := &callWindow{}
+			for _, call := range tc.calls {
+				w.add(call)
+			}
+
+			count := w.count(tc.now)
+			if !cmp.Equal(count, tc.expectedCount) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedCount, count))
+			}
+		})
+	}
+}
+
+func Test_subscriptionFromPath(t *testing.T) {
+	testCases := []struct {
+		name                   string
+		path                   string
+		expectedSubscriptionID string
+	}{
+		{
+			name:                   "case 0: resource path",
+			path:                   "/subscriptions/1BE3B2E6-497B-45B9-915F-EB35CAE23C6A/resourceGroups/abc12/providers/Microsoft.Compute/virtualMachineScaleSets",
+			expectedSubscriptionID: "1be3b2e6-497b-45b9-915f-eb35cae23c6a",
+		},
+		{
+			name:                   "case 1: subscription path",
+			path:                   "/subscriptions/1be3b2e6-497b-45b9-915f-eb35cae23c6a",
+			expectedSubscriptionID: "1be3b2e6-497b-45b9-915f-eb35cae23c6a",
+		},
+		{
+			name:                   "case 2: Microsoft Graph path",
+			path:                   "/v1.0/applications",
+			expectedSubscriptionID: "",
+		},
+		{
+			name:                   "case 3: tenant level ARM path",
+			path:                   "/providers/Microsoft.ResourceGraph/resources",
+			expectedSubscriptionID: "",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			subscriptionID := subscriptionFromPath(tc.path)
+			if !cmp.Equal(subscriptionID, tc.expectedSubscriptionID) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedSubscriptionID, subscriptionID))
+			}
+		})
+	}
+}
+
+func Test_retryAfter(t *testing.T) {
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name             string
+		value            string
+		expectedDuration time.Duration
+	}{
+		{
+			name:             "case 0: no header",
+			value:            "",
+			expectedDuration: defaultThrottleBackoff,
+		},
+		{
+			name:             "case 1: seconds",
+			value:            "17",
+			expectedDuration: 17 * time.Second,
+		},
+		{
+			name:             "case 2: HTTP date",
+			value:            "Mon, 01 Mar 2021 12:05:00 GMT",
+			expectedDuration: 5 * time.Minute,
+		},
+		{
+			name:             "case 3: date in the past",
+			value:            "Mon, 01 Mar 2021 11:00:00 GMT",
+			expectedDuration: defaultThrottleBackoff,
+		},
+		{
+			name:             "case 4: too long",
+			value:            "86400",
+			expectedDuration: maxThrottleBackoff,
+		},
+		{
+			name:             "case 5: invalid",
+			value:            "soon",
+			expectedDuration: defaultThrottleBackoff,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			d := retryAfter(tc.value, now)
+			if !cmp.Equal(d, tc.expectedDuration) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedDuration, d))
+			}
+		})
+	}
+}
+
+func Test_budgetSender_Exhausted(t *testing.T) {
+	testCases := []struct {
+		name          string
+		ctx           context.Context
+		path          string
+		expectedError bool
+	}{
+		{
+			name:          "case 0: call to a subscription whose budget is exhausted",
+			ctx:           context.Background(),
+			path:          "/subscriptions/sub1/resourceGroups",
+			expectedError: true,
+		},
+		{
+			name:          "case 1: exempt call to a subscription whose budget is exhausted",
+			ctx:           WithBudgetExempt(context.Background()),
+			path:          "/subscriptions/sub1/resourceGroups",
+			expectedError: false,
+		},
+		{
+			name:          "case 2: call to a subscription with budget left",
+			ctx:           context.Background(),
+			path:          "/subscriptions/sub2/resourceGroups",
+			expectedError: false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			b := newBudget(1)
+			b.calls["sub1"] = &callWindow{}
+			b.calls["sub1"].add(time.Now())
+
+			s := &budgetSender{
+				sender: autorest.SenderFunc(func(req *http.Request) (*http.Response, error) {
+					return &http.Response{StatusCode: http.StatusOK}, nil
+				}),
+				budget: b,
+			}
+
+			req, err := http.NewRequestWithContext(tc.ctx, http.MethodGet, "https://management.azure.com"+tc.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = s.Do(req)
+			if IsBudgetExhausted(err) != tc.expectedError {
+				t.Fatalf("IsBudgetExhausted(err) == %t, want %t, err == %v", IsBudgetExhausted(err), tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func Test_budgetSender_ThrottleBackoff(t *testing.T) {
+	testCases := []struct {
+		name          string
+		ctx           context.Context
+		path          string
+		expectedError bool
+	}{
+		{
+			name:          "case 0: call to a recently throttled subscription",
+			ctx:           context.Background(),
+			path:          "/subscriptions/sub1/resourceGroups",
+			expectedError: false,
+		},
+		{
+			name:          "case 1: call with backoff to a recently throttled subscription",
+			ctx:           WithThrottleBackoff(context.Background(), 10*time.Minute),
+			path:          "/subscriptions/sub1/resourceGroups",
+			expectedError: true,
+		},
+		{
+			name:          "case 2: call with backoff to another subscription",
+			ctx:           WithThrottleBackoff(context.Background(), 10*time.Minute),
+			path:          "/subscriptions/sub2/resourceGroups",
+			expectedError: false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			b := newBudget(0)
+			b.throttledAt["sub1"] = time.Now().Add(-5 * time.Minute)
+
+			s := &budgetSender{
+				sender: autorest.SenderFunc(func(req *http.Request) (*http.Response, error) {
+					return &http.Response{StatusCode: http.StatusOK}, nil
+				}),
+				budget: b,
+			}
+
+			req, err := http.NewRequestWithContext(tc.ctx, http.MethodGet, "https://management.azure.com"+tc.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = s.Do(req)
+			if IsThrottled(err) != tc.expectedError {
+				t.Fatalf("IsThrottled(err) == %t, want %t, err == %v", IsThrottled(err), tc.expectedError, err)
+			}
+		})
+	}
+}
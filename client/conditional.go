@@ -0,0 +1,180 @@
+package client
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+)
+
+const (
+	// conditionalCacheMaxEntries is the maximum number of responses cached
+	// for conditional requests. The least recently used ones are evicted
+	// first.
+	conditionalCacheMaxEntries = 1000
+	// conditionalCacheMaxBodySize is the maximum size of a cached response
+	// body. Larger responses are not cached.
+	conditionalCacheMaxBodySize = 1 << 20
+)
+
+// conditionalCache holds the responses of the conditional requests, which all
+// clients of a factory share.
+type conditionalCache struct {
+	mutex   sync.Mutex
+	entries map[string]*conditionalCacheEntry
+	// notModified is the number of responses served from the cache because
+	// the resource has not been modified.
+	notModified int64
+}
+
+type conditionalCacheEntry struct {
+	body     []byte
+	etag     string
+	header   http.Header
+	lastUsed time.Time
+}
+
+// conditionalSender sends conditional GET requests for the resources whose
+// responses carried an ETag before. When the Azure API responds with 304 Not
+// Modified, the cached response is returned instead, which saves reading and
+// transferring rarely changing resources again. APIs not supporting ETags
+// are not affected.
+type conditionalSender struct {
+	sender autorest.Sender
+	cache  *conditionalCache
+}
+
+func newConditionalCache() *conditionalCache {
+	return &conditionalCache{
+		entries: map[string]*conditionalCacheEntry{},
+	}
+}
+
+func (s *conditionalSender) Do(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" {
+		return s.sender.Do(req)
+	}
+
+	key := req.URL.String()
+
+	c := s.cache
+	c.mutex.Lock()
+	cached, ok := c.entries[key]
+	if ok {
+		cached.lastUsed = time.Now()
+	}
+	c.mutex.Unlock()
+
+	if ok {
+		req.Header.Set("If-None-Match", cached.etag)
+	}
+
+	resp, err := s.sender.Do(req)
+	if ok {
+		// Retries send the same request again, so the condition is set
+		// again only if the response is still cached by then.
+		req.Header.Del("If-None-Match")
+	}
+	if err != nil || resp == nil {
+		return resp, err
+	}
+
+	if ok && resp.StatusCode == http.StatusNotModified {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+
+		c.mutex.Lock()
+		c.notModified++
+		c.mutex.Unlock()
+
+		return cachedResponse(resp, cached), nil
+	}
+
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" {
+		return resp, nil
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, conditionalCacheMaxBodySize+1))
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+	if len(body) > conditionalCacheMaxBodySize {
+		resp.Body = &readCloser{Reader: io.MultiReader(bytes.NewReader(body), resp.Body), Closer: resp.Body}
+		return resp, nil
+	}
+	_ = resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	c.mutex.Lock()
+	c.entries[key] = &conditionalCacheEntry{
+		body:     body,
+		etag:     etag,
+		header:   resp.Header.Clone(),
+		lastUsed: time.Now(),
+	}
+	c.evict()
+	c.mutex.Unlock()
+
+	return resp, nil
+}
+
+// ConditionalCacheStats returns the number of cached responses and the
+// number of responses served from the cache so far.
+func (f *Factory) ConditionalCacheStats() (entries int, notModified int64) {
+	c := f.conditional
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return len(c.entries), c.notModified
+}
+
+// cachedResponse returns the cached response of a request which got a 304
+// response. The headers of the 304 response take precedence, because they
+// carry the current rate limits.
+func cachedResponse(resp *http.Response, cached *conditionalCacheEntry) *http.Response {
+	header := cached.header.Clone()
+	for k, v := range resp.Header {
+		header[k] = v
+	}
+	header.Del("Content-Length")
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         resp.Proto,
+		ProtoMajor:    resp.ProtoMajor,
+		ProtoMinor:    resp.ProtoMinor,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(cached.body)),
+		ContentLength: int64(len(cached.body)),
+		Request:       resp.Request,
+	}
+}
+
+// evict removes the least recently used responses while there are too many.
+// The cache mutex must be held.
+func (c *conditionalCache) evict() {
+	for len(c.entries) > conditionalCacheMaxEntries {
+		var oldestKey string
+		var oldest time.Time
+		for key, entry := range c.entries {
+			if oldestKey == "" || entry.lastUsed.Before(oldest) {
+				oldestKey = key
+				oldest = entry.lastUsed
+			}
+		}
+
+		delete(c.entries, oldestKey)
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
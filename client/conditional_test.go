@@ -0,0 +1,121 @@
+package client
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type fakeResponse struct {
+	statusCode int
+	etag       string
+	body       string
+}
+
+// fakeSender returns the given responses in order and records the
+// If-None-Match header of every request.
+type fakeSender struct {
+	responses   []fakeResponse
+	ifNoneMatch []string
+}
+
+func (s *fakeSender) Do(req *http.Request) (*http.Response, error) {
+	s.ifNoneMatch = append(s.ifNoneMatch, req.Header.Get("If-None-Match"))
+
+	r := s.responses[0]
+	s.responses = s.responses[1:]
+
+	header := http.Header{}
+	if r.etag != "" {
+		header.Set("ETag", r.etag)
+	}
+
+	resp := &http.Response{
+		StatusCode: r.statusCode,
+		Header:     header,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte(r.body))),
+		Request:    req,
+	}
+
+	return resp, nil
+}
+
+func Test_conditionalSender(t *testing.T) {
+	testCases := []struct {
+		name                string
+		responses           []fakeResponse
+		expectedIfNoneMatch []string
+		expectedBodies      []string
+	}{
+		{
+			name: "case 0: not modified response is served from the cache",
+			responses: []fakeResponse{
+				{statusCode: http.StatusOK, etag: `"1"`, body: "nsg"},
+				{statusCode: http.StatusNotModified},
+			},
+			expectedIfNoneMatch: []string{"", `"1"`},
+			expectedBodies:      []string{"nsg", "nsg"},
+		},
+		{
+			name: "case 1: modified response replaces the cached one",
+			responses: []fakeResponse{
+				{statusCode: http.StatusOK, etag: `"1"`, body: "nsg"},
+				{statusCode: http.StatusOK, etag: `"2"`, body: "updated nsg"},
+				{statusCode: http.StatusNotModified},
+			},
+			expectedIfNoneMatch: []string{"", `"1"`, `"2"`},
+			expectedBodies:      []string{"nsg", "updated nsg", "updated nsg"},
+		},
+		{
+			name: "case 2: responses without ETag are not cached",
+			responses: []fakeResponse{
+				{statusCode: http.StatusOK, body: "vmss"},
+				{statusCode: http.StatusOK, body: "vmss"},
+			},
+			expectedIfNoneMatch: []string{"", ""},
+			expectedBodies:      []string{"vmss", "vmss"},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			fake := &fakeSender{responses: tc.responses}
+			sender := &conditionalSender{sender: fake, cache: newConditionalCache()}
+
+			var bodies []string
+			for range tc.expectedBodies {
+				req, err := http.NewRequest(http.MethodGet, "https://management.azure.com/subscriptions/1/resourceGroups/abc12/providers/Microsoft.Network/networkSecurityGroups/abc12-nsg", nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				resp, err := sender.Do(req)
+				if err != nil {
+					t.Fatalf("error == %#v, want nil", err)
+				}
+				if resp.StatusCode != http.StatusOK {
+					t.Fatalf("status code == %d, want %d", resp.StatusCode, http.StatusOK)
+				}
+
+				body, err := ioutil.ReadAll(resp.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				bodies = append(bodies, string(body))
+			}
+
+			if !cmp.Equal(fake.ifNoneMatch, tc.expectedIfNoneMatch) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedIfNoneMatch, fake.ifNoneMatch))
+			}
+			if !cmp.Equal(bodies, tc.expectedBodies) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedBodies, bodies))
+			}
+		})
+	}
+}
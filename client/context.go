@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// collectorContextKey is the key of the name of the collector the calls to
+// the Azure APIs are made for in the context of their requests.
+type collectorContextKey struct{}
+
+// WithCollector returns a copy of the given context carrying the name of the
+// collector the calls made with it are made for.
+func WithCollector(ctx context.Context, collector string) context.Context {
+	return context.WithValue(ctx, collectorContextKey{}, collector)
+}
+
+// budgetExemptContextKey is the key marking the calls which are made even
+// when the ARM call budget of their subscription is exhausted.
+type budgetExemptContextKey struct{}
+
+// WithBudgetExempt returns a copy of the given context whose ARM calls are
+// made even when the call budget of their subscription is exhausted, e.g. the
+// ones of critical collectors.
+func WithBudgetExempt(ctx context.Context) context.Context {
+	return context.WithValue(ctx, budgetExemptContextKey{}, true)
+}
+
+func budgetExemptFromContext(ctx context.Context) bool {
+	exempt, _ := ctx.Value(budgetExemptContextKey{}).(bool)
+	return exempt
+}
+
+// throttleBackoffContextKey is the key of how long calls to a subscription are
+// refused after Azure throttled a call to it.
+type throttleBackoffContextKey struct{}
+
+// WithThrottleBackoff returns a copy of the given context whose ARM calls to a
+// subscription are refused for the given backoff after Azure throttled a call
+// to it, e.g. the ones of low priority collectors. Without, calls are only
+// refused until Azure allows them to be retried.
+func WithThrottleBackoff(ctx context.Context, backoff time.Duration) context.Context {
+	return context.WithValue(ctx, throttleBackoffContextKey{}, backoff)
+}
+
+func throttleBackoffFromContext(ctx context.Context) (time.Duration, bool) {
+	backoff, ok := ctx.Value(throttleBackoffContextKey{}).(time.Duration)
+	return backoff, ok
+}
+
+// CollectorFromContext returns the name of the collector the calls made with
+// the given context are made for, or an empty string when they are not made
+// for a collector.
+func CollectorFromContext(ctx context.Context) string {
+	collector, _ := ctx.Value(collectorContextKey{}).(string)
+	return collector
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+
+	"github.com/giantswarm/azure-collector/v2/pkg/dryrun"
+)
+
+const (
+	// dryRunBody is the body of the responses to the calls which are not
+	// sent in a dry run. It is an empty list, so collectors don't call the
+	// APIs for any resources.
+	dryRunBody = `{"value":[]}`
+)
+
+// dryRunSender records the calls to the Azure APIs instead of sending them
+// when the dry run is enabled, and responds with empty lists. Token requests
+// are responded with a fake token, so calls are still authorized.
+type dryRunSender struct {
+	sender  autorest.Sender
+	service string
+}
+
+func (s *dryRunSender) Do(req *http.Request) (*http.Response, error) {
+	if !dryrun.Enabled() {
+		return s.sender.Do(req)
+	}
+
+	body := dryRunBody
+	if s.service == ServiceLogin {
+		expiresOn := time.Now().Add(time.Hour).Unix()
+		body = fmt.Sprintf(`{"access_token":"dry-run","token_type":"Bearer","expires_in":"3600","expires_on":"%d"}`, expiresOn)
+	} else {
+		c := dryrun.Call{
+			Collector: CollectorFromContext(req.Context()),
+			Service:   s.service,
+			Operation: Operation(req),
+		}
+
+		dryrun.Record(c)
+	}
+
+	resp := &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewBufferString(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+
+	return resp, nil
+}
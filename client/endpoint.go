@@ -0,0 +1,162 @@
+package client
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/Azure/go-autorest/autorest/azure/auth"
+	"github.com/giantswarm/microerror"
+)
+
+// EndpointConfig overrides the endpoints of the Azure public cloud, e.g. to
+// reach the Azure Resource Manager through a private endpoint or a forward
+// proxy.
+type EndpointConfig struct {
+	// ActiveDirectory is the login endpoint tokens are requested from, e.g.
+	// "https://login.microsoftonline.com/". The public cloud one is used
+	// when empty.
+	ActiveDirectory string
+	// ResourceManager is the endpoint of the Azure Resource Manager, e.g.
+	// "https://management.azure.com/". The public cloud one is used when
+	// empty. Tokens are still requested for the public cloud audience.
+	ResourceManager string
+	// KeyVaultDNSSuffix is the DNS suffix of the key vault data plane, e.g.
+	// "vault.azure.net". Tokens for key vaults are requested for the
+	// matching audience. The public cloud one is used when empty.
+	KeyVaultDNSSuffix string
+	// MicrosoftGraph is the endpoint of Microsoft Graph, e.g.
+	// "https://graph.microsoft.com/", which is also the audience tokens for
+	// it are requested for. The public cloud one is used when empty.
+	MicrosoftGraph string
+}
+
+// validateEndpoints returns an error when any of the configured endpoints is
+// invalid.
+func validateEndpoints(config EndpointConfig) error {
+	if config.ActiveDirectory != "" && !isEndpoint(config.ActiveDirectory) {
+		return microerror.Maskf(invalidConfigError, "%T.ActiveDirectory must be an absolute URL, got %#q", config, config.ActiveDirectory)
+	}
+	if config.ResourceManager != "" && !isEndpoint(config.ResourceManager) {
+		return microerror.Maskf(invalidConfigError, "%T.ResourceManager must be an absolute URL, got %#q", config, config.ResourceManager)
+	}
+	if strings.ContainsAny(config.KeyVaultDNSSuffix, ":/") {
+		return microerror.Maskf(invalidConfigError, "%T.KeyVaultDNSSuffix must be a DNS suffix, got %#q", config, config.KeyVaultDNSSuffix)
+	}
+	if config.MicrosoftGraph != "" && !isEndpoint(config.MicrosoftGraph) {
+		return microerror.Maskf(invalidConfigError, "%T.MicrosoftGraph must be an absolute URL, got %#q", config, config.MicrosoftGraph)
+	}
+
+	return nil
+}
+
+// NewClientCredentialsConfig works like auth.NewClientCredentialsConfig, but
+// uses the configured login endpoint.
+func (f *Factory) NewClientCredentialsConfig(clientID, clientSecret, tenantID string) auth.ClientCredentialsConfig {
+	credentials := auth.NewClientCredentialsConfig(clientID, clientSecret, tenantID)
+	credentials.AADEndpoint = f.activeDirectoryEndpoint()
+
+	return credentials
+}
+
+// NewAuthorizer works like auth.ClientCredentialsConfig.Authorizer, but
+// requests tokens with the configured HTTP client, so they are subject to the
+// same timeouts and trusted certificates as the API requests.
+func (f *Factory) NewAuthorizer(credentials auth.ClientCredentialsConfig) (autorest.Authorizer, error) {
+	if len(credentials.AuxTenants) == 0 {
+		token, err := credentials.ServicePrincipalToken()
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		token.SetSender(f.newSender(ServiceLogin))
+
+		return autorest.NewBearerAuthorizer(token), nil
+	}
+
+	token, err := credentials.MultiTenantServicePrincipalToken()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	token.PrimaryToken.SetSender(f.newSender(ServiceLogin))
+	for _, auxiliaryToken := range token.AuxiliaryTokens {
+		auxiliaryToken.SetSender(f.newSender(ServiceLogin))
+	}
+
+	return autorest.NewMultiTenantServicePrincipalTokenAuthorizer(token), nil
+}
+
+// RefreshToken requests a new token with the given credentials. It is meant
+// to check whether the credentials are still valid.
+func (f *Factory) RefreshToken(ctx context.Context, credentials auth.ClientCredentialsConfig) error {
+	token, err := credentials.ServicePrincipalToken()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	token.SetSender(f.newSender(ServiceLogin))
+
+	err = token.RefreshWithContext(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (f *Factory) activeDirectoryEndpoint() string {
+	if f.endpoints.ActiveDirectory != "" {
+		return f.endpoints.ActiveDirectory
+	}
+
+	return azure.PublicCloud.ActiveDirectoryEndpoint
+}
+
+// resourceManagerBaseURI returns the base URI of the clients sending requests
+// to the Azure Resource Manager. The given default base URI of the SDK
+// package is used unless a custom endpoint is configured.
+func (f *Factory) resourceManagerBaseURI(defaultBaseURI string) string {
+	if f.endpoints.ResourceManager != "" {
+		return strings.TrimSuffix(f.endpoints.ResourceManager, "/")
+	}
+
+	return defaultBaseURI
+}
+
+// KeyVaultBaseURL returns the data plane URL of the key vault with the given
+// name.
+func (f *Factory) KeyVaultBaseURL(name string) string {
+	return "https://" + name + "." + f.keyVaultDNSSuffix()
+}
+
+// keyVaultResource returns the audience of the tokens for key vaults.
+func (f *Factory) keyVaultResource() string {
+	return "https://" + f.keyVaultDNSSuffix()
+}
+
+func (f *Factory) keyVaultDNSSuffix() string {
+	if f.endpoints.KeyVaultDNSSuffix != "" {
+		return strings.Trim(f.endpoints.KeyVaultDNSSuffix, ".")
+	}
+
+	return azure.PublicCloud.KeyVaultDNSSuffix
+}
+
+// microsoftGraphEndpoint returns the endpoint of Microsoft Graph with a
+// trailing slash.
+func (f *Factory) microsoftGraphEndpoint() string {
+	if f.endpoints.MicrosoftGraph != "" {
+		return strings.TrimSuffix(f.endpoints.MicrosoftGraph, "/") + "/"
+	}
+
+	return MicrosoftGraphEndpoint
+}
+
+func isEndpoint(endpoint string) bool {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return false
+	}
+
+	return u.IsAbs() && u.Host != ""
+}
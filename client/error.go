@@ -1,6 +1,7 @@
 package client
 
 import (
+	"github.com/Azure/go-autorest/autorest"
 	"github.com/giantswarm/microerror"
 )
 
@@ -12,3 +13,35 @@ var invalidConfigError = &microerror.Error{
 func IsInvalidConfig(err error) bool {
 	return microerror.Cause(err) == invalidConfigError
 }
+
+var throttledError = &microerror.Error{
+	Kind: "throttledError",
+}
+
+// IsThrottled asserts throttledError, which is returned instead of calling a
+// subscription Azure recently throttled.
+func IsThrottled(err error) bool {
+	return microerror.Cause(err) == throttledError
+}
+
+var budgetExhaustedError = &microerror.Error{
+	Kind: "budgetExhaustedError",
+}
+
+// IsBudgetExhausted asserts budgetExhaustedError, which is returned instead of
+// calling a subscription whose ARM call budget is exhausted.
+func IsBudgetExhausted(err error) bool {
+	return microerror.Cause(err) == budgetExhaustedError
+}
+
+// SubscriptionFromError returns the lower cased subscription ID of the ARM
+// request which failed with the given error, or an empty string when it is
+// not an error of the Azure APIs.
+func SubscriptionFromError(err error) string {
+	dErr, ok := microerror.Cause(err).(autorest.DetailedError)
+	if !ok || dErr.Response == nil || dErr.Response.Request == nil {
+		return ""
+	}
+
+	return subscriptionFromPath(dErr.Response.Request.URL.Path)
+}
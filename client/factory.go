@@ -0,0 +1,216 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure/auth"
+	"github.com/giantswarm/microerror"
+)
+
+const (
+	// factoryCacheTTL is how long cached authorizers and client sets are
+	// kept without being used, e.g. after their credentials got rotated.
+	factoryCacheTTL = time.Hour
+)
+
+type FactoryConfig struct {
+	// HTTP configures the HTTP clients used to send requests to the Azure
+	// APIs.
+	HTTP HTTPConfig
+	// Endpoints overrides the endpoints of the Azure public cloud.
+	Endpoints EndpointConfig
+	// CallBudget is the maximum number of ARM calls per hour and
+	// subscription the collector is allowed to make. There is no limit when
+	// it is zero.
+	CallBudget int
+}
+
+// Factory creates the Azure clients and credentials. The clients it creates
+// share its HTTP transport, endpoints, ARM call budgets and conditional
+// cache. Authorizers and client sets are cached, so collectors share them
+// instead of creating their own on every collection.
+type Factory struct {
+	httpConfig HTTPConfig
+	transport  http.RoundTripper
+	endpoints  EndpointConfig
+
+	budget      *budget
+	conditional *conditionalCache
+
+	mutex           sync.Mutex
+	authorizerCache map[string]*authorizerCacheEntry
+	clientSetCache  map[clientSetCacheKey]*clientSetCacheEntry
+}
+
+type authorizerCacheEntry struct {
+	authorizer autorest.Authorizer
+	lastUsed   time.Time
+}
+
+// clientSetCacheKey identifies a client set by its subscription and
+// credentials. Cached authorizers are reused, so they are part of the key.
+type clientSetCacheKey struct {
+	authorizer       autorest.Authorizer
+	clientID         string
+	clientSecretHash string
+	gsTenantID       string
+	partnerID        string
+	subscriptionID   string
+	tenantID         string
+}
+
+type clientSetCacheEntry struct {
+	clientSet *AzureClientSet
+	lastUsed  time.Time
+}
+
+func NewFactory(config FactoryConfig) (*Factory, error) {
+	transport, err := newHTTPTransport(config.HTTP)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	err = validateEndpoints(config.Endpoints)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	if config.CallBudget < 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CallBudget must not be negative, got %d", config, config.CallBudget)
+	}
+
+	f := &Factory{
+		httpConfig: config.HTTP,
+		transport:  transport,
+		endpoints:  config.Endpoints,
+
+		budget:      newBudget(config.CallBudget),
+		conditional: newConditionalCache(),
+
+		authorizerCache: map[string]*authorizerCacheEntry{},
+		clientSetCache:  map[clientSetCacheKey]*clientSetCacheEntry{},
+	}
+
+	return f, nil
+}
+
+// GetAuthorizer returns the authorizer of the given credentials. Authorizers
+// are cached, so the tokens they acquired are reused across collections and
+// only refreshed shortly before they expire.
+func (f *Factory) GetAuthorizer(credentials auth.ClientCredentialsConfig) (autorest.Authorizer, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	now := time.Now()
+	f.evictExpired(now)
+
+	key := hash(
+		credentials.ClientID,
+		credentials.ClientSecret,
+		credentials.TenantID,
+		strings.Join(credentials.AuxTenants, ","),
+		credentials.AADEndpoint,
+		credentials.Resource,
+	)
+
+	entry, ok := f.authorizerCache[key]
+	if !ok {
+		authorizer, err := f.NewAuthorizer(credentials)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		entry = &authorizerCacheEntry{
+			authorizer: authorizer,
+		}
+		f.authorizerCache[key] = entry
+	}
+	entry.lastUsed = now
+
+	return entry.authorizer, nil
+}
+
+// GetAzureClientSet returns the Azure API clients of the given config. Client
+// sets are cached by the factory of the config per subscription and
+// credentials, so collectors share them instead of creating their own on
+// every collection.
+func GetAzureClientSet(config AzureClientSetConfig) (*AzureClientSet, error) {
+	if config.Factory == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Factory must not be empty", config)
+	}
+
+	f := config.Factory
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	now := time.Now()
+	f.evictExpired(now)
+
+	key := clientSetCacheKey{
+		authorizer:       config.Authorizer,
+		clientID:         config.ClientID,
+		clientSecretHash: hash(config.ClientSecret),
+		gsTenantID:       config.GSTenantID,
+		partnerID:        config.PartnerID,
+		subscriptionID:   config.SubscriptionID,
+		tenantID:         config.TenantID,
+	}
+
+	entry, ok := f.clientSetCache[key]
+	if !ok {
+		clientSet, err := NewAzureClientSet(config)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		entry = &clientSetCacheEntry{
+			clientSet: clientSet,
+		}
+		f.clientSetCache[key] = entry
+	}
+	entry.lastUsed = now
+
+	return entry.clientSet, nil
+}
+
+// CacheSizes returns the number of cached authorizers and client sets.
+func (f *Factory) CacheSizes() (authorizers int, clientSets int) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return len(f.authorizerCache), len(f.clientSetCache)
+}
+
+// evictExpired removes the cache entries which have not been used within the
+// cache TTL. The factory mutex must be held.
+func (f *Factory) evictExpired(now time.Time) {
+	for key, entry := range f.authorizerCache {
+		if now.Sub(entry.lastUsed) > factoryCacheTTL {
+			delete(f.authorizerCache, key)
+		}
+	}
+	for key, entry := range f.clientSetCache {
+		if now.Sub(entry.lastUsed) > factoryCacheTTL {
+			delete(f.clientSetCache, key)
+		}
+	}
+}
+
+// hash returns the hex encoded SHA-256 hash of the given values, so secrets
+// are not kept in cache keys.
+func hash(values ...string) string {
+	h := sha256.New()
+	for _, v := range values {
+		_, _ = h.Write([]byte(v))
+		_, _ = h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/giantswarm/microerror"
+)
+
+const (
+	// MicrosoftGraphEndpoint is both the base URI of Microsoft Graph in the
+	// public cloud and the resource tokens are requested for. It is used
+	// unless another endpoint is configured.
+	MicrosoftGraphEndpoint = "https://graph.microsoft.com/"
+
+	microsoftGraphVersion = "v1.0"
+)
+
+// GraphClient sends requests to Microsoft Graph. It replaces the Azure AD
+// Graph SDK clients, as the Azure AD Graph API is retired.
+type GraphClient struct {
+	autorest.Client
+	BaseURI string
+}
+
+// NewGraphClient creates a new GraphClient for the v1.0 Microsoft Graph API
+// of the given endpoint, which must have a trailing slash.
+func NewGraphClient(endpoint string) GraphClient {
+	return GraphClient{
+		Client:  autorest.NewClientWithUserAgent(autorest.UserAgent()),
+		BaseURI: endpoint + microsoftGraphVersion,
+	}
+}
+
+// GetJSON fetches the Microsoft Graph resource found at the given path and
+// unmarshals the response body into result. Like in the SDK, query
+// parameters like "$select" or "$filter" have to be encoded with
+// autorest.Encode.
+func (c GraphClient) GetJSON(ctx context.Context, path string, parameters map[string]interface{}, result interface{}) error {
+	decorators := []autorest.PrepareDecorator{
+		autorest.AsGet(),
+		autorest.WithBaseURL(c.BaseURI),
+		autorest.WithPath(path),
+	}
+	if len(parameters) > 0 {
+		decorators = append(decorators, autorest.WithQueryParameters(parameters))
+	}
+
+	preparer := autorest.CreatePreparer(decorators...)
+
+	return c.getJSON(ctx, preparer, "GetJSON", result)
+}
+
+// GetNextJSON fetches the next page of a collection. The "@odata.nextLink"
+// returned by Microsoft Graph already contains all query parameters.
+func (c GraphClient) GetNextJSON(ctx context.Context, nextLink string, result interface{}) error {
+	preparer := autorest.CreatePreparer(
+		autorest.AsGet(),
+		autorest.WithBaseURL(nextLink),
+	)
+
+	return c.getJSON(ctx, preparer, "GetNextJSON", result)
+}
+
+func (c GraphClient) getJSON(ctx context.Context, preparer autorest.Preparer, method string, result interface{}) error {
+	req, err := preparer.Prepare((&http.Request{}).WithContext(ctx))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	resp, err := c.Send(req, autorest.DoRetryForStatusCodes(c.RetryAttempts, c.RetryDuration, autorest.StatusCodesForRetry...))
+	if err != nil {
+		return microerror.Mask(autorest.NewErrorWithError(err, "client.GraphClient", method, resp, "Failure sending request"))
+	}
+
+	err = autorest.Respond(
+		resp,
+		autorest.WithErrorUnlessStatusCode(http.StatusOK),
+		autorest.ByUnmarshallingJSON(result),
+		autorest.ByClosing(),
+	)
+	if err != nil {
+		return microerror.Mask(autorest.NewErrorWithError(err, "client.GraphClient", method, resp, "Failure responding to request"))
+	}
+
+	return nil
+}
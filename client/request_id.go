@@ -0,0 +1,149 @@
+package client
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/pkg/logging"
+)
+
+const (
+	// clientRequestIDHeader is the header Azure APIs log the request ID
+	// chosen by the client in, which Azure support can look requests up by.
+	clientRequestIDHeader = "x-ms-client-request-id"
+	// returnClientRequestIDHeader asks Azure APIs to return the client
+	// request ID in the response.
+	returnClientRequestIDHeader = "x-ms-return-client-request-id"
+	// requestIDHeader is the header Azure APIs return their own request ID
+	// in.
+	requestIDHeader = "x-ms-request-id"
+)
+
+var (
+	requestDurationHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "azure_operator",
+		Subsystem: "api",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of the requests sent to the Azure APIs by service, method and status code. The client request IDs are attached as exemplars.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{
+		"service",
+		"method",
+		"code",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(requestDurationHistogram)
+}
+
+// requestIDSender attaches a client request ID to every request sent to the
+// Azure APIs, so Azure support tickets can reference the exact requests. The
+// ID is logged along with failed requests and attached as exemplar to the
+// request durations. Retries are sent with the ID of the first attempt.
+type requestIDSender struct {
+	sender autorest.Sender
+	// logger logs the failed requests. They are not logged when it is nil.
+	logger  micrologger.Logger
+	service string
+}
+
+func (s *requestIDSender) Do(req *http.Request) (*http.Response, error) {
+	id := req.Header.Get(clientRequestIDHeader)
+	if id == "" {
+		id = newRequestID()
+		req.Header.Set(clientRequestIDHeader, id)
+	}
+	req.Header.Set(returnClientRequestIDHeader, "true")
+
+	start := time.Now()
+	resp, err := s.sender.Do(req)
+	duration := time.Since(start)
+
+	code := "error"
+	if err == nil && resp != nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+
+	observer := requestDurationHistogram.WithLabelValues(s.service, req.Method, code)
+	if e, ok := observer.(prometheus.ExemplarObserver); ok {
+		e.ObserveWithExemplar(duration.Seconds(), prometheus.Labels{"client_request_id": id})
+	} else {
+		observer.Observe(duration.Seconds())
+	}
+
+	logFailedRequest(s.logger, req, resp, err, id)
+
+	return resp, err
+}
+
+// RequestIDsFromError returns the client request ID and the Azure request ID
+// of the request which failed with the given error. They are empty when it
+// is not an error of the Azure APIs.
+func RequestIDsFromError(err error) (clientRequestID string, requestID string) {
+	dErr, ok := microerror.Cause(err).(autorest.DetailedError)
+	if !ok || dErr.Response == nil {
+		return "", ""
+	}
+
+	if dErr.Response.Request != nil {
+		clientRequestID = dErr.Response.Request.Header.Get(clientRequestIDHeader)
+	}
+	requestID = dErr.Response.Header.Get(requestIDHeader)
+
+	return clientRequestID, requestID
+}
+
+// logFailedRequest logs the request IDs of requests which failed, or which
+// the Azure APIs responded to with an error other than 404 Not Found. Not
+// found resources are expected by collectors, e.g. for deleted clusters.
+func logFailedRequest(logger micrologger.Logger, req *http.Request, resp *http.Response, err error, id string) {
+	if logger == nil {
+		return
+	}
+
+	// The query is not logged, as it may contain SAS tokens.
+	keyVals := []interface{}{
+		"level", "warning",
+		"method", req.Method,
+		"host", req.URL.Host,
+		"path", req.URL.Path,
+		"clientrequestid", id,
+	}
+
+	switch {
+	case err != nil:
+		keyVals = append(keyVals,
+			"message", "request to the Azure API failed",
+			"error", logging.Redact(err.Error()),
+		)
+	case resp != nil && resp.StatusCode >= http.StatusBadRequest && resp.StatusCode != http.StatusNotFound:
+		keyVals = append(keyVals,
+			"message", fmt.Sprintf("Azure API responded with status %d", resp.StatusCode),
+			"requestid", resp.Header.Get(requestIDHeader),
+		)
+	default:
+		return
+	}
+
+	logger.LogCtx(req.Context(), keyVals...)
+}
+
+// newRequestID returns a random version 4 UUID.
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
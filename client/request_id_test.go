@@ -0,0 +1,130 @@
+package client
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/giantswarm/microerror"
+	"github.com/google/go-cmp/cmp"
+)
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+// headerSender records the client request ID headers of every request and
+// responds with 200 OK.
+type headerSender struct {
+	clientRequestIDs []string
+	returnIDs        []string
+}
+
+func (s *headerSender) Do(req *http.Request) (*http.Response, error) {
+	s.clientRequestIDs = append(s.clientRequestIDs, req.Header.Get(clientRequestIDHeader))
+	s.returnIDs = append(s.returnIDs, req.Header.Get(returnClientRequestIDHeader))
+
+	return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Request: req}, nil
+}
+
+func Test_requestIDSender(t *testing.T) {
+	testCases := []struct {
+		name       string
+		header     string
+		expectedID string
+	}{
+		{
+			name: "case 0: random ID is attached",
+		},
+		{
+			name:       "case 1: ID set by the caller is kept",
+			header:     "6b0d2e4c-1f4c-4a63-9f4e-6e5e2bd1c0a1",
+			expectedID: "6b0d2e4c-1f4c-4a63-9f4e-6e5e2bd1c0a1",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			fake := &headerSender{}
+			s := &requestIDSender{sender: fake, service: ServiceCompute}
+
+			req, err := http.NewRequest(http.MethodGet, "https://management.azure.com/subscriptions/sub/providers", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.header != "" {
+				req.Header.Set(clientRequestIDHeader, tc.header)
+			}
+
+			// Retries send the same request again.
+			for j := 0; j < 2; j++ {
+				_, err = s.Do(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			id := fake.clientRequestIDs[0]
+			if tc.expectedID != "" && id != tc.expectedID {
+				t.Fatalf("ID = %#q, want %#q", id, tc.expectedID)
+			}
+			if !uuidRegexp.MatchString(id) {
+				t.Fatalf("ID %#q is not a UUID", id)
+			}
+			if !cmp.Equal(fake.clientRequestIDs, []string{id, id}) {
+				t.Fatalf("\n\n%s\n", cmp.Diff([]string{id, id}, fake.clientRequestIDs))
+			}
+			if !cmp.Equal(fake.returnIDs, []string{"true", "true"}) {
+				t.Fatalf("\n\n%s\n", cmp.Diff([]string{"true", "true"}, fake.returnIDs))
+			}
+		})
+	}
+}
+
+func Test_RequestIDsFromError(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://management.azure.com/subscriptions/sub/providers", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(clientRequestIDHeader, "client-id")
+
+	resp := &http.Response{
+		StatusCode: http.StatusInternalServerError,
+		Header:     http.Header{"X-Ms-Request-Id": []string{"azure-id"}},
+		Request:    req,
+	}
+
+	testCases := []struct {
+		name                    string
+		err                     error
+		expectedClientRequestID string
+		expectedRequestID       string
+	}{
+		{
+			name:                    "case 0: IDs of a failed Azure API request",
+			err:                     microerror.Mask(autorest.NewErrorWithResponse("compute.VirtualMachinesClient", "List", resp, "Failure responding to request")),
+			expectedClientRequestID: "client-id",
+			expectedRequestID:       "azure-id",
+		},
+		{
+			name: "case 1: no IDs of other errors",
+			err:  microerror.Mask(invalidConfigError),
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			clientRequestID, requestID := RequestIDsFromError(tc.err)
+			if clientRequestID != tc.expectedClientRequestID {
+				t.Fatalf("client request ID = %#q, want %#q", clientRequestID, tc.expectedClientRequestID)
+			}
+			if requestID != tc.expectedRequestID {
+				t.Fatalf("request ID = %#q, want %#q", requestID, tc.expectedRequestID)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/giantswarm/microerror"
+)
+
+// RESTClient sends requests to the Azure Resource Manager using API versions
+// which are newer than the ones of the SDK packages we use. It is meant for
+// the few properties the vendored SDK does not know about yet.
+type RESTClient struct {
+	autorest.Client
+	BaseURI        string
+	SubscriptionID string
+}
+
+// NewRESTClient creates a new RESTClient sending requests for the given
+// subscription to the Azure Resource Manager at the given base URI.
+func NewRESTClient(baseURI, subscriptionID string) RESTClient {
+	return RESTClient{
+		Client:         autorest.NewClientWithUserAgent(autorest.UserAgent()),
+		BaseURI:        baseURI,
+		SubscriptionID: subscriptionID,
+	}
+}
+
+// GetJSON fetches the ARM resource found at the given path and unmarshals the
+// response body into result. The path is usually a resource ID.
+func (c RESTClient) GetJSON(ctx context.Context, path, apiVersion string, result interface{}) error {
+	return c.GetJSONWithParameters(ctx, path, apiVersion, nil, result)
+}
+
+// GetJSONWithParameters works like GetJSON, but sends the given query
+// parameters along, e.g. "$filter" or "$expand". Like in the SDK, values have
+// to be encoded with autorest.Encode.
+func (c RESTClient) GetJSONWithParameters(ctx context.Context, path, apiVersion string, parameters map[string]interface{}, result interface{}) error {
+	queryParameters := map[string]interface{}{
+		"api-version": apiVersion,
+	}
+	for k, v := range parameters {
+		queryParameters[k] = v
+	}
+
+	preparer := autorest.CreatePreparer(
+		autorest.AsGet(),
+		autorest.WithBaseURL(c.BaseURI),
+		autorest.WithPath(path),
+		autorest.WithQueryParameters(queryParameters),
+	)
+
+	return c.getJSON(ctx, preparer, "GetJSON", result)
+}
+
+// PostJSON sends a POST request to the given path and unmarshals the response
+// body into result. It is meant for ARM actions like queries. The body is
+// marshalled to JSON unless it is nil.
+func (c RESTClient) PostJSON(ctx context.Context, path, apiVersion string, body, result interface{}) error {
+	decorators := []autorest.PrepareDecorator{
+		autorest.AsPost(),
+		autorest.WithBaseURL(c.BaseURI),
+		autorest.WithPath(path),
+		autorest.WithQueryParameters(map[string]interface{}{
+			"api-version": apiVersion,
+		}),
+	}
+	if body != nil {
+		decorators = append(decorators, autorest.AsContentType("application/json; charset=utf-8"), autorest.WithJSON(body))
+	}
+
+	preparer := autorest.CreatePreparer(decorators...)
+
+	return c.getJSON(ctx, preparer, "PostJSON", result)
+}
+
+// GetNextJSON fetches the next page of a list response. The nextLink returned
+// by ARM already contains the API version.
+func (c RESTClient) GetNextJSON(ctx context.Context, nextLink string, result interface{}) error {
+	preparer := autorest.CreatePreparer(
+		autorest.AsGet(),
+		autorest.WithBaseURL(nextLink),
+	)
+
+	return c.getJSON(ctx, preparer, "GetNextJSON", result)
+}
+
+func (c RESTClient) getJSON(ctx context.Context, preparer autorest.Preparer, method string, result interface{}) error {
+	req, err := preparer.Prepare((&http.Request{}).WithContext(ctx))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	resp, err := c.Send(req, azure.DoRetryWithRegistration(c.Client))
+	if err != nil {
+		return microerror.Mask(autorest.NewErrorWithError(err, "client.RESTClient", method, resp, "Failure sending request"))
+	}
+
+	err = autorest.Respond(
+		resp,
+		azure.WithErrorUnlessStatusCode(http.StatusOK),
+		autorest.ByUnmarshallingJSON(result),
+		autorest.ByClosing(),
+	)
+	if err != nil {
+		// We wrap the error the same way the SDK does, so it can be
+		// inspected for the response status code.
+		return microerror.Mask(autorest.NewErrorWithError(err, "client.RESTClient", method, resp, "Failure responding to request"))
+	}
+
+	return nil
+}
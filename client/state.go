@@ -0,0 +1,156 @@
+package client
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/giantswarm/microerror"
+)
+
+// budgetState is the state of the call budgets and throttled subscriptions
+// which is persisted across restarts, so a restarted collector does not
+// resume calling subscriptions Azure is still throttling.
+type budgetState struct {
+	// Calls are the number of ARM calls per subscription and minute since
+	// the Unix epoch.
+	Calls          map[string]map[int64]int `json:"calls"`
+	ThrottledAt    map[string]time.Time     `json:"throttledAt"`
+	ThrottledUntil map[string]time.Time     `json:"throttledUntil"`
+}
+
+// LoadBudgetState restores the call budgets and throttled subscriptions from
+// the file at the given path as written by SaveBudgetState. Nothing is
+// restored when the file does not exist. Calls and throttles which expired in
+// the meantime are dropped.
+func (f *Factory) LoadBudgetState(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	var state budgetState
+	err = json.Unmarshal(b, &state)
+	if err != nil {
+		return microerror.Maskf(invalidConfigError, "invalid budget state in %#q: %s", path, err)
+	}
+
+	f.budget.restoreState(state, time.Now())
+
+	return nil
+}
+
+// SaveBudgetState writes the call budgets and throttled subscriptions to the
+// file at the given path. The file is replaced atomically, so it is never
+// read partially written.
+func (f *Factory) SaveBudgetState(path string) error {
+	b, err := json.Marshal(f.budget.currentState(time.Now()))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	defer os.Remove(file.Name()) // nolint: errcheck
+
+	_, err = file.Write(b)
+	if err != nil {
+		_ = file.Close()
+		return microerror.Mask(err)
+	}
+
+	err = file.Close()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	err = os.Rename(file.Name(), path)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func (b *budget) currentState(now time.Time) budgetState {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	state := budgetState{
+		Calls:          map[string]map[int64]int{},
+		ThrottledAt:    map[string]time.Time{},
+		ThrottledUntil: map[string]time.Time{},
+	}
+
+	minute := now.Unix() / 60
+	for subscriptionID, w := range b.calls {
+		for i := range w.counts {
+			if w.counts[i] == 0 || w.minutes[i] <= minute-budgetWindowMinutes {
+				continue
+			}
+			if state.Calls[subscriptionID] == nil {
+				state.Calls[subscriptionID] = map[int64]int{}
+			}
+			state.Calls[subscriptionID][w.minutes[i]] = w.counts[i]
+		}
+	}
+
+	// Throttles are only kept as long as calls are counted, which is longer
+	// than the backoff of any context.
+	for subscriptionID, at := range b.throttledAt {
+		if now.Sub(at) < budgetWindowMinutes*time.Minute {
+			state.ThrottledAt[subscriptionID] = at
+		}
+	}
+	for subscriptionID, until := range b.throttledUntil {
+		if now.Before(until) {
+			state.ThrottledUntil[subscriptionID] = until
+		}
+	}
+
+	return state
+}
+
+func (b *budget) restoreState(state budgetState, now time.Time) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	minute := now.Unix() / 60
+	for subscriptionID, counts := range state.Calls {
+		for m, count := range counts {
+			if m <= minute-budgetWindowMinutes || m > minute {
+				continue
+			}
+
+			w, ok := b.calls[subscriptionID]
+			if !ok {
+				w = &callWindow{}
+				b.calls[subscriptionID] = w
+			}
+
+			i := m % budgetWindowMinutes
+			if w.minutes[i] != m {
+				w.minutes[i] = m
+				w.counts[i] = 0
+			}
+			w.counts[i] += count
+		}
+	}
+
+	for subscriptionID, at := range state.ThrottledAt {
+		if now.Sub(at) < budgetWindowMinutes*time.Minute && at.After(b.throttledAt[subscriptionID]) {
+			b.throttledAt[subscriptionID] = at
+		}
+	}
+	for subscriptionID, until := range state.ThrottledUntil {
+		if now.Before(until) && until.After(b.throttledUntil[subscriptionID]) {
+			b.throttledUntil[subscriptionID] = until
+		}
+	}
+}
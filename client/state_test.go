@@ -0,0 +1,82 @@
+package client
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_budgetState(t *testing.T) {
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	minute := now.Unix() / 60
+
+	testCases := []struct {
+		name          string
+		state         budgetState
+		expectedState budgetState
+	}{
+		{
+			name: "case 0: calls and throttles are restored",
+			state: budgetState{
+				Calls: map[string]map[int64]int{
+					"sub1": {minute - 10: 3, minute: 2},
+				},
+				ThrottledAt: map[string]time.Time{
+					"sub1": now.Add(-time.Minute),
+				},
+				ThrottledUntil: map[string]time.Time{
+					"sub1": now.Add(5 * time.Minute),
+				},
+			},
+			expectedState: budgetState{
+				Calls: map[string]map[int64]int{
+					"sub1": {minute - 10: 3, minute: 2},
+				},
+				ThrottledAt: map[string]time.Time{
+					"sub1": now.Add(-time.Minute),
+				},
+				ThrottledUntil: map[string]time.Time{
+					"sub1": now.Add(5 * time.Minute),
+				},
+			},
+		},
+		{
+			name: "case 1: expired calls and throttles are dropped",
+			state: budgetState{
+				Calls: map[string]map[int64]int{
+					"sub1": {minute - budgetWindowMinutes: 3, minute - 1: 1},
+					"sub2": {minute - 2*budgetWindowMinutes: 7},
+				},
+				ThrottledAt: map[string]time.Time{
+					"sub1": now.Add(-2 * time.Hour),
+				},
+				ThrottledUntil: map[string]time.Time{
+					"sub1": now.Add(-time.Second),
+				},
+			},
+			expectedState: budgetState{
+				Calls: map[string]map[int64]int{
+					"sub1": {minute - 1: 1},
+				},
+				ThrottledAt:    map[string]time.Time{},
+				ThrottledUntil: map[string]time.Time{},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			b := newBudget(0)
+			b.restoreState(tc.state, now)
+
+			state := b.currentState(now)
+			if !cmp.Equal(state, tc.expectedState) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedState, state))
+			}
+		})
+	}
+}
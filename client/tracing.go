@@ -0,0 +1,53 @@
+package client
+
+import (
+	"net/http"
+
+	"github.com/Azure/go-autorest/autorest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/giantswarm/azure-collector/v2/pkg/logging"
+	"github.com/giantswarm/azure-collector/v2/pkg/tracing"
+)
+
+// tracingSender records a span for every request sent to the Azure APIs, as
+// child of the span of the collection sending it. Retries are recorded as
+// spans of their own. The query of the request URL is not recorded, as it
+// may contain SAS tokens.
+type tracingSender struct {
+	sender  autorest.Sender
+	service string
+}
+
+func (s *tracingSender) Do(req *http.Request) (*http.Response, error) {
+	attributes := []attribute.KeyValue{
+		semconv.HTTPMethodKey.String(req.Method),
+		semconv.HTTPHostKey.String(req.URL.Host),
+		semconv.HTTPTargetKey.String(req.URL.Path),
+		attribute.String("azure.service", s.service),
+		attribute.String("azure.client_request_id", req.Header.Get(clientRequestIDHeader)),
+	}
+	if subscriptionID := subscriptionFromPath(req.URL.Path); subscriptionID != "" {
+		attributes = append(attributes, attribute.String("azure.subscription", subscriptionID))
+	}
+
+	ctx, span := tracing.Tracer().Start(req.Context(), req.Method+" "+req.URL.Host,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attributes...),
+	)
+	defer span.End()
+
+	resp, err := s.sender.Do(req.WithContext(ctx))
+	if err != nil {
+		span.SetStatus(codes.Error, logging.Redact(err.Error()))
+		return resp, err
+	}
+
+	span.SetAttributes(semconv.HTTPStatusCodeKey.Int(resp.StatusCode))
+	span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(resp.StatusCode))
+
+	return resp, nil
+}
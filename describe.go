@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/giantswarm/apiextensions/v2/pkg/clientset/versioned"
+	g8sfake "github.com/giantswarm/apiextensions/v2/pkg/clientset/versioned/fake"
+	"github.com/giantswarm/k8sclient/v4/pkg/k8sclient"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/service/collector"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+// Formats the metric descriptors are written in.
+const (
+	describeFormatJSON     = "json"
+	describeFormatMarkdown = "markdown"
+)
+
+// describeK8sClient provides the collectors with Kubernetes clients which are
+// never called, so metrics can be described without a cluster. Other clients
+// are not used when creating the collectors.
+type describeK8sClient struct {
+	k8sclient.Interface
+
+	ctrlClient ctrlclient.Client
+	g8sClient  versioned.Interface
+	k8sClient  kubernetes.Interface
+}
+
+func (c *describeK8sClient) CtrlClient() ctrlclient.Client {
+	return c.ctrlClient
+}
+
+func (c *describeK8sClient) G8sClient() versioned.Interface {
+	return c.g8sClient
+}
+
+func (c *describeK8sClient) K8sClient() kubernetes.Interface {
+	return c.k8sClient
+}
+
+// newDescribeCommand returns the command writing the descriptors of the
+// metrics of all collectors, e.g. to generate docs and dashboards. Metrics
+// depending on the configuration, like the cost tag labels, are described as
+// configured by the same flags as the daemon.
+func newDescribeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "describe",
+		Short: "Describe the metrics of all collectors.",
+		Long:  "Describe the name, help, labels, collector and stability level of the metrics of all collectors.",
+	}
+
+	format := cmd.Flags().String("format", describeFormatJSON, "Format of the descriptors, either json or markdown.")
+	costTagLabels := cmd.Flags().StringSlice(f.Service.Collector.Cost.TagLabels, []string{}, "Azure resource tags which are exposed as labels of the cost metrics, e.g. cost-center.")
+	monitorMetricsConfigFile := cmd.Flags().String(f.Service.Collector.MonitorMetrics.ConfigFile, "", "Path of the YAML file defining the Azure Monitor metrics to expose. When empty no such metrics are described.")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		err := describe(os.Stdout, *format, *costTagLabels, *monitorMetricsConfigFile)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		return nil
+	}
+
+	return cmd
+}
+
+func describe(w io.Writer, format string, costTagLabels []string, monitorMetricsConfigFile string) error {
+	if format != describeFormatJSON && format != describeFormatMarkdown {
+		return microerror.Maskf(invalidFlagError, "format must be %s or %s, got %#q", describeFormatJSON, describeFormatMarkdown, format)
+	}
+
+	// The logs of the collectors must not end up in the descriptors.
+	logger, err := micrologger.New(micrologger.Config{
+		IOWriter: os.Stderr,
+	})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	var set *collector.Set
+	{
+		k8sClient := &describeK8sClient{
+			ctrlClient: ctrlfake.NewFakeClient(),
+			g8sClient:  g8sfake.NewSimpleClientset(),
+			k8sClient:  k8sfake.NewSimpleClientset(),
+		}
+
+		clientFactory, err := client.NewFactory(client.FactoryConfig{})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		credentialSource, err := credential.NewSource(credential.SourceConfig{
+			Backend:       credential.NewKubernetesBackend(k8sClient.K8sClient(), k8sClient.G8sClient()),
+			ClientFactory: clientFactory,
+		})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		c := collector.SetConfig{
+			CredentialSource: credentialSource,
+			K8sClient:        k8sClient,
+			Logger:           logger,
+
+			// The settings required to create the collectors do not
+			// change their metrics.
+			ControlPlaneResourceGroup: "describe",
+			GSTenantID:                "describe",
+			Location:                  "westeurope",
+			RoleAssignmentsLimit:      4000,
+
+			CostTagLabels:            costTagLabels,
+			MonitorMetricsConfigFile: monitorMetricsConfigFile,
+		}
+
+		set, err = collector.NewSet(c)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	descriptors, err := set.Descriptors()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	switch format {
+	case describeFormatJSON:
+		e := json.NewEncoder(w)
+		e.SetIndent("", "  ")
+
+		err = e.Encode(descriptors)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	case describeFormatMarkdown:
+		fmt.Fprintln(w, "| Name | Help | Labels | Collector | Stability |")
+		fmt.Fprintln(w, "| --- | --- | --- | --- | --- |")
+		for _, d := range descriptors {
+			fmt.Fprintf(w, "| `%s` | %s | %s | `%s` | %s |\n", d.Name, strings.ReplaceAll(d.Help, "|", `\|`), strings.Join(d.Labels, ", "), d.Collector, d.Stability)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,7 @@
+package audit
+
+type Audit struct {
+	MaxBackups string
+	MaxSize    string
+	Output     string
+}
@@ -1,10 +1,34 @@
 package azure
 
 type Azure struct {
+	CAFile         string
+	CallBudget     string
 	ClientID       string
 	ClientSecret   string
+	Endpoints      Endpoints
 	PartnerID      string
+	Proxy          Proxy
 	SubscriptionID string
 	TenantID       string
 	SPTenantID     string
+	StateFile      string
+	Timeout        Timeout
+}
+
+type Endpoints struct {
+	ActiveDirectory   string
+	KeyVaultDNSSuffix string
+	MicrosoftGraph    string
+	ResourceManager   string
+}
+
+type Proxy struct {
+	HTTP    string
+	HTTPS   string
+	NoProxy string
+}
+
+type Timeout struct {
+	Default  string
+	Services string
 }
@@ -0,0 +1,41 @@
+package collector
+
+type Collector struct {
+	Cost                Cost
+	DiagnosticSettings  DiagnosticSettings
+	MonitorMetrics      MonitorMetrics
+	ResourceGroups      ResourceGroups
+	RoleAssignments     RoleAssignments
+	ShutdownGracePeriod string
+	StartSpread         string
+	SuccessRatioWindows string
+	TagCompliance       TagCompliance
+	Timeout             string
+}
+
+type Cost struct {
+	TagLabels string
+}
+
+type DiagnosticSettings struct {
+	Destination   string
+	ResourceTypes string
+}
+
+type MonitorMetrics struct {
+	ConfigFile string
+}
+
+type ResourceGroups struct {
+	Exclude string
+	Include string
+	Tags    string
+}
+
+type RoleAssignments struct {
+	Limit string
+}
+
+type TagCompliance struct {
+	RequiredTags string
+}
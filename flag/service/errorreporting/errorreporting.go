@@ -0,0 +1,7 @@
+package errorreporting
+
+type ErrorReporting struct {
+	DSN              string
+	Environment      string
+	FailureThreshold string
+}
@@ -0,0 +1,5 @@
+package grpchealth
+
+type GRPCHealth struct {
+	Address string
+}
@@ -0,0 +1,8 @@
+package log
+
+type Log struct {
+	CollectorLevels string
+	DedupInterval   string
+	Format          string
+	Level           string
+}
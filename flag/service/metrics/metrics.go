@@ -0,0 +1,16 @@
+package metrics
+
+type Metrics struct {
+	ConstLabels  string
+	DropLabels   string
+	HashLabels   string
+	RenameLabels string
+	TLS          TLS
+}
+
+type TLS struct {
+	Address string
+	CAFile  string
+	CrtFile string
+	KeyFile string
+}
@@ -0,0 +1,6 @@
+package runtimeconfig
+
+type RuntimeConfig struct {
+	Name      string
+	Namespace string
+}
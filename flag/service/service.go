@@ -3,12 +3,32 @@ package service
 import (
 	"github.com/giantswarm/operatorkit/v2/pkg/flag/service/kubernetes"
 
+	"github.com/giantswarm/azure-collector/v2/flag/service/audit"
 	"github.com/giantswarm/azure-collector/v2/flag/service/azure"
+	"github.com/giantswarm/azure-collector/v2/flag/service/collector"
+	"github.com/giantswarm/azure-collector/v2/flag/service/errorreporting"
+	"github.com/giantswarm/azure-collector/v2/flag/service/grpchealth"
+	"github.com/giantswarm/azure-collector/v2/flag/service/log"
+	"github.com/giantswarm/azure-collector/v2/flag/service/metrics"
+	"github.com/giantswarm/azure-collector/v2/flag/service/runtimeconfig"
+	"github.com/giantswarm/azure-collector/v2/flag/service/standalone"
+	"github.com/giantswarm/azure-collector/v2/flag/service/tracing"
 )
 
 type Service struct {
+	Audit                     audit.Audit
 	Azure                     azure.Azure
+	Collector                 collector.Collector
 	ControlPlaneResourceGroup string
+	DryRun                    string
+	ErrorReporting            errorreporting.ErrorReporting
+	FeatureGates              string
+	GRPCHealth                grpchealth.GRPCHealth
 	Kubernetes                kubernetes.Kubernetes
 	Location                  string
+	Log                       log.Log
+	Metrics                   metrics.Metrics
+	RuntimeConfig             runtimeconfig.RuntimeConfig
+	Standalone                standalone.Standalone
+	Tracing                   tracing.Tracing
 }
@@ -0,0 +1,8 @@
+package standalone
+
+type Standalone struct {
+	CredentialsFile string
+	Enabled         string
+	ResourceGroups  string
+	SubscriptionIDs string
+}
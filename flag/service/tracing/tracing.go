@@ -0,0 +1,7 @@
+package tracing
+
+type Tracing struct {
+	Endpoint    string
+	Insecure    string
+	SampleRatio string
+}
@@ -33,3 +33,15 @@ Selector labels
 app.kubernetes.io/name: {{ include "azure-collector.name" . | quote }}
 app.kubernetes.io/instance: {{ .Release.Name | quote }}
 {{- end -}}
+
+{{/*
+Proxy configuration, which is kept in the Secret as the proxy URLs may hold
+credentials.
+*/}}
+{{- define "azure-collector.proxyConfig" -}}
+service:
+  azure:
+    proxy:
+      http: '{{ .Values.azure.proxy.http }}'
+      https: '{{ .Values.azure.proxy.https }}'
+{{- end -}}
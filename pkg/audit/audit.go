@@ -0,0 +1,144 @@
+// Package audit writes a record of every call sent to the Azure APIs, as
+// required by some customers for the compliance of read access to their
+// subscriptions.
+package audit
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+)
+
+const (
+	// OutputStdout writes the records to the standard output, along with
+	// the logs.
+	OutputStdout = "stdout"
+)
+
+var (
+	auditMutex sync.Mutex
+	auditOut   io.Writer
+)
+
+// Config configures where the records are written to.
+type Config struct {
+	// Output is either "stdout" or the path of the file the records are
+	// written to. No records are written when it is empty.
+	Output string
+	// MaxSize is the size in bytes after which the file is rotated. It is
+	// never rotated when zero.
+	MaxSize int64
+	// MaxBackups is the number of rotated files which are kept, e.g.
+	// audit.log.1 to audit.log.5.
+	MaxBackups int
+}
+
+// Record is the record of a single call. Calls which did not get a response
+// have the status "error".
+type Record struct {
+	Time            time.Time `json:"time"`
+	Subscription    string    `json:"subscription,omitempty"`
+	Service         string    `json:"service"`
+	Operation       string    `json:"operation,omitempty"`
+	Method          string    `json:"method"`
+	Host            string    `json:"host"`
+	Path            string    `json:"path"`
+	Status          string    `json:"status"`
+	Duration        float64   `json:"duration"`
+	ClientRequestID string    `json:"clientrequestid,omitempty"`
+}
+
+// Configure configures the output of the records. It is meant to be called
+// once on startup.
+func Configure(config Config) error {
+	if config.MaxSize < 0 {
+		return microerror.Maskf(invalidConfigError, "%T.MaxSize must not be negative", config)
+	}
+	if config.MaxBackups < 0 {
+		return microerror.Maskf(invalidConfigError, "%T.MaxBackups must not be negative", config)
+	}
+
+	var out io.Writer
+	switch config.Output {
+	case "":
+	case OutputStdout:
+		out = os.Stdout
+	default:
+		f, err := newRotatingFile(config.Output, config.MaxSize, config.MaxBackups)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		out = f
+	}
+
+	auditMutex.Lock()
+	defer auditMutex.Unlock()
+
+	if f, ok := auditOut.(*rotatingFile); ok {
+		_ = f.Close()
+	}
+	auditOut = out
+
+	return nil
+}
+
+// Close closes the file the records are written to. No records are written
+// anymore afterwards. It is meant to be called once on shutdown.
+func Close() error {
+	auditMutex.Lock()
+	defer auditMutex.Unlock()
+
+	// The standard output is left open.
+	f, ok := auditOut.(*rotatingFile)
+	auditOut = nil
+	if ok {
+		err := f.Close()
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	return nil
+}
+
+// Enabled returns whether records are written.
+func Enabled() bool {
+	auditMutex.Lock()
+	defer auditMutex.Unlock()
+
+	return auditOut != nil
+}
+
+// Write writes the given record as a line of JSON. Records are marked with
+// "audit": true, so they can be told apart from the logs written to the
+// standard output.
+func Write(r Record) error {
+	auditMutex.Lock()
+	defer auditMutex.Unlock()
+
+	if auditOut == nil {
+		return nil
+	}
+
+	b, err := json.Marshal(struct {
+		Audit bool `json:"audit"`
+		Record
+	}{
+		Audit:  true,
+		Record: r,
+	})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	_, err = auditOut.Write(append(b, '\n'))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
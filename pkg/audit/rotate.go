@@ -0,0 +1,111 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/giantswarm/microerror"
+)
+
+// rotatingFile appends to the file at the given path and rotates it once it
+// grew beyond its maximum size. Rotated files are renamed to path.1, path.2
+// and so on, the oldest ones being removed.
+type rotatingFile struct {
+	file       *os.File
+	maxBackups int
+	maxSize    int64
+	path       string
+	size       int64
+}
+
+func newRotatingFile(path string, maxSize int64, maxBackups int) (*rotatingFile, error) {
+	r := &rotatingFile{
+		maxBackups: maxBackups,
+		maxSize:    maxSize,
+		path:       path,
+	}
+
+	err := r.open()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return r, nil
+}
+
+// Write writes the given record. Records are not split across files.
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		err := r.rotate()
+		if err != nil {
+			return 0, microerror.Mask(err)
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	if err != nil {
+		return n, microerror.Mask(err)
+	}
+
+	return n, nil
+}
+
+func (r *rotatingFile) Close() error {
+	return r.file.Close()
+}
+
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return microerror.Mask(err)
+	}
+
+	r.file = f
+	r.size = info.Size()
+
+	return nil
+}
+
+func (r *rotatingFile) rotate() error {
+	err := r.file.Close()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if r.maxBackups == 0 {
+		err = os.Remove(r.path)
+		if err != nil && !os.IsNotExist(err) {
+			return microerror.Mask(err)
+		}
+	} else {
+		for i := r.maxBackups - 1; i > 0; i-- {
+			err = os.Rename(backupPath(r.path, i), backupPath(r.path, i+1))
+			if err != nil && !os.IsNotExist(err) {
+				return microerror.Mask(err)
+			}
+		}
+
+		err = os.Rename(r.path, backupPath(r.path, 1))
+		if err != nil && !os.IsNotExist(err) {
+			return microerror.Mask(err)
+		}
+	}
+
+	err = r.open()
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+func backupPath(path string, i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
@@ -0,0 +1,106 @@
+package audit
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_rotatingFile(t *testing.T) {
+	testCases := []struct {
+		name          string
+		maxSize       int64
+		maxBackups    int
+		records       []string
+		expectedFiles map[string]string
+	}{
+		{
+			name:       "case 0: file is not rotated below the max size",
+			maxSize:    10,
+			maxBackups: 2,
+			records:    []string{"a\n", "b\n"},
+			expectedFiles: map[string]string{
+				"audit.log": "a\nb\n",
+			},
+		},
+		{
+			name:       "case 1: file is rotated beyond the max size",
+			maxSize:    4,
+			maxBackups: 2,
+			records:    []string{"a\n", "b\n", "c\n", "d\n", "e\n"},
+			expectedFiles: map[string]string{
+				"audit.log":   "e\n",
+				"audit.log.1": "c\nd\n",
+				"audit.log.2": "a\nb\n",
+			},
+		},
+		{
+			name:       "case 2: oldest files are removed",
+			maxSize:    2,
+			maxBackups: 1,
+			records:    []string{"a\n", "b\n", "c\n"},
+			expectedFiles: map[string]string{
+				"audit.log":   "c\n",
+				"audit.log.1": "b\n",
+			},
+		},
+		{
+			name:       "case 3: file is truncated without backups",
+			maxSize:    2,
+			maxBackups: 0,
+			records:    []string{"a\n", "b\n"},
+			expectedFiles: map[string]string{
+				"audit.log": "b\n",
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			dir, err := ioutil.TempDir("", "audit")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			f, err := newRotatingFile(filepath.Join(dir, "audit.log"), tc.maxSize, tc.maxBackups)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, r := range tc.records {
+				_, err = f.Write([]byte(r))
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			err = f.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			infos, err := ioutil.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			files := map[string]string{}
+			for _, info := range infos {
+				b, err := ioutil.ReadFile(filepath.Join(dir, info.Name()))
+				if err != nil {
+					t.Fatal(err)
+				}
+				files[info.Name()] = string(b)
+			}
+
+			if !cmp.Equal(files, tc.expectedFiles) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedFiles, files))
+			}
+		})
+	}
+}
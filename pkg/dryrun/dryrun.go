@@ -0,0 +1,120 @@
+// Package dryrun records the calls to the Azure APIs the collectors would
+// make instead of sending them, so the rate limit impact of collectors can be
+// estimated before enabling them.
+package dryrun
+
+import (
+	"sort"
+	"sync"
+)
+
+const (
+	// CollectorBackground is the collector of the calls which are not made
+	// by a collector, e.g. the ones of the resource group filter.
+	CollectorBackground = "background"
+)
+
+var (
+	dryRunMutex sync.Mutex
+	enabled     bool
+	calls       = map[Call]int{}
+	collections = map[string]int{}
+)
+
+// Call is an operation of an Azure API a collector would call.
+type Call struct {
+	Collector string
+	Service   string
+	Operation string
+}
+
+// PlannedCall is an operation of an Azure API along with the number of
+// times it would be called.
+type PlannedCall struct {
+	Call
+	// Calls is the number of calls recorded since the start.
+	Calls int
+	// PerCollection is the average number of calls per collection of the
+	// collector. It is zero for the calls of the background collector.
+	PerCollection float64
+}
+
+// Configure enables or disables the dry run. It is meant to be called once on
+// startup.
+func Configure(enable bool) {
+	dryRunMutex.Lock()
+	defer dryRunMutex.Unlock()
+
+	enabled = enable
+}
+
+// Enabled returns whether the calls to the Azure APIs are only recorded
+// instead of being sent.
+func Enabled() bool {
+	dryRunMutex.Lock()
+	defer dryRunMutex.Unlock()
+
+	return enabled
+}
+
+// StartCollection counts a collection of the given collector, so the calls
+// per collection can be estimated.
+func StartCollection(collector string) {
+	dryRunMutex.Lock()
+	defer dryRunMutex.Unlock()
+
+	if !enabled {
+		return
+	}
+
+	collections[collector]++
+}
+
+// Record records the given call. Calls of an empty collector are recorded
+// as calls of the background collector.
+func Record(call Call) {
+	dryRunMutex.Lock()
+	defer dryRunMutex.Unlock()
+
+	if !enabled {
+		return
+	}
+
+	if call.Collector == "" {
+		call.Collector = CollectorBackground
+	}
+
+	calls[call]++
+}
+
+// Plan returns the calls recorded so far ordered by collector, service and
+// operation.
+func Plan() []PlannedCall {
+	dryRunMutex.Lock()
+	defer dryRunMutex.Unlock()
+
+	var plan []PlannedCall
+	for call, n := range calls {
+		p := PlannedCall{
+			Call:  call,
+			Calls: n,
+		}
+		if c := collections[call.Collector]; c > 0 {
+			p.PerCollection = float64(n) / float64(c)
+		}
+
+		plan = append(plan, p)
+	}
+
+	sort.Slice(plan, func(i, j int) bool {
+		if plan[i].Collector != plan[j].Collector {
+			return plan[i].Collector < plan[j].Collector
+		}
+		if plan[i].Service != plan[j].Service {
+			return plan[i].Service < plan[j].Service
+		}
+		return plan[i].Operation < plan[j].Operation
+	})
+
+	return plan
+}
@@ -0,0 +1,82 @@
+package dryrun
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Plan(t *testing.T) {
+	testCases := []struct {
+		name         string
+		enabled      bool
+		collections  []string
+		calls        []Call
+		expectedPlan []PlannedCall
+	}{
+		{
+			name:        "case 0: nothing is recorded when disabled",
+			enabled:     false,
+			collections: []string{"deployment"},
+			calls: []Call{
+				{Collector: "deployment", Service: "resources", Operation: "Microsoft.Resources/deployments/read"},
+			},
+			expectedPlan: nil,
+		},
+		{
+			name:        "case 1: calls are estimated per collection",
+			enabled:     true,
+			collections: []string{"deployment", "deployment", "node_vmss"},
+			calls: []Call{
+				{Collector: "node_vmss", Service: "compute", Operation: "Microsoft.Compute/virtualMachineScaleSets/read"},
+				{Collector: "deployment", Service: "resources", Operation: "Microsoft.Resources/deployments/read"},
+				{Collector: "deployment", Service: "resources", Operation: "Microsoft.Resources/deployments/read"},
+				{Collector: "deployment", Service: "resources", Operation: "Microsoft.Resources/deployments/read"},
+				{Collector: "", Service: "resources", Operation: "Microsoft.ResourceGraph/resources/action"},
+			},
+			expectedPlan: []PlannedCall{
+				{
+					Call:  Call{Collector: "background", Service: "resources", Operation: "Microsoft.ResourceGraph/resources/action"},
+					Calls: 1,
+				},
+				{
+					Call:          Call{Collector: "deployment", Service: "resources", Operation: "Microsoft.Resources/deployments/read"},
+					Calls:         3,
+					PerCollection: 1.5,
+				},
+				{
+					Call:          Call{Collector: "node_vmss", Service: "compute", Operation: "Microsoft.Compute/virtualMachineScaleSets/read"},
+					Calls:         1,
+					PerCollection: 1,
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			dryRunMutex.Lock()
+			calls = map[Call]int{}
+			collections = map[string]int{}
+			dryRunMutex.Unlock()
+
+			Configure(tc.enabled)
+			defer Configure(false)
+
+			for _, c := range tc.collections {
+				StartCollection(c)
+			}
+			for _, c := range tc.calls {
+				Record(c)
+			}
+
+			plan := Plan()
+			if !cmp.Equal(plan, tc.expectedPlan) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedPlan, plan))
+			}
+		})
+	}
+}
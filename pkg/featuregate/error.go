@@ -0,0 +1,14 @@
+package featuregate
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var invalidFeatureGateError = &microerror.Error{
+	Kind: "invalidFeatureGateError",
+}
+
+// IsInvalidFeatureGate asserts invalidFeatureGateError.
+func IsInvalidFeatureGate(err error) bool {
+	return microerror.Cause(err) == invalidFeatureGateError
+}
@@ -0,0 +1,149 @@
+// Package featuregate controls experimental collectors and behaviors, so new
+// metrics can be rolled out gradually without separate builds. Like in
+// Kubernetes, alpha features are disabled by default, beta features are
+// enabled by default and GA features are always enabled.
+package featuregate
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/giantswarm/microerror"
+)
+
+// Stage is the maturity of a feature.
+type Stage string
+
+const (
+	// StageAlpha features are disabled unless enabled explicitly. They may
+	// change or be removed in any release.
+	StageAlpha Stage = "alpha"
+	// StageBeta features are enabled unless disabled explicitly.
+	StageBeta Stage = "beta"
+	// StageGA features are always enabled. Their gate is only kept so
+	// existing configurations stay valid.
+	StageGA Stage = "ga"
+)
+
+// Features.
+const (
+	// ClusterFanOut collects the clusters of a collector concurrently.
+	// Clusters are collected one after another when disabled.
+	ClusterFanOut = "ClusterFanOut"
+	// GuestDiskUsage enables the GuestDiskUsage collector, which queries the
+	// Log Analytics logs of every cluster.
+	GuestDiskUsage = "GuestDiskUsage"
+	// LastKnownGood serves the metrics of the last complete collection of
+	// collectors whose collection runs into its deadline.
+	LastKnownGood = "LastKnownGood"
+	// NodeVMSS enables the NodeVMSS collector, which lists every VMSS
+	// instance of every cluster.
+	NodeVMSS = "NodeVMSS"
+)
+
+// Gate is the gate of a feature.
+type Gate struct {
+	Name        string
+	Stage       Stage
+	Description string
+}
+
+var (
+	// Gates are the gates of all features ordered by name.
+	Gates = []Gate{
+		{Name: ClusterFanOut, Stage: StageBeta, Description: "Collect the clusters of a collector concurrently."},
+		{Name: GuestDiskUsage, Stage: StageAlpha, Description: "Expose the OS disk usage of nodes reported by the Azure Monitor agent with VM insights."},
+		{Name: LastKnownGood, Stage: StageBeta, Description: "Serve the metrics of the last complete collection of collectors whose collection runs into its deadline."},
+		{Name: NodeVMSS, Stage: StageBeta, Description: "Map Kubernetes nodes to the VMSS instances backing them, listing every instance of every cluster."},
+	}
+
+	featureGatesMutex sync.RWMutex
+	enabled           = defaults(Gates)
+)
+
+// Configure enables and disables the features as given in the form
+// name=enabled, e.g. NodeVMSS=false. Features not given are enabled unless
+// alpha. It is meant to be called once on startup.
+func Configure(values []string) error {
+	e, err := parse(Gates, values)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	featureGatesMutex.Lock()
+	defer featureGatesMutex.Unlock()
+
+	enabled = e
+
+	return nil
+}
+
+// Enabled returns whether the given feature is enabled.
+func Enabled(name string) bool {
+	featureGatesMutex.RLock()
+	defer featureGatesMutex.RUnlock()
+
+	return enabled[name]
+}
+
+// Status returns the enabled features in the form name=enabled ordered by
+// name, e.g. for logging them on startup.
+func Status() []string {
+	featureGatesMutex.RLock()
+	defer featureGatesMutex.RUnlock()
+
+	var status []string
+	for name, e := range enabled {
+		status = append(status, name+"="+strconv.FormatBool(e))
+	}
+	sort.Strings(status)
+
+	return status
+}
+
+func defaults(gates []Gate) map[string]bool {
+	enabled := map[string]bool{}
+	for _, g := range gates {
+		enabled[g.Name] = g.Stage != StageAlpha
+	}
+
+	return enabled
+}
+
+// parse returns whether every one of the given gates is enabled as configured
+// by the given values. Unknown features and disabled GA features are
+// rejected.
+func parse(gates []Gate, values []string) (map[string]bool, error) {
+	stages := map[string]Stage{}
+	for _, g := range gates {
+		stages[g.Name] = g.Stage
+	}
+
+	enabled := defaults(gates)
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 {
+			return nil, microerror.Maskf(invalidFeatureGateError, "feature gate %#q must be in the form name=enabled", value)
+		}
+
+		name := strings.TrimSpace(parts[0])
+		stage, ok := stages[name]
+		if !ok {
+			return nil, microerror.Maskf(invalidFeatureGateError, "unknown feature %#q", name)
+		}
+
+		e, err := strconv.ParseBool(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, microerror.Maskf(invalidFeatureGateError, "feature gate %#q must be either true or false", value)
+		}
+		if stage == StageGA && !e {
+			return nil, microerror.Maskf(invalidFeatureGateError, "feature %#q is GA and can't be disabled anymore", name)
+		}
+
+		enabled[name] = e
+	}
+
+	return enabled, nil
+}
@@ -0,0 +1,81 @@
+package featuregate
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parse(t *testing.T) {
+	gates := []Gate{
+		{Name: "Alpha", Stage: StageAlpha},
+		{Name: "Beta", Stage: StageBeta},
+		{Name: "GA", Stage: StageGA},
+	}
+
+	testCases := []struct {
+		name            string
+		values          []string
+		expectedEnabled map[string]bool
+		errorMatcher    func(error) bool
+	}{
+		{
+			name:            "case 0: beta and GA features are enabled by default",
+			expectedEnabled: map[string]bool{"Alpha": false, "Beta": true, "GA": true},
+		},
+		{
+			name:            "case 1: alpha features are enabled explicitly",
+			values:          []string{"Alpha=true"},
+			expectedEnabled: map[string]bool{"Alpha": true, "Beta": true, "GA": true},
+		},
+		{
+			name:            "case 2: beta features are disabled explicitly",
+			values:          []string{" Beta = false "},
+			expectedEnabled: map[string]bool{"Alpha": false, "Beta": false, "GA": true},
+		},
+		{
+			name:         "case 3: unknown features are rejected",
+			values:       []string{"Gamma=true"},
+			errorMatcher: IsInvalidFeatureGate,
+		},
+		{
+			name:         "case 4: malformed feature gates are rejected",
+			values:       []string{"Alpha"},
+			errorMatcher: IsInvalidFeatureGate,
+		},
+		{
+			name:         "case 5: non boolean feature gates are rejected",
+			values:       []string{"Alpha=yes"},
+			errorMatcher: IsInvalidFeatureGate,
+		},
+		{
+			name:         "case 6: GA features can't be disabled",
+			values:       []string{"GA=false"},
+			errorMatcher: IsInvalidFeatureGate,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			enabled, err := parse(gates, tc.values)
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if !cmp.Equal(enabled, tc.expectedEnabled) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedEnabled, enabled))
+			}
+		})
+	}
+}
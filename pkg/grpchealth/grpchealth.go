@@ -0,0 +1,137 @@
+// Package grpchealth serves the standard grpc.health.v1 service, so
+// deployments using gRPC probes or service meshes can health check the
+// collector.
+package grpchealth
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	// servingInterval is how often the serving status is checked for
+	// changes, which are then sent to the clients watching it.
+	servingInterval = time.Second
+)
+
+type Config struct {
+	Logger micrologger.Logger
+	// Serving returns whether the collector is serving. It is not serving
+	// while shutting down.
+	Serving func() bool
+
+	// Address is the address the service is served on, e.g. ":8001".
+	Address string
+	// Services are the names of the services which can be checked in
+	// addition to the empty one of the whole server, e.g.
+	// "azure-collector".
+	Services []string
+}
+
+type Server struct {
+	logger  micrologger.Logger
+	serving func() bool
+
+	address      string
+	grpcServer   *grpc.Server
+	healthServer *health.Server
+	services     []string
+
+	bootOnce     sync.Once
+	shutdownOnce sync.Once
+	stopped      chan struct{}
+}
+
+func New(config Config) (*Server, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.Serving == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Serving must not be empty", config)
+	}
+
+	if config.Address == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Address must not be empty", config)
+	}
+
+	s := &Server{
+		logger:  config.Logger,
+		serving: config.Serving,
+
+		address:      config.Address,
+		grpcServer:   grpc.NewServer(),
+		healthServer: health.NewServer(),
+		services:     append([]string{""}, config.Services...),
+
+		stopped: make(chan struct{}),
+	}
+
+	healthpb.RegisterHealthServer(s.grpcServer, s.healthServer)
+	s.update()
+
+	return s, nil
+}
+
+// Boot starts serving the health service in the background.
+func (s *Server) Boot() {
+	s.bootOnce.Do(func() {
+		listener, err := net.Listen("tcp", s.address)
+		if err != nil {
+			s.logger.Errorf(context.Background(), err, "failed to listen for gRPC health service on %#q", s.address)
+			return
+		}
+
+		go func() {
+			err := s.grpcServer.Serve(listener)
+			if err != nil {
+				s.logger.Errorf(context.Background(), err, "failed to serve gRPC health service on %#q", s.address)
+			}
+		}()
+
+		go func() {
+			ticker := time.NewTicker(servingInterval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-s.stopped:
+					return
+				case <-ticker.C:
+					s.update()
+				}
+			}
+		}()
+	})
+}
+
+// Shutdown stops serving the health service. The services are reported as
+// not serving to the clients watching them before their watches are closed.
+func (s *Server) Shutdown() {
+	s.shutdownOnce.Do(func() {
+		close(s.stopped)
+		s.healthServer.Shutdown()
+		s.grpcServer.Stop()
+	})
+}
+
+// update sets the serving status of all services to the one of the
+// collector. Clients watching a service are only notified when its status
+// changes.
+func (s *Server) update() {
+	status := healthpb.HealthCheckResponse_SERVING
+	if !s.serving() {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+
+	for _, service := range s.services {
+		s.healthServer.SetServingStatus(service, status)
+	}
+}
@@ -0,0 +1,88 @@
+package grpchealth
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func Test_Check(t *testing.T) {
+	testCases := []struct {
+		name           string
+		service        string
+		serving        bool
+		expectedCode   codes.Code
+		expectedStatus healthpb.HealthCheckResponse_ServingStatus
+	}{
+		{
+			name:           "case 0: server is serving",
+			service:        "",
+			serving:        true,
+			expectedCode:   codes.OK,
+			expectedStatus: healthpb.HealthCheckResponse_SERVING,
+		},
+		{
+			name:           "case 1: named service is not serving while shutting down",
+			service:        "azure-collector",
+			serving:        false,
+			expectedCode:   codes.OK,
+			expectedStatus: healthpb.HealthCheckResponse_NOT_SERVING,
+		},
+		{
+			name:         "case 2: unknown service is not found",
+			service:      "unknown",
+			serving:      true,
+			expectedCode: codes.NotFound,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			c := Config{
+				Logger:  microloggertest.New(),
+				Serving: func() bool { return tc.serving },
+
+				Address:  "127.0.0.1:0",
+				Services: []string{"azure-collector"},
+			}
+
+			s, err := New(c)
+			if err != nil {
+				t.Fatalf("expected no error, got %#v", err)
+			}
+			defer s.Shutdown()
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("expected no error, got %#v", err)
+			}
+			go func() {
+				_ = s.grpcServer.Serve(listener)
+			}()
+
+			conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+			if err != nil {
+				t.Fatalf("expected no error, got %#v", err)
+			}
+			defer conn.Close()
+
+			resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{Service: tc.service})
+			if status.Code(err) != tc.expectedCode {
+				t.Fatalf("expected gRPC status %s, got %s", tc.expectedCode, status.Code(err))
+			}
+
+			if resp.GetStatus() != tc.expectedStatus {
+				t.Fatalf("expected serving status %s, got %s", tc.expectedStatus, resp.GetStatus())
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package loadtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+)
+
+const (
+	// emptyListBody is the body of the responses to the calls to APIs the
+	// backend does not fake. Like in a dry run, collectors don't call the
+	// APIs for any resources then.
+	emptyListBody = `{"value":[]}`
+	// scaleSetName is the name of the only scale set of every cluster.
+	scaleSetName = "nodepool-p1"
+	// vmSize is the size of all VMSS instances.
+	vmSize = "Standard_D4s_v3"
+	// instancesPageSize is the number of VMSS instances per page. Azure
+	// returns pages of this size as well.
+	instancesPageSize = 100
+)
+
+// Call is an operation of an Azure API a collector called.
+type Call struct {
+	Collector string
+	Operation string
+}
+
+// backend fakes the Azure APIs. It serves the resource groups, scale sets and
+// VMSS instances of the synthetic fleet and empty lists for all other calls.
+// It is used as the transport of the Azure clients, so no request leaves the
+// process.
+type backend struct {
+	fleet   fleet
+	latency time.Duration
+
+	mutex sync.Mutex
+	calls map[Call]int
+	total int
+}
+
+func newBackend(fleet fleet, latency time.Duration) *backend {
+	b := &backend{
+		fleet:   fleet,
+		latency: latency,
+
+		calls: map[Call]int{},
+	}
+
+	return b
+}
+
+// RoundTrip responds to the given request after the configured latency, like
+// Azure would for the synthetic fleet.
+func (b *backend) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_, _ = ioutil.ReadAll(req.Body)
+		_ = req.Body.Close()
+	}
+
+	c := Call{
+		Collector: client.CollectorFromContext(req.Context()),
+		Operation: client.Operation(req),
+	}
+
+	b.mutex.Lock()
+	b.calls[c]++
+	b.total++
+	b.mutex.Unlock()
+
+	if b.latency > 0 {
+		timer := time.NewTimer(b.latency)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+
+	body, err := b.respond(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+
+	return resp, nil
+}
+
+// Calls returns the number of calls per collector and operation and the total
+// number of calls since the backend was created.
+func (b *backend) Calls() (map[Call]int, int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	calls := map[Call]int{}
+	for c, n := range b.calls {
+		calls[c] = n
+	}
+
+	return calls, b.total
+}
+
+func (b *backend) respond(req *http.Request) ([]byte, error) {
+	if strings.HasSuffix(req.URL.Path, "/oauth2/token") {
+		expiresOn := time.Now().Add(time.Hour).Unix()
+		return []byte(fmt.Sprintf(`{"access_token":"load-test","token_type":"Bearer","expires_in":"3600","expires_on":"%d"}`, expiresOn)), nil
+	}
+
+	if req.Method != http.MethodGet {
+		return []byte(emptyListBody), nil
+	}
+
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	if len(segments) < 3 || !strings.EqualFold(segments[0], "subscriptions") {
+		return []byte(emptyListBody), nil
+	}
+
+	subscriptionID := segments[1]
+
+	switch {
+	// /subscriptions/{subscription}/resourcegroups
+	case len(segments) == 3 && strings.EqualFold(segments[2], "resourceGroups"):
+		return json.Marshal(list{Value: b.resourceGroups(subscriptionID)})
+
+	// /subscriptions/{subscription}/resourceGroups/{cluster}/providers/Microsoft.Compute/virtualMachineScaleSets
+	case len(segments) == 7 && strings.EqualFold(segments[6], "virtualMachineScaleSets"):
+		if !b.fleet.hasCluster(subscriptionID, segments[3]) {
+			return []byte(emptyListBody), nil
+		}
+
+		return json.Marshal(list{Value: []interface{}{b.scaleSet(subscriptionID, segments[3])}})
+
+	// /subscriptions/{subscription}/resourceGroups/{cluster}/providers/Microsoft.Compute/virtualMachineScaleSets/{scaleSet}/virtualMachines
+	case len(segments) == 9 && strings.EqualFold(segments[8], "virtualMachines"):
+		if !b.fleet.hasCluster(subscriptionID, segments[3]) || segments[7] != scaleSetName {
+			return []byte(emptyListBody), nil
+		}
+
+		return b.instances(req, subscriptionID, segments[3])
+	}
+
+	return []byte(emptyListBody), nil
+}
+
+type list struct {
+	Value    []interface{} `json:"value"`
+	NextLink string        `json:"nextLink,omitempty"`
+}
+
+func (b *backend) resourceGroups(subscriptionID string) []interface{} {
+	var groups []interface{}
+	for _, c := range b.fleet.clusters {
+		if c.subscriptionID != subscriptionID {
+			continue
+		}
+
+		groups = append(groups, map[string]interface{}{
+			"id":       "/subscriptions/" + subscriptionID + "/resourceGroups/" + c.id,
+			"name":     c.id,
+			"location": b.fleet.location,
+			"properties": map[string]interface{}{
+				"provisioningState": "Succeeded",
+			},
+		})
+	}
+
+	return groups
+}
+
+func (b *backend) scaleSet(subscriptionID, clusterID string) interface{} {
+	return map[string]interface{}{
+		"id":       scaleSetID(subscriptionID, clusterID),
+		"name":     scaleSetName,
+		"location": b.fleet.location,
+		"sku": map[string]interface{}{
+			"name":     vmSize,
+			"capacity": b.fleet.instances,
+		},
+		"properties": map[string]interface{}{
+			"provisioningState": "Succeeded",
+		},
+	}
+}
+
+// instances returns the page of the VMSS instances of the given cluster
+// requested by the $skiptoken query parameter. The next link points to the
+// following page, if any.
+func (b *backend) instances(req *http.Request, subscriptionID, clusterID string) ([]byte, error) {
+	start, _ := strconv.Atoi(req.URL.Query().Get("$skiptoken"))
+
+	end := start + instancesPageSize
+	if end > b.fleet.instances {
+		end = b.fleet.instances
+	}
+
+	var l list
+	for i := start; i < end; i++ {
+		l.Value = append(l.Value, map[string]interface{}{
+			"id":         instanceID(subscriptionID, clusterID, i),
+			"instanceId": strconv.Itoa(i),
+			"name":       scaleSetName + "_" + strconv.Itoa(i),
+			"location":   b.fleet.location,
+			"zones":      []string{strconv.Itoa(i%3 + 1)},
+			"sku": map[string]interface{}{
+				"name": vmSize,
+			},
+			"properties": map[string]interface{}{
+				"provisioningState": "Succeeded",
+				"osProfile": map[string]interface{}{
+					"computerName": nodeName(i),
+				},
+			},
+		})
+	}
+
+	if end < b.fleet.instances {
+		next := *req.URL
+		query := next.Query()
+		query.Set("$skiptoken", strconv.Itoa(end))
+		next.RawQuery = query.Encode()
+
+		l.NextLink = next.String()
+	}
+
+	return json.Marshal(l)
+}
+
+func scaleSetID(subscriptionID, clusterID string) string {
+	return "/subscriptions/" + subscriptionID + "/resourceGroups/" + clusterID + "/providers/Microsoft.Compute/virtualMachineScaleSets/" + scaleSetName
+}
+
+func instanceID(subscriptionID, clusterID string, i int) string {
+	return scaleSetID(subscriptionID, clusterID) + "/virtualMachines/" + strconv.Itoa(i)
+}
+
+func nodeName(i int) string {
+	return fmt.Sprintf("%s-%06d", scaleSetName, i)
+}
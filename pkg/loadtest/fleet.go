@@ -0,0 +1,161 @@
+package loadtest
+
+import (
+	"fmt"
+
+	providerv1alpha1 "github.com/giantswarm/apiextensions/v2/pkg/apis/provider/v1alpha1"
+	releasev1alpha1 "github.com/giantswarm/apiextensions/v3/pkg/apis/release/v1alpha1"
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	capiv1alpha3 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	expcapiv1alpha3 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
+
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	// clusterNamespace is the namespace of the CRs of all clusters.
+	clusterNamespace = "default"
+	// gsTenantID is the tenant of the credentials of all subscriptions, so
+	// they are single tenant ones.
+	gsTenantID = "00000000-0000-0000-0000-000000000000"
+)
+
+// fleet is the synthetic fleet of clusters the collectors are run against.
+// The clusters are spread over the subscriptions evenly. Every cluster has a
+// single scale set with the same number of instances.
+type fleet struct {
+	clusters      []cluster
+	subscriptions []string
+	instances     int
+	location      string
+	// run tells the credentials of separate load tests apart, so they
+	// don't share cached Azure clients.
+	run int
+}
+
+type cluster struct {
+	id             string
+	subscriptionID string
+}
+
+func newFleet(clusters, subscriptions, instances int, location string, run int) fleet {
+	f := fleet{
+		instances: instances,
+		location:  location,
+		run:       run,
+	}
+
+	for i := 0; i < subscriptions; i++ {
+		f.subscriptions = append(f.subscriptions, fmt.Sprintf("00000000-0000-0000-0000-%012d", i+1))
+	}
+
+	for i := 0; i < clusters; i++ {
+		f.clusters = append(f.clusters, cluster{
+			id:             fmt.Sprintf("lt%05d", i),
+			subscriptionID: f.subscriptions[i%subscriptions],
+		})
+	}
+
+	return f
+}
+
+func (f fleet) hasCluster(subscriptionID, clusterID string) bool {
+	for _, c := range f.clusters {
+		if c.id == clusterID && c.subscriptionID == subscriptionID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// azureConfigs returns the AzureConfig CRs of the clusters referencing the
+// credential secrets of their subscriptions.
+func (f fleet) azureConfigs() []runtime.Object {
+	var objects []runtime.Object
+	for _, c := range f.clusters {
+		cr := &providerv1alpha1.AzureConfig{}
+		cr.Name = c.id
+		cr.Namespace = clusterNamespace
+		cr.Spec.Azure.CredentialSecret.Name = credentialName(c.subscriptionID)
+		cr.Spec.Azure.CredentialSecret.Namespace = credential.CredentialNamespace
+
+		objects = append(objects, cr)
+	}
+
+	return objects
+}
+
+// credentialSecrets returns a credential secret per subscription.
+func (f fleet) credentialSecrets() []runtime.Object {
+	var objects []runtime.Object
+	for _, subscriptionID := range f.subscriptions {
+		secret := &corev1.Secret{}
+		secret.Name = credentialName(subscriptionID)
+		secret.Namespace = credential.CredentialNamespace
+		secret.Labels = map[string]string{
+			"giantswarm.io/managed-by": "credentiald",
+		}
+		secret.Data = map[string][]byte{
+			credential.ClientIDKey:       []byte(fmt.Sprintf("load-test-%d", f.run)),
+			credential.ClientSecretKey:   []byte(fmt.Sprintf("load-test-secret-%d", f.run)),
+			credential.SubscriptionIDKey: []byte(subscriptionID),
+			credential.TenantIDKey:       []byte(gsTenantID),
+		}
+
+		objects = append(objects, secret)
+	}
+
+	return objects
+}
+
+// clusterCRs returns the Cluster and MachinePool CRs of the clusters. The
+// machine pools reference the nodes of all VMSS instances, so the instances
+// are mapped to their nodes like in a real installation.
+func (f fleet) clusterCRs() []runtime.Object {
+	var objects []runtime.Object
+	for _, c := range f.clusters {
+		cluster := &capiv1alpha3.Cluster{}
+		cluster.Name = c.id
+		cluster.Namespace = clusterNamespace
+
+		machinePool := &expcapiv1alpha3.MachinePool{}
+		machinePool.Name = c.id + "-" + scaleSetName
+		machinePool.Namespace = clusterNamespace
+		for i := 0; i < f.instances; i++ {
+			machinePool.Spec.ProviderIDList = append(machinePool.Spec.ProviderIDList, "azure://"+instanceID(c.subscriptionID, c.id, i))
+			machinePool.Status.NodeRefs = append(machinePool.Status.NodeRefs, corev1.ObjectReference{Name: nodeName(i)})
+		}
+
+		objects = append(objects, cluster, machinePool)
+	}
+
+	return objects
+}
+
+// newScheme returns the scheme of the CRs the collectors read, like the one
+// of the Kubernetes clients of the service.
+func newScheme() (*runtime.Scheme, error) {
+	scheme := runtime.NewScheme()
+
+	addToSchemes := []func(*runtime.Scheme) error{
+		providerv1alpha1.AddToScheme,
+		capiv1alpha3.AddToScheme,
+		expcapiv1alpha3.AddToScheme,
+		releasev1alpha1.AddToScheme,
+	}
+	for _, addToScheme := range addToSchemes {
+		err := addToScheme(scheme)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	return scheme, nil
+}
+
+func credentialName(subscriptionID string) string {
+	return "credential-" + subscriptionID
+}
@@ -0,0 +1,307 @@
+// Package loadtest runs the collector set against a fake Azure backend serving
+// a synthetic fleet of clusters, measuring the scrape latency, memory and
+// Azure API calls of the collectors. It is used by the benchmarks catching
+// regressions in the cost of the collectors and for soak tests of large
+// installations.
+package loadtest
+
+import (
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/apiextensions/v2/pkg/clientset/versioned"
+	g8sfake "github.com/giantswarm/apiextensions/v2/pkg/clientset/versioned/fake"
+	"github.com/giantswarm/k8sclient/v4/pkg/k8sclient"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/kubernetes"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/service/collector"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	location = "westeurope"
+)
+
+var (
+	// runMutex ensures only a single load test runs at a time, as the
+	// collectors share their self-monitoring metrics globally.
+	runMutex sync.Mutex
+	runs     int
+)
+
+type Config struct {
+	Logger micrologger.Logger
+
+	// Clusters is the number of clusters of the synthetic fleet.
+	Clusters int
+	// InstancesPerCluster is the number of VMSS instances of every cluster.
+	InstancesPerCluster int
+	// Subscriptions is the number of subscriptions the clusters are spread
+	// over. It defaults to a single one.
+	Subscriptions int
+
+	// CollectTimeout is the deadline of a single collection of every
+	// collector. Collections have no deadline when it is zero.
+	CollectTimeout time.Duration
+	// Latency is how long the fake backend takes to respond to a call, like
+	// the Azure APIs would.
+	Latency time.Duration
+}
+
+// Scrape is the outcome of a single scrape of all collectors.
+type Scrape struct {
+	// Duration is how long the scrape took.
+	Duration time.Duration
+	// Samples is the number of samples exposed.
+	Samples int
+	// Calls is the number of calls sent to the Azure APIs.
+	Calls int
+	// AllocatedBytes is the number of bytes allocated during the scrape.
+	AllocatedBytes uint64
+	// LiveHeapBytes is the size of the live heap after the scrape. It is
+	// only measured by Run.
+	LiveHeapBytes uint64
+}
+
+// Result is the outcome of a load test.
+type Result struct {
+	Scrapes []Scrape
+	// Calls is the number of calls sent to the Azure APIs by collector and
+	// operation.
+	Calls map[Call]int
+}
+
+// LoadTest runs the collector set against a fake Azure backend. The Azure
+// clients of its collectors send their requests to the backend. Only a single
+// load test can exist at a time until it is closed.
+type LoadTest struct {
+	backend  *backend
+	registry *prometheus.Registry
+}
+
+// New creates the collector set and the fake Azure backend serving the
+// synthetic fleet described by the given config. It blocks until the load
+// test created before is closed.
+func New(config Config) (*LoadTest, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.Clusters <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Clusters must be greater than zero", config)
+	}
+	if config.InstancesPerCluster < 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.InstancesPerCluster must not be negative", config)
+	}
+	if config.Subscriptions < 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Subscriptions must not be negative", config)
+	}
+	if config.Subscriptions == 0 {
+		config.Subscriptions = 1
+	}
+
+	runMutex.Lock()
+	runs++
+
+	l, err := newLoadTest(config, newFleet(config.Clusters, config.Subscriptions, config.InstancesPerCluster, location, runs))
+	if err != nil {
+		runMutex.Unlock()
+		return nil, microerror.Mask(err)
+	}
+
+	return l, nil
+}
+
+func newLoadTest(config Config, f fleet) (*LoadTest, error) {
+	b := newBackend(f, config.Latency)
+
+	var clientFactory *client.Factory
+	{
+		c := client.FactoryConfig{
+			HTTP: client.HTTPConfig{
+				Transport: b,
+			},
+		}
+
+		var err error
+		clientFactory, err = client.NewFactory(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	scheme, err := newScheme()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var set *collector.Set
+	{
+		k8sClient := &k8sClients{
+			ctrlClient: ctrlfake.NewFakeClientWithScheme(scheme, f.clusterCRs()...),
+			g8sClient:  g8sfake.NewSimpleClientset(f.azureConfigs()...),
+			k8sClient:  k8sfake.NewSimpleClientset(f.credentialSecrets()...),
+		}
+
+		credentialSource, err := credential.NewSource(credential.SourceConfig{
+			Backend:       credential.NewKubernetesBackend(k8sClient.K8sClient(), k8sClient.G8sClient()),
+			ClientFactory: clientFactory,
+		})
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		c := collector.SetConfig{
+			CredentialSource: credentialSource,
+			K8sClient:        k8sClient,
+			Logger:           config.Logger,
+
+			ControlPlaneResourceGroup: "load-test",
+			GSTenantID:                gsTenantID,
+			Location:                  location,
+			RoleAssignmentsLimit:      4000,
+
+			CollectTimeout: config.CollectTimeout,
+		}
+
+		set, err = collector.NewSet(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	registry := prometheus.NewRegistry()
+	err = registry.Register(set)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	l := &LoadTest{
+		backend:  b,
+		registry: registry,
+	}
+
+	return l, nil
+}
+
+// Close stops sending the requests of the Azure clients to the fake backend,
+// so the next load test can be created.
+func (l *LoadTest) Close() error {
+	runMutex.Unlock()
+
+	return nil
+}
+
+// Scrape scrapes all collectors once, like Prometheus would.
+func (l *LoadTest) Scrape() (Scrape, error) {
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, callsBefore := l.backend.Calls()
+
+	start := time.Now()
+	families, err := l.registry.Gather()
+	if err != nil {
+		return Scrape{}, microerror.Mask(err)
+	}
+	duration := time.Since(start)
+
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+	_, callsAfter := l.backend.Calls()
+
+	var samples int
+	for _, f := range families {
+		samples += len(f.GetMetric())
+	}
+
+	s := Scrape{
+		Duration:       duration,
+		Samples:        samples,
+		Calls:          callsAfter - callsBefore,
+		AllocatedBytes: after.TotalAlloc - before.TotalAlloc,
+	}
+
+	return s, nil
+}
+
+// Run scrapes all collectors the given number of times, waiting for the given
+// interval between scrapes, and measures the live heap after every scrape, so
+// memory growing over time can be spotted in soak tests.
+func (l *LoadTest) Run(scrapes int, interval time.Duration) (Result, error) {
+	var result Result
+	for i := 0; i < scrapes; i++ {
+		if i > 0 {
+			time.Sleep(interval)
+		}
+
+		s, err := l.Scrape()
+		if err != nil {
+			return Result{}, microerror.Mask(err)
+		}
+
+		runtime.GC()
+
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		s.LiveHeapBytes = m.HeapAlloc
+
+		result.Scrapes = append(result.Scrapes, s)
+	}
+
+	result.Calls, _ = l.backend.Calls()
+
+	return result, nil
+}
+
+// MaxDuration returns the duration of the slowest scrape.
+func (r Result) MaxDuration() time.Duration {
+	var max time.Duration
+	for _, s := range r.Scrapes {
+		if s.Duration > max {
+			max = s.Duration
+		}
+	}
+
+	return max
+}
+
+// HeapGrowth returns how much the live heap grew from the first to the last
+// scrape. It is negative if the heap shrank.
+func (r Result) HeapGrowth() int64 {
+	if len(r.Scrapes) == 0 {
+		return 0
+	}
+
+	return int64(r.Scrapes[len(r.Scrapes)-1].LiveHeapBytes) - int64(r.Scrapes[0].LiveHeapBytes)
+}
+
+// k8sClients provides the collectors with fake Kubernetes clients holding
+// the CRs and credential secrets of the synthetic fleet. Other clients are
+// not used by the collectors.
+type k8sClients struct {
+	k8sclient.Interface
+
+	ctrlClient ctrlclient.Client
+	g8sClient  versioned.Interface
+	k8sClient  kubernetes.Interface
+}
+
+func (c *k8sClients) CtrlClient() ctrlclient.Client {
+	return c.ctrlClient
+}
+
+func (c *k8sClients) G8sClient() versioned.Interface {
+	return c.g8sClient
+}
+
+func (c *k8sClients) K8sClient() kubernetes.Interface {
+	return c.k8sClient
+}
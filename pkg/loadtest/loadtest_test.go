@@ -0,0 +1,119 @@
+package loadtest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/giantswarm/micrologger"
+)
+
+func newTestLogger(t testing.TB) micrologger.Logger {
+	logger, err := micrologger.New(micrologger.Config{
+		IOWriter: ioutil.Discard,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %#v", err)
+	}
+
+	return logger
+}
+
+func Test_LoadTest(t *testing.T) {
+	clusters := 3
+	instances := 150
+	scrapes := 2
+
+	l, err := New(Config{
+		Logger:              newTestLogger(t),
+		Clusters:            clusters,
+		InstancesPerCluster: instances,
+		Subscriptions:       2,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %#v", err)
+	}
+	defer l.Close()
+
+	result, err := l.Run(scrapes, 0)
+	if err != nil {
+		t.Fatalf("expected no error, got %#v", err)
+	}
+
+	if len(result.Scrapes) != scrapes {
+		t.Fatalf("expected %d scrapes, got %d", scrapes, len(result.Scrapes))
+	}
+
+	// Every scrape exposes at least the VMSS instance of every node.
+	for i, s := range result.Scrapes {
+		if s.Samples < clusters*instances {
+			t.Fatalf("expected scrape %d to expose at least %d samples, got %d", i, clusters*instances, s.Samples)
+		}
+		if s.Calls == 0 {
+			t.Fatalf("expected scrape %d to call the Azure APIs, got no calls", i)
+		}
+	}
+
+	// The instances of every cluster are listed in two pages per scrape.
+	c := Call{
+		Collector: "node_vmss",
+		Operation: "Microsoft.Compute/virtualMachineScaleSets/virtualMachines/read",
+	}
+	expected := clusters * 2 * scrapes
+	if result.Calls[c] != expected {
+		t.Fatalf("expected %d calls of %#v, got %d", expected, c, result.Calls[c])
+	}
+}
+
+// BenchmarkScrape measures the cost of a scrape of all collectors for fleets
+// of different sizes, so regressions in the latency, allocations and Azure
+// API calls of the collectors are caught. It is run by make bench.
+func BenchmarkScrape(b *testing.B) {
+	fleets := []struct {
+		clusters  int
+		instances int
+	}{
+		{clusters: 10, instances: 10},
+		{clusters: 10, instances: 1000},
+		{clusters: 100, instances: 10},
+	}
+
+	for _, f := range fleets {
+		b.Run(fmt.Sprintf("clusters=%d/instances=%d", f.clusters, f.instances), func(b *testing.B) {
+			l, err := New(Config{
+				Logger:              newTestLogger(b),
+				Clusters:            f.clusters,
+				InstancesPerCluster: f.instances,
+				Subscriptions:       2,
+			})
+			if err != nil {
+				b.Fatalf("expected no error, got %#v", err)
+			}
+			defer l.Close()
+
+			// The first scrape fills the caches of the clients.
+			_, err = l.Scrape()
+			if err != nil {
+				b.Fatalf("expected no error, got %#v", err)
+			}
+
+			var calls, samples int
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				s, err := l.Scrape()
+				if err != nil {
+					b.Fatalf("expected no error, got %#v", err)
+				}
+
+				calls += s.Calls
+				samples += s.Samples
+			}
+
+			b.ReportMetric(float64(calls)/float64(b.N), "calls/op")
+			b.ReportMetric(float64(samples)/float64(b.N), "samples/op")
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	// occurrencesKey is the key of the number of occurrences in the summaries
+	// of repeated log lines.
+	occurrencesKey = "occurrences"
+	// summaryInterval is how often the summaries whose dedup interval is over
+	// are written when nothing else is logged.
+	summaryInterval = 10 * time.Second
+)
+
+// repeatedLine is a log line which was written within the dedup interval
+// before.
+type repeatedLine struct {
+	line  map[string]interface{}
+	count int
+	first time.Time
+}
+
+// dedup returns whether the given log line is a repeated one, which is
+// counted instead of written. Log lines are identical when they only differ
+// in their time and caller, e.g. the same error of a collector on every
+// scrape.
+func (w *Writer) dedup(line map[string]interface{}, now time.Time) bool {
+	if w.config.DedupInterval <= 0 {
+		return false
+	}
+
+	key := dedupKey(line)
+
+	r, ok := w.repeated[key]
+	if ok {
+		r.count++
+		return true
+	}
+
+	w.repeated[key] = &repeatedLine{
+		line:  line,
+		first: now,
+	}
+
+	return false
+}
+
+// Boot writes the summaries of the repeated log lines once their dedup
+// interval is over until the given context is canceled, so they are written
+// even when nothing else is logged.
+func (w *Writer) Boot(ctx context.Context) {
+	ticker := time.NewTicker(summaryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.mutex.Lock()
+			_ = w.writeSummaries(w.now(), false)
+			w.mutex.Unlock()
+		}
+	}
+}
+
+// Flush writes the summaries of all repeated log lines right away, whether
+// their dedup interval is over or not. It is meant to be called on shutdown,
+// so the repeated log lines are not lost.
+func (w *Writer) Flush() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	return w.writeSummaries(w.now(), true)
+}
+
+// writeSummaries writes the summaries of the log lines whose dedup interval is
+// over, or of all of them when flushing, and which were repeated within it.
+// Summaries are written along with other log lines and by Boot.
+func (w *Writer) writeSummaries(now time.Time, flush bool) error {
+	for key, r := range w.repeated {
+		window := now.Sub(r.first)
+		if window >= w.config.DedupInterval {
+			window = w.config.DedupInterval
+		} else if !flush {
+			continue
+		}
+
+		delete(w.repeated, key)
+
+		if r.count == 0 {
+			continue
+		}
+
+		summary := map[string]interface{}{}
+		for k, v := range r.line {
+			summary[k] = v
+		}
+		summary["message"] = fmt.Sprintf("%s (repeated %d times in the last %s)", stringValue(r.line, "message"), r.count, window.Round(time.Second))
+		summary["time"] = now.UTC().Format(time.RFC3339Nano)
+		summary[occurrencesKey] = r.count
+
+		err := w.writeLine(summary, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dedupKey returns the key identifying repeated log lines. Request IDs are
+// unique per request, so lines only differing in them are repeated as well.
+// The summary carries the IDs of the first one.
+func dedupKey(line map[string]interface{}) string {
+	key := map[string]interface{}{}
+	for k, v := range line {
+		if k == "caller" || k == "time" || k == "clientrequestid" || k == "requestid" {
+			continue
+		}
+		key[k] = v
+	}
+
+	// Maps are marshaled with sorted keys, so identical lines have the same
+	// key.
+	b, err := json.Marshal(key)
+	if err != nil {
+		return fmt.Sprint(key)
+	}
+
+	return string(b)
+}
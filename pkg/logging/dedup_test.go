@@ -0,0 +1,128 @@
+package logging
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Writer_Dedup(t *testing.T) {
+	start := time.Date(2020, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	type write struct {
+		after time.Duration
+		line  string
+	}
+
+	testCases := []struct {
+		name           string
+		dedupInterval  time.Duration
+		writes         []write
+		flushAfter     time.Duration
+		expectedOutput string
+	}{
+		{
+			name:          "case 0: repeated errors are summarized once the interval is over",
+			dedupInterval: 5 * time.Minute,
+			writes: []write{
+				{after: 0, line: `{"caller":"a.go:1","level":"error","message":"forbidden","time":"1"}`},
+				{after: time.Minute, line: `{"caller":"a.go:1","level":"error","message":"forbidden","time":"2"}`},
+				{after: 2 * time.Minute, line: `{"caller":"a.go:2","level":"error","message":"forbidden","time":"3"}`},
+				{after: 6 * time.Minute, line: `{"level":"info","message":"collected"}`},
+			},
+			expectedOutput: `{"caller":"a.go:1","level":"error","message":"forbidden","time":"1"}` + "\n" +
+				`{"caller":"a.go:1","level":"error","message":"forbidden (repeated 2 times in the last 5m0s)","occurrences":2,"time":"2020-07-01T12:06:00Z"}` + "\n" +
+				`{"level":"info","message":"collected"}` + "\n",
+		},
+		{
+			name:          "case 1: errors are written again after the interval",
+			dedupInterval: 5 * time.Minute,
+			writes: []write{
+				{after: 0, line: `{"level":"error","message":"forbidden"}`},
+				{after: 5 * time.Minute, line: `{"level":"error","message":"forbidden"}`},
+			},
+			expectedOutput: `{"level":"error","message":"forbidden"}` + "\n" +
+				`{"level":"error","message":"forbidden"}` + "\n",
+		},
+		{
+			name:          "case 2: different errors and info lines are not collapsed",
+			dedupInterval: 5 * time.Minute,
+			writes: []write{
+				{after: 0, line: `{"collector":"usage","level":"error","message":"forbidden"}`},
+				{after: 0, line: `{"collector":"deployment","level":"error","message":"forbidden"}`},
+				{after: 0, line: `{"level":"info","message":"collected"}`},
+				{after: 0, line: `{"level":"info","message":"collected"}`},
+			},
+			expectedOutput: `{"collector":"usage","level":"error","message":"forbidden"}` + "\n" +
+				`{"collector":"deployment","level":"error","message":"forbidden"}` + "\n" +
+				`{"level":"info","message":"collected"}` + "\n" +
+				`{"level":"info","message":"collected"}` + "\n",
+		},
+		{
+			name:          "case 3: nothing is collapsed without interval",
+			dedupInterval: 0,
+			writes: []write{
+				{after: 0, line: `{"level":"warning","message":"throttled"}`},
+				{after: time.Second, line: `{"level":"warning","message":"throttled"}`},
+			},
+			expectedOutput: `{"level":"warning","message":"throttled"}` + "\n" +
+				`{"level":"warning","message":"throttled"}` + "\n",
+		},
+		{
+			name:          "case 4: repeated errors are summarized when flushed before the interval is over",
+			dedupInterval: 5 * time.Minute,
+			writes: []write{
+				{after: 0, line: `{"level":"error","message":"forbidden"}`},
+				{after: time.Minute, line: `{"level":"error","message":"forbidden"}`},
+			},
+			flushAfter: 2 * time.Minute,
+			expectedOutput: `{"level":"error","message":"forbidden"}` + "\n" +
+				`{"level":"error","message":"forbidden (repeated 1 times in the last 2m0s)","occurrences":1,"time":"2020-07-01T12:02:00Z"}` + "\n",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			var b bytes.Buffer
+			w := NewWriter(&b)
+
+			err := w.Configure(Config{
+				Level:         LevelDebug,
+				Format:        FormatJSON,
+				DedupInterval: tc.dedupInterval,
+			})
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			for _, write := range tc.writes {
+				now := start.Add(write.after)
+				w.now = func() time.Time { return now }
+
+				_, err := w.Write([]byte(write.line + "\n"))
+				if err != nil {
+					t.Fatalf("error == %#v, want nil", err)
+				}
+			}
+
+			if tc.flushAfter > 0 {
+				now := start.Add(tc.flushAfter)
+				w.now = func() time.Time { return now }
+
+				err := w.Flush()
+				if err != nil {
+					t.Fatalf("error == %#v, want nil", err)
+				}
+			}
+
+			if !cmp.Equal(b.String(), tc.expectedOutput) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedOutput, b.String()))
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package logging
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	// redacted replaces the secrets scrubbed from log lines.
+	redacted = "REDACTED"
+	// minSecretLength is the minimum length of the secrets added to be
+	// scrubbed, so short values don't scrub unrelated text.
+	minSecretLength = 8
+)
+
+var (
+	// redactions are the patterns of secrets which are scrubbed from every
+	// log line, e.g. when an error of the Azure SDK contains the request
+	// which failed.
+	redactions = []struct {
+		pattern     *regexp.Regexp
+		replacement string
+	}{
+		// Bearer tokens in Authorization headers.
+		{
+			pattern:     regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-._~+/]+=*`),
+			replacement: "${1}" + redacted,
+		},
+		// JSON Web Tokens, e.g. access tokens in token responses.
+		{
+			pattern:     regexp.MustCompile(`eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`),
+			replacement: redacted,
+		},
+		// Signatures of SAS tokens in URLs and connection strings.
+		{
+			pattern:     regexp.MustCompile(`(?i)((?:[?&;]|\\u0026)sig=)[^&;\s"\\]+`),
+			replacement: "${1}" + redacted,
+		},
+		// Client secrets and assertions in token requests.
+		{
+			pattern:     regexp.MustCompile(`(?i)((?:client_secret|client_assertion|refresh_token|access_token)=)[^&\s"\\]+`),
+			replacement: "${1}" + redacted,
+		},
+		// Client secrets and tokens in JSON bodies.
+		{
+			pattern:     regexp.MustCompile(`(?i)("(?:clientSecret|client_secret|password|access_token|refresh_token)"\s*:\s*")[^"]+`),
+			replacement: "${1}" + redacted,
+		},
+		// Account keys in storage connection strings.
+		{
+			pattern:     regexp.MustCompile(`(?i)((?:AccountKey|SharedAccessKey)=)[^;\s"\\]+`),
+			replacement: "${1}" + redacted,
+		},
+	}
+
+	secretsMutex sync.RWMutex
+	// secrets are the known secrets which are scrubbed from every log line,
+	// e.g. the client secrets of the credential secrets.
+	secrets = map[string]struct{}{}
+)
+
+// AddSecret adds the given secret to the known secrets which are scrubbed
+// from every log line, in case it ends up in an error or dumped request in a
+// form the patterns don't catch.
+func AddSecret(secret string) {
+	if len(secret) < minSecretLength {
+		return
+	}
+
+	secretsMutex.Lock()
+	defer secretsMutex.Unlock()
+
+	secrets[secret] = struct{}{}
+}
+
+// Redact scrubs client secrets, SAS tokens, bearer tokens and the added
+// secrets from the given text, e.g. an error or a URL.
+func Redact(s string) string {
+	secretsMutex.RLock()
+	for secret := range secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+
+		// Secrets are escaped in JSON, e.g. in the stack of errors.
+		escaped, err := json.Marshal(secret)
+		if err == nil {
+			s = strings.ReplaceAll(s, strings.Trim(string(escaped), `"`), redacted)
+		}
+	}
+	secretsMutex.RUnlock()
+
+	for _, r := range redactions {
+		s = r.pattern.ReplaceAllString(s, r.replacement)
+	}
+
+	return s
+}
+
+// redactValue scrubs the secrets of all strings of the given value of a
+// decoded JSON log line. It returns whether any secret was scrubbed.
+func redactValue(v interface{}) (interface{}, bool) {
+	switch v := v.(type) {
+	case string:
+		r := Redact(v)
+		return r, r != v
+	case map[string]interface{}:
+		var changed bool
+		for k, e := range v {
+			r, ok := redactValue(e)
+			if ok {
+				v[k] = r
+				changed = true
+			}
+		}
+		return v, changed
+	case []interface{}:
+		var changed bool
+		for i, e := range v {
+			r, ok := redactValue(e)
+			if ok {
+				v[i] = r
+				changed = true
+			}
+		}
+		return v, changed
+	default:
+		return v, false
+	}
+}
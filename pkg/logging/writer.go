@@ -0,0 +1,306 @@
+// Package logging filters and formats the log lines written by micrologger and
+// scrubs secrets from them.
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+)
+
+// Log formats.
+const (
+	FormatConsole = "console"
+	FormatJSON    = "json"
+)
+
+// Log levels, from the most to the least verbose.
+const (
+	LevelDebug   = "debug"
+	LevelInfo    = "info"
+	LevelWarning = "warning"
+	LevelError   = "error"
+)
+
+const (
+	// collectorKey is the key of the collector name in the log lines of
+	// collectors.
+	collectorKey = "collector"
+)
+
+var (
+	levels = []string{
+		LevelDebug,
+		LevelInfo,
+		LevelWarning,
+		LevelError,
+	}
+
+	// consoleKeys are the keys printed up front in the console format.
+	consoleKeys = map[string]bool{
+		"caller":     true,
+		collectorKey: true,
+		"level":      true,
+		"message":    true,
+		"time":       true,
+	}
+)
+
+// Config configures the log lines written by a Writer.
+type Config struct {
+	// Level is the minimum level of the log lines written, e.g. "info".
+	Level string
+	// Format is the format log lines are written in, either "json" or
+	// "console".
+	Format string
+	// CollectorLevels overrides Level for the log lines of the given
+	// collectors, e.g. "vmss_rate_limit", to debug a single collector.
+	CollectorLevels map[string]string
+	// DedupInterval is the interval identical warnings and errors are
+	// collapsed in. The first one is written right away, the ones repeated
+	// within the interval are summarized with their count once it is over.
+	// Nothing is collapsed when it is zero.
+	DedupInterval time.Duration
+}
+
+// Writer is the writer of micrologger, which filters the JSON log lines
+// written by their level and writes them in the configured format. All lines
+// are written as they are until it is configured.
+type Writer struct {
+	mutex    sync.Mutex
+	config   Config
+	now      func() time.Time
+	repeated map[string]*repeatedLine
+	writer   io.Writer
+}
+
+// NewWriter returns a writer writing to the given one.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		config: Config{
+			Level:  LevelDebug,
+			Format: FormatJSON,
+		},
+		now:      time.Now,
+		repeated: map[string]*repeatedLine{},
+		writer:   w,
+	}
+}
+
+// Configure configures the log lines written afterwards. It is meant to be
+// called once the command line flags are parsed.
+func (w *Writer) Configure(config Config) error {
+	if !isLevel(config.Level) {
+		return microerror.Maskf(invalidConfigError, "%T.Level must be one of %v, got %#q", config, levels, config.Level)
+	}
+	if config.Format != FormatConsole && config.Format != FormatJSON {
+		return microerror.Maskf(invalidConfigError, "%T.Format must be one of %v, got %#q", config, []string{FormatConsole, FormatJSON}, config.Format)
+	}
+	if config.DedupInterval < 0 {
+		return microerror.Maskf(invalidConfigError, "%T.DedupInterval must not be negative", config)
+	}
+	for collector, level := range config.CollectorLevels {
+		if !isLevel(level) {
+			return microerror.Maskf(invalidConfigError, "%T.CollectorLevels of collector %#q must be one of %v, got %#q", config, collector, levels, level)
+		}
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.config = config
+
+	return nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	var line map[string]interface{}
+	err := json.Unmarshal(p, &line)
+	if err != nil {
+		// Lines which are not written by micrologger are written as they
+		// are, except for secrets.
+		_, err = w.writer.Write([]byte(Redact(string(p))))
+		if err != nil {
+			return 0, err
+		}
+
+		return len(p), nil
+	}
+
+	// Secrets are scrubbed from all values, e.g. the stacks of errors. Lines
+	// are encoded again when anything was scrubbed.
+	raw := p
+	if _, changed := redactValue(line); changed {
+		raw = nil
+	}
+
+	// Lines of unknown levels are written like info ones.
+	level := stringValue(line, "level")
+	if !isLevel(level) {
+		level = LevelInfo
+	}
+
+	minLevel := w.config.Level
+	if l, ok := w.config.CollectorLevels[stringValue(line, collectorKey)]; ok {
+		minLevel = l
+	}
+
+	if levelIndex(level) < levelIndex(minLevel) {
+		return len(p), nil
+	}
+
+	now := w.now()
+
+	err = w.writeSummaries(now, false)
+	if err != nil {
+		return 0, err
+	}
+
+	if levelIndex(level) >= levelIndex(LevelWarning) && w.dedup(line, now) {
+		return len(p), nil
+	}
+
+	err = w.writeLine(line, raw)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// writeLine writes the given log line in the configured format. The raw JSON
+// line is written as it is if given.
+func (w *Writer) writeLine(line map[string]interface{}, raw []byte) error {
+	if w.config.Format == FormatConsole {
+		raw = formatConsole(line)
+	} else if raw == nil {
+		b, err := json.Marshal(line)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		raw = append(b, '\n')
+	}
+
+	_, err := w.writer.Write(raw)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// formatConsole formats the given log line to be read by humans, e.g.
+// "2020-07-01T12:00:00Z DEBUG [vmss_rate_limit] message key=value".
+func formatConsole(line map[string]interface{}) []byte {
+	var b bytes.Buffer
+
+	if t := stringValue(line, "time"); t != "" {
+		b.WriteString(t)
+		b.WriteString(" ")
+	}
+
+	level := stringValue(line, "level")
+	if level == "" {
+		level = LevelInfo
+	}
+	b.WriteString(strings.ToUpper(level))
+
+	if collector := stringValue(line, collectorKey); collector != "" {
+		b.WriteString(" [")
+		b.WriteString(collector)
+		b.WriteString("]")
+	}
+
+	if message := stringValue(line, "message"); message != "" {
+		b.WriteString(" ")
+		b.WriteString(message)
+	}
+
+	var keys []string
+	for k := range line {
+		if !consoleKeys[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		b.WriteString(" ")
+		b.WriteString(k)
+		b.WriteString("=")
+		b.WriteString(formatValue(line[k]))
+	}
+
+	if caller := stringValue(line, "caller"); caller != "" {
+		b.WriteString(" caller=")
+		b.WriteString(caller)
+	}
+
+	b.WriteString("\n")
+
+	return b.Bytes()
+}
+
+func formatValue(v interface{}) string {
+	s, ok := v.(string)
+	if !ok {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		s = string(b)
+	}
+
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return fmt.Sprintf("%q", s)
+	}
+
+	return s
+}
+
+func isLevel(level string) bool {
+	return levelIndex(level) >= 0
+}
+
+func levelIndex(level string) int {
+	for i, l := range levels {
+		if l == level {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func stringValue(line map[string]interface{}, key string) string {
+	s, _ := line[key].(string)
+	return s
+}
+
+// ParseCollectorLevels parses collector log levels in the form
+// "collector=level", e.g. "vmss_rate_limit=debug", as passed on the command
+// line.
+func ParseCollectorLevels(values []string) (map[string]string, error) {
+	collectorLevels := map[string]string{}
+
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, microerror.Maskf(invalidConfigError, "collector log level must be in the form collector=level, got %#q", value)
+		}
+
+		collectorLevels[parts[0]] = parts[1]
+	}
+
+	return collectorLevels, nil
+}
@@ -0,0 +1,239 @@
+package logging
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Writer(t *testing.T) {
+	testCases := []struct {
+		name           string
+		config         Config
+		lines          []string
+		expectedOutput string
+	}{
+		{
+			name: "case 0: lines below the level are dropped",
+			config: Config{
+				Level:  LevelInfo,
+				Format: FormatJSON,
+			},
+			lines: []string{
+				`{"level":"debug","message":"a"}` + "\n",
+				`{"level":"info","message":"b"}` + "\n",
+				`{"level":"error","message":"c"}` + "\n",
+			},
+			expectedOutput: `{"level":"info","message":"b"}` + "\n" +
+				`{"level":"error","message":"c"}` + "\n",
+		},
+		{
+			name: "case 1: lines without or of unknown level are info",
+			config: Config{
+				Level:  LevelInfo,
+				Format: FormatJSON,
+			},
+			lines: []string{
+				`{"message":"a"}` + "\n",
+				`{"level":"notice","message":"b"}` + "\n",
+			},
+			expectedOutput: `{"message":"a"}` + "\n" +
+				`{"level":"notice","message":"b"}` + "\n",
+		},
+		{
+			name: "case 2: collector levels override the level",
+			config: Config{
+				Level:  LevelWarning,
+				Format: FormatJSON,
+				CollectorLevels: map[string]string{
+					"vmss_rate_limit": LevelDebug,
+					"usage":           LevelError,
+				},
+			},
+			lines: []string{
+				`{"collector":"vmss_rate_limit","level":"debug","message":"a"}` + "\n",
+				`{"collector":"usage","level":"warning","message":"b"}` + "\n",
+				`{"collector":"deployment","level":"warning","message":"c"}` + "\n",
+				`{"level":"info","message":"d"}` + "\n",
+			},
+			expectedOutput: `{"collector":"vmss_rate_limit","level":"debug","message":"a"}` + "\n" +
+				`{"collector":"deployment","level":"warning","message":"c"}` + "\n",
+		},
+		{
+			name: "case 3: console format",
+			config: Config{
+				Level:  LevelDebug,
+				Format: FormatConsole,
+			},
+			lines: []string{
+				`{"caller":"collector/usage.go:42","collector":"usage","level":"debug","message":"collecting usage","subscription":"a b","time":"2020-07-01T12:00:00Z","count":3}` + "\n",
+				`{"level":"error","message":"failed"}` + "\n",
+			},
+			expectedOutput: `2020-07-01T12:00:00Z DEBUG [usage] collecting usage count=3 subscription="a b" caller=collector/usage.go:42` + "\n" +
+				`ERROR failed` + "\n",
+		},
+		{
+			name: "case 4: lines not written by micrologger are written as they are",
+			config: Config{
+				Level:  LevelError,
+				Format: FormatConsole,
+			},
+			lines: []string{
+				"starting\n",
+			},
+			expectedOutput: "starting\n",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			var b bytes.Buffer
+			w := NewWriter(&b)
+
+			err := w.Configure(tc.config)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			for _, line := range tc.lines {
+				n, err := w.Write([]byte(line))
+				if err != nil {
+					t.Fatalf("error == %#v, want nil", err)
+				}
+				if n != len(line) {
+					t.Fatalf("n == %d, want %d", n, len(line))
+				}
+			}
+
+			if !cmp.Equal(b.String(), tc.expectedOutput) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedOutput, b.String()))
+			}
+		})
+	}
+}
+
+func Test_Writer_Configure(t *testing.T) {
+	testCases := []struct {
+		name         string
+		config       Config
+		errorMatcher func(error) bool
+	}{
+		{
+			name: "case 0: valid config",
+			config: Config{
+				Level:  LevelInfo,
+				Format: FormatConsole,
+				CollectorLevels: map[string]string{
+					"usage": LevelDebug,
+				},
+			},
+			errorMatcher: nil,
+		},
+		{
+			name: "case 1: unknown level",
+			config: Config{
+				Level:  "verbose",
+				Format: FormatJSON,
+			},
+			errorMatcher: IsInvalidConfig,
+		},
+		{
+			name: "case 2: unknown format",
+			config: Config{
+				Level:  LevelInfo,
+				Format: "logfmt",
+			},
+			errorMatcher: IsInvalidConfig,
+		},
+		{
+			name: "case 3: unknown collector level",
+			config: Config{
+				Level:  LevelInfo,
+				Format: FormatJSON,
+				CollectorLevels: map[string]string{
+					"usage": "trace",
+				},
+			},
+			errorMatcher: IsInvalidConfig,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			err := NewWriter(&bytes.Buffer{}).Configure(tc.config)
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+		})
+	}
+}
+
+func Test_ParseCollectorLevels(t *testing.T) {
+	testCases := []struct {
+		name           string
+		values         []string
+		expectedLevels map[string]string
+		errorMatcher   func(error) bool
+	}{
+		{
+			name:           "case 0: no values",
+			values:         nil,
+			expectedLevels: map[string]string{},
+		},
+		{
+			name:   "case 1: collector levels",
+			values: []string{"usage=debug", "vmss_rate_limit=error"},
+			expectedLevels: map[string]string{
+				"usage":           "debug",
+				"vmss_rate_limit": "error",
+			},
+		},
+		{
+			name:         "case 2: missing level",
+			values:       []string{"usage"},
+			errorMatcher: IsInvalidConfig,
+		},
+		{
+			name:         "case 3: missing collector",
+			values:       []string{"=debug"},
+			errorMatcher: IsInvalidConfig,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			levels, err := ParseCollectorLevels(tc.values)
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if !cmp.Equal(levels, tc.expectedLevels) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedLevels, levels))
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// Package tracing configures the OpenTelemetry tracing of collections and
+// Azure API calls.
+package tracing
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// tracerName is the name of the tracer all spans are started with.
+	tracerName = "github.com/giantswarm/azure-collector"
+)
+
+// Config configures the export of spans.
+type Config struct {
+	// Endpoint is the OTLP/HTTP endpoint spans are exported to, e.g.
+	// "otel-collector:4318". Spans are not recorded when it is empty.
+	Endpoint string
+	// Insecure disables TLS when exporting spans.
+	Insecure bool
+	// SampleRatio is the ratio of the traces which are sampled, from 0 to 1.
+	SampleRatio float64
+
+	ServiceName    string
+	ServiceVersion string
+}
+
+// Configure configures the tracer provider spans are started with. It
+// returns the function flushing the spans not exported yet, which is meant to
+// be called on shutdown.
+func Configure(ctx context.Context, config Config) (func(context.Context) error, error) {
+	if config.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	if config.SampleRatio < 0 || config.SampleRatio > 1 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.SampleRatio must be between 0 and 1", config)
+	}
+	if config.ServiceName == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ServiceName must not be empty", config)
+	}
+
+	options := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(config.Endpoint),
+	}
+	if config.Insecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(ctx, options...)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceNameKey.String(config.ServiceName),
+			semconv.ServiceVersionKey.String(config.ServiceVersion),
+		)),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Tracer returns the tracer spans are started with. Its spans are not
+// recorded until tracing is configured.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
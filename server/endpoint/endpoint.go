@@ -2,7 +2,6 @@ package endpoint
 
 import (
 	"github.com/giantswarm/microendpoint/endpoint/healthz"
-	versionendpoint "github.com/giantswarm/microendpoint/endpoint/version"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 
@@ -17,7 +16,6 @@ type Config struct {
 // Endpoint is the endpoint collection.
 type Endpoint struct {
 	Healthz *healthz.Endpoint
-	Version *versionendpoint.Endpoint
 }
 
 func New(config Config) (*Endpoint, error) {
@@ -35,22 +33,8 @@ func New(config Config) (*Endpoint, error) {
 		}
 	}
 
-	var versionEndpoint *versionendpoint.Endpoint
-	{
-		c := versionendpoint.Config{
-			Logger:  config.Logger,
-			Service: config.Service.Version,
-		}
-
-		versionEndpoint, err = versionendpoint.New(c)
-		if err != nil {
-			return nil, microerror.Mask(err)
-		}
-	}
-
 	newEndpoint := &Endpoint{
 		Healthz: healthzEndpoint,
-		Version: versionEndpoint,
 	}
 
 	return newEndpoint, nil
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/giantswarm/azure-collector/v2/service/collector"
+)
+
+const (
+	// indexPath is the path the landing page is served on.
+	indexPath = "/"
+)
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .Name }}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+.skipped { color: #999; }
+</style>
+</head>
+<body>
+<h1>{{ .Name }}</h1>
+<h2>Endpoints</h2>
+<ul>
+{{- range .Endpoints }}
+<li>{{ if .Path }}<a href="{{ .Path }}">{{ .Path }}</a>{{ else }}{{ .Address }}{{ end }}: {{ .Description }}</li>
+{{- end }}
+</ul>
+<h2>Collectors</h2>
+{{- range .Collectors }}
+<h3 id="{{ .Name }}"{{ if not .Collected }} class="skipped"{{ end }}>{{ .Name }}</h3>
+<p>Priority {{ .Priority }}, {{ if .Collected }}collected{{ else }}skipped while the Azure APIs are under pressure{{ end }}.</p>
+<table>
+<tr><th>Metric</th><th>Help</th><th>Labels</th><th>Stability</th></tr>
+{{- range .Metrics }}
+<tr><td><code>{{ .Name }}</code></td><td>{{ .Help }}</td><td>{{ range $i, $l := .Labels }}{{ if $i }}, {{ end }}<code>{{ $l }}</code>{{ end }}</td><td>{{ .Stability }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+</body>
+</html>
+`))
+
+// indexEndpoint is an endpoint listed on the landing page. Endpoints served
+// on other addresses have no path.
+type indexEndpoint struct {
+	Path        string
+	Address     string
+	Description string
+}
+
+type indexPage struct {
+	Name       string
+	Endpoints  []indexEndpoint
+	Collectors []collector.Status
+}
+
+// indexHandler serves a landing page on / listing the given endpoints and the
+// metrics of every collector along with whether it is currently collected.
+// Collectors are described on every request, so the page reflects the
+// current pressure on the Azure APIs. Nothing is collected to render it.
+func indexHandler(h http.Handler, name string, endpoints []indexEndpoint, statuses func() ([]collector.Status, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != indexPath {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		collectors, err := statuses()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		page := indexPage{
+			Name:       name,
+			Endpoints:  endpoints,
+			Collectors: collectors,
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = indexTemplate.Execute(w, page)
+	})
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/azure-collector/v2/service/collector"
+)
+
+func Test_indexHandler(t *testing.T) {
+	endpoints := []indexEndpoint{
+		{Path: "/metrics", Description: "Metrics."},
+		{Address: ":8001", Description: "gRPC health."},
+	}
+	statuses := func() ([]collector.Status, error) {
+		s := []collector.Status{
+			{
+				Name:      "collector.Deployment",
+				Priority:  "normal",
+				Collected: true,
+				Metrics: []collector.Descriptor{
+					{Name: "azure_operator_deployment", Help: "Deployment <status>.", Labels: []string{"cluster_id", "status"}, Stability: collector.StabilityStable},
+				},
+			},
+			{
+				Name:      "collector.ClusterCost",
+				Priority:  "low",
+				Collected: false,
+			},
+		}
+
+		return s, nil
+	}
+
+	testCases := []struct {
+		name             string
+		path             string
+		expectedCode     int
+		expectedContains []string
+	}{
+		{
+			name:         "case 0: endpoints and metrics of collectors are listed",
+			path:         "/",
+			expectedCode: http.StatusOK,
+			expectedContains: []string{
+				`<a href="/metrics">/metrics</a>`,
+				":8001: gRPC health.",
+				`<h3 id="collector.Deployment">collector.Deployment</h3>`,
+				"<code>azure_operator_deployment</code>",
+				"Deployment &lt;status&gt;.",
+				"<code>cluster_id</code>, <code>status</code>",
+				`<h3 id="collector.ClusterCost" class="skipped">collector.ClusterCost</h3>`,
+			},
+		},
+		{
+			name:         "case 1: other paths are passed on",
+			path:         "/metrics",
+			expectedCode: http.StatusTeapot,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+			})
+
+			w := httptest.NewRecorder()
+			indexHandler(next, "azure-collector", endpoints, statuses).ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			if w.Code != tc.expectedCode {
+				t.Fatalf("expected code %d, got %d", tc.expectedCode, w.Code)
+			}
+
+			for _, s := range tc.expectedContains {
+				if !strings.Contains(w.Body.String(), s) {
+					t.Fatalf("expected page to contain %q, got\n%s", s, w.Body.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/giantswarm/azure-collector/v2/pkg/tracing"
+	"github.com/giantswarm/azure-collector/v2/service/collector"
+)
+
+const (
+	// scrapeTimeoutHeader is the header Prometheus sends the scrape timeout
+	// in.
+	scrapeTimeoutHeader = "X-Prometheus-Scrape-Timeout-Seconds"
+	// maxScrapeTimeoutMargin is the maximum time left to gather and encode
+	// the collected metrics before Prometheus gives up on the scrape. A
+	// tenth of the scrape timeout is left when it is shorter.
+	maxScrapeTimeoutMargin = 2 * time.Second
+	// metricsPath is the path microkit serves the metrics on.
+	metricsPath = "/metrics"
+)
+
+// scrapeHandler bounds the collections of metrics requests by the timeout of
+// the Prometheus scrape, minus a safety margin. Collectors still waiting for
+// Azure APIs by then give up, so the metrics collected so far are served
+// instead of the scrape failing as a whole. Scrapes are traced as the parent
+// span of their collections.
+func scrapeHandler(h http.Handler, collections *collector.Collections) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != metricsPath {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, span := tracing.Tracer().Start(r.Context(), "scrape", trace.WithSpanKind(trace.SpanKindServer))
+		defer span.End()
+
+		deadline, ok := scrapeDeadline(time.Now(), r.Header.Get(scrapeTimeoutHeader))
+		if ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithDeadline(ctx, deadline)
+			defer cancel()
+		}
+
+		done := collections.StartScrape(ctx)
+		defer done()
+
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// scrapeDeadline returns the deadline of the collections of a scrape started
+// at the given time with the given scrape timeout header. There is none when
+// the header is missing or invalid.
+func scrapeDeadline(now time.Time, header string) (time.Time, bool) {
+	if header == "" {
+		return time.Time{}, false
+	}
+
+	seconds, err := strconv.ParseFloat(header, 64)
+	if err != nil || seconds <= 0 {
+		return time.Time{}, false
+	}
+
+	timeout := time.Duration(seconds * float64(time.Second))
+
+	margin := timeout / 10
+	if margin > maxScrapeTimeoutMargin {
+		margin = maxScrapeTimeoutMargin
+	}
+
+	return now.Add(timeout - margin), true
+}
+
+// drainingHandler rejects metrics requests while the collections are drained
+// on shutdown, so Prometheus does not wait for collections which are canceled
+// anyway.
+func drainingHandler(h http.Handler, collections *collector.Collections) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == metricsPath && collections.Draining() {
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// openMetricsHandler serves the metrics in the OpenMetrics format to scrapers
+// negotiating it, so the client request IDs attached as exemplars to the
+// durations of Azure API requests are exposed. Other scrapers still get the
+// text format.
+func openMetricsHandler(h http.Handler) http.Handler {
+	metrics := promhttp.InstrumentMetricHandler(
+		prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{
+			EnableOpenMetrics: true,
+		}),
+	)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != metricsPath {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		metrics.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_scrapeDeadline(t *testing.T) {
+	now := time.Date(2020, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name             string
+		header           string
+		expectedDeadline time.Time
+		expectedOK       bool
+	}{
+		{
+			name:       "case 0: missing header",
+			header:     "",
+			expectedOK: false,
+		},
+		{
+			name:       "case 1: invalid header",
+			header:     "ten",
+			expectedOK: false,
+		},
+		{
+			name:       "case 2: zero timeout",
+			header:     "0",
+			expectedOK: false,
+		},
+		{
+			name:       "case 3: negative timeout",
+			header:     "-10",
+			expectedOK: false,
+		},
+		{
+			name:             "case 4: short timeout leaves a tenth",
+			header:           "10",
+			expectedDeadline: now.Add(9 * time.Second),
+			expectedOK:       true,
+		},
+		{
+			name:             "case 5: fractional timeout",
+			header:           "0.5",
+			expectedDeadline: now.Add(450 * time.Millisecond),
+			expectedOK:       true,
+		},
+		{
+			name:             "case 6: long timeout leaves the maximum margin",
+			header:           "60",
+			expectedDeadline: now.Add(58 * time.Second),
+			expectedOK:       true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			deadline, ok := scrapeDeadline(now, tc.header)
+
+			if ok != tc.expectedOK {
+				t.Fatalf("ok = %v, want %v", ok, tc.expectedOK)
+			}
+			if !deadline.Equal(tc.expectedDeadline) {
+				t.Fatalf("deadline = %v, want %v", deadline, tc.expectedDeadline)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+)
+
+// metricsTLSConfig configures the listener serving the metrics to clients
+// presenting a certificate signed by the given CA only.
+type metricsTLSConfig struct {
+	// Address is the address the metrics are served on, e.g. ":8443".
+	Address string
+	// CAFile is the path of the PEM encoded CA certificates client
+	// certificates are verified against.
+	CAFile string
+	// CrtFile and KeyFile are the paths of the PEM encoded certificate and
+	// key of the listener. They are reloaded once changed, e.g. when
+	// renewed by cert-manager.
+	CrtFile string
+	KeyFile string
+}
+
+// newMetricsTLSServer returns the server serving the metrics with the given
+// handler to clients presenting a valid client certificate only.
+func newMetricsTLSServer(config metricsTLSConfig, h http.Handler) (*http.Server, error) {
+	if config.CAFile == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CAFile must not be empty", config)
+	}
+	if config.CrtFile == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CrtFile must not be empty", config)
+	}
+	if config.KeyFile == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.KeyFile must not be empty", config)
+	}
+
+	pem, err := ioutil.ReadFile(config.CAFile)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(pem) {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CAFile %#q does not contain any PEM encoded certificate", config, config.CAFile)
+	}
+
+	certificate := &reloadingCertificate{
+		crtFile: config.CrtFile,
+		keyFile: config.KeyFile,
+	}
+
+	// The certificate is loaded once upfront, so invalid files fail the
+	// startup instead of every handshake.
+	_, err = certificate.get(nil)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	s := &http.Server{
+		Addr:    config.Address,
+		Handler: h,
+		TLSConfig: &tls.Config{
+			ClientAuth:     tls.RequireAndVerifyClientCert,
+			ClientCAs:      clientCAs,
+			GetCertificate: certificate.get,
+			MinVersion:     tls.VersionTLS12,
+		},
+	}
+
+	return s, nil
+}
+
+// reloadingCertificate loads the certificate of the listener again once its
+// files changed.
+type reloadingCertificate struct {
+	crtFile string
+	keyFile string
+
+	mutex       sync.Mutex
+	certificate *tls.Certificate
+	modified    time.Time
+}
+
+func (c *reloadingCertificate) get(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	modified, err := lastModified(c.crtFile, c.keyFile)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	if c.certificate == nil || modified.After(c.modified) {
+		certificate, err := tls.LoadX509KeyPair(c.crtFile, c.keyFile)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		c.certificate = &certificate
+		c.modified = modified
+	}
+
+	return c.certificate, nil
+}
+
+func lastModified(paths ...string) (time.Time, error) {
+	var modified time.Time
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return time.Time{}, microerror.Mask(err)
+		}
+
+		if info.ModTime().After(modified) {
+			modified = info.ModTime()
+		}
+	}
+
+	return modified, nil
+}
+
+// metricsTLSOnlyHandler refuses metrics requests on the main listener when the
+// metrics are served with client certificates only, so the Azure inventory
+// can't be read without one. The health and version endpoints are still
+// served for probes.
+func metricsTLSOnlyHandler(h http.Handler, address string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == metricsPath {
+			http.Error(w, "metrics are served with client certificates on "+address+" only", http.StatusForbidden)
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
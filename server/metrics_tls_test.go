@@ -0,0 +1,262 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_newMetricsTLSServer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "metrics-tls")
+	if err != nil {
+		t.Fatalf("expected no error, got %#v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca, caKey := newTestCertificate(t, "ca", nil, nil)
+	otherCA, otherCAKey := newTestCertificate(t, "other-ca", nil, nil)
+	serverCrt, serverKey := newTestCertificate(t, "127.0.0.1", ca, caKey)
+	clientCrt, clientKey := newTestCertificate(t, "prometheus", ca, caKey)
+	otherClientCrt, otherClientKey := newTestCertificate(t, "prometheus", otherCA, otherCAKey)
+
+	config := metricsTLSConfig{
+		CAFile:  writeTestPEM(t, dir, "ca.crt", "CERTIFICATE", ca.Raw),
+		CrtFile: writeTestPEM(t, dir, "tls.crt", "CERTIFICATE", serverCrt.Raw),
+		KeyFile: writeTestPEM(t, dir, "tls.key", "EC PRIVATE KEY", marshalTestKey(t, serverKey)),
+	}
+
+	s, err := newMetricsTLSServer(config, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	if err != nil {
+		t.Fatalf("expected no error, got %#v", err)
+	}
+	// Handshake errors of refused clients are expected.
+	s.ErrorLog = log.New(ioutil.Discard, "", 0)
+
+	// httptest would serve its own certificate instead of the one of the
+	// server.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("expected no error, got %#v", err)
+	}
+	go func() { _ = s.ServeTLS(l, "", "") }()
+	defer s.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	testCases := []struct {
+		name         string
+		certificates []tls.Certificate
+		expectedCode int
+	}{
+		{
+			name:         "case 0: clients presenting a certificate signed by the CA are served",
+			certificates: []tls.Certificate{{Certificate: [][]byte{clientCrt.Raw}, PrivateKey: clientKey}},
+			expectedCode: http.StatusTeapot,
+		},
+		{
+			name:         "case 1: clients presenting no certificate are refused",
+			certificates: nil,
+		},
+		{
+			name:         "case 2: clients presenting a certificate signed by another CA are refused",
+			certificates: []tls.Certificate{{Certificate: [][]byte{otherClientCrt.Raw}, PrivateKey: otherClientKey}},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			client := &http.Client{
+				Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{
+						Certificates: tc.certificates,
+						RootCAs:      roots,
+					},
+				},
+			}
+
+			res, err := client.Get("https://" + l.Addr().String() + metricsPath)
+			if tc.expectedCode == 0 {
+				if err == nil {
+					res.Body.Close()
+					t.Fatalf("expected handshake error, got code %d", res.StatusCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %#v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != tc.expectedCode {
+				t.Fatalf("expected code %d, got %d", tc.expectedCode, res.StatusCode)
+			}
+		})
+	}
+}
+
+func Test_reloadingCertificate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "metrics-tls")
+	if err != nil {
+		t.Fatalf("expected no error, got %#v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca, caKey := newTestCertificate(t, "ca", nil, nil)
+
+	write := func(crt *x509.Certificate, key *ecdsa.PrivateKey, modified time.Time) {
+		crtFile := writeTestPEM(t, dir, "tls.crt", "CERTIFICATE", crt.Raw)
+		keyFile := writeTestPEM(t, dir, "tls.key", "EC PRIVATE KEY", marshalTestKey(t, key))
+
+		for _, p := range []string{crtFile, keyFile} {
+			err := os.Chtimes(p, modified, modified)
+			if err != nil {
+				t.Fatalf("expected no error, got %#v", err)
+			}
+		}
+	}
+
+	first, firstKey := newTestCertificate(t, "first", ca, caKey)
+	second, secondKey := newTestCertificate(t, "second", ca, caKey)
+
+	now := time.Now()
+	write(first, firstKey, now.Add(-time.Minute))
+
+	c := &reloadingCertificate{
+		crtFile: filepath.Join(dir, "tls.crt"),
+		keyFile: filepath.Join(dir, "tls.key"),
+	}
+
+	certificate, err := c.get(nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %#v", err)
+	}
+	if string(certificate.Certificate[0]) != string(first.Raw) {
+		t.Fatalf("expected first certificate")
+	}
+
+	write(second, secondKey, now)
+
+	certificate, err = c.get(nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %#v", err)
+	}
+	if string(certificate.Certificate[0]) != string(second.Raw) {
+		t.Fatalf("expected renewed certificate")
+	}
+}
+
+func Test_metricsTLSOnlyHandler(t *testing.T) {
+	testCases := []struct {
+		name         string
+		path         string
+		expectedCode int
+	}{
+		{
+			name:         "case 0: metrics are refused",
+			path:         "/metrics",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "case 1: health is served for probes",
+			path:         "/healthz",
+			expectedCode: http.StatusTeapot,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+			})
+
+			w := httptest.NewRecorder()
+			metricsTLSOnlyHandler(next, ":8443").ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			if w.Code != tc.expectedCode {
+				t.Fatalf("expected code %d, got %d", tc.expectedCode, w.Code)
+			}
+		})
+	}
+}
+
+// newTestCertificate returns a certificate with the given common name signed
+// by the given parent. It is a self-signed CA when there is no parent.
+func newTestCertificate(t *testing.T, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("expected no error, got %#v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+		template.ExtKeyUsage = nil
+		template.IPAddresses = nil
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("expected no error, got %#v", err)
+	}
+
+	crt, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("expected no error, got %#v", err)
+	}
+
+	return crt, key
+}
+
+func marshalTestKey(t *testing.T, key *ecdsa.PrivateKey) []byte {
+	b, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("expected no error, got %#v", err)
+	}
+
+	return b
+}
+
+func writeTestPEM(t *testing.T, dir, name, blockType string, b []byte) string {
+	p := filepath.Join(dir, name)
+
+	err := ioutil.WriteFile(p, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: b}), 0600)
+	if err != nil {
+		t.Fatalf("expected no error, got %#v", err)
+	}
+
+	return p
+}
@@ -12,14 +12,21 @@ import (
 	"github.com/giantswarm/micrologger"
 	"github.com/spf13/viper"
 
+	"github.com/giantswarm/azure-collector/v2/flag"
+	"github.com/giantswarm/azure-collector/v2/pkg/grpchealth"
+	"github.com/giantswarm/azure-collector/v2/pkg/logging"
 	"github.com/giantswarm/azure-collector/v2/server/endpoint"
 	"github.com/giantswarm/azure-collector/v2/service"
 )
 
 type Config struct {
-	Logger  micrologger.Logger
-	Service *service.Service
-	Viper   *viper.Viper
+	Flag   *flag.Flag
+	Logger micrologger.Logger
+	// LogWriter is the writer of Logger. The summaries of the repeated log
+	// lines it holds back are flushed on shutdown.
+	LogWriter *logging.Writer
+	Service   *service.Service
+	Viper     *viper.Viper
 
 	ProjectName string
 }
@@ -27,9 +34,15 @@ type Config struct {
 func New(config Config) (microserver.Server, error) {
 	var err error
 
+	if config.Flag == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Flag must not be empty", config)
+	}
 	if config.Logger == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
 	}
+	if config.LogWriter == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.LogWriter must not be empty", config)
+	}
 	if config.Service == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Service must not be empty", config)
 	}
@@ -41,6 +54,10 @@ func New(config Config) (microserver.Server, error) {
 		return nil, microerror.Maskf(invalidConfigError, "%T.ProjectName must not be empty", config)
 	}
 
+	// The collections of all collectors are bounded by the scrapes of the
+	// metrics endpoint and drained on shutdown.
+	collections := config.Service.Collections()
+
 	var endpointCollection *endpoint.Endpoint
 	{
 		c := endpoint.Config{
@@ -54,8 +71,60 @@ func New(config Config) (microserver.Server, error) {
 		}
 	}
 
+	endpoints := []indexEndpoint{
+		{Path: metricsPath, Description: "Metrics of all collectors in the Prometheus and OpenMetrics formats."},
+		{Path: "/healthz", Description: "Health of the collector."},
+		{Path: versionPath, Description: "Build information and collectors as JSON."},
+	}
+
+	// The gRPC health service reports the collector as not serving while
+	// the collections are drained on shutdown, like the metrics endpoint.
+	var grpcHealthServer *grpchealth.Server
+	if address := config.Viper.GetString(config.Flag.Service.GRPCHealth.Address); address != "" {
+		c := grpchealth.Config{
+			Logger:  config.Logger,
+			Serving: func() bool { return !collections.Draining() },
+
+			Address:  address,
+			Services: []string{config.ProjectName},
+		}
+
+		grpcHealthServer, err = grpchealth.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		endpoints = append(endpoints, indexEndpoint{Address: address, Description: "Standard grpc.health.v1 service over cleartext HTTP/2."})
+	}
+
+	// Installations where only the in-cluster Prometheus may read the Azure
+	// inventory serve the metrics to clients presenting a certificate signed
+	// by the configured CA only. The main listener still serves the health
+	// endpoint for probes, which can't present client certificates.
+	var metricsTLSServer *http.Server
+	metricsTLSAddress := config.Viper.GetString(config.Flag.Service.Metrics.TLS.Address)
+	if metricsTLSAddress != "" {
+		c := metricsTLSConfig{
+			Address: metricsTLSAddress,
+			CAFile:  config.Viper.GetString(config.Flag.Service.Metrics.TLS.CAFile),
+			CrtFile: config.Viper.GetString(config.Flag.Service.Metrics.TLS.CrtFile),
+			KeyFile: config.Viper.GetString(config.Flag.Service.Metrics.TLS.KeyFile),
+		}
+
+		metricsTLSServer, err = newMetricsTLSServer(c, drainingHandler(scrapeHandler(openMetricsHandler(http.NotFoundHandler()), collections), collections))
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		endpoints[0] = indexEndpoint{Address: metricsTLSAddress + metricsPath, Description: "Metrics of all collectors in the Prometheus and OpenMetrics formats served with TLS to clients presenting a certificate signed by the configured CA."}
+	}
+
 	newServer := &server{
-		logger: config.Logger,
+		logger:           config.Logger,
+		logWriter:        config.LogWriter,
+		grpcHealthServer: grpcHealthServer,
+		metricsTLSServer: metricsTLSServer,
+		service:          config.Service,
 
 		bootOnce: sync.Once{},
 		config: microserver.Config{
@@ -65,9 +134,21 @@ func New(config Config) (microserver.Server, error) {
 
 			Endpoints: []microserver.Endpoint{
 				endpointCollection.Healthz,
-				endpointCollection.Version,
 			},
 			ErrorEncoder: encodeError,
+			// Collections are bounded by the timeout of the Prometheus
+			// scrape they serve and traced as part of it. No scrapes
+			// are accepted anymore while shutting down. Exemplars are
+			// served to scrapers negotiating OpenMetrics. The build
+			// information is served on /version, and a landing page
+			// listing the endpoints and metrics on /.
+			HandlerWrapper: func(h http.Handler) http.Handler {
+				h = versionHandler(drainingHandler(scrapeHandler(openMetricsHandler(h), collections), collections), config.Service.BuildInfo())
+				if metricsTLSAddress != "" {
+					h = metricsTLSOnlyHandler(h, metricsTLSAddress)
+				}
+				return indexHandler(h, config.ProjectName, endpoints, config.Service.CollectorStatuses)
+			},
 		},
 		shutdownOnce: sync.Once{},
 	}
@@ -76,7 +157,11 @@ func New(config Config) (microserver.Server, error) {
 }
 
 type server struct {
-	logger micrologger.Logger
+	logger           micrologger.Logger
+	logWriter        *logging.Writer
+	grpcHealthServer *grpchealth.Server
+	metricsTLSServer *http.Server
+	service          *service.Service
 
 	bootOnce     sync.Once
 	config       microserver.Config
@@ -85,8 +170,19 @@ type server struct {
 
 func (s *server) Boot() {
 	s.bootOnce.Do(func() {
-		// Here goes your custom boot logic for your server/endpoint if
-		// any.
+		if s.grpcHealthServer != nil {
+			s.grpcHealthServer.Boot()
+		}
+
+		if s.metricsTLSServer != nil {
+			go func() {
+				// The certificate is provided by the TLS config.
+				err := s.metricsTLSServer.ListenAndServeTLS("", "")
+				if err != nil && err != http.ErrServerClosed {
+					s.logger.Errorf(context.Background(), err, "failed to serve metrics with TLS on %#q", s.metricsTLSServer.Addr)
+				}
+			}()
+		}
 	})
 }
 
@@ -96,8 +192,22 @@ func (s *server) Config() microserver.Config {
 
 func (s *server) Shutdown() {
 	s.shutdownOnce.Do(func() {
-		// Here goes your custom shutdown logic for your
-		// server/endpoint if any.
+		s.service.Shutdown()
+
+		if s.grpcHealthServer != nil {
+			s.grpcHealthServer.Shutdown()
+		}
+
+		if s.metricsTLSServer != nil {
+			_ = s.metricsTLSServer.Close()
+		}
+
+		// Nothing is logged by the collectors anymore once the service
+		// is shut down.
+		err := s.logWriter.Flush()
+		if err != nil {
+			s.logger.Errorf(context.Background(), err, "failed to flush repeated log lines")
+		}
 	})
 }
 
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/giantswarm/azure-collector/v2/service"
+)
+
+func Test_versionHandler(t *testing.T) {
+	info := service.BuildInfo{
+		Version:    "2.4.1",
+		GitCommit:  "abc123",
+		GoVersion:  "go1.15.8",
+		Collectors: []string{"collector.Deployment", "collector.Usage"},
+	}
+
+	testCases := []struct {
+		name             string
+		method           string
+		path             string
+		expectedCode     int
+		expectedInfo     *service.BuildInfo
+		expectedNextCall bool
+	}{
+		{
+			name:         "case 0: build information is served",
+			method:       http.MethodGet,
+			path:         "/version",
+			expectedCode: http.StatusOK,
+			expectedInfo: &info,
+		},
+		{
+			name:         "case 1: other methods are not allowed",
+			method:       http.MethodPost,
+			path:         "/version",
+			expectedCode: http.StatusMethodNotAllowed,
+		},
+		{
+			name:             "case 2: other paths are passed on",
+			method:           http.MethodGet,
+			path:             "/metrics",
+			expectedCode:     http.StatusTeapot,
+			expectedNextCall: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			var nextCalled bool
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				nextCalled = true
+				w.WriteHeader(http.StatusTeapot)
+			})
+
+			w := httptest.NewRecorder()
+			versionHandler(next, info).ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+
+			if w.Code != tc.expectedCode {
+				t.Fatalf("expected code %d, got %d", tc.expectedCode, w.Code)
+			}
+			if nextCalled != tc.expectedNextCall {
+				t.Fatalf("expected next handler called %t, got %t", tc.expectedNextCall, nextCalled)
+			}
+
+			if tc.expectedInfo != nil {
+				var served service.BuildInfo
+				err := json.Unmarshal(w.Body.Bytes(), &served)
+				if err != nil {
+					t.Fatalf("expected no error, got %#v", err)
+				}
+
+				if !cmp.Equal(served, *tc.expectedInfo) {
+					t.Fatalf("\n\n%s\n", cmp.Diff(*tc.expectedInfo, served))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"runtime"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/service/collector"
+)
+
+var (
+	buildInfoGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: collector.MetricsNamespace,
+		Name:      "build_info",
+		Help:      "Build information of the collector. The value is always 1.",
+	}, []string{
+		"version",
+		"revision",
+		"goversion",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(buildInfoGauge)
+}
+
+// BuildInfo is the build information of the service along with the
+// collectors it runs.
+type BuildInfo struct {
+	Version    string   `json:"version"`
+	GitCommit  string   `json:"gitCommit"`
+	GoVersion  string   `json:"goVersion"`
+	Collectors []string `json:"collectors"`
+}
+
+func newBuildInfo(version string, gitCommit string, collectors []string) BuildInfo {
+	info := BuildInfo{
+		Version:    version,
+		GitCommit:  gitCommit,
+		GoVersion:  runtime.Version(),
+		Collectors: collectors,
+	}
+
+	buildInfoGauge.WithLabelValues(info.Version, info.GitCommit, info.GoVersion).Set(1)
+
+	return info
+}
@@ -0,0 +1,215 @@
+// Package clusterlabels resolves the labels attached to the series of the
+// workload clusters of a Giant Swarm management cluster, e.g. the release and
+// Kubernetes versions given by their Release CRs and the organization owning
+// them, so the fleet can be queried by release and customer without joining
+// other metrics.
+package clusterlabels
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	releasev1alpha1 "github.com/giantswarm/apiextensions/v3/pkg/apis/release/v1alpha1"
+	securityv1alpha1 "github.com/giantswarm/apiextensions/v3/pkg/apis/security/v1alpha1"
+	"github.com/giantswarm/apiextensions/v3/pkg/label"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capiv1alpha3 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// KubernetesVersion is the label holding the Kubernetes version of the
+	// release of the cluster.
+	KubernetesVersion = "kubernetes_version"
+	// Organization is the label holding the name of the organization owning
+	// the cluster.
+	Organization = "organization"
+	// ReleaseVersion is the label holding the release version of the
+	// cluster.
+	ReleaseVersion = "release_version"
+
+	kubernetesComponentName = "kubernetes"
+
+	// organizationNamespacePrefix is the prefix of the namespaces of the
+	// organizations, which are named org-<organization>.
+	organizationNamespacePrefix = "org-"
+
+	// refreshInterval is how often the clusters and their releases are
+	// looked up again. Releases change on cluster upgrades only.
+	refreshInterval = 5 * time.Minute
+)
+
+type Config struct {
+	CtrlClient ctrlclient.Client
+	Logger     micrologger.Logger
+}
+
+// Resolver periodically resolves the labels of all workload clusters from
+// their Cluster, Release and Organization CRs.
+type Resolver struct {
+	ctrlClient ctrlclient.Client
+	logger     micrologger.Logger
+
+	mutex sync.RWMutex
+	// labels are the labels of the clusters by cluster ID. It is nil until
+	// the clusters have been looked up once.
+	labels map[string]map[string]string
+}
+
+func New(config Config) (*Resolver, error) {
+	if config.CtrlClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CtrlClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	r := &Resolver{
+		ctrlClient: config.CtrlClient,
+		logger:     config.Logger,
+	}
+
+	return r, nil
+}
+
+// Boot periodically resolves the labels of the clusters until the given
+// context is canceled.
+func (r *Resolver) Boot(ctx context.Context) {
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		err := r.refresh(ctx)
+		if err != nil {
+			r.logger.Errorf(ctx, err, "failed to resolve cluster labels")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Labels returns the labels of the given cluster. Clusters which are unknown
+// or not looked up yet have no labels. The returned map must not be modified.
+func (r *Resolver) Labels(clusterID string) map[string]string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.labels[clusterID]
+}
+
+func (r *Resolver) refresh(ctx context.Context) error {
+	clusters := &capiv1alpha3.ClusterList{}
+	err := r.ctrlClient.List(ctx, clusters, ctrlclient.InNamespace(metav1.NamespaceAll))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	// Clusters are still labeled by the organization label and their
+	// releases when the organizations can't be looked up.
+	organizations, err := r.getOrganizationNamespaces(ctx)
+	if err != nil {
+		r.logger.Errorf(ctx, err, "failed to list organizations")
+	}
+
+	// Many clusters share the same release, so we only fetch every Release
+	// CR once. Releases which fail to be fetched are only tried once too,
+	// and their clusters are left without release labels.
+	kubernetesVersions := map[string]string{}
+	failedReleases := map[string]bool{}
+	labels := map[string]map[string]string{}
+	for _, cluster := range clusters.Items {
+		clusterLabels := map[string]string{}
+
+		if releaseVersion := cluster.Labels[label.ReleaseVersion]; releaseVersion != "" && !failedReleases[releaseVersion] {
+			kubernetesVersion, ok := kubernetesVersions[releaseVersion]
+			if !ok {
+				kubernetesVersion, err = r.getKubernetesVersion(ctx, releaseVersion)
+				if err != nil {
+					r.logger.Errorf(ctx, err, "failed to get Release %#q", releaseVersion)
+					failedReleases[releaseVersion] = true
+				} else {
+					kubernetesVersions[releaseVersion] = kubernetesVersion
+					ok = true
+				}
+			}
+
+			if ok {
+				clusterLabels[KubernetesVersion] = kubernetesVersion
+				clusterLabels[ReleaseVersion] = releaseVersion
+			}
+		}
+
+		if organization := organizationOf(cluster, organizations); organization != "" {
+			clusterLabels[Organization] = organization
+		}
+
+		if len(clusterLabels) > 0 {
+			labels[cluster.Name] = clusterLabels
+		}
+	}
+
+	r.mutex.Lock()
+	r.labels = labels
+	r.mutex.Unlock()
+
+	return nil
+}
+
+// getOrganizationNamespaces returns the names of the organizations by their
+// namespace.
+func (r *Resolver) getOrganizationNamespaces(ctx context.Context) (map[string]string, error) {
+	organizations := &securityv1alpha1.OrganizationList{}
+	err := r.ctrlClient.List(ctx, organizations)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	namespaces := map[string]string{}
+	for _, organization := range organizations.Items {
+		namespaces[organizationNamespacePrefix+organization.Name] = organization.Name
+	}
+
+	return namespaces, nil
+}
+
+// getKubernetesVersion returns the Kubernetes version of the given release,
+// or an empty string when the Release CR does not exist.
+func (r *Resolver) getKubernetesVersion(ctx context.Context, releaseVersion string) (string, error) {
+	release := &releasev1alpha1.Release{}
+	err := r.ctrlClient.Get(ctx, ctrlclient.ObjectKey{Name: "v" + strings.TrimPrefix(releaseVersion, "v")}, release)
+	if apierrors.IsNotFound(err) {
+		r.logger.Debugf(ctx, "Release %#q not found", releaseVersion)
+		return "", nil
+	} else if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	for _, component := range release.Spec.Components {
+		if component.Name == kubernetesComponentName {
+			return component.Version, nil
+		}
+	}
+
+	return "", nil
+}
+
+// organizationOf returns the name of the organization owning the given
+// cluster. Clusters created before organizations had namespaces only carry the
+// organization label, while the ones in the namespace of an organization may
+// lack it. It is empty when the cluster has no organization.
+func organizationOf(cluster capiv1alpha3.Cluster, organizations map[string]string) string {
+	if organization := cluster.Labels[label.Organization]; organization != "" {
+		return organization
+	}
+
+	return organizations[cluster.Namespace]
+}
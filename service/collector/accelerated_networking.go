@@ -0,0 +1,218 @@
+package collector
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	acceleratedNetworkingCapability = "AcceleratedNetworkingEnabled"
+
+	acceleratedNetworkingEnabled     = "enabled"
+	acceleratedNetworkingDisabled    = "disabled"
+	acceleratedNetworkingUnsupported = "unsupported"
+)
+
+var (
+	acceleratedNetworkingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "accelerated_networking", "nics"),
+		"Number of node pool NICs by accelerated networking state. The disabled state means the VM size supports accelerated networking but it is not enabled.",
+		[]string{
+			"cluster_id",
+			"node_pool",
+			"vm_size",
+			"state",
+		},
+		nil,
+	)
+)
+
+type AcceleratedNetworkingConfig struct {
+	CredentialSource *credential.Source
+	Location         string
+	Collections      *Collections
+	Logger           micrologger.Logger
+	GSTenantID       string
+}
+
+type AcceleratedNetworking struct {
+	credentialSource *credential.Source
+	location         string
+	collections      *Collections
+	logger           micrologger.Logger
+	gsTenantID       string
+}
+
+// NewAcceleratedNetworking exposes metrics about the accelerated networking configuration of the cluster node pools.
+// Mixed configurations within an installation cause inconsistent network performance between nodes.
+func NewAcceleratedNetworking(config AcceleratedNetworkingConfig) (*AcceleratedNetworking, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Location == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Location must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	a := &AcceleratedNetworking{
+		credentialSource: config.CredentialSource,
+		location:         config.Location,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		gsTenantID:       config.GSTenantID,
+	}
+
+	return a, nil
+}
+
+func (a *AcceleratedNetworking) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := a.collections.newCollectContext("accelerated_networking")
+	defer cancel()
+
+	azureClientSets, err := getClientSetsByCluster(ctx, a.credentialSource, a.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	// The VM sizes supporting accelerated networking only depend on the
+	// subscription and location, so we look them up once per subscription.
+	supportedBySubscription := newPerSubscription()
+
+	forEachCluster(ctx, ch, azureClientSets, func(ctx context.Context, ch chan<- prometheus.Metric, clusterID string, azureClientSet *client.AzureClientSet) error {
+		subscriptionID := azureClientSet.VirtualMachineScaleSetsClient.SubscriptionID
+
+		v, err := supportedBySubscription.get(subscriptionID, func() (interface{}, error) {
+			return a.getSupportedVMSizes(ctx, azureClientSet.ResourceSkusClient)
+		})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		supported := v.(map[string]bool)
+
+		scaleSets, err := listScaleSets(ctx, azureClientSet.VirtualMachineScaleSetsClient, clusterID)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, vmss := range scaleSets {
+			var vmSize string
+			var capacity int64
+			if vmss.Sku != nil {
+				vmSize = to.String(vmss.Sku.Name)
+				capacity = to.Int64(vmss.Sku.Capacity)
+			}
+
+			counts := map[string]int64{
+				acceleratedNetworkingEnabled:     0,
+				acceleratedNetworkingDisabled:    0,
+				acceleratedNetworkingUnsupported: 0,
+			}
+			for _, nic := range scaleSetNICConfigurations(vmss) {
+				state := acceleratedNetworkingUnsupported
+				if nic.VirtualMachineScaleSetNetworkConfigurationProperties != nil && to.Bool(nic.EnableAcceleratedNetworking) {
+					state = acceleratedNetworkingEnabled
+				} else if supported[strings.ToLower(vmSize)] {
+					state = acceleratedNetworkingDisabled
+				}
+
+				// Every instance of the scale set gets one NIC per NIC
+				// configuration.
+				counts[state] += capacity
+			}
+
+			for state, count := range counts {
+				ch <- prometheus.MustNewConstMetric(
+					acceleratedNetworkingDesc,
+					prometheus.GaugeValue,
+					float64(count),
+					clusterID,
+					to.String(vmss.Name),
+					vmSize,
+					state,
+				)
+			}
+		}
+
+		return nil
+	})
+
+	return nil
+}
+
+func (a *AcceleratedNetworking) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- acceleratedNetworkingDesc
+	return nil
+}
+
+// getSupportedVMSizes returns the lower cased names of the VM sizes which
+// support accelerated networking in the installation location.
+func (a *AcceleratedNetworking) getSupportedVMSizes(ctx context.Context, resourceSkusClient *compute.ResourceSkusClient) (map[string]bool, error) {
+	supported := map[string]bool{}
+
+	skus, err := resourceSkusClient.ListComplete(ctx, "location eq '"+a.location+"'")
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	for skus.NotDone() {
+		sku := skus.Value()
+
+		if strings.EqualFold(to.String(sku.ResourceType), "virtualMachines") && skuAvailableInLocation(sku, a.location) && sku.Capabilities != nil {
+			for _, capability := range *sku.Capabilities {
+				if to.String(capability.Name) == acceleratedNetworkingCapability && strings.EqualFold(to.String(capability.Value), "True") {
+					supported[strings.ToLower(to.String(sku.Name))] = true
+				}
+			}
+		}
+
+		if err := skus.NextWithContext(ctx); err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	return supported, nil
+}
+
+// scaleSetNICConfigurations returns the NIC configurations of the scale set
+// model.
+func scaleSetNICConfigurations(vmss compute.VirtualMachineScaleSet) []compute.VirtualMachineScaleSetNetworkConfiguration {
+	if vmss.VirtualMachineScaleSetProperties == nil ||
+		vmss.VirtualMachineProfile == nil ||
+		vmss.VirtualMachineProfile.NetworkProfile == nil ||
+		vmss.VirtualMachineProfile.NetworkProfile.NetworkInterfaceConfigurations == nil {
+		return nil
+	}
+
+	return *vmss.VirtualMachineProfile.NetworkProfile.NetworkInterfaceConfigurations
+}
+
+func skuAvailableInLocation(sku compute.ResourceSku, location string) bool {
+	if sku.Locations == nil {
+		return false
+	}
+
+	for _, l := range *sku.Locations {
+		if strings.EqualFold(l, location) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,210 @@
+package collector
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/preview/monitor/mgmt/2019-06-01/insights"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	activityLogStatusFailed = "Failed"
+	activityLogSelect       = "eventTimestamp,operationName,status,resourceGroupName,resourceProviderName"
+
+	// activityLogDelay is how long it takes for events to show up in the
+	// Activity Log. We only query events older than that, so we don't miss
+	// events which arrive late.
+	activityLogDelay = 5 * time.Minute
+)
+
+// activityLogOperations are the suffixes of the operations we track. Reads
+// are left out, since they don't change anything.
+var activityLogOperations = []string{
+	"write",
+	"delete",
+}
+
+var (
+	activityLogFailedOperationsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: MetricsNamespace,
+			Subsystem: "activity_log",
+			Name:      "failed_operations_total",
+			Help:      "Total number of failed write and delete operations found in the Activity Log, e.g. because of quota errors, policy denials or missing permissions.",
+		},
+		[]string{
+			"subscription",
+			"resource_provider",
+			"resource_group",
+			"operation",
+		},
+	)
+)
+
+type ActivityLogConfig struct {
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+
+	GSTenantID string
+}
+
+type ActivityLog struct {
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+
+	failedOperations *prometheus.CounterVec
+
+	gsTenantID string
+
+	// lastEventTimestamps holds the end of the last queried timespan per
+	// subscription.
+	lastEventTimestamps map[string]time.Time
+	mutex               sync.Mutex
+}
+
+func init() {
+	prometheus.MustRegister(activityLogFailedOperationsCounter)
+}
+
+// NewActivityLog tails the Activity Log of the subscriptions found in the "credential-*" secrets of the control
+// plane and counts failed write and delete operations per resource provider and resource group. This surfaces quota
+// errors, policy denials and RBAC failures the operator hides.
+func NewActivityLog(config ActivityLogConfig) (*ActivityLog, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	a := &ActivityLog{
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		failedOperations: activityLogFailedOperationsCounter,
+		gsTenantID:       config.GSTenantID,
+
+		lastEventTimestamps: map[string]time.Time{},
+	}
+
+	return a, nil
+}
+
+func (a *ActivityLog) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := a.collections.newCollectContext("activity_log")
+	defer cancel()
+	clientSets, err := credential.GetAzureClientSetsFromCredentialSecretsBySubscription(ctx, a.credentialSource, a.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	end := time.Now().Add(-activityLogDelay).UTC()
+	for subscriptionID, azureClientSet := range clientSets {
+		start, ok := a.lastEventTimestamps[subscriptionID]
+		if !ok {
+			// We only count events which happen after we started, so the
+			// counters don't jump on restarts.
+			a.lastEventTimestamps[subscriptionID] = end
+			continue
+		}
+
+		err := a.countFailedOperations(ctx, azureClientSet.ActivityLogsClient, subscriptionID, start, end)
+		if err != nil {
+			// We retry the same timespan during the next scrape.
+			a.logger.Errorf(ctx, err, "an error occurred querying the Activity Log of subscription %#q", subscriptionID)
+			continue
+		}
+
+		a.lastEventTimestamps[subscriptionID] = end
+	}
+
+	return nil
+}
+
+func (a *ActivityLog) Describe(ch chan<- *prometheus.Desc) error {
+	return nil
+}
+
+func (a *ActivityLog) countFailedOperations(ctx context.Context, activityLogsClient *insights.ActivityLogsClient, subscriptionID string, start, end time.Time) error {
+	filter := "eventTimestamp ge '" + start.Format(time.RFC3339) + "' and eventTimestamp le '" + end.Format(time.RFC3339) + "'"
+
+	// Counting is done only after the whole timespan was read, so a failing
+	// request does not lead to counting events twice.
+	counts := map[[3]string]int{}
+
+	iterator, err := activityLogsClient.ListComplete(ctx, filter, activityLogSelect)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for iterator.NotDone() {
+		event := iterator.Value()
+
+		// The end of the timespan is inclusive, so it is excluded from the
+		// next query.
+		if event.EventTimestamp != nil && !event.EventTimestamp.ToTime().Equal(start) && event.Status != nil && to.String(event.Status.Value) == activityLogStatusFailed {
+			operation := activityLogOperation(event)
+			resourceGroup := strings.ToLower(to.String(event.ResourceGroupName))
+
+			// Operations on the subscription itself have no resource group
+			// and are always counted.
+			if operation != "" && (resourceGroup == "" || a.credentialSource.MatchResourceGroup(resourceGroup)) {
+				var provider string
+				if event.ResourceProviderName != nil {
+					provider = to.String(event.ResourceProviderName.Value)
+				}
+
+				counts[[3]string{provider, resourceGroup, operation}]++
+			}
+		}
+
+		err = iterator.NextWithContext(ctx)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	for k, count := range counts {
+		a.failedOperations.WithLabelValues(subscriptionID, k[0], k[1], k[2]).Add(float64(count))
+	}
+
+	return nil
+}
+
+// activityLogOperation returns the tracked operation type of the given event,
+// e.g. "write" for "Microsoft.Compute/virtualMachineScaleSets/write", or an
+// empty string for operations which are not tracked.
+func activityLogOperation(event insights.EventData) string {
+	if event.OperationName == nil {
+		return ""
+	}
+
+	name := strings.ToLower(to.String(event.OperationName.Value))
+	for _, o := range activityLogOperations {
+		if strings.HasSuffix(name, "/"+o) {
+			return o
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,126 @@
+package collector
+
+import (
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+)
+
+var (
+	armBudgetCallsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "arm_budget", "calls"),
+		"Number of ARM calls the collector made in the last hour per subscription.",
+		[]string{
+			"subscription",
+		},
+		nil,
+	)
+	armBudgetLimitDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "arm_budget", "limit"),
+		"Maximum number of ARM calls per hour and subscription the collector is allowed to make.",
+		nil,
+		nil,
+	)
+	armBudgetExhaustedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "arm_budget", "exhausted"),
+		"Whether the ARM call budget of the subscription is exhausted, in which case only critical collectors are collected.",
+		[]string{
+			"subscription",
+		},
+		nil,
+	)
+	armBudgetThrottledUntilDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "arm_budget", "throttled_until_seconds"),
+		"Unix time until which calls to the subscription are refused as Azure throttled them. It is kept across restarts when a state file is configured.",
+		[]string{
+			"subscription",
+		},
+		nil,
+	)
+)
+
+type ARMBudgetConfig struct {
+	ClientFactory *client.Factory
+	Logger        micrologger.Logger
+}
+
+type ARMBudget struct {
+	clientFactory *client.Factory
+	logger        micrologger.Logger
+}
+
+// NewARMBudget exposes the number of ARM calls the collector made per subscription against the configured budget,
+// so the share of the subscription rate limits consumed by the collector is visible.
+func NewARMBudget(config ARMBudgetConfig) (*ARMBudget, error) {
+	if config.ClientFactory == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ClientFactory must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	a := &ARMBudget{
+		clientFactory: config.ClientFactory,
+		logger:        config.Logger,
+	}
+
+	return a, nil
+}
+
+func (a *ARMBudget) Collect(ch chan<- prometheus.Metric) error {
+	limit := a.clientFactory.BudgetLimit()
+
+	for subscriptionID, calls := range a.clientFactory.BudgetCalls() {
+		ch <- prometheus.MustNewConstMetric(
+			armBudgetCallsDesc,
+			prometheus.GaugeValue,
+			float64(calls),
+			subscriptionID,
+		)
+
+		if limit == 0 {
+			continue
+		}
+
+		var exhausted float64
+		if calls >= limit {
+			exhausted = 1
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			armBudgetExhaustedDesc,
+			prometheus.GaugeValue,
+			exhausted,
+			subscriptionID,
+		)
+	}
+
+	for subscriptionID, until := range a.clientFactory.ThrottledSubscriptions() {
+		ch <- prometheus.MustNewConstMetric(
+			armBudgetThrottledUntilDesc,
+			prometheus.GaugeValue,
+			float64(until.Unix()),
+			subscriptionID,
+		)
+	}
+
+	if limit > 0 {
+		ch <- prometheus.MustNewConstMetric(
+			armBudgetLimitDesc,
+			prometheus.GaugeValue,
+			float64(limit),
+		)
+	}
+
+	return nil
+}
+
+func (a *ARMBudget) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- armBudgetCallsDesc
+	ch <- armBudgetLimitDesc
+	ch <- armBudgetExhaustedDesc
+	ch <- armBudgetThrottledUntilDesc
+	return nil
+}
@@ -0,0 +1,316 @@
+package collector
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	recoveryServicesVaultResourceType = "Microsoft.RecoveryServices/vaults"
+	recoveryServicesAPIVersion        = "2021-02-10"
+	backupVaultResourceType           = "Microsoft.DataProtection/backupVaults"
+	backupVaultAPIVersion             = "2021-07-01"
+
+	backupJobOperationBackup = "backup"
+	backupJobStatusFailed    = "failed"
+)
+
+// backupJob is a backup job of either a Recovery Services vault or a Backup
+// vault.
+type backupJob struct {
+	Item      string
+	Operation string
+	Status    string
+	StartTime time.Time
+}
+
+// recoveryServicesJobList holds the subset of the Recovery Services backup job
+// properties we need.
+type recoveryServicesJobList struct {
+	NextLink string `json:"nextLink"`
+	Value    []struct {
+		Properties struct {
+			EntityFriendlyName string     `json:"entityFriendlyName"`
+			Operation          string     `json:"operation"`
+			StartTime          *time.Time `json:"startTime"`
+			Status             string     `json:"status"`
+		} `json:"properties"`
+	} `json:"value"`
+}
+
+// backupVaultJobList holds the subset of the Backup vault job properties we
+// need.
+type backupVaultJobList struct {
+	NextLink string `json:"nextLink"`
+	Value    []struct {
+		Properties struct {
+			DataSourceName    string     `json:"dataSourceName"`
+			OperationCategory string     `json:"operationCategory"`
+			StartTime         *time.Time `json:"startTime"`
+			Status            string     `json:"status"`
+		} `json:"properties"`
+	} `json:"value"`
+}
+
+var (
+	backupJobLatestStatusDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "backup_job", "latest_status"),
+		"Status of the latest backup job of the protected item.",
+		[]string{
+			"resource_group",
+			"vault",
+			"item",
+			"status",
+		},
+		nil,
+	)
+	backupJobLatestAgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "backup_job", "latest_age_seconds"),
+		"Time since the latest backup job of the protected item started.",
+		[]string{
+			"resource_group",
+			"vault",
+			"item",
+		},
+		nil,
+	)
+	backupJobFailedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "backup_job", "failed"),
+		"Number of failed backup jobs of the vault which are still listed by Azure.",
+		[]string{
+			"resource_group",
+			"vault",
+		},
+		nil,
+	)
+)
+
+type BackupJobConfig struct {
+	CredentialSource          *credential.Source
+	Collections               *Collections
+	Logger                    micrologger.Logger
+	ControlPlaneResourceGroup string
+	GSTenantID                string
+}
+
+type BackupJob struct {
+	credentialSource          *credential.Source
+	collections               *Collections
+	logger                    micrologger.Logger
+	controlPlaneResourceGroup string
+	gsTenantID                string
+}
+
+// NewBackupJob exposes the status and age of the latest backup jobs of the Recovery Services vaults and Backup vaults
+// in the managed resource groups, e.g. the ones backing up etcd disks, together with the number of failed jobs.
+func NewBackupJob(config BackupJobConfig) (*BackupJob, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.ControlPlaneResourceGroup == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ControlPlaneResourceGroup must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	b := &BackupJob{
+		credentialSource:          config.CredentialSource,
+		collections:               config.Collections,
+		logger:                    config.Logger,
+		controlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+		gsTenantID:                config.GSTenantID,
+	}
+
+	return b, nil
+}
+
+func (b *BackupJob) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := b.collections.newCollectContext("backup_job")
+	defer cancel()
+
+	resourceGroups, err := getManagedResourceGroups(ctx, b.credentialSource, b.gsTenantID, b.controlPlaneResourceGroup)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	listers := map[string]func(context.Context, *client.RESTClient, string) ([]backupJob, error){
+		recoveryServicesVaultResourceType: getRecoveryServicesJobs,
+		backupVaultResourceType:           getBackupVaultJobs,
+	}
+
+	now := time.Now()
+	for _, resourceGroup := range resourceGroups {
+		for resourceType, listJobs := range listers {
+			vaults, err := getResourcesByType(ctx, resourceGroup.AzureClientSet.ResourcesClient, resourceGroup.Name, resourceType)
+			if IsNotFound(err) {
+				break
+			} else if err != nil {
+				return microerror.Mask(err)
+			}
+
+			for _, vault := range vaults {
+				vaultName := to.String(vault.Name)
+
+				jobs, err := listJobs(ctx, resourceGroup.AzureClientSet.RESTClient, to.String(vault.ID))
+				if err != nil {
+					b.logger.Errorf(ctx, err, "an error occurred listing the backup jobs of vault %#q", vaultName)
+					continue
+				}
+
+				latest, failed := summarizeBackupJobs(jobs)
+
+				for item, job := range latest {
+					ch <- prometheus.MustNewConstMetric(
+						backupJobLatestStatusDesc,
+						prometheus.GaugeValue,
+						gaugeValue,
+						resourceGroup.Name,
+						vaultName,
+						item,
+						job.Status,
+					)
+					ch <- prometheus.MustNewConstMetric(
+						backupJobLatestAgeDesc,
+						prometheus.GaugeValue,
+						now.Sub(job.StartTime).Seconds(),
+						resourceGroup.Name,
+						vaultName,
+						item,
+					)
+				}
+
+				ch <- prometheus.MustNewConstMetric(
+					backupJobFailedDesc,
+					prometheus.GaugeValue,
+					float64(failed),
+					resourceGroup.Name,
+					vaultName,
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (b *BackupJob) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- backupJobLatestStatusDesc
+	ch <- backupJobLatestAgeDesc
+	ch <- backupJobFailedDesc
+	return nil
+}
+
+func getRecoveryServicesJobs(ctx context.Context, restClient *client.RESTClient, vaultID string) ([]backupJob, error) {
+	var jobs []backupJob
+
+	var list recoveryServicesJobList
+	err := restClient.GetJSON(ctx, vaultID+"/backupJobs", recoveryServicesAPIVersion, &list)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	for {
+		for _, j := range list.Value {
+			if j.Properties.StartTime == nil {
+				continue
+			}
+
+			jobs = append(jobs, backupJob{
+				Item:      j.Properties.EntityFriendlyName,
+				Operation: j.Properties.Operation,
+				Status:    j.Properties.Status,
+				StartTime: *j.Properties.StartTime,
+			})
+		}
+
+		if list.NextLink == "" {
+			break
+		}
+
+		nextLink := list.NextLink
+		list = recoveryServicesJobList{}
+		err = restClient.GetNextJSON(ctx, nextLink, &list)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	return jobs, nil
+}
+
+func getBackupVaultJobs(ctx context.Context, restClient *client.RESTClient, vaultID string) ([]backupJob, error) {
+	var jobs []backupJob
+
+	var list backupVaultJobList
+	err := restClient.GetJSON(ctx, vaultID+"/backupJobs", backupVaultAPIVersion, &list)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	for {
+		for _, j := range list.Value {
+			if j.Properties.StartTime == nil {
+				continue
+			}
+
+			jobs = append(jobs, backupJob{
+				Item:      j.Properties.DataSourceName,
+				Operation: j.Properties.OperationCategory,
+				Status:    j.Properties.Status,
+				StartTime: *j.Properties.StartTime,
+			})
+		}
+
+		if list.NextLink == "" {
+			break
+		}
+
+		nextLink := list.NextLink
+		list = backupVaultJobList{}
+		err = restClient.GetNextJSON(ctx, nextLink, &list)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	return jobs, nil
+}
+
+// summarizeBackupJobs returns the latest backup job per protected item and
+// the number of failed backup jobs. Jobs of other operations, like restores,
+// are ignored.
+func summarizeBackupJobs(jobs []backupJob) (map[string]backupJob, int) {
+	latest := map[string]backupJob{}
+	var failed int
+
+	for _, job := range jobs {
+		if !strings.EqualFold(job.Operation, backupJobOperationBackup) {
+			continue
+		}
+		if strings.EqualFold(job.Status, backupJobStatusFailed) {
+			failed++
+		}
+
+		if l, ok := latest[job.Item]; !ok || job.StartTime.After(l.StartTime) {
+			latest[job.Item] = job
+		}
+	}
+
+	return latest, failed
+}
@@ -0,0 +1,164 @@
+package collector
+
+import (
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	bastionHostResourceType  = "Microsoft.Network/bastionHosts"
+	bastionHostSessionMetric = "sessions"
+)
+
+var (
+	bastionHostInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "bastion_host", "info"),
+		"Bastion host information.",
+		[]string{
+			"subscription",
+			"resource_group",
+			"name",
+			"sku",
+			"provisioning_state",
+		},
+		nil,
+	)
+	bastionHostSessionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "bastion_host", "sessions"),
+		"Average number of active sessions on the Bastion host as reported by Azure Monitor.",
+		[]string{
+			"subscription",
+			"resource_group",
+			"name",
+		},
+		nil,
+	)
+)
+
+type BastionHostConfig struct {
+	CredentialSource          *credential.Source
+	Collections               *Collections
+	Logger                    micrologger.Logger
+	ControlPlaneResourceGroup string
+	GSTenantID                string
+}
+
+type BastionHost struct {
+	credentialSource          *credential.Source
+	collections               *Collections
+	logger                    micrologger.Logger
+	controlPlaneResourceGroup string
+	gsTenantID                string
+}
+
+// NewBastionHost exposes metrics about the Azure Bastion hosts deployed in the control plane resource group.
+// Those hosts are used for break-glass access, so they need to be available when everything else is broken.
+func NewBastionHost(config BastionHostConfig) (*BastionHost, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.ControlPlaneResourceGroup == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ControlPlaneResourceGroup must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	b := &BastionHost{
+		credentialSource:          config.CredentialSource,
+		collections:               config.Collections,
+		logger:                    config.Logger,
+		controlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+		gsTenantID:                config.GSTenantID,
+	}
+
+	return b, nil
+}
+
+func (b *BastionHost) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := b.collections.newCollectContext("bastion_host")
+	defer cancel()
+
+	if !b.credentialSource.MatchResourceGroup(b.controlPlaneResourceGroup) {
+		return nil
+	}
+
+	clientSets, err := credential.GetAzureClientSetsFromCredentialSecretsBySubscription(ctx, b.credentialSource, b.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for subscriptionID, azureClientSet := range clientSets {
+		// We use the generic resources API because it returns the SKU of
+		// the Bastion hosts, which the network API version we use does not.
+		hosts, err := azureClientSet.ResourcesClient.ListByResourceGroupComplete(ctx, b.controlPlaneResourceGroup, "resourceType eq '"+bastionHostResourceType+"'", "provisioningState", nil)
+		if IsNotFound(err) {
+			// The control plane resource group only exists in the
+			// subscription of the control plane.
+			continue
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for hosts.NotDone() {
+			host := hosts.Value()
+
+			var sku string
+			if host.Sku != nil {
+				sku = to.String(host.Sku.Name)
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				bastionHostInfoDesc,
+				prometheus.GaugeValue,
+				gaugeValue,
+				subscriptionID,
+				b.controlPlaneResourceGroup,
+				to.String(host.Name),
+				sku,
+				to.String(host.ProvisioningState),
+			)
+
+			values, err := getMonitorMetricValues(ctx, azureClientSet.MetricsClient, to.String(host.ID), []string{bastionHostSessionMetric}, aggregationAverage, "")
+			if err != nil {
+				b.logger.Errorf(ctx, err, "an error occurred fetching the session count of Bastion host %#q", to.String(host.Name))
+			} else {
+				var sessions float64
+				for _, v := range values {
+					sessions += v.Value
+				}
+
+				ch <- prometheus.MustNewConstMetric(
+					bastionHostSessionsDesc,
+					prometheus.GaugeValue,
+					sessions,
+					subscriptionID,
+					b.controlPlaneResourceGroup,
+					to.String(host.Name),
+				)
+			}
+
+			if err := hosts.NextWithContext(ctx); err != nil {
+				return microerror.Mask(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (b *BastionHost) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- bastionHostInfoDesc
+	ch <- bastionHostSessionsDesc
+	return nil
+}
@@ -0,0 +1,192 @@
+package collector
+
+import (
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2019-06-01/storage"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	softDeleteScopeBlob      = "blob"
+	softDeleteScopeContainer = "container"
+)
+
+var (
+	blobSoftDeleteEnabledDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "storage_account", "soft_delete_enabled"),
+		"Whether soft delete is enabled for blobs or containers of the storage account.",
+		[]string{
+			"resource_group",
+			"storage_account",
+			"scope",
+		},
+		nil,
+	)
+	blobSoftDeleteRetentionDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "storage_account", "soft_delete_retention_days"),
+		"Number of days deleted blobs or containers of the storage account are retained.",
+		[]string{
+			"resource_group",
+			"storage_account",
+			"scope",
+		},
+		nil,
+	)
+	lifecyclePolicyRulesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "storage_account", "lifecycle_policy_rules"),
+		"Number of rules in the lifecycle management policy of the storage account. Zero means there is no policy.",
+		[]string{
+			"resource_group",
+			"storage_account",
+		},
+		nil,
+	)
+)
+
+type BlobDataProtectionConfig struct {
+	CredentialSource          *credential.Source
+	Collections               *Collections
+	Logger                    micrologger.Logger
+	ControlPlaneResourceGroup string
+	GSTenantID                string
+}
+
+type BlobDataProtection struct {
+	credentialSource          *credential.Source
+	collections               *Collections
+	logger                    micrologger.Logger
+	controlPlaneResourceGroup string
+	gsTenantID                string
+}
+
+// NewBlobDataProtection exposes whether soft delete and lifecycle management policies are configured on the storage
+// accounts in the managed resource groups. Without soft delete, accidentally deleted backups are gone for good.
+func NewBlobDataProtection(config BlobDataProtectionConfig) (*BlobDataProtection, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.ControlPlaneResourceGroup == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ControlPlaneResourceGroup must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	b := &BlobDataProtection{
+		credentialSource:          config.CredentialSource,
+		collections:               config.Collections,
+		logger:                    config.Logger,
+		controlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+		gsTenantID:                config.GSTenantID,
+	}
+
+	return b, nil
+}
+
+func (b *BlobDataProtection) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := b.collections.newCollectContext("blob_data_protection")
+	defer cancel()
+
+	resourceGroups, err := getManagedResourceGroups(ctx, b.credentialSource, b.gsTenantID, b.controlPlaneResourceGroup)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, resourceGroup := range resourceGroups {
+		azureClientSet := resourceGroup.AzureClientSet
+
+		accounts, err := azureClientSet.StorageAccountsClient.ListByResourceGroup(ctx, resourceGroup.Name)
+		if IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+		if accounts.Value == nil {
+			continue
+		}
+
+		for _, account := range *accounts.Value {
+			accountName := to.String(account.Name)
+
+			// Only general purpose and blob storage accounts have a blob
+			// service.
+			if account.Kind == storage.FileStorage {
+				continue
+			}
+
+			properties, err := azureClientSet.BlobServicesClient.GetServiceProperties(ctx, resourceGroup.Name, accountName)
+			if err != nil {
+				b.logger.Errorf(ctx, err, "an error occurred fetching the blob service properties of storage account %#q", accountName)
+			} else if properties.BlobServicePropertiesProperties != nil {
+				policies := map[string]*storage.DeleteRetentionPolicy{
+					softDeleteScopeBlob:      properties.DeleteRetentionPolicy,
+					softDeleteScopeContainer: properties.ContainerDeleteRetentionPolicy,
+				}
+
+				for scope, policy := range policies {
+					var enabled float64
+					var days int32
+					if policy != nil && to.Bool(policy.Enabled) {
+						enabled = 1
+						days = to.Int32(policy.Days)
+					}
+
+					ch <- prometheus.MustNewConstMetric(
+						blobSoftDeleteEnabledDesc,
+						prometheus.GaugeValue,
+						enabled,
+						resourceGroup.Name,
+						accountName,
+						scope,
+					)
+					ch <- prometheus.MustNewConstMetric(
+						blobSoftDeleteRetentionDesc,
+						prometheus.GaugeValue,
+						float64(days),
+						resourceGroup.Name,
+						accountName,
+						scope,
+					)
+				}
+			}
+
+			var rules int
+			policy, err := azureClientSet.ManagementPoliciesClient.Get(ctx, resourceGroup.Name, accountName)
+			if IsNotFound(err) {
+				// There is no lifecycle management policy.
+			} else if err != nil {
+				b.logger.Errorf(ctx, err, "an error occurred fetching the lifecycle management policy of storage account %#q", accountName)
+				continue
+			} else if policy.ManagementPolicyProperties != nil && policy.Policy != nil && policy.Policy.Rules != nil {
+				rules = len(*policy.Policy.Rules)
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				lifecyclePolicyRulesDesc,
+				prometheus.GaugeValue,
+				float64(rules),
+				resourceGroup.Name,
+				accountName,
+			)
+		}
+	}
+
+	return nil
+}
+
+func (b *BlobDataProtection) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- blobSoftDeleteEnabledDesc
+	ch <- blobSoftDeleteRetentionDesc
+	ch <- lifecyclePolicyRulesDesc
+	return nil
+}
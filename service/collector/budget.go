@@ -0,0 +1,198 @@
+package collector
+
+import (
+	"strconv"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	consumptionAPIVersion = "2021-10-01"
+)
+
+// budgetList holds the subset of the budget properties we need.
+type budgetList struct {
+	NextLink string `json:"nextLink"`
+	Value    []struct {
+		Name       string `json:"name"`
+		Properties struct {
+			Amount       float64 `json:"amount"`
+			TimeGrain    string  `json:"timeGrain"`
+			CurrentSpend struct {
+				Amount float64 `json:"amount"`
+				Unit   string  `json:"unit"`
+			} `json:"currentSpend"`
+			Notifications map[string]struct {
+				Enabled       bool    `json:"enabled"`
+				Threshold     float64 `json:"threshold"`
+				ThresholdType string  `json:"thresholdType"`
+			} `json:"notifications"`
+		} `json:"properties"`
+	} `json:"value"`
+}
+
+var (
+	budgetAmountDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "budget", "amount"),
+		"Amount of the budget per time grain.",
+		[]string{
+			"subscription",
+			"budget",
+			"time_grain",
+			"currency",
+		},
+		nil,
+	)
+	budgetConsumptionRatioDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "budget", "consumption_ratio"),
+		"Current spend of the budget's time grain as a fraction of the budget amount.",
+		[]string{
+			"subscription",
+			"budget",
+			"time_grain",
+		},
+		nil,
+	)
+	budgetNotificationThresholdDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "budget", "notification_threshold_ratio"),
+		"Threshold of the budget notification as a fraction of the budget amount.",
+		[]string{
+			"subscription",
+			"budget",
+			"notification",
+			"threshold_type",
+			"enabled",
+		},
+		nil,
+	)
+)
+
+type BudgetConfig struct {
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+
+	GSTenantID string
+}
+
+type Budget struct {
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+
+	gsTenantID string
+}
+
+// NewBudget exposes the consumption of the budgets defined in the subscriptions found in the "credential-*" secrets
+// of the control plane, together with their notification thresholds, so budgets can be alerted on with Prometheus
+// instead of email notifications.
+func NewBudget(config BudgetConfig) (*Budget, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	b := &Budget{
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		gsTenantID:       config.GSTenantID,
+	}
+
+	return b, nil
+}
+
+func (b *Budget) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := b.collections.newCollectContext("budget")
+	defer cancel()
+	clientSets, err := credential.GetAzureClientSetsFromCredentialSecretsBySubscription(ctx, b.credentialSource, b.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for subscriptionID, azureClientSet := range clientSets {
+		restClient := azureClientSet.RESTClient
+
+		var budgets budgetList
+		err := restClient.GetJSON(ctx, "/subscriptions/"+subscriptionID+"/providers/Microsoft.Consumption/budgets", consumptionAPIVersion, &budgets)
+		if err != nil {
+			b.logger.Errorf(ctx, err, "an error occurred fetching the budgets of subscription %#q", subscriptionID)
+			continue
+		}
+
+		for {
+			for _, budget := range budgets.Value {
+				p := budget.Properties
+
+				ch <- prometheus.MustNewConstMetric(
+					budgetAmountDesc,
+					prometheus.GaugeValue,
+					p.Amount,
+					subscriptionID,
+					budget.Name,
+					p.TimeGrain,
+					p.CurrentSpend.Unit,
+				)
+
+				if p.Amount > 0 {
+					ch <- prometheus.MustNewConstMetric(
+						budgetConsumptionRatioDesc,
+						prometheus.GaugeValue,
+						p.CurrentSpend.Amount/p.Amount,
+						subscriptionID,
+						budget.Name,
+						p.TimeGrain,
+					)
+				}
+
+				for name, notification := range p.Notifications {
+					// Thresholds are given in percent of the budget amount.
+					ch <- prometheus.MustNewConstMetric(
+						budgetNotificationThresholdDesc,
+						prometheus.GaugeValue,
+						notification.Threshold/100,
+						subscriptionID,
+						budget.Name,
+						name,
+						notification.ThresholdType,
+						strconv.FormatBool(notification.Enabled),
+					)
+				}
+			}
+
+			if budgets.NextLink == "" {
+				break
+			}
+
+			nextLink := budgets.NextLink
+			budgets = budgetList{}
+			err = restClient.GetNextJSON(ctx, nextLink, &budgets)
+			if err != nil {
+				b.logger.Errorf(ctx, err, "an error occurred fetching the budgets of subscription %#q", subscriptionID)
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+func (b *Budget) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- budgetAmountDesc
+	ch <- budgetConsumptionRatioDesc
+	ch <- budgetNotificationThresholdDesc
+	return nil
+}
@@ -0,0 +1,92 @@
+package collector
+
+import (
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+)
+
+var (
+	clientCacheEntriesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "client_cache", "entries"),
+		"Number of cached Azure authorizers, client sets and responses by type.",
+		[]string{
+			"type",
+		},
+		nil,
+	)
+	clientCacheNotModifiedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "client_cache", "not_modified_total"),
+		"Number of responses served from the cache because the Azure API reported the resource as not modified.",
+		nil,
+		nil,
+	)
+)
+
+type ClientCacheConfig struct {
+	ClientFactory *client.Factory
+	Logger        micrologger.Logger
+}
+
+type ClientCache struct {
+	clientFactory *client.Factory
+	logger        micrologger.Logger
+}
+
+// NewClientCache exposes the size of the caches of Azure authorizers, client sets and responses the collectors share.
+func NewClientCache(config ClientCacheConfig) (*ClientCache, error) {
+	if config.ClientFactory == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ClientFactory must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	c := &ClientCache{
+		clientFactory: config.ClientFactory,
+		logger:        config.Logger,
+	}
+
+	return c, nil
+}
+
+func (c *ClientCache) Collect(ch chan<- prometheus.Metric) error {
+	authorizers, clientSets := c.clientFactory.CacheSizes()
+
+	ch <- prometheus.MustNewConstMetric(
+		clientCacheEntriesDesc,
+		prometheus.GaugeValue,
+		float64(authorizers),
+		"authorizer",
+	)
+	ch <- prometheus.MustNewConstMetric(
+		clientCacheEntriesDesc,
+		prometheus.GaugeValue,
+		float64(clientSets),
+		"client_set",
+	)
+
+	responses, notModified := c.clientFactory.ConditionalCacheStats()
+
+	ch <- prometheus.MustNewConstMetric(
+		clientCacheEntriesDesc,
+		prometheus.GaugeValue,
+		float64(responses),
+		"response",
+	)
+	ch <- prometheus.MustNewConstMetric(
+		clientCacheNotModifiedDesc,
+		prometheus.CounterValue,
+		float64(notModified),
+	)
+
+	return nil
+}
+
+func (c *ClientCache) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- clientCacheEntriesDesc
+	ch <- clientCacheNotModifiedDesc
+	return nil
+}
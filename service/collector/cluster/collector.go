@@ -9,6 +9,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	capiv1alpha3 "sigs.k8s.io/cluster-api/api/v1alpha3"
 	client "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/azure-collector/v2/service/credential"
 )
 
 const (
@@ -16,23 +18,28 @@ const (
 )
 
 type Collectors struct {
-	ctrlClient client.Client
-	logger     micrologger.Logger
+	ctrlClient       client.Client
+	credentialSource *credential.Source
+	logger           micrologger.Logger
 
 	collectors []ClusterCollector
 }
 
-func NewCollectors(ctrlClient client.Client, logger micrologger.Logger) (*Collectors, error) {
+func NewCollectors(ctrlClient client.Client, credentialSource *credential.Source, logger micrologger.Logger) (*Collectors, error) {
 	if ctrlClient == nil {
 		return nil, microerror.Maskf(invalidConfigError, "ctrlClient must not be empty")
 	}
+	if credentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "credentialSource must not be empty")
+	}
 	if logger == nil {
 		return nil, microerror.Maskf(invalidConfigError, "logger must not be empty")
 	}
 
 	c := &Collectors{
-		ctrlClient: ctrlClient,
-		logger:     logger,
+		ctrlClient:       ctrlClient,
+		credentialSource: credentialSource,
+		logger:           logger,
 	}
 
 	return c, nil
@@ -53,6 +60,11 @@ func (c *Collectors) Collect(ch chan<- prometheus.Metric) error {
 	}
 
 	for _, cr := range clusters.Items {
+		// The resource group of a cluster is named after it.
+		if !c.credentialSource.MatchResourceGroup(cr.Name) {
+			continue
+		}
+
 		for _, collector := range c.collectors {
 			err := collector.Collect(ctx, &cr, ch)
 			if err != nil {
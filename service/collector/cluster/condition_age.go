@@ -0,0 +1,72 @@
+package cluster
+
+import (
+	"context"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+	capiv1alpha3 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type ConditionAge struct {
+	ctrlClient client.Client
+	logger     micrologger.Logger
+}
+
+var (
+	clusterConditionAgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "cluster", "condition_age_seconds"),
+		"Time since the last transition of the Cluster CR status condition, e.g. how long a cluster has been creating or upgrading.",
+		[]string{
+			"cluster_id",
+			"condition",
+			"status",
+		},
+		nil,
+	)
+)
+
+func NewConditionAge(ctrlClient client.Client, logger micrologger.Logger) (*ConditionAge, error) {
+	if ctrlClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "ctrlClient must not be empty")
+	}
+	if logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "logger must not be empty")
+	}
+
+	c := &ConditionAge{
+		ctrlClient: ctrlClient,
+		logger:     logger,
+	}
+
+	return c, nil
+}
+
+func (c *ConditionAge) Collect(ctx context.Context, cluster *capiv1alpha3.Cluster, ch chan<- prometheus.Metric) error {
+	now := time.Now()
+
+	for _, condition := range cluster.Status.Conditions {
+		if condition.LastTransitionTime.IsZero() {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			clusterConditionAgeDesc,
+			prometheus.GaugeValue,
+			now.Sub(condition.LastTransitionTime.Time).Seconds(),
+			cluster.Name,
+			string(condition.Type),
+			string(condition.Status),
+		)
+	}
+
+	return nil
+}
+
+func (c *ConditionAge) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- clusterConditionAgeDesc
+	return nil
+}
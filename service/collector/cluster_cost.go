@@ -0,0 +1,181 @@
+package collector
+
+import (
+	"context"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	costColumnMeterCategory = "MeterCategory"
+	costColumnUsageDate     = "UsageDate"
+)
+
+type ClusterCostConfig struct {
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+
+	GSTenantID string
+	// TagLabels are the tags of the cluster resource groups which are exposed
+	// as labels.
+	TagLabels []string
+}
+
+type ClusterCost struct {
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+	costQuerier      *costQuerier
+
+	dailyDesc       *prometheus.Desc
+	monthToDateDesc *prometheus.Desc
+
+	gsTenantID string
+	tagLabels  []string
+}
+
+// NewClusterCost exposes the actual cost of the clusters per meter category, both for the previous day and for the
+// current month, to provide chargeback data per tenant. The configured tags of the cluster resource groups are
+// exposed as labels, so chargeback queries need no joins.
+func NewClusterCost(config ClusterCostConfig) (*ClusterCost, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	tagLabelNames, err := costTagLabelNames(config.TagLabels)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	labels := append([]string{"cluster_id", "meter_category", "currency"}, tagLabelNames...)
+
+	c := &ClusterCost{
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		costQuerier:      newCostQuerier(),
+
+		dailyDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricsNamespace, "cluster_cost", "daily"),
+			"Actual cost of the resources of the cluster resource group on the previous day.",
+			labels,
+			nil,
+		),
+		monthToDateDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricsNamespace, "cluster_cost", "month_to_date"),
+			"Actual cost of the resources of the cluster resource group since the beginning of the month.",
+			labels,
+			nil,
+		),
+
+		gsTenantID: config.GSTenantID,
+		tagLabels:  config.TagLabels,
+	}
+
+	return c, nil
+}
+
+func (c *ClusterCost) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := c.collections.newCollectContext("cluster_cost")
+	defer cancel()
+
+	clientSets, err := getClientSetsByCluster(ctx, c.credentialSource, c.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	now := time.Now().UTC()
+	yesterday := now.AddDate(0, 0, -1)
+	monthStart := usageDate(startOfMonth(now))
+
+	// On the first day of the month, the previous day belongs to the previous
+	// month, so we always query from the beginning of the month yesterday is
+	// in.
+	query := actualCostQuery(startOfMonth(yesterday), now, costColumnMeterCategory)
+
+	forEachCluster(ctx, ch, clientSets, func(ctx context.Context, ch chan<- prometheus.Metric, clusterID string, azureClientSet *client.AzureClientSet) error {
+		restClient := azureClientSet.RESTClient
+
+		// The resource group of a cluster is named after the cluster ID.
+		path := "/subscriptions/" + restClient.SubscriptionID + "/resourceGroups/" + clusterID + "/providers/Microsoft.CostManagement/query"
+
+		rows, err := c.costQuerier.Query(ctx, restClient, path, query)
+		if IsNotFound(err) {
+			return nil
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		// The cost is still exposed with empty tag values when the tags
+		// can't be looked up.
+		tagValues, err := getResourceGroupTagLabelValues(ctx, azureClientSet.GroupsClient, clusterID, c.tagLabels)
+		if err != nil {
+			c.logger.Errorf(ctx, err, "an error occurred fetching the tags of cluster %#q", clusterID)
+		}
+
+		daily := map[[2]string]float64{}
+		monthToDate := map[[2]string]float64{}
+		for _, row := range rows {
+			k := [2]string{row.String(costColumnMeterCategory), row.String(costColumnCurrency)}
+			date := int(row.Float(costColumnUsageDate))
+
+			if date == usageDate(yesterday) {
+				daily[k] += row.Float(costColumnCost)
+			}
+			if date >= monthStart {
+				monthToDate[k] += row.Float(costColumnCost)
+			}
+		}
+
+		for k, cost := range daily {
+			ch <- prometheus.MustNewConstMetric(
+				c.dailyDesc,
+				prometheus.GaugeValue,
+				cost,
+				append([]string{clusterID, k[0], k[1]}, tagValues...)...,
+			)
+		}
+		for k, cost := range monthToDate {
+			ch <- prometheus.MustNewConstMetric(
+				c.monthToDateDesc,
+				prometheus.GaugeValue,
+				cost,
+				append([]string{clusterID, k[0], k[1]}, tagValues...)...,
+			)
+		}
+
+		return nil
+	})
+
+	return nil
+}
+
+func (c *ClusterCost) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- c.dailyDesc
+	ch <- c.monthToDateDesc
+	return nil
+}
+
+// usageDate returns the given day in the format Cost Management uses for the
+// UsageDate column, e.g. 20201031.
+func usageDate(t time.Time) int {
+	t = t.UTC()
+	return t.Year()*10000 + int(t.Month())*100 + t.Day()
+}
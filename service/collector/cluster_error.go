@@ -0,0 +1,239 @@
+package collector
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	// clusterErrorTTL is how long the error of a cluster is exported after
+	// it was last seen, e.g. when the cluster got deleted in the meantime.
+	clusterErrorTTL = time.Hour
+)
+
+// Reasons of the errors of clusters.
+const (
+	clusterErrorReasonBudgetExhausted = "budget_exhausted"
+	clusterErrorReasonCredentials     = "credentials"
+	clusterErrorReasonNotFound        = "not_found"
+	clusterErrorReasonThrottled       = "throttled"
+	clusterErrorReasonTimeout         = "timeout"
+	clusterErrorReasonUnauthorized    = "unauthorized"
+	clusterErrorReasonUnknown         = "unknown"
+)
+
+var (
+	clusterErrorDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "cluster", "error"),
+		"Whether collecting the cluster fails for the given reason in any collector. The other clusters are still collected.",
+		[]string{
+			"cluster_id",
+			"reason",
+		},
+		nil,
+	)
+
+	clusterErrors = newClusterErrorTracker(clusterErrorTTL)
+)
+
+type clusterErrorKey struct {
+	collector string
+	cluster   string
+}
+
+type clusterErrorEntry struct {
+	err     error
+	logged  bool
+	reason  string
+	updated time.Time
+}
+
+// clusterErrorTracker tracks the clusters which failed to be collected by
+// collector.
+type clusterErrorTracker struct {
+	mutex   sync.Mutex
+	entries map[clusterErrorKey]*clusterErrorEntry
+	ttl     time.Duration
+}
+
+func newClusterErrorTracker(ttl time.Duration) *clusterErrorTracker {
+	return &clusterErrorTracker{
+		entries: map[clusterErrorKey]*clusterErrorEntry{},
+		ttl:     ttl,
+	}
+}
+
+// set records the error of the given cluster in the given collector. It is
+// logged on the next collection of the cluster errors unless the reason did
+// not change.
+func (t *clusterErrorTracker) set(collector, cluster string, reason string, err error, now time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	key := clusterErrorKey{collector: collector, cluster: cluster}
+
+	e, ok := t.entries[key]
+	if !ok || e.reason != reason {
+		e = &clusterErrorEntry{
+			reason: reason,
+		}
+		t.entries[key] = e
+	}
+	e.err = err
+	e.updated = now
+}
+
+// clear removes the error of the given cluster in the given collector. When
+// a reason is given, it is only removed when it is of this reason.
+func (t *clusterErrorTracker) clear(collector, cluster string, reason string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	key := clusterErrorKey{collector: collector, cluster: cluster}
+
+	e, ok := t.entries[key]
+	if !ok {
+		return
+	}
+	if reason != "" && e.reason != reason {
+		return
+	}
+
+	delete(t.entries, key)
+}
+
+// errors returns the reasons of the errors of every cluster, and calls log
+// with the errors not logged yet. Errors not seen for longer than the TTL are
+// removed.
+func (t *clusterErrorTracker) errors(now time.Time, log func(collector, cluster string, err error)) map[string]map[string]bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	reasons := map[string]map[string]bool{}
+	for key, e := range t.entries {
+		if now.Sub(e.updated) > t.ttl {
+			delete(t.entries, key)
+			continue
+		}
+
+		if !e.logged {
+			log(key.collector, key.cluster, e.err)
+			e.logged = true
+		}
+
+		if reasons[key.cluster] == nil {
+			reasons[key.cluster] = map[string]bool{}
+		}
+		reasons[key.cluster][e.reason] = true
+	}
+
+	return reasons
+}
+
+// getClientSetsByCluster returns the Azure client sets of the clusters by
+// cluster ID. Clusters whose credentials can't be used are exported as
+// cluster errors of the collector of the given context and skipped.
+func getClientSetsByCluster(ctx context.Context, source *credential.Source, gsTenantID string) (map[string]*client.AzureClientSet, error) {
+	clientSets, failures, err := credential.GetAzureClientSetsByCluster(ctx, source, gsTenantID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	collector := collectorFromContext(ctx)
+	now := time.Now()
+	for clusterID, err := range failures {
+		clusterErrors.set(collector, clusterID, clusterErrorReasonCredentials, err, now)
+	}
+	for clusterID := range clientSets {
+		clusterErrors.clear(collector, clusterID, clusterErrorReasonCredentials)
+	}
+
+	return clientSets, nil
+}
+
+// clusterErrorReason returns the reason of the given error of a cluster.
+func clusterErrorReason(ctx context.Context, err error) string {
+	if ctx.Err() != nil {
+		return clusterErrorReasonTimeout
+	}
+	if client.IsThrottled(err) {
+		return clusterErrorReasonThrottled
+	}
+	if client.IsBudgetExhausted(err) {
+		return clusterErrorReasonBudgetExhausted
+	}
+
+	dErr, ok := microerror.Cause(err).(autorest.DetailedError)
+	if !ok {
+		return clusterErrorReasonUnknown
+	}
+
+	switch dErr.StatusCode {
+	case http.StatusNotFound:
+		return clusterErrorReasonNotFound
+	case http.StatusTooManyRequests:
+		return clusterErrorReasonThrottled
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return clusterErrorReasonUnauthorized
+	default:
+		return clusterErrorReasonUnknown
+	}
+}
+
+type ClusterErrorConfig struct {
+	Logger micrologger.Logger
+}
+
+type ClusterError struct {
+	logger micrologger.Logger
+}
+
+// NewClusterError exposes the clusters which fail to be collected, e.g.
+// because of invalid credentials or a deleted resource group, along with the
+// reason. Their errors are logged once when they are first seen.
+func NewClusterError(config ClusterErrorConfig) (*ClusterError, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	c := &ClusterError{
+		logger: config.Logger,
+	}
+
+	return c, nil
+}
+
+func (c *ClusterError) Collect(ch chan<- prometheus.Metric) error {
+	reasons := clusterErrors.errors(time.Now(), func(collector, cluster string, err error) {
+		c.logger.Errorf(context.Background(), err, "collector %#q failed to collect cluster %#q", collector, cluster)
+	})
+
+	for cluster, rs := range reasons {
+		for reason := range rs {
+			ch <- prometheus.MustNewConstMetric(
+				clusterErrorDesc,
+				prometheus.GaugeValue,
+				gaugeValue,
+				cluster,
+				reason,
+			)
+		}
+	}
+
+	return nil
+}
+
+func (c *ClusterError) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- clusterErrorDesc
+	return nil
+}
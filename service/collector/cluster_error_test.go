@@ -0,0 +1,95 @@
+package collector
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_clusterErrorTracker(t *testing.T) {
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	err := errors.New("test")
+
+	testCases := []struct {
+		name            string
+		update          func(tracker *clusterErrorTracker)
+		expectedReasons map[string]map[string]bool
+		expectedLogged  []string
+	}{
+		{
+			name: "case 0: errors of every collector are exported once per reason",
+			update: func(tracker *clusterErrorTracker) {
+				tracker.set("deployment", "abc12", clusterErrorReasonNotFound, err, now)
+				tracker.set("node_vmss", "abc12", clusterErrorReasonNotFound, err, now)
+				tracker.set("flow_log", "abc12", clusterErrorReasonCredentials, err, now)
+				tracker.set("flow_log", "def34", clusterErrorReasonUnauthorized, err, now)
+			},
+			expectedReasons: map[string]map[string]bool{
+				"abc12": {clusterErrorReasonNotFound: true, clusterErrorReasonCredentials: true},
+				"def34": {clusterErrorReasonUnauthorized: true},
+			},
+			expectedLogged: []string{"deployment/abc12", "flow_log/abc12", "flow_log/def34", "node_vmss/abc12"},
+		},
+		{
+			name: "case 1: errors are cleared once the cluster is collected",
+			update: func(tracker *clusterErrorTracker) {
+				tracker.set("deployment", "abc12", clusterErrorReasonNotFound, err, now)
+				tracker.clear("deployment", "abc12", "")
+			},
+			expectedReasons: map[string]map[string]bool{},
+		},
+		{
+			name: "case 2: errors of other reasons are kept",
+			update: func(tracker *clusterErrorTracker) {
+				tracker.set("deployment", "abc12", clusterErrorReasonNotFound, err, now)
+				tracker.clear("deployment", "abc12", clusterErrorReasonCredentials)
+			},
+			expectedReasons: map[string]map[string]bool{
+				"abc12": {clusterErrorReasonNotFound: true},
+			},
+			expectedLogged: []string{"deployment/abc12"},
+		},
+		{
+			name: "case 3: errors not seen within the TTL expire",
+			update: func(tracker *clusterErrorTracker) {
+				tracker.set("deployment", "abc12", clusterErrorReasonNotFound, err, now.Add(-2*time.Hour))
+				tracker.set("deployment", "def34", clusterErrorReasonNotFound, err, now.Add(-time.Minute))
+			},
+			expectedReasons: map[string]map[string]bool{
+				"def34": {clusterErrorReasonNotFound: true},
+			},
+			expectedLogged: []string{"deployment/def34"},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			tracker := newClusterErrorTracker(time.Hour)
+			tc.update(tracker)
+
+			var logged []string
+			log := func(collector, cluster string, err error) {
+				logged = append(logged, collector+"/"+cluster)
+			}
+
+			reasons := tracker.errors(now, log)
+			if !cmp.Equal(reasons, tc.expectedReasons) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedReasons, reasons))
+			}
+
+			// Errors are only logged when they are first seen.
+			tracker.errors(now, log)
+
+			sort.Strings(logged)
+			if !cmp.Equal(logged, tc.expectedLogged) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedLogged, logged))
+			}
+		})
+	}
+}
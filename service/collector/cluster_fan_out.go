@@ -0,0 +1,181 @@
+package collector
+
+import (
+	"context"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/pkg/featuregate"
+)
+
+const (
+	// clusterBufferSize is the maximum number of metrics of a cluster held
+	// in memory while the metrics of the clusters before it are sent.
+	clusterBufferSize = 1000
+	// clusterConcurrency is the maximum number of clusters a collector
+	// collects at the same time.
+	clusterConcurrency = 10
+	// clusterTimeoutShare is the share of the time left until the deadline
+	// of the collection a single cluster may take, so a cluster whose Azure
+	// APIs hang does not hold up the others.
+	clusterTimeoutShare = 2
+	// defaultClusterTimeout is the deadline of the collection of a single
+	// cluster when the collection has no deadline.
+	defaultClusterTimeout = time.Minute
+)
+
+// forEachCluster calls collect with the Azure client set of every cluster,
+// collecting up to clusterConcurrency clusters at the same time, each bounded
+// by clusterTimeout. The metrics are sent on to ch ordered by cluster ID and
+// in the order collect sent them. The metrics of the cluster being sent are
+// streamed, while the clusters after it buffer up to clusterBufferSize
+// metrics each before they wait for their turn. Clusters failing to be
+// collected, including panics, are exported as cluster errors of the
+// collector of the given context instead of failing the collection of the
+// other clusters. collect is called concurrently, so state shared between
+// clusters must be synchronized.
+func forEachCluster(ctx context.Context, ch chan<- prometheus.Metric, clientSets map[string]*client.AzureClientSet, collect func(ctx context.Context, ch chan<- prometheus.Metric, clusterID string, azureClientSet *client.AzureClientSet) error) {
+	collector := collectorFromContext(ctx)
+
+	var clusterIDs []string
+	for clusterID := range clientSets {
+		clusterIDs = append(clusterIDs, clusterID)
+	}
+	sort.Strings(clusterIDs)
+
+	concurrency := clusterConcurrency
+	if !featuregate.Enabled(featuregate.ClusterFanOut) {
+		concurrency = 1
+	}
+
+	timeout := clusterTimeout(ctx, time.Now())
+
+	buffers := make([]chan prometheus.Metric, len(clusterIDs))
+	for i := range buffers {
+		buffers[i] = make(chan prometheus.Metric, clusterBufferSize)
+	}
+
+	// Clusters are started in the order they are sent, so the cluster being
+	// sent is always running and waiting clusters can't keep it from
+	// starting.
+	go func() {
+		semaphore := make(chan struct{}, concurrency)
+		for i, clusterID := range clusterIDs {
+			i, clusterID := i, clusterID
+
+			semaphore <- struct{}{}
+			go func() {
+				defer func() { <-semaphore }()
+
+				ctx, cancel := context.WithTimeout(ctx, timeout)
+				defer cancel()
+
+				err := collectCluster(ctx, buffers[i], clusterID, clientSets[clusterID], collect)
+
+				// Failures are cluster errors, so the other clusters are
+				// still collected. They are tracked before the cluster is
+				// done, so they are known once forEachCluster returns.
+				if err != nil {
+					clusterErrors.set(collector, clusterID, clusterErrorReason(ctx, err), err, time.Now())
+				} else {
+					clusterErrors.clear(collector, clusterID, "")
+				}
+
+				close(buffers[i])
+			}()
+		}
+	}()
+
+	for _, buffer := range buffers {
+		for m := range buffer {
+			ch <- m
+		}
+	}
+}
+
+// collectCluster calls collect with the given cluster. Panics are returned as
+// errors, so they fail the given cluster only.
+func collectCluster(ctx context.Context, ch chan<- prometheus.Metric, clusterID string, azureClientSet *client.AzureClientSet, collect func(ctx context.Context, ch chan<- prometheus.Metric, clusterID string, azureClientSet *client.AzureClientSet) error) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = microerror.Maskf(executionFailedError, "collecting cluster %#q panicked: %v\n%s", clusterID, v, debug.Stack())
+		}
+	}()
+
+	return collect(ctx, ch, clusterID, azureClientSet)
+}
+
+// clusterTimeout returns the deadline of the collection of a single cluster
+// within the collection of the given context. It is a share of the time left
+// until the deadline of the collection, so it follows the collect timeout.
+func clusterTimeout(ctx context.Context, now time.Time) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return defaultClusterTimeout
+	}
+
+	return deadline.Sub(now) / clusterTimeoutShare
+}
+
+// perSubscription looks up values per subscription once within a collection
+// of concurrently collected clusters, e.g. the flow logs of the Network
+// Watcher of a subscription. Failed lookups are tried again by the next
+// cluster of the subscription.
+type perSubscription struct {
+	mutex   sync.Mutex
+	lookups map[string]*subscriptionLookup
+}
+
+type subscriptionLookup struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+func newPerSubscription() *perSubscription {
+	return &perSubscription{
+		lookups: map[string]*subscriptionLookup{},
+	}
+}
+
+// get returns the value of the given subscription, looking it up with lookup
+// unless it was looked up already. Concurrent calls for the same subscription
+// wait for the lookup in progress.
+func (p *perSubscription) get(subscriptionID string, lookup func() (interface{}, error)) (interface{}, error) {
+	p.mutex.Lock()
+	l, ok := p.lookups[subscriptionID]
+	if !ok {
+		l = &subscriptionLookup{done: make(chan struct{})}
+		p.lookups[subscriptionID] = l
+	}
+	p.mutex.Unlock()
+
+	if ok {
+		<-l.done
+		if l.err != nil {
+			return p.get(subscriptionID, lookup)
+		}
+
+		return l.value, nil
+	}
+
+	l.value, l.err = lookup()
+	if l.err != nil {
+		p.mutex.Lock()
+		delete(p.lookups, subscriptionID)
+		p.mutex.Unlock()
+	}
+	close(l.done)
+
+	if l.err != nil {
+		return nil, microerror.Mask(l.err)
+	}
+
+	return l.value, nil
+}
@@ -0,0 +1,217 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+)
+
+func Test_forEachCluster(t *testing.T) {
+	desc := prometheus.NewDesc("test_cluster", "Test cluster.", []string{"cluster_id", "index"}, nil)
+
+	testCases := []struct {
+		name            string
+		clusters        []string
+		failing         map[string]bool
+		panicking       map[string]bool
+		expectedMetrics [][2]string
+		expectedErrors  map[string]map[string]bool
+	}{
+		{
+			name:     "case 0: metrics are sent ordered by cluster",
+			clusters: []string{"c3", "a1", "b2"},
+			expectedMetrics: [][2]string{
+				{"a1", "0"}, {"a1", "1"},
+				{"b2", "0"}, {"b2", "1"},
+				{"c3", "0"}, {"c3", "1"},
+			},
+			expectedErrors: map[string]map[string]bool{},
+		},
+		{
+			name:     "case 1: failing clusters are cluster errors",
+			clusters: []string{"a1", "b2"},
+			failing:  map[string]bool{"a1": true},
+			expectedMetrics: [][2]string{
+				{"a1", "0"}, {"a1", "1"},
+				{"b2", "0"}, {"b2", "1"},
+			},
+			expectedErrors: map[string]map[string]bool{
+				"a1": {clusterErrorReasonUnknown: true},
+			},
+		},
+		{
+			name:      "case 2: panicking clusters are cluster errors",
+			clusters:  []string{"a1", "b2"},
+			panicking: map[string]bool{"b2": true},
+			expectedMetrics: [][2]string{
+				{"a1", "0"}, {"a1", "1"},
+				{"b2", "0"}, {"b2", "1"},
+			},
+			expectedErrors: map[string]map[string]bool{
+				"b2": {clusterErrorReasonUnknown: true},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			clusterErrors = newClusterErrorTracker(clusterErrorTTL)
+
+			clientSets := map[string]*client.AzureClientSet{}
+			for _, clusterID := range tc.clusters {
+				clientSets[clusterID] = &client.AzureClientSet{}
+			}
+
+			ctx := client.WithCollector(context.Background(), "test")
+
+			// Later clusters finish first, but their metrics are still
+			// sent after the ones of the clusters before them.
+			ch := make(chan prometheus.Metric, 100)
+			forEachCluster(ctx, ch, clientSets, func(ctx context.Context, ch chan<- prometheus.Metric, clusterID string, azureClientSet *client.AzureClientSet) error {
+				time.Sleep(time.Duration(len(tc.clusters)-int(clusterID[1]-'0')) * 10 * time.Millisecond)
+
+				for i := 0; i < 2; i++ {
+					ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, 1, clusterID, strconv.Itoa(i))
+				}
+
+				if tc.panicking[clusterID] {
+					panic("failed")
+				}
+				if tc.failing[clusterID] {
+					return errors.New("failed")
+				}
+
+				return nil
+			})
+			close(ch)
+
+			var metrics [][2]string
+			for m := range ch {
+				var metric dto.Metric
+				err := m.Write(&metric)
+				if err != nil {
+					t.Fatalf("expected no error, got %#v", err)
+				}
+
+				metrics = append(metrics, [2]string{metric.GetLabel()[0].GetValue(), metric.GetLabel()[1].GetValue()})
+			}
+
+			if !cmp.Equal(metrics, tc.expectedMetrics) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedMetrics, metrics))
+			}
+
+			reasons := clusterErrors.errors(time.Now(), func(collector, cluster string, err error) {})
+			if !cmp.Equal(reasons, tc.expectedErrors) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedErrors, reasons))
+			}
+		})
+	}
+}
+
+func Test_perSubscription(t *testing.T) {
+	p := newPerSubscription()
+
+	var mutex sync.Mutex
+	lookups := map[string]int{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		subscriptionID := "sub-" + strconv.Itoa(i%2)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			v, err := p.get(subscriptionID, func() (interface{}, error) {
+				mutex.Lock()
+				lookups[subscriptionID]++
+				mutex.Unlock()
+
+				time.Sleep(10 * time.Millisecond)
+
+				return subscriptionID + "-value", nil
+			})
+			if err != nil {
+				t.Errorf("expected no error, got %#v", err)
+				return
+			}
+			if v != subscriptionID+"-value" {
+				t.Errorf("expected %#q, got %#q", subscriptionID+"-value", v)
+			}
+		}()
+	}
+	wg.Wait()
+
+	expected := map[string]int{"sub-0": 1, "sub-1": 1}
+	if !cmp.Equal(lookups, expected) {
+		t.Fatalf("\n\n%s\n", cmp.Diff(expected, lookups))
+	}
+
+	// Failed lookups are tried again.
+	_, err := p.get("sub-2", func() (interface{}, error) { return nil, errors.New("failed") })
+	if err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+
+	v, err := p.get("sub-2", func() (interface{}, error) { return "sub-2-value", nil })
+	if err != nil {
+		t.Fatalf("expected no error, got %#v", err)
+	}
+	if v != "sub-2-value" {
+		t.Fatalf("expected %#q, got %#q", "sub-2-value", v)
+	}
+}
+
+func Test_clusterTimeout(t *testing.T) {
+	now := time.Now()
+
+	testCases := []struct {
+		name            string
+		deadline        time.Time
+		expectedTimeout time.Duration
+	}{
+		{
+			name:            "case 0: collection without deadline",
+			expectedTimeout: defaultClusterTimeout,
+		},
+		{
+			name:            "case 1: share of the time left until the deadline",
+			deadline:        now.Add(2 * time.Minute),
+			expectedTimeout: time.Minute,
+		},
+		{
+			name:            "case 2: short collect timeout",
+			deadline:        now.Add(20 * time.Second),
+			expectedTimeout: 10 * time.Second,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			ctx := context.Background()
+			if !tc.deadline.IsZero() {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithDeadline(ctx, tc.deadline)
+				defer cancel()
+			}
+
+			timeout := clusterTimeout(ctx, now)
+			if timeout != tc.expectedTimeout {
+				t.Fatalf("expected %s, got %s", tc.expectedTimeout, timeout)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package collector
+
+import (
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/service/collector/key"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	connectionMonitorChecksFailedMetric = "ChecksFailedPercent"
+	connectionMonitorRoundTripMetric    = "RoundTripTimeMs"
+	connectionMonitorTestGroupDimension = "TestGroupName"
+)
+
+var (
+	connectionMonitorReachabilityDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "connection_monitor", "reachability_percent"),
+		"Percentage of successful connectivity checks per Connection Monitor test group.",
+		[]string{
+			"subscription",
+			"connection_monitor",
+			"test_group",
+		},
+		nil,
+	)
+	connectionMonitorRoundTripDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "connection_monitor", "round_trip_time_ms"),
+		"Average round trip time in milliseconds per Connection Monitor test group.",
+		[]string{
+			"subscription",
+			"connection_monitor",
+			"test_group",
+		},
+		nil,
+	)
+)
+
+type ConnectionMonitorConfig struct {
+	CredentialSource *credential.Source
+	Location         string
+	Collections      *Collections
+	Logger           micrologger.Logger
+	GSTenantID       string
+}
+
+type ConnectionMonitor struct {
+	credentialSource *credential.Source
+	location         string
+	collections      *Collections
+	logger           micrologger.Logger
+	gsTenantID       string
+}
+
+// NewConnectionMonitor exposes the results of the Connection Monitors configured on the Network Watcher of the installation location.
+// It exposes metrics for every subscription found in the "credential-*" secrets of the control plane.
+func NewConnectionMonitor(config ConnectionMonitorConfig) (*ConnectionMonitor, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Location == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Location must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	c := &ConnectionMonitor{
+		credentialSource: config.CredentialSource,
+		location:         config.Location,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		gsTenantID:       config.GSTenantID,
+	}
+
+	return c, nil
+}
+
+func (c *ConnectionMonitor) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := c.collections.newCollectContext("connection_monitor")
+	defer cancel()
+
+	clientSets, err := credential.GetAzureClientSetsFromCredentialSecretsBySubscription(ctx, c.credentialSource, c.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for subscriptionID, azureClientSet := range clientSets {
+		watchers, err := getNetworkWatchers(ctx, azureClientSet.WatchersClient, c.location)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, watcher := range watchers {
+			monitors, err := azureClientSet.ConnectionMonitorsClient.List(ctx, key.ResourceGroupFromID(to.String(watcher.ID)), to.String(watcher.Name))
+			if err != nil {
+				return microerror.Mask(err)
+			}
+			if monitors.Value == nil {
+				continue
+			}
+
+			for _, monitor := range *monitors.Value {
+				// The test results are not part of the Connection Monitor
+				// resource. Azure publishes them as Azure Monitor metrics
+				// split by test group.
+				values, err := getMonitorMetricValues(
+					ctx,
+					azureClientSet.MetricsClient,
+					to.String(monitor.ID),
+					[]string{connectionMonitorChecksFailedMetric, connectionMonitorRoundTripMetric},
+					aggregationAverage,
+					connectionMonitorTestGroupDimension+" eq '*'",
+				)
+				if err != nil {
+					c.logger.Errorf(ctx, err, "an error occurred fetching the results of Connection Monitor %#q", to.String(monitor.Name))
+					continue
+				}
+
+				for _, v := range values {
+					switch v.Name {
+					case connectionMonitorChecksFailedMetric:
+						ch <- prometheus.MustNewConstMetric(
+							connectionMonitorReachabilityDesc,
+							prometheus.GaugeValue,
+							100-v.Value,
+							subscriptionID,
+							to.String(monitor.Name),
+							v.Dimension(connectionMonitorTestGroupDimension),
+						)
+					case connectionMonitorRoundTripMetric:
+						ch <- prometheus.MustNewConstMetric(
+							connectionMonitorRoundTripDesc,
+							prometheus.GaugeValue,
+							v.Value,
+							subscriptionID,
+							to.String(monitor.Name),
+							v.Dimension(connectionMonitorTestGroupDimension),
+						)
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *ConnectionMonitor) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- connectionMonitorReachabilityDesc
+	ch <- connectionMonitorRoundTripDesc
+	return nil
+}
@@ -0,0 +1,322 @@
+package collector
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/containerregistry/mgmt/2019-05-01/containerregistry"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+var (
+	containerRegistryUsageCurrentDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "container_registry_usage", "current"),
+		"Current usage of the container registry, e.g. its storage in bytes.",
+		[]string{
+			"resource_group",
+			"registry",
+			"name",
+			"unit",
+		},
+		nil,
+	)
+	containerRegistryUsageLimitDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "container_registry_usage", "limit"),
+		"Usage limit of the container registry as defined by its SKU.",
+		[]string{
+			"resource_group",
+			"registry",
+			"name",
+			"unit",
+		},
+		nil,
+	)
+	containerRegistryWebhookHealthyDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "container_registry_webhook", "healthy"),
+		"Whether the container registry webhook is enabled and its latest event was delivered successfully.",
+		[]string{
+			"resource_group",
+			"registry",
+			"webhook",
+		},
+		nil,
+	)
+	containerRegistryReplicationStatusDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "container_registry_replication", "status"),
+		"Status of the geo-replication of the container registry.",
+		[]string{
+			"resource_group",
+			"registry",
+			"location",
+			"status",
+		},
+		nil,
+	)
+)
+
+type ContainerRegistryConfig struct {
+	CredentialSource          *credential.Source
+	Collections               *Collections
+	Logger                    micrologger.Logger
+	ControlPlaneResourceGroup string
+	GSTenantID                string
+}
+
+type ContainerRegistry struct {
+	credentialSource          *credential.Source
+	collections               *Collections
+	logger                    micrologger.Logger
+	controlPlaneResourceGroup string
+	gsTenantID                string
+}
+
+// NewContainerRegistry exposes the usage against the SKU limits, the webhook health and the geo-replication status
+// of the container registries in the managed resource groups.
+func NewContainerRegistry(config ContainerRegistryConfig) (*ContainerRegistry, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.ControlPlaneResourceGroup == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ControlPlaneResourceGroup must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	c := &ContainerRegistry{
+		credentialSource:          config.CredentialSource,
+		collections:               config.Collections,
+		logger:                    config.Logger,
+		controlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+		gsTenantID:                config.GSTenantID,
+	}
+
+	return c, nil
+}
+
+func (c *ContainerRegistry) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := c.collections.newCollectContext("container_registry")
+	defer cancel()
+
+	resourceGroups, err := getManagedResourceGroups(ctx, c.credentialSource, c.gsTenantID, c.controlPlaneResourceGroup)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, resourceGroup := range resourceGroups {
+		azureClientSet := resourceGroup.AzureClientSet
+
+		registries, err := getContainerRegistries(ctx, azureClientSet.RegistriesClient, resourceGroup.Name)
+		if IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, registry := range registries {
+			registryName := to.String(registry.Name)
+
+			err = c.collectUsages(ctx, ch, azureClientSet, resourceGroup.Name, registryName)
+			if err != nil {
+				c.logger.Errorf(ctx, err, "an error occurred fetching the usages of container registry %#q", registryName)
+			}
+
+			err = c.collectWebhooks(ctx, ch, azureClientSet, resourceGroup.Name, registryName)
+			if err != nil {
+				c.logger.Errorf(ctx, err, "an error occurred fetching the webhooks of container registry %#q", registryName)
+			}
+
+			// Only premium registries can be geo-replicated.
+			if registry.Sku != nil && registry.Sku.Name == containerregistry.Premium {
+				err = c.collectReplications(ctx, ch, azureClientSet, resourceGroup.Name, registryName)
+				if err != nil {
+					c.logger.Errorf(ctx, err, "an error occurred fetching the replications of container registry %#q", registryName)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *ContainerRegistry) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- containerRegistryUsageCurrentDesc
+	ch <- containerRegistryUsageLimitDesc
+	ch <- containerRegistryWebhookHealthyDesc
+	ch <- containerRegistryReplicationStatusDesc
+	return nil
+}
+
+func (c *ContainerRegistry) collectUsages(ctx context.Context, ch chan<- prometheus.Metric, azureClientSet *client.AzureClientSet, resourceGroup, registry string) error {
+	usages, err := azureClientSet.RegistriesClient.ListUsages(ctx, resourceGroup, registry)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if usages.Value == nil {
+		return nil
+	}
+
+	for _, usage := range *usages.Value {
+		name := to.String(usage.Name)
+		unit := strings.ToLower(string(usage.Unit))
+
+		if usage.CurrentValue != nil {
+			ch <- prometheus.MustNewConstMetric(
+				containerRegistryUsageCurrentDesc,
+				prometheus.GaugeValue,
+				float64(*usage.CurrentValue),
+				resourceGroup,
+				registry,
+				name,
+				unit,
+			)
+		}
+		if usage.Limit != nil {
+			ch <- prometheus.MustNewConstMetric(
+				containerRegistryUsageLimitDesc,
+				prometheus.GaugeValue,
+				float64(*usage.Limit),
+				resourceGroup,
+				registry,
+				name,
+				unit,
+			)
+		}
+	}
+
+	return nil
+}
+
+func (c *ContainerRegistry) collectWebhooks(ctx context.Context, ch chan<- prometheus.Metric, azureClientSet *client.AzureClientSet, resourceGroup, registry string) error {
+	webhooks, err := azureClientSet.WebhooksClient.ListComplete(ctx, resourceGroup, registry)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for webhooks.NotDone() {
+		webhook := webhooks.Value()
+		webhookName := to.String(webhook.Name)
+
+		healthy := webhook.WebhookProperties != nil && webhook.Status == containerregistry.WebhookStatusEnabled
+		if healthy {
+			// A webhook without any event yet is considered healthy.
+			events, err := azureClientSet.WebhooksClient.ListEvents(ctx, resourceGroup, registry, webhookName)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
+			if latest := latestWebhookEvent(events.Values()); latest != nil && latest.EventResponseMessage != nil {
+				healthy = strings.HasPrefix(to.String(latest.EventResponseMessage.StatusCode), "2")
+			}
+		}
+
+		var v float64
+		if healthy {
+			v = 1
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			containerRegistryWebhookHealthyDesc,
+			prometheus.GaugeValue,
+			v,
+			resourceGroup,
+			registry,
+			webhookName,
+		)
+
+		err = webhooks.NextWithContext(ctx)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	return nil
+}
+
+func (c *ContainerRegistry) collectReplications(ctx context.Context, ch chan<- prometheus.Metric, azureClientSet *client.AzureClientSet, resourceGroup, registry string) error {
+	replications, err := azureClientSet.ReplicationsClient.ListComplete(ctx, resourceGroup, registry)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for replications.NotDone() {
+		replication := replications.Value()
+
+		var status string
+		if replication.ReplicationProperties != nil && replication.Status != nil {
+			status = to.String(replication.Status.DisplayStatus)
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			containerRegistryReplicationStatusDesc,
+			prometheus.GaugeValue,
+			gaugeValue,
+			resourceGroup,
+			registry,
+			to.String(replication.Location),
+			status,
+		)
+
+		err = replications.NextWithContext(ctx)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	return nil
+}
+
+// getContainerRegistries returns the container registries found in the given
+// resource group.
+func getContainerRegistries(ctx context.Context, registriesClient *containerregistry.RegistriesClient, resourceGroup string) ([]containerregistry.Registry, error) {
+	var registries []containerregistry.Registry
+
+	iterator, err := registriesClient.ListByResourceGroupComplete(ctx, resourceGroup)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	for iterator.NotDone() {
+		registries = append(registries, iterator.Value())
+
+		err = iterator.NextWithContext(ctx)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	return registries, nil
+}
+
+// latestWebhookEvent returns the most recent of the given webhook events.
+func latestWebhookEvent(events []containerregistry.Event) *containerregistry.Event {
+	var latest *containerregistry.Event
+	var latestTimestamp time.Time
+
+	for i, event := range events {
+		if event.EventRequestMessage == nil || event.EventRequestMessage.Content == nil || event.EventRequestMessage.Content.Timestamp == nil {
+			continue
+		}
+
+		timestamp := event.EventRequestMessage.Content.Timestamp.ToTime()
+		if latest == nil || timestamp.After(latestTimestamp) {
+			latest = &events[i]
+			latestTimestamp = timestamp
+		}
+	}
+
+	return latest
+}
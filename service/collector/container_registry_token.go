@@ -0,0 +1,216 @@
+package collector
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/service/collector/key"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	// containerRegistryTokenAPIVersion is the first stable API version
+	// supporting repository scoped tokens, which the SDK version we use does
+	// not know about.
+	containerRegistryTokenAPIVersion = "2021-09-01"
+
+	containerRegistryTokenStatusEnabled = "enabled"
+)
+
+// containerRegistryTokenList holds the subset of the container registry token
+// properties we need.
+type containerRegistryTokenList struct {
+	NextLink string `json:"nextLink"`
+	Value    []struct {
+		Name       string `json:"name"`
+		Properties struct {
+			Credentials *struct {
+				Passwords []struct {
+					Expiry *time.Time `json:"expiry"`
+					Name   string     `json:"name"`
+				} `json:"passwords"`
+			} `json:"credentials"`
+			ScopeMapID string `json:"scopeMapId"`
+			Status     string `json:"status"`
+		} `json:"properties"`
+	} `json:"value"`
+}
+
+var (
+	containerRegistryTokenEnabledDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "container_registry_token", "enabled"),
+		"Whether the container registry token is enabled.",
+		[]string{
+			"resource_group",
+			"registry",
+			"token",
+			"scope_map",
+		},
+		nil,
+	)
+	containerRegistryTokenExpiryDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "container_registry_token", "password_expiry_timestamp_seconds"),
+		"Unix timestamp at which the password of the container registry token expires.",
+		[]string{
+			"resource_group",
+			"registry",
+			"token",
+			"scope_map",
+			"password",
+		},
+		nil,
+	)
+)
+
+type ContainerRegistryTokenConfig struct {
+	CredentialSource          *credential.Source
+	Collections               *Collections
+	Logger                    micrologger.Logger
+	ControlPlaneResourceGroup string
+	GSTenantID                string
+}
+
+type ContainerRegistryToken struct {
+	credentialSource          *credential.Source
+	collections               *Collections
+	logger                    micrologger.Logger
+	controlPlaneResourceGroup string
+	gsTenantID                string
+}
+
+// NewContainerRegistryToken exposes the status and the password expiration of the tokens of the container registries
+// in the managed resource groups, so image pulls of the clusters do not start failing unexpectedly.
+func NewContainerRegistryToken(config ContainerRegistryTokenConfig) (*ContainerRegistryToken, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.ControlPlaneResourceGroup == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ControlPlaneResourceGroup must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	c := &ContainerRegistryToken{
+		credentialSource:          config.CredentialSource,
+		collections:               config.Collections,
+		logger:                    config.Logger,
+		controlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+		gsTenantID:                config.GSTenantID,
+	}
+
+	return c, nil
+}
+
+func (c *ContainerRegistryToken) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := c.collections.newCollectContext("container_registry_token")
+	defer cancel()
+
+	resourceGroups, err := getManagedResourceGroups(ctx, c.credentialSource, c.gsTenantID, c.controlPlaneResourceGroup)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, resourceGroup := range resourceGroups {
+		registries, err := getContainerRegistries(ctx, resourceGroup.AzureClientSet.RegistriesClient, resourceGroup.Name)
+		if IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, registry := range registries {
+			registryName := to.String(registry.Name)
+
+			err = c.collectTokens(ctx, ch, resourceGroup.AzureClientSet.RESTClient, resourceGroup.Name, registryName, to.String(registry.ID))
+			if err != nil {
+				c.logger.Errorf(ctx, err, "an error occurred fetching the tokens of container registry %#q", registryName)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *ContainerRegistryToken) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- containerRegistryTokenEnabledDesc
+	ch <- containerRegistryTokenExpiryDesc
+	return nil
+}
+
+func (c *ContainerRegistryToken) collectTokens(ctx context.Context, ch chan<- prometheus.Metric, restClient *client.RESTClient, resourceGroup, registry, registryID string) error {
+	var tokens containerRegistryTokenList
+	err := restClient.GetJSON(ctx, registryID+"/tokens", containerRegistryTokenAPIVersion, &tokens)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for {
+		for _, token := range tokens.Value {
+			scopeMap := key.ResourceNameFromID(token.Properties.ScopeMapID)
+
+			var enabled float64
+			if strings.EqualFold(token.Properties.Status, containerRegistryTokenStatusEnabled) {
+				enabled = 1
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				containerRegistryTokenEnabledDesc,
+				prometheus.GaugeValue,
+				enabled,
+				resourceGroup,
+				registry,
+				token.Name,
+				scopeMap,
+			)
+
+			if token.Properties.Credentials == nil {
+				continue
+			}
+
+			// Passwords without expiry never expire.
+			for _, password := range token.Properties.Credentials.Passwords {
+				if password.Expiry == nil {
+					continue
+				}
+
+				ch <- prometheus.MustNewConstMetric(
+					containerRegistryTokenExpiryDesc,
+					prometheus.GaugeValue,
+					float64(password.Expiry.Unix()),
+					resourceGroup,
+					registry,
+					token.Name,
+					scopeMap,
+					password.Name,
+				)
+			}
+		}
+
+		if tokens.NextLink == "" {
+			break
+		}
+
+		nextLink := tokens.NextLink
+		tokens = containerRegistryTokenList{}
+		err = restClient.GetNextJSON(ctx, nextLink, &tokens)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,278 @@
+package collector
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/pkg/dryrun"
+	"github.com/giantswarm/azure-collector/v2/pkg/tracing"
+)
+
+// Collections creates the contexts of the collections of the collectors of a
+// set. It bounds them by the collection timeout and the scrapes in progress,
+// spreads their starts and drains them on shutdown. Collectors can be disabled
+// while the collector is running.
+type Collections struct {
+	settingsMutex sync.RWMutex
+	// collectTimeout is the deadline of a single collection of every
+	// collector. Collections have no deadline when it is zero.
+	collectTimeout time.Duration
+	// disabled are the names of the collectors which are not collected,
+	// e.g. "collector.CostAnomaly".
+	disabled map[string]bool
+	// startSpread is the window the starts of the collections of a scrape
+	// are spread over. Collections start right away when it is zero.
+	startSpread time.Duration
+
+	randMutex sync.Mutex
+	// random is the source of the jitter of the starts of collections.
+	random *rand.Rand
+
+	scrapesMutex sync.Mutex
+	// scrapes are the contexts of the scrapes in progress by their ID.
+	scrapes      map[int]context.Context
+	nextScrapeID int
+
+	mutex sync.Mutex
+	// context is the parent of the contexts of all collections. It is
+	// canceled once the collections in progress on shutdown have been
+	// drained or the grace period is over.
+	context context.Context
+	cancel  context.CancelFunc
+	// inFlight is the number of collections in progress.
+	inFlight int
+	// draining is closed once the last collection in progress finished
+	// while draining. It is nil when not draining.
+	draining chan struct{}
+	drained  bool
+}
+
+// NewCollections returns the collections of a set with the given settings.
+func NewCollections(config RuntimeConfig) (*Collections, error) {
+	if config.CollectTimeout < 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CollectTimeout must not be negative", config)
+	}
+	if config.StartSpread < 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.StartSpread must not be negative", config)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c := &Collections{
+		random: rand.New(rand.NewSource(time.Now().UnixNano())),
+
+		scrapes: map[int]context.Context{},
+
+		context: ctx,
+		cancel:  cancel,
+	}
+	c.Configure(config)
+
+	return c, nil
+}
+
+// Configure applies the given settings to the collections. The collections in
+// progress keep the former settings.
+func (c *Collections) Configure(config RuntimeConfig) {
+	disabled := map[string]bool{}
+	for _, name := range config.Disabled {
+		disabled[name] = true
+	}
+
+	c.settingsMutex.Lock()
+	defer c.settingsMutex.Unlock()
+
+	c.collectTimeout = config.CollectTimeout
+	c.disabled = disabled
+	c.startSpread = config.StartSpread
+}
+
+func (c *Collections) isDisabled(name string) bool {
+	c.settingsMutex.RLock()
+	defer c.settingsMutex.RUnlock()
+
+	return c.disabled[name]
+}
+
+// newCollectContext returns the context of a single collection of the given
+// collector, which is canceled once the configured collection timeout expired
+// or the scrape it serves is about to time out, whichever comes first. The
+// start of the collection is delayed to spread the collections of a scrape. It
+// bounds the time slow Azure APIs can make a scrape take, so the metrics
+// collected by then are served instead of none at all. The collection is
+// traced as a span of the scrape, which the spans of its Azure API calls are
+// children of. The name of the collector is kept in the context.
+func (c *Collections) newCollectContext(collector string) (context.Context, context.CancelFunc) {
+	scrape := c.getScrape()
+
+	deadline, ok := c.collectDeadline(time.Now(), scrape)
+
+	c.startCollection()
+
+	// Collections still waiting for their start on shutdown are canceled
+	// once drained.
+	c.waitForStart(c.context, collector, deadline, ok)
+
+	dryrun.StartCollection(collector)
+
+	ctx := client.WithCollector(c.context, collector)
+	if criticalCollections[collector] {
+		ctx = client.WithBudgetExempt(ctx)
+	}
+	if lowCollections[collector] {
+		ctx = client.WithThrottleBackoff(ctx, pressureWindow)
+	}
+	ctx = trace.ContextWithSpan(ctx, trace.SpanFromContext(scrape))
+	ctx, span := tracing.Tracer().Start(ctx, "collect "+collector, trace.WithAttributes(attribute.String("collector", collector)))
+
+	var cancel context.CancelFunc
+	if ok {
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+
+	return ctx, func() {
+		if ctx.Err() == context.DeadlineExceeded {
+			span.SetStatus(codes.Error, "collection timed out")
+		}
+		cancel()
+		span.End()
+		c.finishCollection()
+	}
+}
+
+// collectDeadline returns the deadline of a collection started at the given
+// time serving the given scrape. There is none when neither the collection
+// timeout nor the scrape bound it.
+func (c *Collections) collectDeadline(now time.Time, scrape context.Context) (time.Time, bool) {
+	c.settingsMutex.RLock()
+	timeout := c.collectTimeout
+	c.settingsMutex.RUnlock()
+
+	deadline, ok := scrape.Deadline()
+	if timeout > 0 {
+		d := now.Add(timeout)
+		if !ok || d.Before(deadline) {
+			deadline = d
+			ok = true
+		}
+	}
+
+	return deadline, ok
+}
+
+// collectorFromContext returns the name of the collector of the collection
+// with the given context, e.g. "resource_group".
+func collectorFromContext(ctx context.Context) string {
+	return client.CollectorFromContext(ctx)
+}
+
+// Drain waits for the collections in progress to finish until the given
+// context is done, and cancels the Azure calls of the remaining ones then.
+// Collections started afterwards are canceled right away. It is meant to be
+// called once on shutdown.
+func (c *Collections) Drain(ctx context.Context) error {
+	c.mutex.Lock()
+	c.drained = true
+	if c.inFlight == 0 {
+		c.mutex.Unlock()
+		c.cancel()
+		return nil
+	}
+	done := make(chan struct{})
+	c.draining = done
+	c.mutex.Unlock()
+
+	defer c.cancel()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return microerror.Mask(ctx.Err())
+	}
+}
+
+// Draining returns whether the collections are drained for shutdown, so no
+// new scrapes must be accepted.
+func (c *Collections) Draining() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.drained
+}
+
+func (c *Collections) startCollection() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.inFlight++
+}
+
+func (c *Collections) finishCollection() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.inFlight--
+	if c.inFlight == 0 && c.draining != nil {
+		close(c.draining)
+		c.draining = nil
+	}
+}
+
+// StartScrape bounds the collections started until the returned function is
+// called by the deadline of the given context, e.g. the one of the Prometheus
+// scrape they serve, and traces them as children of its span. The gatherer of
+// the metrics endpoint does not pass a context to the collectors, so
+// concurrent scrapes bound collections by the earliest deadline.
+func (c *Collections) StartScrape(ctx context.Context) func() {
+	c.scrapesMutex.Lock()
+	defer c.scrapesMutex.Unlock()
+
+	id := c.nextScrapeID
+	c.nextScrapeID++
+	c.scrapes[id] = ctx
+
+	return func() {
+		c.scrapesMutex.Lock()
+		defer c.scrapesMutex.Unlock()
+
+		delete(c.scrapes, id)
+	}
+}
+
+// getScrape returns the context of the scrape in progress with the earliest
+// deadline. It returns the background context when there is none.
+func (c *Collections) getScrape() context.Context {
+	c.scrapesMutex.Lock()
+	defer c.scrapesMutex.Unlock()
+
+	var scrape context.Context
+	var earliest time.Time
+	for _, ctx := range c.scrapes {
+		deadline, ok := ctx.Deadline()
+		if scrape != nil && (!ok || (!earliest.IsZero() && !deadline.Before(earliest))) {
+			continue
+		}
+
+		scrape = ctx
+		if ok {
+			earliest = deadline
+		}
+	}
+
+	if scrape == nil {
+		return context.Background()
+	}
+
+	return scrape
+}
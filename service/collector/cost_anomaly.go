@@ -0,0 +1,162 @@
+package collector
+
+import (
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/service/collector/key"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	costAlertStatusActive = "active"
+	costAlertTypeAnomaly  = "anomaly"
+)
+
+// costAlertList holds the subset of the Cost Management alert properties we
+// need.
+type costAlertList struct {
+	NextLink string `json:"nextLink"`
+	Value    []struct {
+		Name       string `json:"name"`
+		Properties struct {
+			Status     string `json:"status"`
+			Definition struct {
+				Type string `json:"type"`
+			} `json:"definition"`
+			Details struct {
+				// Amount is the expected spend, CurrentSpend the actual one.
+				Amount              float64  `json:"amount"`
+				CurrentSpend        float64  `json:"currentSpend"`
+				Unit                string   `json:"unit"`
+				ResourceGroupFilter []string `json:"resourceGroupFilter"`
+			} `json:"details"`
+		} `json:"properties"`
+	} `json:"value"`
+}
+
+var (
+	costAnomalyDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "cost", "anomaly"),
+		"Spend above the expected amount of an active Cost Management anomaly alert.",
+		[]string{
+			"subscription",
+			"resource_group",
+			"alert",
+			"currency",
+		},
+		nil,
+	)
+)
+
+type CostAnomalyConfig struct {
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+
+	GSTenantID string
+}
+
+type CostAnomaly struct {
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+
+	gsTenantID string
+}
+
+// NewCostAnomaly exposes the active Cost Management anomaly alerts of the subscriptions found in the "credential-*"
+// secrets of the control plane, so unexpected spend spikes page someone instead of surprising at the end of the
+// month.
+func NewCostAnomaly(config CostAnomalyConfig) (*CostAnomaly, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	c := &CostAnomaly{
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		gsTenantID:       config.GSTenantID,
+	}
+
+	return c, nil
+}
+
+func (c *CostAnomaly) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := c.collections.newCollectContext("cost_anomaly")
+	defer cancel()
+	clientSets, err := credential.GetAzureClientSetsFromCredentialSecretsBySubscription(ctx, c.credentialSource, c.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for subscriptionID, azureClientSet := range clientSets {
+		restClient := azureClientSet.RESTClient
+
+		var alerts costAlertList
+		err := restClient.GetJSON(ctx, "/subscriptions/"+subscriptionID+"/providers/Microsoft.CostManagement/alerts", costManagementAPIVersion, &alerts)
+		if err != nil {
+			c.logger.Errorf(ctx, err, "an error occurred fetching the cost alerts of subscription %#q", subscriptionID)
+			continue
+		}
+
+		for {
+			for _, alert := range alerts.Value {
+				p := alert.Properties
+				if !strings.EqualFold(p.Status, costAlertStatusActive) || !strings.EqualFold(p.Definition.Type, costAlertTypeAnomaly) {
+					continue
+				}
+
+				// Anomalies scoped to the whole subscription have no
+				// resource group filter.
+				var resourceGroup string
+				if len(p.Details.ResourceGroupFilter) > 0 {
+					resourceGroup = strings.ToLower(key.ResourceNameFromID(p.Details.ResourceGroupFilter[0]))
+				}
+
+				ch <- prometheus.MustNewConstMetric(
+					costAnomalyDesc,
+					prometheus.GaugeValue,
+					p.Details.CurrentSpend-p.Details.Amount,
+					subscriptionID,
+					resourceGroup,
+					alert.Name,
+					p.Details.Unit,
+				)
+			}
+
+			if alerts.NextLink == "" {
+				break
+			}
+
+			nextLink := alerts.NextLink
+			alerts = costAlertList{}
+			err = restClient.GetNextJSON(ctx, nextLink, &alerts)
+			if err != nil {
+				c.logger.Errorf(ctx, err, "an error occurred fetching the cost alerts of subscription %#q", subscriptionID)
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *CostAnomaly) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- costAnomalyDesc
+	return nil
+}
@@ -0,0 +1,202 @@
+package collector
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+)
+
+const (
+	costManagementAPIVersion = "2021-10-01"
+
+	// costManagementRefreshInterval is how often the same Cost Management
+	// query is sent. Cost data is only refreshed a few times a day and the
+	// API is heavily throttled, so querying it on every scrape is pointless.
+	costManagementRefreshInterval = time.Hour
+
+	costTypeActual    = "ActualCost"
+	costTypeAmortized = "AmortizedCost"
+
+	costColumnCost     = "Cost"
+	costColumnCurrency = "Currency"
+)
+
+// costQuery is the body of Cost Management query and forecast requests.
+type costQuery struct {
+	Type       string               `json:"type"`
+	Timeframe  string               `json:"timeframe"`
+	TimePeriod *costQueryTimePeriod `json:"timePeriod,omitempty"`
+	Dataset    costQueryDataset     `json:"dataset"`
+
+	// The following fields are only supported by forecasts.
+	IncludeActualCost       *bool `json:"includeActualCost,omitempty"`
+	IncludeFreshPartialCost *bool `json:"includeFreshPartialCost,omitempty"`
+}
+
+type costQueryTimePeriod struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+type costQueryDataset struct {
+	Granularity string                          `json:"granularity,omitempty"`
+	Aggregation map[string]costQueryAggregation `json:"aggregation"`
+	Grouping    []costQueryGrouping             `json:"grouping,omitempty"`
+	Filter      *costQueryFilter                `json:"filter,omitempty"`
+}
+
+type costQueryAggregation struct {
+	Name     string `json:"name"`
+	Function string `json:"function"`
+}
+
+type costQueryGrouping struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+type costQueryFilter struct {
+	Dimensions *costQueryComparison `json:"dimensions,omitempty"`
+}
+
+type costQueryComparison struct {
+	Name     string   `json:"name"`
+	Operator string   `json:"operator"`
+	Values   []string `json:"values"`
+}
+
+// costQueryResult is the response of Cost Management query and forecast
+// requests. Rows hold one value per column.
+type costQueryResult struct {
+	Properties struct {
+		Columns []struct {
+			Name string `json:"name"`
+		} `json:"columns"`
+		Rows [][]interface{} `json:"rows"`
+	} `json:"properties"`
+}
+
+// costQueryRow maps the lower cased column names of a query result to the
+// values of a single row.
+type costQueryRow map[string]interface{}
+
+// Float returns the number found in the given column or zero if there is
+// none.
+func (r costQueryRow) Float(column string) float64 {
+	v, _ := r[strings.ToLower(column)].(float64)
+	return v
+}
+
+// String returns the string found in the given column or an empty string if
+// there is none.
+func (r costQueryRow) String(column string) string {
+	v, _ := r[strings.ToLower(column)].(string)
+	return v
+}
+
+// rows returns the rows of the query result keyed by column name.
+func (r costQueryResult) rows() []costQueryRow {
+	var rows []costQueryRow
+	for _, values := range r.Properties.Rows {
+		row := costQueryRow{}
+		for i, column := range r.Properties.Columns {
+			if i < len(values) {
+				row[strings.ToLower(column.Name)] = values[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// actualCostQuery returns a query for the actual cost within the given time
+// period summed up per day and grouped by the given dimensions.
+func actualCostQuery(from, to time.Time, groupBy ...string) costQuery {
+	q := costQuery{
+		Type:      costTypeActual,
+		Timeframe: "Custom",
+		TimePeriod: &costQueryTimePeriod{
+			From: from,
+			To:   to,
+		},
+		Dataset: costQueryDataset{
+			Granularity: "Daily",
+			Aggregation: map[string]costQueryAggregation{
+				"totalCost": {
+					Name:     costColumnCost,
+					Function: "Sum",
+				},
+			},
+		},
+	}
+
+	for _, name := range groupBy {
+		q.Dataset.Grouping = append(q.Dataset.Grouping, costQueryGrouping{
+			Type: "Dimension",
+			Name: name,
+		})
+	}
+
+	return q
+}
+
+// startOfMonth returns the beginning of the month the given time is in.
+func startOfMonth(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// costQuerier sends Cost Management requests and caches their results for
+// costManagementRefreshInterval.
+type costQuerier struct {
+	mutex   sync.Mutex
+	entries map[string]costQuerierEntry
+}
+
+type costQuerierEntry struct {
+	rows    []costQueryRow
+	expires time.Time
+}
+
+func newCostQuerier() *costQuerier {
+	return &costQuerier{
+		entries: map[string]costQuerierEntry{},
+	}
+}
+
+// Query sends the query to the given Cost Management action path, e.g.
+// "/subscriptions/<id>/providers/Microsoft.CostManagement/query", unless a
+// result for the path is cached. Only the first page of the result is read,
+// which holds up to 1000 rows and is plenty for the grouped queries we send.
+func (q *costQuerier) Query(ctx context.Context, restClient *client.RESTClient, path string, query costQuery) ([]costQueryRow, error) {
+	q.mutex.Lock()
+	entry, ok := q.entries[path]
+	q.mutex.Unlock()
+
+	if ok && time.Now().Before(entry.expires) {
+		return entry.rows, nil
+	}
+
+	var result costQueryResult
+	err := restClient.PostJSON(ctx, path, costManagementAPIVersion, query, &result)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	entry = costQuerierEntry{
+		rows:    result.rows(),
+		expires: time.Now().Add(costManagementRefreshInterval),
+	}
+
+	q.mutex.Lock()
+	q.entries[path] = entry
+	q.mutex.Unlock()
+
+	return entry.rows, nil
+}
@@ -0,0 +1,63 @@
+package collector
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_costQueryResult_rows(t *testing.T) {
+	testCases := []struct {
+		name          string
+		result        string
+		expectedCosts map[string]float64
+	}{
+		{
+			name:          "case 0: no rows",
+			result:        `{"properties":{"columns":[{"name":"Cost","type":"Number"},{"name":"Currency","type":"String"}],"rows":[]}}`,
+			expectedCosts: map[string]float64{},
+		},
+		{
+			name: "case 1: rows grouped by resource group",
+			result: `{"properties":{"columns":[{"name":"Cost","type":"Number"},{"name":"UsageDate","type":"Number"},{"name":"ResourceGroupName","type":"String"},{"name":"Currency","type":"String"}],"rows":[
+[1.5,20201001,"ab1c2","EUR"],
+[2.25,20201002,"ab1c2","EUR"],
+[10,20201001,"xy9z8","EUR"]
+]}}`,
+			expectedCosts: map[string]float64{
+				"ab1c2/EUR": 3.75,
+				"xy9z8/EUR": 10,
+			},
+		},
+		{
+			name:   "case 2: rows with missing values",
+			result: `{"properties":{"columns":[{"name":"Cost","type":"Number"},{"name":"ResourceGroupName","type":"String"},{"name":"Currency","type":"String"}],"rows":[[4,"ab1c2"]]}}`,
+			expectedCosts: map[string]float64{
+				"ab1c2/": 4,
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			var result costQueryResult
+			err := json.Unmarshal([]byte(tc.result), &result)
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			costs := map[string]float64{}
+			for _, row := range result.rows() {
+				costs[row.String("resourceGroupName")+"/"+row.String(costColumnCurrency)] += row.Float(costColumnCost)
+			}
+
+			if !cmp.Equal(costs, tc.expectedCosts) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedCosts, costs))
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package collector
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2019-05-01/resources"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+)
+
+var invalidLabelNameCharacters = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// costTagLabelNames returns the Prometheus label names the given Azure tags
+// are exposed as on cost metrics, e.g. "tag_cost_center" for "cost-center".
+// Tags whose label names are the same, e.g. "team-a" and "team_a", are
+// rejected, as metrics can't have the same label twice.
+func costTagLabelNames(tags []string) ([]string, error) {
+	var names []string
+	seen := map[string]string{}
+	for _, t := range tags {
+		name := "tag_" + invalidLabelNameCharacters.ReplaceAllString(strings.ToLower(t), "_")
+		if other, ok := seen[name]; ok {
+			return nil, microerror.Maskf(invalidConfigError, "tags %#q and %#q must not both be exposed as label %#q", other, t, name)
+		}
+		seen[name] = t
+
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+// costTagLabelValues returns the values of the given tags in the same order
+// as costTagLabelNames returns their label names. Azure treats tag names case
+// insensitively, so we do too. Missing tags have an empty value.
+func costTagLabelValues(tags []string, resourceTags map[string]*string) []string {
+	var values []string
+	for _, t := range tags {
+		var value string
+		for k, v := range resourceTags {
+			if strings.EqualFold(k, t) {
+				value = to.String(v)
+				break
+			}
+		}
+		values = append(values, value)
+	}
+
+	return values
+}
+
+// getResourceGroupTagLabelValues returns the values of the given tags of the
+// resource group. It does not send any request when no tags are given. When
+// the tags can't be looked up, empty values are returned along with the
+// error, so the cost can still be exposed.
+func getResourceGroupTagLabelValues(ctx context.Context, groupsClient *resources.GroupsClient, resourceGroup string, tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	group, err := groupsClient.Get(ctx, resourceGroup)
+	if err != nil {
+		return costTagLabelValues(tags, nil), microerror.Mask(err)
+	}
+
+	return costTagLabelValues(tags, group.Tags), nil
+}
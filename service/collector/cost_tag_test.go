@@ -0,0 +1,81 @@
+package collector
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_costTagLabels(t *testing.T) {
+	testCases := []struct {
+		name           string
+		tags           []string
+		resourceTags   map[string]*string
+		expectedNames  []string
+		expectedValues []string
+		errorMatcher   func(error) bool
+	}{
+		{
+			name:           "case 0: no tags",
+			tags:           nil,
+			resourceTags:   map[string]*string{"cost-center": to.StringPtr("1234")},
+			expectedNames:  nil,
+			expectedValues: nil,
+		},
+		{
+			name: "case 1: tags with special characters and different casing",
+			tags: []string{"giantswarm.io/cluster", "Cost-Center"},
+			resourceTags: map[string]*string{
+				"giantswarm.io/cluster": to.StringPtr("ab1c2"),
+				"cost-center":           to.StringPtr("1234"),
+			},
+			expectedNames:  []string{"tag_giantswarm_io_cluster", "tag_cost_center"},
+			expectedValues: []string{"ab1c2", "1234"},
+		},
+		{
+			name:           "case 2: missing tag",
+			tags:           []string{"cost-center"},
+			resourceTags:   nil,
+			expectedNames:  []string{"tag_cost_center"},
+			expectedValues: []string{""},
+		},
+		{
+			name:           "case 3: tags exposed as the same label",
+			tags:           []string{"team-a", "team_a"},
+			resourceTags:   nil,
+			expectedNames:  nil,
+			expectedValues: []string{"", ""},
+			errorMatcher:   IsInvalidConfig,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			names, err := costTagLabelNames(tc.tags)
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if !cmp.Equal(names, tc.expectedNames) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedNames, names))
+			}
+
+			values := costTagLabelValues(tc.tags, tc.resourceTags)
+			if !cmp.Equal(values, tc.expectedValues) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedValues, values))
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package collector
+
+import (
+	"github.com/giantswarm/apiextensions/v3/pkg/label"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/giantswarm/azure-collector/v2/service/collector/key"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	credentialSecretReasonNotFound  = "not_found"
+	credentialSecretReasonMalformed = "malformed"
+)
+
+var (
+	credentialSecretInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "credential_secret", "info"),
+		"Metadata of the credential secrets of the management cluster.",
+		[]string{
+			"name",
+			"namespace",
+			"organization",
+			"auth_type",
+		},
+		nil,
+	)
+	credentialSecretCreatedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "credential_secret", "created"),
+		"Creation date of the credential secret in Unix time.",
+		[]string{
+			"name",
+			"namespace",
+		},
+		nil,
+	)
+	credentialSecretCountDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "credential_secret", "count"),
+		"Number of credential secrets of the management cluster.",
+		[]string{
+			"organization",
+			"auth_type",
+		},
+		nil,
+	)
+	credentialSecretInvalidDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "credential_secret", "invalid"),
+		"Set to 1 for every cluster referencing a credential secret which is missing or malformed.",
+		[]string{
+			"cluster_id",
+			"name",
+			"namespace",
+			"reason",
+		},
+		nil,
+	)
+)
+
+type CredentialSecretConfig struct {
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+}
+
+type CredentialSecret struct {
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+}
+
+// NewCredentialSecret exposes an inventory of the credential secrets on the management cluster and the clusters
+// referencing a credential secret which is missing or malformed. Those clusters are not covered by any of the
+// collectors using cluster credentials.
+func NewCredentialSecret(config CredentialSecretConfig) (*CredentialSecret, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	c := &CredentialSecret{
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+	}
+
+	return c, nil
+}
+
+func (c *CredentialSecret) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := c.collections.newCollectContext("credential_secret")
+	defer cancel()
+
+	secrets, err := credential.GetCredentialSecrets(ctx, c.credentialSource)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	type countKey struct {
+		organization string
+		authType     string
+	}
+	counts := map[countKey]int{}
+
+	for _, secret := range secrets {
+		organization := secret.Labels[label.Organization]
+		authType := credential.GetAuthType(&secret)
+
+		ch <- prometheus.MustNewConstMetric(
+			credentialSecretInfoDesc,
+			prometheus.GaugeValue,
+			gaugeValue,
+			secret.Name,
+			secret.Namespace,
+			organization,
+			authType,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			credentialSecretCreatedDesc,
+			prometheus.GaugeValue,
+			float64(secret.CreationTimestamp.Unix()),
+			secret.Name,
+			secret.Namespace,
+		)
+
+		counts[countKey{organization: organization, authType: authType}]++
+	}
+
+	for k, count := range counts {
+		ch <- prometheus.MustNewConstMetric(
+			credentialSecretCountDesc,
+			prometheus.GaugeValue,
+			float64(count),
+			k.organization,
+			k.authType,
+		)
+	}
+
+	crs, err := credential.GetAzureConfigs(ctx, c.credentialSource)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, cr := range crs {
+		name := key.CredentialName(cr)
+		namespace := key.CredentialNamespace(cr)
+
+		var reason string
+		{
+			secret, err := c.credentialSource.CredentialSecret(ctx, name, namespace)
+			if apierrors.IsNotFound(microerror.Cause(err)) {
+				reason = credentialSecretReasonNotFound
+			} else if err != nil {
+				return microerror.Mask(err)
+			} else if err := credential.ValidateSecret(secret); err != nil {
+				c.logger.Debugf(ctx, "credential secret %#q of cluster %#q is malformed: %s", namespace+"/"+name, cr.GetName(), err)
+				reason = credentialSecretReasonMalformed
+			}
+		}
+
+		if reason == "" {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			credentialSecretInvalidDesc,
+			prometheus.GaugeValue,
+			gaugeValue,
+			cr.GetName(),
+			name,
+			namespace,
+			reason,
+		)
+	}
+
+	return nil
+}
+
+func (c *CredentialSecret) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- credentialSecretInfoDesc
+	ch <- credentialSecretCreatedDesc
+	ch <- credentialSecretCountDesc
+	ch <- credentialSecretInvalidDesc
+	return nil
+}
@@ -0,0 +1,246 @@
+package collector
+
+import (
+	"context"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/service/collector/key"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	// credentialProbeInterval is how often a token is acquired with the same
+	// credentials. Clusters often share them, and acquiring tokens on every
+	// scrape only adds load on Azure AD.
+	credentialProbeInterval = 10 * time.Minute
+
+	credentialErrorClassExpiredSecret  = "expired_secret"
+	credentialErrorClassInvalidSecret  = "invalid_secret"
+	credentialErrorClassDisabledSP     = "disabled_sp"
+	credentialErrorClassTenantMismatch = "tenant_mismatch"
+	credentialErrorClassUnknown        = "unknown"
+)
+
+// credentialErrorClasses maps the Azure AD error codes returned when
+// acquiring a token to the error classes we expose.
+var credentialErrorClasses = map[string]string{
+	// The client secret is expired.
+	"AADSTS7000222": credentialErrorClassExpiredSecret,
+	// The client secret is wrong.
+	"AADSTS7000215": credentialErrorClassInvalidSecret,
+	// The application or its service principal is disabled.
+	"AADSTS7000112": credentialErrorClassDisabledSP,
+	"AADSTS7000111": credentialErrorClassDisabledSP,
+	// The application is not found in the tenant.
+	"AADSTS700016": credentialErrorClassTenantMismatch,
+	// The tenant is not found.
+	"AADSTS90002": credentialErrorClassTenantMismatch,
+}
+
+var aadErrorCodeRegexp = regexp.MustCompile(`AADSTS\d+`)
+
+var (
+	credentialValidDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "credential", "valid"),
+		"Whether a token could be acquired with the credentials of the cluster. The error_class label tells why it could not.",
+		[]string{
+			"cluster_id",
+			labelClientId,
+			labelTenantId,
+			"error_class",
+		},
+		nil,
+	)
+)
+
+type CredentialValidityConfig struct {
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+
+	GSTenantID string
+}
+
+type CredentialValidity struct {
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+	prober           *credentialProber
+
+	gsTenantID string
+}
+
+// NewCredentialValidity acquires a token with the credentials of every cluster and exposes whether that worked. Unlike
+// the expiration dates, this catches deleted or disabled service principals and secrets which were rotated in Azure
+// only. Credentials shared by clusters are probed once per credentialProbeInterval.
+func NewCredentialValidity(config CredentialValidityConfig) (*CredentialValidity, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	c := &CredentialValidity{
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		prober:           newCredentialProber(),
+		gsTenantID:       config.GSTenantID,
+	}
+
+	return c, nil
+}
+
+func (c *CredentialValidity) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := c.collections.newCollectContext("credential_validity")
+	defer cancel()
+
+	// Clusters whose credential secret is missing or malformed are
+	// reported as cluster errors and by the credential secret collector.
+	clientSets, err := getClientSetsByCluster(ctx, c.credentialSource, c.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	crs, err := credential.GetAzureConfigs(ctx, c.credentialSource)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	configs := map[string]*client.AzureClientSetConfig{}
+	for _, cr := range crs {
+		if _, ok := clientSets[cr.GetName()]; !ok {
+			continue
+		}
+
+		config, err := credential.GetAzureConfigFromSecretName(ctx, c.credentialSource, key.CredentialName(cr), key.CredentialNamespace(cr), c.gsTenantID)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		configs[cr.GetName()] = config
+	}
+
+	forEachCluster(ctx, ch, clientSets, func(ctx context.Context, ch chan<- prometheus.Metric, clusterID string, azureClientSet *client.AzureClientSet) error {
+		config, ok := configs[clusterID]
+		if !ok {
+			return nil
+		}
+
+		errorClass, err := c.prober.Probe(ctx, config)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		var valid float64
+		if errorClass == "" {
+			valid = 1
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			credentialValidDesc,
+			prometheus.GaugeValue,
+			valid,
+			clusterID,
+			config.ClientID,
+			config.TenantID,
+			errorClass,
+		)
+
+		return nil
+	})
+
+	return nil
+}
+
+func (c *CredentialValidity) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- credentialValidDesc
+	return nil
+}
+
+// credentialErrorClass returns the error class of the given token
+// acquisition error. The Azure AD error code is part of the error message,
+// e.g. "AADSTS7000222: The provided client secret keys are expired.".
+func credentialErrorClass(err error) string {
+	for _, code := range aadErrorCodeRegexp.FindAllString(err.Error(), -1) {
+		class, ok := credentialErrorClasses[code]
+		if ok {
+			return class
+		}
+	}
+
+	return credentialErrorClassUnknown
+}
+
+// credentialProber acquires tokens with the credentials of the clusters and
+// caches the outcome per client and tenant ID for credentialProbeInterval.
+type credentialProber struct {
+	mutex   sync.Mutex
+	entries map[[2]string]*credentialProberEntry
+}
+
+type credentialProberEntry struct {
+	// mutex is held while probing, so clusters sharing credentials wait for
+	// the same probe.
+	mutex      sync.Mutex
+	errorClass string
+	expires    time.Time
+}
+
+func newCredentialProber() *credentialProber {
+	return &credentialProber{
+		entries: map[[2]string]*credentialProberEntry{},
+	}
+}
+
+// Probe returns the error class of acquiring a token with the given
+// credentials, which is empty when that worked. Errors not returned by Azure
+// AD, e.g. failing connections, tell nothing about the credentials and are
+// returned instead of being cached.
+func (p *credentialProber) Probe(ctx context.Context, config *client.AzureClientSetConfig) (string, error) {
+	k := [2]string{config.ClientID, config.TenantID}
+
+	p.mutex.Lock()
+	entry, ok := p.entries[k]
+	if !ok {
+		entry = &credentialProberEntry{}
+		p.entries[k] = entry
+	}
+	p.mutex.Unlock()
+
+	entry.mutex.Lock()
+	defer entry.mutex.Unlock()
+
+	if time.Now().Before(entry.expires) {
+		return entry.errorClass, nil
+	}
+
+	var errorClass string
+	err := config.Factory.RefreshToken(ctx, config.Factory.NewClientCredentialsConfig(config.ClientID, config.ClientSecret, config.TenantID))
+	if err != nil {
+		if !aadErrorCodeRegexp.MatchString(err.Error()) {
+			return "", microerror.Mask(err)
+		}
+
+		errorClass = credentialErrorClass(err)
+	}
+
+	entry.errorClass = errorClass
+	entry.expires = time.Now().Add(credentialProbeInterval)
+
+	return errorClass, nil
+}
@@ -0,0 +1,47 @@
+package collector
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func Test_credentialErrorClass(t *testing.T) {
+	testCases := []struct {
+		name          string
+		err           error
+		expectedClass string
+	}{
+		{
+			name:          "case 0: expired secret",
+			err:           errors.New(`adal: Refresh request failed. Status Code = '401'. Response body: {"error":"invalid_client","error_description":"AADSTS7000222: The provided client secret keys for app '00000000-0000-0000-0000-000000000000' are expired.","error_codes":[7000222]}`),
+			expectedClass: "expired_secret",
+		},
+		{
+			name:          "case 1: application not found in tenant",
+			err:           errors.New(`adal: Refresh request failed. Status Code = '400'. Response body: {"error":"unauthorized_client","error_description":"AADSTS700016: Application with identifier '00000000-0000-0000-0000-000000000000' was not found in the directory 'example'."}`),
+			expectedClass: "tenant_mismatch",
+		},
+		{
+			name:          "case 2: unknown error code",
+			err:           errors.New(`adal: Refresh request failed. Status Code = '400'. Response body: {"error_description":"AADSTS12345: Something else."}`),
+			expectedClass: "unknown",
+		},
+		{
+			name:          "case 3: network error",
+			err:           errors.New("dial tcp: lookup login.microsoftonline.com: no such host"),
+			expectedClass: "unknown",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			class := credentialErrorClass(tc.err)
+			if class != tc.expectedClass {
+				t.Fatalf("class == %#q, want %#q", class, tc.expectedClass)
+			}
+		})
+	}
+}
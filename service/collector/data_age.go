@@ -0,0 +1,165 @@
+package collector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/giantswarm/exporterkit/collector"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	dataAgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "data", "age_seconds"),
+		"Seconds since the last complete collection of the collector, or since the start of the process when there was none yet. With the LastKnownGood feature, its metrics of that collection are served while later collections run into their deadline.",
+		[]string{
+			"collector",
+		},
+		nil,
+	)
+
+	// processStarted is when the process started. It is the age of the data
+	// of collectors without a complete collection yet.
+	processStarted = time.Now()
+
+	// lastCollections are the times of the last complete collection of
+	// every collector.
+	lastCollections = newCollectionTimes()
+)
+
+// collectionTimes are the times of the last complete collection of every
+// collector.
+type collectionTimes struct {
+	mutex sync.Mutex
+	times map[string]time.Time
+}
+
+func newCollectionTimes() *collectionTimes {
+	return &collectionTimes{
+		times: map[string]time.Time{},
+	}
+}
+
+// init records the given time for the given collector unless there is one
+// already.
+func (t *collectionTimes) init(collector string, started time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if _, ok := t.times[collector]; !ok {
+		t.times[collector] = started
+	}
+}
+
+func (t *collectionTimes) record(collector string, collected time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.times[collector] = collected
+}
+
+// all returns a copy of the times of every collector.
+func (t *collectionTimes) all() map[string]time.Time {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	times := map[string]time.Time{}
+	for collector, collected := range t.times {
+		times[collector] = collected
+	}
+
+	return times
+}
+
+// dataAgeCollector records the start of the complete collections of a
+// collector, i.e. the ones which neither fail nor run into their deadline.
+type dataAgeCollector struct {
+	collections *Collections
+	collector   collector.Interface
+	name        string
+	now         func() time.Time
+}
+
+// withDataAges records the complete collections of the given collectors, so
+// the age of their data is exposed by the DataAge collector.
+func withDataAges(collectors []collector.Interface, collections *Collections) []collector.Interface {
+	var wrapped []collector.Interface
+	for _, c := range collectors {
+		d := &dataAgeCollector{
+			collections: collections,
+			collector:   c,
+			name:        collectorName(c),
+			now:         time.Now,
+		}
+		lastCollections.init(d.name, processStarted)
+
+		wrapped = append(wrapped, d)
+	}
+
+	return wrapped
+}
+
+func (d *dataAgeCollector) Collect(ch chan<- prometheus.Metric) error {
+	started := d.now()
+	deadline, ok := d.collections.collectDeadline(started, d.collections.getScrape())
+
+	err := d.collector.Collect(ch)
+	if err != nil {
+		return err
+	}
+
+	if !ok || d.now().Before(deadline) {
+		lastCollections.record(d.name, started)
+	}
+
+	return nil
+}
+
+func (d *dataAgeCollector) Describe(ch chan<- *prometheus.Desc) error {
+	return d.collector.Describe(ch)
+}
+
+type DataAgeConfig struct {
+	Logger micrologger.Logger
+}
+
+type DataAge struct {
+	logger micrologger.Logger
+}
+
+// NewDataAge exposes the age of the metrics served for every collector, so
+// dashboards can tell fresh from stale data. Collectors without a complete
+// collection yet are exposed with the age of the process.
+func NewDataAge(config DataAgeConfig) (*DataAge, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	d := &DataAge{
+		logger: config.Logger,
+	}
+
+	return d, nil
+}
+
+func (d *DataAge) Collect(ch chan<- prometheus.Metric) error {
+	now := time.Now()
+
+	for collector, collected := range lastCollections.all() {
+		ch <- prometheus.MustNewConstMetric(
+			dataAgeDesc,
+			prometheus.GaugeValue,
+			now.Sub(collected).Seconds(),
+			collector,
+		)
+	}
+
+	return nil
+}
+
+func (d *DataAge) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- dataAgeDesc
+	return nil
+}
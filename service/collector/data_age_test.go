@@ -0,0 +1,82 @@
+package collector
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/exporterkit/collector"
+	"github.com/giantswarm/microerror"
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func Test_dataAgeCollector(t *testing.T) {
+	timeout := time.Minute
+	failed := microerror.Mask(invalidConfigError)
+	started := time.Date(2021, 5, 4, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name         string
+		collections  []testCollection
+		expectedTime time.Time
+	}{
+		{
+			name:         "case 0: collector without collection has the age of the process",
+			collections:  nil,
+			expectedTime: processStarted,
+		},
+		{
+			name: "case 1: complete collections are recorded",
+			collections: []testCollection{
+				{values: []float64{1}, duration: time.Second},
+				{values: []float64{2}, duration: time.Second},
+			},
+			expectedTime: started.Add(time.Second),
+		},
+		{
+			name: "case 2: collections running into their deadline are not recorded",
+			collections: []testCollection{
+				{values: []float64{1}, duration: time.Second},
+				{values: []float64{2}, duration: 2 * timeout},
+			},
+			expectedTime: started,
+		},
+		{
+			name: "case 3: failed collections are not recorded",
+			collections: []testCollection{
+				{values: []float64{1}, err: failed},
+			},
+			expectedTime: processStarted,
+		},
+	}
+
+	collections, err := NewCollections(RuntimeConfig{CollectTimeout: timeout})
+	if err != nil {
+		t.Fatalf("expected no error, got %#v", err)
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			lastCollections = newCollectionTimes()
+
+			clock := &testClock{now: started}
+
+			d := withDataAges([]collector.Interface{&testCollector{clock: clock, collections: tc.collections}}, collections)[0].(*dataAgeCollector)
+			d.now = clock.Now
+
+			for range tc.collections {
+				ch := make(chan prometheus.Metric, 10)
+				_ = d.Collect(ch)
+				close(ch)
+			}
+
+			collected := lastCollections.all()["collector.testCollector"]
+			if !cmp.Equal(collected, tc.expectedTime) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedTime, collected))
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package collector
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-11-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+var (
+	ddosProtectionVNetDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "ddos_protection", "vnet_covered"),
+		"Whether the cluster virtual network is covered by a DDoS protection plan.",
+		[]string{
+			"cluster_id",
+			"vnet",
+			"ddos_protection_plan",
+		},
+		nil,
+	)
+	ddosProtectionCoverageDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "ddos_protection", "coverage_ratio"),
+		"Ratio of cluster virtual networks covered by a DDoS protection plan.",
+		[]string{
+			"installation",
+		},
+		nil,
+	)
+)
+
+type DDoSProtectionConfig struct {
+	InstallationName string
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+	GSTenantID       string
+}
+
+type DDoSProtection struct {
+	installationName string
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+	gsTenantID       string
+}
+
+// NewDDoSProtection exposes metrics about the DDoS protection plan coverage of the cluster virtual networks.
+// It lists the virtual networks in every cluster resource group using the cluster Azure credentials.
+func NewDDoSProtection(config DDoSProtectionConfig) (*DDoSProtection, error) {
+	if config.InstallationName == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.InstallationName must not be empty", config)
+	}
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	d := &DDoSProtection{
+		installationName: config.InstallationName,
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		gsTenantID:       config.GSTenantID,
+	}
+
+	return d, nil
+}
+
+func (d *DDoSProtection) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := d.collections.newCollectContext("ddos_protection")
+	defer cancel()
+
+	azureClientSets, err := getClientSetsByCluster(ctx, d.credentialSource, d.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	var covered, total int
+	var mutex sync.Mutex
+	forEachCluster(ctx, ch, azureClientSets, func(ctx context.Context, ch chan<- prometheus.Metric, clusterID string, azureClientSet *client.AzureClientSet) error {
+		vnets, err := azureClientSet.VirtualNetworksClient.ListComplete(ctx, clusterID)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for vnets.NotDone() {
+			vnet := vnets.Value()
+
+			planID := ddosProtectionPlanID(vnet)
+			var isCovered float64
+			mutex.Lock()
+			if planID != "" {
+				isCovered = 1
+				covered++
+			}
+			total++
+			mutex.Unlock()
+
+			ch <- prometheus.MustNewConstMetric(
+				ddosProtectionVNetDesc,
+				prometheus.GaugeValue,
+				isCovered,
+				clusterID,
+				to.String(vnet.Name),
+				planID,
+			)
+
+			if err := vnets.NextWithContext(ctx); err != nil {
+				return microerror.Mask(err)
+			}
+		}
+
+		return nil
+	})
+
+	// Without any virtual network there is nothing which could be
+	// uncovered, so the installation is considered compliant.
+	ratio := float64(1)
+	if total > 0 {
+		ratio = float64(covered) / float64(total)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		ddosProtectionCoverageDesc,
+		prometheus.GaugeValue,
+		ratio,
+		d.installationName,
+	)
+
+	return nil
+}
+
+func (d *DDoSProtection) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- ddosProtectionVNetDesc
+	ch <- ddosProtectionCoverageDesc
+	return nil
+}
+
+// ddosProtectionPlanID returns the ID of the DDoS protection plan covering the
+// given virtual network or an empty string if it is not covered.
+func ddosProtectionPlanID(vnet network.VirtualNetwork) string {
+	if vnet.VirtualNetworkPropertiesFormat == nil {
+		return ""
+	}
+	if !to.Bool(vnet.EnableDdosProtection) || vnet.DdosProtectionPlan == nil {
+		return ""
+	}
+
+	return to.String(vnet.DdosProtectionPlan.ID)
+}
@@ -2,14 +2,15 @@ package collector
 
 import (
 	"context"
+	"time"
 
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2019-05-01/resources"
 	"github.com/Azure/go-autorest/autorest/to"
-	"github.com/giantswarm/apiextensions/v2/pkg/clientset/versioned"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	"github.com/prometheus/client_golang/prometheus"
-	"k8s.io/client-go/kubernetes"
 
+	"github.com/giantswarm/azure-collector/v2/client"
 	"github.com/giantswarm/azure-collector/v2/service/credential"
 )
 
@@ -18,8 +19,24 @@ const (
 	statusFailed    = "Failed"
 	statusRunning   = "Running"
 	statusSucceeded = "Succeeded"
+
+	deploymentAPIVersion = "2021-04-01"
 )
 
+// deploymentWithError holds the subset of the deployment properties we need
+// which are not supported by the SDK.
+type deploymentWithError struct {
+	Properties struct {
+		Error *deploymentError `json:"error"`
+	} `json:"properties"`
+}
+
+type deploymentError struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Details []deploymentError `json:"details"`
+}
+
 var (
 	deploymentDesc = prometheus.NewDesc(
 		prometheus.BuildFQName(MetricsNamespace, "deployment", "status"),
@@ -31,30 +48,48 @@ var (
 		},
 		nil,
 	)
+	deploymentFailedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "deployment", "failed"),
+		"Number of failed deployments among the most recent deployments of the cluster resource group.",
+		[]string{
+			"cluster_id",
+		},
+		nil,
+	)
+	deploymentLastFailureDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "deployment", "last_failure"),
+		"Date of the most recent failed deployment of the cluster resource group in Unix time, labeled with the error code of the provider causing the failure.",
+		[]string{
+			"cluster_id",
+			"deployment_name",
+			"error_code",
+		},
+		nil,
+	)
 )
 
 type DeploymentConfig struct {
-	G8sClient  versioned.Interface
-	K8sClient  kubernetes.Interface
-	Logger     micrologger.Logger
-	GSTenantID string
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+	GSTenantID       string
 }
 
 type Deployment struct {
-	g8sClient  versioned.Interface
-	k8sClient  kubernetes.Interface
-	logger     micrologger.Logger
-	gsTenantID string
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+	gsTenantID       string
 }
 
 // NewDeployment exposes metrics about the Azure ARM Deployments for every cluster on this installation.
 // It finds the cluster in the control plane, and uses the cluster Azure credentials to find the Deployments info.
 func NewDeployment(config DeploymentConfig) (*Deployment, error) {
-	if config.G8sClient == nil {
-		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
 	}
-	if config.K8sClient == nil {
-		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
 	}
 	if config.Logger == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
@@ -64,30 +99,40 @@ func NewDeployment(config DeploymentConfig) (*Deployment, error) {
 	}
 
 	d := &Deployment{
-		g8sClient:  config.G8sClient,
-		k8sClient:  config.K8sClient,
-		logger:     config.Logger,
-		gsTenantID: config.GSTenantID,
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		gsTenantID:       config.GSTenantID,
 	}
 
 	return d, nil
 }
 
 func (d *Deployment) Collect(ch chan<- prometheus.Metric) error {
-	ctx := context.Background()
-	azureClientSets, err := credential.GetAzureClientSetsByCluster(ctx, d.k8sClient, d.g8sClient, d.gsTenantID)
+	ctx, cancel := d.collections.newCollectContext("deployment")
+	defer cancel()
+	azureClientSets, err := getClientSetsByCluster(ctx, d.credentialSource, d.gsTenantID)
 	if err != nil {
 		return microerror.Mask(err)
 	}
 
-	for clusterID, azureClientSet := range azureClientSets {
-		r, err := azureClientSet.DeploymentsClient.ListByResourceGroup(context.Background(), clusterID, "", to.Int32Ptr(100))
+	forEachCluster(ctx, ch, azureClientSets, func(ctx context.Context, ch chan<- prometheus.Metric, clusterID string, azureClientSet *client.AzureClientSet) error {
+		r, err := azureClientSet.DeploymentsClient.ListByResourceGroup(ctx, clusterID, "", to.Int32Ptr(100))
 		if err != nil {
 			return microerror.Mask(err)
 		}
 
+		var failed int
+		var lastFailure resources.DeploymentExtended
 		for r.NotDone() {
 			for _, v := range r.Values() {
+				if to.String(v.Properties.ProvisioningState) == statusFailed {
+					failed++
+					if lastFailure.Properties == nil || deploymentTime(v).After(deploymentTime(lastFailure)) {
+						lastFailure = v
+					}
+				}
+
 				ch <- prometheus.MustNewConstMetric(
 					deploymentDesc,
 					prometheus.GaugeValue,
@@ -127,16 +172,70 @@ func (d *Deployment) Collect(ch chan<- prometheus.Metric) error {
 				return microerror.Mask(err)
 			}
 		}
-	}
+
+		ch <- prometheus.MustNewConstMetric(
+			deploymentFailedDesc,
+			prometheus.GaugeValue,
+			float64(failed),
+			clusterID,
+		)
+
+		if lastFailure.Properties != nil {
+			// The deployment error is not supported by the SDK version we
+			// use.
+			var deployment deploymentWithError
+			err := azureClientSet.RESTClient.GetJSON(ctx, to.String(lastFailure.ID), deploymentAPIVersion, &deployment)
+			if err != nil {
+				d.logger.Errorf(ctx, err, "an error occurred fetching deployment %#q of cluster %#q", to.String(lastFailure.Name), clusterID)
+				return nil
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				deploymentLastFailureDesc,
+				prometheus.GaugeValue,
+				float64(deploymentTime(lastFailure).Unix()),
+				clusterID,
+				to.String(lastFailure.Name),
+				deploymentErrorCode(deployment.Properties.Error),
+			)
+		}
+
+		return nil
+	})
 
 	return nil
 }
 
 func (d *Deployment) Describe(ch chan<- *prometheus.Desc) error {
 	ch <- deploymentDesc
+	ch <- deploymentFailedDesc
+	ch <- deploymentLastFailureDesc
 	return nil
 }
 
+// deploymentErrorCode returns the code of the error causing the deployment
+// to fail. The top level error is usually a generic one like
+// "DeploymentFailed", so we return the code of the innermost details.
+func deploymentErrorCode(e *deploymentError) string {
+	if e == nil {
+		return ""
+	}
+
+	for len(e.Details) > 0 {
+		e = &e.Details[0]
+	}
+
+	return e.Code
+}
+
+func deploymentTime(deployment resources.DeploymentExtended) time.Time {
+	if deployment.Properties == nil || deployment.Properties.Timestamp == nil {
+		return time.Time{}
+	}
+
+	return deployment.Properties.Timestamp.Time
+}
+
 func matchedStringToInt(a, b string) int {
 	if a == b {
 		return 1
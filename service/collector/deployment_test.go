@@ -0,0 +1,55 @@
+package collector
+
+import (
+	"strconv"
+	"testing"
+)
+
+func Test_deploymentErrorCode(t *testing.T) {
+	testCases := []struct {
+		name         string
+		err          *deploymentError
+		expectedCode string
+	}{
+		{
+			name:         "case 0: no error",
+			err:          nil,
+			expectedCode: "",
+		},
+		{
+			name: "case 1: error without details",
+			err: &deploymentError{
+				Code: "InvalidTemplate",
+			},
+			expectedCode: "InvalidTemplate",
+		},
+		{
+			name: "case 2: nested provider error",
+			err: &deploymentError{
+				Code: "DeploymentFailed",
+				Details: []deploymentError{
+					{
+						Code: "Conflict",
+						Details: []deploymentError{
+							{
+								Code: "OperationNotAllowed",
+							},
+						},
+					},
+				},
+			},
+			expectedCode: "OperationNotAllowed",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			code := deploymentErrorCode(tc.err)
+			if code != tc.expectedCode {
+				t.Fatalf("code == %#q, want %#q", code, tc.expectedCode)
+			}
+		})
+	}
+}
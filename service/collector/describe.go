@@ -0,0 +1,168 @@
+package collector
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/giantswarm/exporterkit/collector"
+	"github.com/giantswarm/microerror"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Stability levels of the metrics of collectors.
+const (
+	// StabilityStable metrics are neither renamed nor relabeled without a
+	// major release.
+	StabilityStable = "stable"
+	// StabilityAlpha metrics may still change in minor releases.
+	StabilityAlpha = "alpha"
+)
+
+var (
+	// descRegexp matches the string representation of a metric descriptor,
+	// which is the only way to get at its name, help and labels.
+	descRegexp       = regexp.MustCompile(`^Desc\{fqName: ("(?:[^"\\]|\\.)*"), help: ("(?:[^"\\]|\\.)*"), constLabels: \{(.*)\}, variableLabels: \[(.*)\]\}$`)
+	constLabelRegexp = regexp.MustCompile(`(\w+)=("(?:[^"\\]|\\.)*")`)
+
+	// stableCollectors are the collectors whose metrics are stable. The
+	// metrics of all other collectors are alpha.
+	stableCollectors = map[string]bool{
+		"cluster.Collectors":      true,
+		"collector.Deployment":    true,
+		"collector.RateLimit":     true,
+		"collector.ResourceGroup": true,
+		"collector.SPExpiration":  true,
+		"collector.Usage":         true,
+		"collector.VMSSRateLimit": true,
+		"collector.VPNConnection": true,
+	}
+)
+
+// Descriptor describes a metric exported by a collector.
+type Descriptor struct {
+	Name        string            `json:"name"`
+	Help        string            `json:"help"`
+	Labels      []string          `json:"labels"`
+	ConstLabels map[string]string `json:"constLabels,omitempty"`
+	Collector   string            `json:"collector"`
+	Stability   string            `json:"stability"`
+}
+
+// Descriptors returns the descriptors of the metrics of all collectors of the
+// set ordered by name.
+func (s *Set) Descriptors() ([]Descriptor, error) {
+	return describeCollectors(s.collectors)
+}
+
+// Names returns the names of all collectors of the set in order.
+func (s *Set) Names() []string {
+	var names []string
+	for _, c := range s.collectors {
+		names = append(names, collectorName(c))
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func describeCollectors(collectors []collector.Interface) ([]Descriptor, error) {
+	var descriptors []Descriptor
+	for _, c := range collectors {
+		d, err := describeCollector(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		descriptors = append(descriptors, d...)
+	}
+
+	sort.SliceStable(descriptors, func(i, j int) bool { return descriptors[i].Name < descriptors[j].Name })
+
+	return descriptors, nil
+}
+
+// describeCollector returns the descriptors of the metrics of the given
+// collector in the order it describes them.
+func describeCollector(c collector.Interface) ([]Descriptor, error) {
+	name := collectorName(c)
+
+	stability := StabilityAlpha
+	if stableCollectors[name] {
+		stability = StabilityStable
+	}
+
+	ch := make(chan *prometheus.Desc)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(ch)
+		errs <- c.Describe(ch)
+	}()
+
+	var descriptors []Descriptor
+	for desc := range ch {
+		d, err := parseDesc(desc)
+		if err != nil {
+			// The remaining descriptors must still be received, so the
+			// collector is not blocked.
+			for range ch {
+			}
+			return nil, microerror.Mask(err)
+		}
+
+		d.Collector = name
+		d.Stability = stability
+
+		descriptors = append(descriptors, d)
+	}
+
+	err := <-errs
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return descriptors, nil
+}
+
+// parseDesc returns the name, help and labels of the given metric descriptor.
+func parseDesc(desc *prometheus.Desc) (Descriptor, error) {
+	matches := descRegexp.FindStringSubmatch(desc.String())
+	if matches == nil {
+		return Descriptor{}, microerror.Maskf(invalidDescriptorError, "%s", desc.String())
+	}
+
+	name, err := strconv.Unquote(matches[1])
+	if err != nil {
+		return Descriptor{}, microerror.Maskf(invalidDescriptorError, "%s", desc.String())
+	}
+	help, err := strconv.Unquote(matches[2])
+	if err != nil {
+		return Descriptor{}, microerror.Maskf(invalidDescriptorError, "%s", desc.String())
+	}
+
+	d := Descriptor{
+		Name:   name,
+		Help:   help,
+		Labels: []string{},
+	}
+
+	for _, m := range constLabelRegexp.FindAllStringSubmatch(matches[3], -1) {
+		value, err := strconv.Unquote(m[2])
+		if err != nil {
+			return Descriptor{}, microerror.Maskf(invalidDescriptorError, "%s", desc.String())
+		}
+
+		if d.ConstLabels == nil {
+			d.ConstLabels = map[string]string{}
+		}
+		d.ConstLabels[m[1]] = value
+	}
+
+	if matches[4] != "" {
+		d.Labels = strings.Split(matches[4], " ")
+	}
+
+	return d, nil
+}
@@ -0,0 +1,60 @@
+package collector
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func Test_parseDesc(t *testing.T) {
+	testCases := []struct {
+		name               string
+		desc               *prometheus.Desc
+		expectedDescriptor Descriptor
+	}{
+		{
+			name: "case 0: metric without labels",
+			desc: prometheus.NewDesc("azure_operator_arm_budget_limit", "Maximum number of ARM calls.", nil, nil),
+			expectedDescriptor: Descriptor{
+				Name:   "azure_operator_arm_budget_limit",
+				Help:   "Maximum number of ARM calls.",
+				Labels: []string{},
+			},
+		},
+		{
+			name: "case 1: metric with variable and const labels",
+			desc: prometheus.NewDesc(
+				"azure_operator_cluster_error",
+				`Whether collecting the "cluster" fails, e.g. {a, b}.`,
+				[]string{"cluster", "reason"},
+				prometheus.Labels{"window": "1h", "scope": `a "quoted" value`},
+			),
+			expectedDescriptor: Descriptor{
+				Name:   "azure_operator_cluster_error",
+				Help:   `Whether collecting the "cluster" fails, e.g. {a, b}.`,
+				Labels: []string{"cluster", "reason"},
+				ConstLabels: map[string]string{
+					"scope":  `a "quoted" value`,
+					"window": "1h",
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			d, err := parseDesc(tc.desc)
+			if err != nil {
+				t.Fatalf("expected no error, got %#v", err)
+			}
+
+			if !cmp.Equal(d, tc.expectedDescriptor) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedDescriptor, d))
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package collector
+
+import (
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/preview/monitor/mgmt/2019-06-01/insights"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+var (
+	diagnosticSettingsCompliantDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "diagnostic_settings", "compliant"),
+		"Whether the resource has diagnostic settings sending to the expected destination.",
+		[]string{
+			"resource_group",
+			"resource_type",
+			"resource",
+		},
+		nil,
+	)
+)
+
+type DiagnosticSettingsConfig struct {
+	CredentialSource          *credential.Source
+	Collections               *Collections
+	Logger                    micrologger.Logger
+	ControlPlaneResourceGroup string
+	GSTenantID                string
+
+	// Destination is the resource ID of the Log Analytics workspace, storage
+	// account or event hub authorization rule the diagnostic settings are
+	// expected to send to. Any destination is accepted when empty.
+	Destination   string
+	ResourceTypes []string
+}
+
+type DiagnosticSettings struct {
+	credentialSource          *credential.Source
+	collections               *Collections
+	logger                    micrologger.Logger
+	controlPlaneResourceGroup string
+	gsTenantID                string
+
+	destination   string
+	resourceTypes []string
+}
+
+// NewDiagnosticSettings exposes whether the resources of the configured types in the managed resource groups have
+// diagnostic settings sending to the expected destination, as required by audits.
+func NewDiagnosticSettings(config DiagnosticSettingsConfig) (*DiagnosticSettings, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.ControlPlaneResourceGroup == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ControlPlaneResourceGroup must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	d := &DiagnosticSettings{
+		credentialSource:          config.CredentialSource,
+		collections:               config.Collections,
+		logger:                    config.Logger,
+		controlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+		gsTenantID:                config.GSTenantID,
+
+		destination:   config.Destination,
+		resourceTypes: config.ResourceTypes,
+	}
+
+	return d, nil
+}
+
+func (d *DiagnosticSettings) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := d.collections.newCollectContext("diagnostic_settings")
+	defer cancel()
+
+	resourceGroups, err := getManagedResourceGroups(ctx, d.credentialSource, d.gsTenantID, d.controlPlaneResourceGroup)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, resourceGroup := range resourceGroups {
+		azureClientSet := resourceGroup.AzureClientSet
+
+		for _, resourceType := range d.resourceTypes {
+			resources, err := getResourcesByType(ctx, azureClientSet.ResourcesClient, resourceGroup.Name, resourceType)
+			if IsNotFound(err) {
+				break
+			} else if err != nil {
+				return microerror.Mask(err)
+			}
+
+			for _, resource := range resources {
+				settings, err := azureClientSet.DiagnosticSettingsClient.List(ctx, to.String(resource.ID))
+				if err != nil {
+					d.logger.Errorf(ctx, err, "an error occurred fetching the diagnostic settings of resource %#q", to.String(resource.ID))
+					continue
+				}
+
+				var compliant float64
+				if settings.Value != nil && hasDiagnosticSettingsDestination(*settings.Value, d.destination) {
+					compliant = 1
+				}
+
+				ch <- prometheus.MustNewConstMetric(
+					diagnosticSettingsCompliantDesc,
+					prometheus.GaugeValue,
+					compliant,
+					resourceGroup.Name,
+					resourceType,
+					to.String(resource.Name),
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (d *DiagnosticSettings) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- diagnosticSettingsCompliantDesc
+	return nil
+}
+
+// hasDiagnosticSettingsDestination returns true when any of the given
+// diagnostic settings sends to the given destination. An empty destination
+// matches any diagnostic setting.
+func hasDiagnosticSettingsDestination(settings []insights.DiagnosticSettingsResource, destination string) bool {
+	for _, s := range settings {
+		if s.DiagnosticSettings == nil {
+			continue
+		}
+		if destination == "" {
+			return true
+		}
+
+		for _, id := range []*string{s.WorkspaceID, s.StorageAccountID, s.EventHubAuthorizationRuleID} {
+			if strings.EqualFold(to.String(id), destination) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
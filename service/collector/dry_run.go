@@ -0,0 +1,114 @@
+package collector
+
+import (
+	"context"
+	"sync"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/pkg/dryrun"
+)
+
+var (
+	dryRunCallsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "dry_run", "calls"),
+		"Number of calls of the Azure API operation the collector would have made since the start of the dry run.",
+		[]string{
+			"collector",
+			"service",
+			"operation",
+		},
+		nil,
+	)
+	dryRunCallsPerCollectionDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "dry_run", "calls_per_collection"),
+		"Estimated number of calls of the Azure API operation per collection of the collector. Calls for resources the collector would find are not included.",
+		[]string{
+			"collector",
+			"service",
+			"operation",
+		},
+		nil,
+	)
+)
+
+type DryRunConfig struct {
+	Logger micrologger.Logger
+}
+
+type DryRun struct {
+	logger micrologger.Logger
+
+	mutex sync.Mutex
+	// logged are the calls which have been logged already.
+	logged map[dryrun.Call]bool
+}
+
+// NewDryRun exposes the Azure API operations every collector would call in a
+// dry run along with the estimated number of calls per collection, so the
+// rate limit impact of collectors can be estimated before enabling them.
+// Every operation is logged once when it is first planned.
+func NewDryRun(config DryRunConfig) (*DryRun, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	d := &DryRun{
+		logger: config.Logger,
+
+		logged: map[dryrun.Call]bool{},
+	}
+
+	return d, nil
+}
+
+func (d *DryRun) Collect(ch chan<- prometheus.Metric) error {
+	if !dryrun.Enabled() {
+		return nil
+	}
+
+	plan := dryrun.Plan()
+
+	d.mutex.Lock()
+	for _, p := range plan {
+		if d.logged[p.Call] {
+			continue
+		}
+
+		d.logger.LogCtx(context.Background(), "level", "info", "message", "planned Azure API call", "collector", p.Collector, "service", p.Service, "operation", p.Operation)
+		d.logged[p.Call] = true
+	}
+	d.mutex.Unlock()
+
+	for _, p := range plan {
+		ch <- prometheus.MustNewConstMetric(
+			dryRunCallsDesc,
+			prometheus.GaugeValue,
+			float64(p.Calls),
+			p.Collector,
+			p.Service,
+			p.Operation,
+		)
+
+		if p.PerCollection > 0 {
+			ch <- prometheus.MustNewConstMetric(
+				dryRunCallsPerCollectionDesc,
+				prometheus.GaugeValue,
+				p.PerCollection,
+				p.Collector,
+				p.Service,
+				p.Operation,
+			)
+		}
+	}
+
+	return nil
+}
+
+func (d *DryRun) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- dryRunCallsDesc
+	ch <- dryRunCallsPerCollectionDesc
+	return nil
+}
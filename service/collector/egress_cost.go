@@ -0,0 +1,226 @@
+package collector
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/service/collector/key"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	egressMetricName   = "ByteCount"
+	egressMetricFilter = "Direction eq 'Out'"
+
+	// egressMetricInterval is the duration of monitorMetricInterval, which
+	// the byte counts are summed up over.
+	egressMetricInterval = 5 * time.Minute
+)
+
+// egressResourceTypes are the resource types which traffic leaves the
+// clusters through.
+var egressResourceTypes = []string{
+	loadBalancerType,
+	"Microsoft.Network/natGateways",
+}
+
+var (
+	egressBytesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "egress", "bytes_per_second"),
+		"Outbound traffic of the load balancer or NAT gateway of the cluster.",
+		[]string{
+			"cluster_id",
+			"resource_type",
+			"resource",
+		},
+		nil,
+	)
+)
+
+type EgressCostConfig struct {
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+
+	Location   string
+	GSTenantID string
+	// TagLabels are the tags of the cluster resource groups which are exposed
+	// as labels of the cost metric.
+	TagLabels []string
+}
+
+type EgressCost struct {
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+	retailPricer     *retailPricer
+
+	hourlyCostDesc *prometheus.Desc
+
+	location   string
+	gsTenantID string
+	tagLabels  []string
+}
+
+// NewEgressCost exposes the outbound traffic of the load balancers and NAT gateways of the clusters together with an
+// estimated hourly cost, based on the retail price of data transfer out of the installation location.
+func NewEgressCost(config EgressCostConfig) (*EgressCost, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.Location == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Location must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	tagLabelNames, err := costTagLabelNames(config.TagLabels)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	e := &EgressCost{
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		retailPricer:     newRetailPricer(config.CredentialSource.ClientFactory()),
+
+		hourlyCostDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricsNamespace, "egress", "estimated_hourly_cost"),
+			"Estimated hourly cost of the outbound traffic of the cluster based on its current rate and the retail price of data transfer out.",
+			append([]string{"cluster_id", "currency"}, tagLabelNames...),
+			nil,
+		),
+
+		location:   config.Location,
+		gsTenantID: config.GSTenantID,
+		tagLabels:  config.TagLabels,
+	}
+
+	return e, nil
+}
+
+func (e *EgressCost) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := e.collections.newCollectContext("egress_cost")
+	defer cancel()
+
+	clientSets, err := getClientSetsByCluster(ctx, e.credentialSource, e.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	price, err := e.retailPricer.EgressPricePerGB(ctx, e.location)
+	if err != nil {
+		// We still expose the traffic without a price.
+		e.logger.Errorf(ctx, err, "an error occurred fetching the retail price of data transfer out")
+	}
+
+	forEachCluster(ctx, ch, clientSets, func(ctx context.Context, ch chan<- prometheus.Metric, clusterID string, azureClientSet *client.AzureClientSet) error {
+		var bytesPerSecond float64
+		for _, resourceType := range egressResourceTypes {
+			resourceIDs, err := listEgressResourceIDs(ctx, azureClientSet, clusterID, resourceType)
+			if IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return microerror.Mask(err)
+			}
+
+			for _, resourceID := range resourceIDs {
+				values, err := getMonitorMetricValues(ctx, azureClientSet.MetricsClient, resourceID, []string{egressMetricName}, aggregationTotal, egressMetricFilter)
+				if err != nil {
+					e.logger.Errorf(ctx, err, "an error occurred fetching the outbound traffic of %#q", resourceID)
+					continue
+				}
+
+				rate := sumMonitorMetricValues(values) / egressMetricInterval.Seconds()
+				bytesPerSecond += rate
+
+				ch <- prometheus.MustNewConstMetric(
+					egressBytesDesc,
+					prometheus.GaugeValue,
+					rate,
+					clusterID,
+					resourceType,
+					key.ResourceNameFromID(resourceID),
+				)
+			}
+		}
+
+		if price.Currency == "" {
+			return nil
+		}
+
+		// The cost is still exposed with empty tag values when the tags
+		// can't be looked up.
+		tagValues, err := getResourceGroupTagLabelValues(ctx, azureClientSet.GroupsClient, clusterID, e.tagLabels)
+		if err != nil {
+			e.logger.Errorf(ctx, err, "an error occurred fetching the tags of cluster %#q", clusterID)
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			e.hourlyCostDesc,
+			prometheus.GaugeValue,
+			bytesPerSecond*time.Hour.Seconds()/bytesPerGiB*price.Amount,
+			append([]string{clusterID, price.Currency}, tagValues...)...,
+		)
+
+		return nil
+	})
+
+	return nil
+}
+
+func (e *EgressCost) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- egressBytesDesc
+	ch <- e.hourlyCostDesc
+	return nil
+}
+
+// listEgressResourceIDs returns the IDs of the resources of the given type in
+// the resource group of the given cluster, which is named after the cluster
+// ID. Load balancers are picked from the ones of the subscription, which are
+// shared with other collectors.
+func listEgressResourceIDs(ctx context.Context, azureClientSet *client.AzureClientSet, clusterID, resourceType string) ([]string, error) {
+	var ids []string
+
+	if resourceType == loadBalancerType {
+		loadBalancers, err := listLoadBalancers(ctx, azureClientSet.RESTClient)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		for _, lb := range loadBalancers {
+			if strings.EqualFold(key.ResourceGroupFromID(lb.ID), clusterID) {
+				ids = append(ids, lb.ID)
+			}
+		}
+
+		return ids, nil
+	}
+
+	resources, err := getResourcesByType(ctx, azureClientSet.ResourcesClient, clusterID, resourceType)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	for _, resource := range resources {
+		ids = append(ids, to.String(resource.ID))
+	}
+
+	return ids, nil
+}
@@ -16,6 +16,15 @@ func IsInvalidConfig(err error) bool {
 	return microerror.Cause(err) == invalidConfigError
 }
 
+var invalidDescriptorError = &microerror.Error{
+	Kind: "invalidDescriptorError",
+}
+
+// IsInvalidDescriptor asserts invalidDescriptorError.
+func IsInvalidDescriptor(err error) bool {
+	return microerror.Cause(err) == invalidDescriptorError
+}
+
 // IsThrottlingError asserts 429 response.
 func IsThrottlingError(err error) bool {
 	if err == nil {
@@ -36,6 +45,26 @@ func IsThrottlingError(err error) bool {
 	return false
 }
 
+// IsNotFound asserts 404 response.
+func IsNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	c := microerror.Cause(err)
+
+	{
+		dErr, ok := c.(autorest.DetailedError)
+		if ok {
+			if dErr.StatusCode == http.StatusNotFound {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 var tooManyCredentialsError = &microerror.Error{
 	Kind: "tooManyCredentialsError",
 }
@@ -62,3 +91,39 @@ var missingOrganizationLabel = &microerror.Error{
 func IsMissingOrganizationLabel(err error) bool {
 	return microerror.Cause(err) == missingOrganizationLabel
 }
+
+var invalidKeyVaultURLError = &microerror.Error{
+	Kind: "invalidKeyVaultURLError",
+}
+
+// IsInvalidKeyVaultURL asserts invalidKeyVaultURLError.
+func IsInvalidKeyVaultURL(err error) bool {
+	return microerror.Cause(err) == invalidKeyVaultURLError
+}
+
+var executionFailedError = &microerror.Error{
+	Kind: "executionFailedError",
+}
+
+// IsExecutionFailed asserts executionFailedError.
+func IsExecutionFailed(err error) bool {
+	return microerror.Cause(err) == executionFailedError
+}
+
+var priceNotFoundError = &microerror.Error{
+	Kind: "priceNotFoundError",
+}
+
+// IsPriceNotFound asserts priceNotFoundError.
+func IsPriceNotFound(err error) bool {
+	return microerror.Cause(err) == priceNotFoundError
+}
+
+var collectionSkippedError = &microerror.Error{
+	Kind: "collectionSkippedError",
+}
+
+// IsCollectionSkipped asserts collectionSkippedError.
+func IsCollectionSkipped(err error) bool {
+	return microerror.Cause(err) == collectionSkippedError
+}
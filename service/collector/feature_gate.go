@@ -0,0 +1,23 @@
+package collector
+
+import (
+	"github.com/giantswarm/exporterkit/collector"
+
+	"github.com/giantswarm/azure-collector/v2/pkg/featuregate"
+)
+
+// withFeatureGates returns the given collectors without the ones whose
+// feature is disabled. Collectors without a feature are always kept.
+func withFeatureGates(collectors []collector.Interface, features map[collector.Interface]string) []collector.Interface {
+	var enabled []collector.Interface
+	for _, c := range collectors {
+		feature, ok := features[c]
+		if ok && !featuregate.Enabled(feature) {
+			continue
+		}
+
+		enabled = append(enabled, c)
+	}
+
+	return enabled
+}
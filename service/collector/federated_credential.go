@@ -0,0 +1,270 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	managedIdentityAPIVersion  = "2023-01-31"
+	userAssignedIdentityType   = "Microsoft.ManagedIdentity/userAssignedIdentities"
+	openIDConfigurationPath    = "/.well-known/openid-configuration"
+	openIDConfigurationTimeout = 10 * time.Second
+)
+
+// federatedCredentialList holds the subset of the federated identity
+// credential properties we need.
+type federatedCredentialList struct {
+	NextLink string `json:"nextLink"`
+	Value    []struct {
+		Name       string `json:"name"`
+		Properties struct {
+			Audiences []string `json:"audiences"`
+			Issuer    string   `json:"issuer"`
+			Subject   string   `json:"subject"`
+		} `json:"properties"`
+	} `json:"value"`
+}
+
+// openIDConfiguration holds the subset of the OIDC discovery document we
+// need.
+type openIDConfiguration struct {
+	Issuer string `json:"issuer"`
+}
+
+var (
+	federatedCredentialInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "federated_credential", "info"),
+		"Configuration of the federated identity credentials of the user-assigned identities of the cluster.",
+		[]string{
+			"cluster_id",
+			"identity",
+			"credential",
+			"issuer",
+			"subject",
+			"audience",
+		},
+		nil,
+	)
+	federatedCredentialIssuerValidDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "federated_credential", "issuer_valid"),
+		"Whether the OIDC issuer of the federated identity credential serves a discovery document for the same issuer.",
+		[]string{
+			"cluster_id",
+			"identity",
+			"credential",
+			"issuer",
+		},
+		nil,
+	)
+)
+
+type FederatedCredentialConfig struct {
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+
+	GSTenantID string
+}
+
+type FederatedCredential struct {
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+
+	httpClient *http.Client
+
+	gsTenantID string
+}
+
+// NewFederatedCredential exposes the federated identity credentials of the user-assigned identities in the cluster
+// resource groups, which workload identity relies on. It verifies that every OIDC issuer still serves its discovery
+// document, because changing the issuer of a cluster silently breaks the federation.
+func NewFederatedCredential(config FederatedCredentialConfig) (*FederatedCredential, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	f := &FederatedCredential{
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+
+		httpClient: config.CredentialSource.ClientFactory().NewHTTPClient(openIDConfigurationTimeout),
+
+		gsTenantID: config.GSTenantID,
+	}
+
+	return f, nil
+}
+
+func (f *FederatedCredential) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := f.collections.newCollectContext("federated_credential")
+	defer cancel()
+
+	clientSets, err := getClientSetsByCluster(ctx, f.credentialSource, f.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	// Many credentials share the same issuer, so every issuer is only
+	// checked once per scrape. Clusters are collected concurrently, so the
+	// results are guarded by validIssuersMutex.
+	var validIssuersMutex sync.Mutex
+	validIssuers := map[string]bool{}
+
+	forEachCluster(ctx, ch, clientSets, func(ctx context.Context, ch chan<- prometheus.Metric, clusterID string, azureClientSet *client.AzureClientSet) error {
+		// The resource group of a cluster is named after the cluster ID.
+		identities, err := getResourcesByType(ctx, azureClientSet.ResourcesClient, clusterID, userAssignedIdentityType)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, identity := range identities {
+			credentials, err := getFederatedCredentials(ctx, azureClientSet.RESTClient, to.String(identity.ID))
+			if err != nil {
+				f.logger.Errorf(ctx, err, "an error occurred listing the federated credentials of identity %#q", to.String(identity.ID))
+				continue
+			}
+
+			for _, c := range credentials.Value {
+				issuer := c.Properties.Issuer
+
+				ch <- prometheus.MustNewConstMetric(
+					federatedCredentialInfoDesc,
+					prometheus.GaugeValue,
+					gaugeValue,
+					clusterID,
+					to.String(identity.Name),
+					c.Name,
+					issuer,
+					c.Properties.Subject,
+					strings.Join(c.Properties.Audiences, ","),
+				)
+
+				validIssuersMutex.Lock()
+				valid, ok := validIssuers[issuer]
+				validIssuersMutex.Unlock()
+				if !ok {
+					err = f.checkIssuer(ctx, issuer)
+					if err != nil {
+						f.logger.Debugf(ctx, "OIDC issuer %#q of federated credential %#q is not valid: %s", issuer, c.Name, err)
+					}
+					valid = err == nil
+
+					validIssuersMutex.Lock()
+					validIssuers[issuer] = valid
+					validIssuersMutex.Unlock()
+				}
+
+				var value float64
+				if valid {
+					value = 1
+				}
+
+				ch <- prometheus.MustNewConstMetric(
+					federatedCredentialIssuerValidDesc,
+					prometheus.GaugeValue,
+					value,
+					clusterID,
+					to.String(identity.Name),
+					c.Name,
+					issuer,
+				)
+			}
+		}
+
+		return nil
+	})
+
+	return nil
+}
+
+func (f *FederatedCredential) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- federatedCredentialInfoDesc
+	ch <- federatedCredentialIssuerValidDesc
+	return nil
+}
+
+// checkIssuer fetches the OIDC discovery document of the issuer and returns
+// an error unless it is served and announces exactly the same issuer, which
+// is what Azure AD verifies when exchanging tokens.
+func (f *FederatedCredential) checkIssuer(ctx context.Context, issuer string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(issuer, "/")+openIDConfigurationPath, nil)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return microerror.Maskf(executionFailedError, "expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var configuration openIDConfiguration
+	err = json.NewDecoder(resp.Body).Decode(&configuration)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if configuration.Issuer != issuer {
+		return microerror.Maskf(executionFailedError, "discovery document announces issuer %#q", configuration.Issuer)
+	}
+
+	return nil
+}
+
+// getFederatedCredentials lists the federated identity credentials of the
+// user-assigned identity with the given ID.
+func getFederatedCredentials(ctx context.Context, restClient *client.RESTClient, identityID string) (federatedCredentialList, error) {
+	var result federatedCredentialList
+
+	var credentials federatedCredentialList
+	err := restClient.GetJSON(ctx, identityID+"/federatedIdentityCredentials", managedIdentityAPIVersion, &credentials)
+	if err != nil {
+		return federatedCredentialList{}, microerror.Mask(err)
+	}
+
+	for {
+		result.Value = append(result.Value, credentials.Value...)
+
+		if credentials.NextLink == "" {
+			break
+		}
+
+		nextLink := credentials.NextLink
+		credentials = federatedCredentialList{}
+		err = restClient.GetNextJSON(ctx, nextLink, &credentials)
+		if err != nil {
+			return federatedCredentialList{}, microerror.Mask(err)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,169 @@
+package collector
+
+import (
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2019-06-01/storage"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	// File share quotas are configured in GiB.
+	bytesPerGiB = 1024 * 1024 * 1024
+)
+
+var (
+	fileShareQuotaDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "file_share", "quota_bytes"),
+		"Provisioned quota of the Azure Files share in bytes.",
+		[]string{
+			"resource_group",
+			"storage_account",
+			"share",
+		},
+		nil,
+	)
+	fileShareUsedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "file_share", "used_bytes"),
+		"Used capacity of the Azure Files share in bytes.",
+		[]string{
+			"resource_group",
+			"storage_account",
+			"share",
+		},
+		nil,
+	)
+)
+
+type FileShareConfig struct {
+	CredentialSource          *credential.Source
+	Collections               *Collections
+	Logger                    micrologger.Logger
+	ControlPlaneResourceGroup string
+	GSTenantID                string
+}
+
+type FileShare struct {
+	credentialSource          *credential.Source
+	collections               *Collections
+	logger                    micrologger.Logger
+	controlPlaneResourceGroup string
+	gsTenantID                string
+}
+
+// NewFileShare exposes the provisioned quota and the used capacity of the Azure Files shares in the managed resource
+// groups, e.g. the shares backing persistent volumes of the clusters.
+func NewFileShare(config FileShareConfig) (*FileShare, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.ControlPlaneResourceGroup == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ControlPlaneResourceGroup must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	f := &FileShare{
+		credentialSource:          config.CredentialSource,
+		collections:               config.Collections,
+		logger:                    config.Logger,
+		controlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+		gsTenantID:                config.GSTenantID,
+	}
+
+	return f, nil
+}
+
+func (f *FileShare) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := f.collections.newCollectContext("file_share")
+	defer cancel()
+
+	resourceGroups, err := getManagedResourceGroups(ctx, f.credentialSource, f.gsTenantID, f.controlPlaneResourceGroup)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, resourceGroup := range resourceGroups {
+		azureClientSet := resourceGroup.AzureClientSet
+
+		accounts, err := azureClientSet.StorageAccountsClient.ListByResourceGroup(ctx, resourceGroup.Name)
+		if IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+		if accounts.Value == nil {
+			continue
+		}
+
+		for _, account := range *accounts.Value {
+			accountName := to.String(account.Name)
+
+			// Blob storage accounts have no file service.
+			if account.Kind == storage.BlobStorage || account.Kind == storage.BlockBlobStorage {
+				continue
+			}
+
+			iterator, err := azureClientSet.FileSharesClient.ListComplete(ctx, resourceGroup.Name, accountName, "", "", "")
+			if err != nil {
+				f.logger.Errorf(ctx, err, "an error occurred listing the file shares of storage account %#q", accountName)
+				continue
+			}
+
+			for iterator.NotDone() {
+				shareName := to.String(iterator.Value().Name)
+
+				// The used capacity is only returned when fetching a single
+				// share with its statistics.
+				share, err := azureClientSet.FileSharesClient.Get(ctx, resourceGroup.Name, accountName, shareName, storage.Stats)
+				if err != nil {
+					f.logger.Errorf(ctx, err, "an error occurred fetching file share %#q of storage account %#q", shareName, accountName)
+				} else if share.FileShareProperties != nil {
+					if share.ShareQuota != nil {
+						ch <- prometheus.MustNewConstMetric(
+							fileShareQuotaDesc,
+							prometheus.GaugeValue,
+							float64(*share.ShareQuota)*bytesPerGiB,
+							resourceGroup.Name,
+							accountName,
+							shareName,
+						)
+					}
+					if share.ShareUsageBytes != nil {
+						ch <- prometheus.MustNewConstMetric(
+							fileShareUsedDesc,
+							prometheus.GaugeValue,
+							float64(*share.ShareUsageBytes),
+							resourceGroup.Name,
+							accountName,
+							shareName,
+						)
+					}
+				}
+
+				err = iterator.NextWithContext(ctx)
+				if err != nil {
+					return microerror.Mask(err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (f *FileShare) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- fileShareQuotaDesc
+	ch <- fileShareUsedDesc
+	return nil
+}
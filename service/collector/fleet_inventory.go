@@ -0,0 +1,192 @@
+package collector
+
+import (
+	"context"
+	"strings"
+
+	releasev1alpha1 "github.com/giantswarm/apiextensions/v3/pkg/apis/release/v1alpha1"
+	"github.com/giantswarm/apiextensions/v3/pkg/label"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/api/v1alpha3"
+	expcapiv1alpha3 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	kubernetesComponentName = "kubernetes"
+)
+
+var (
+	fleetClustersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "fleet", "clusters"),
+		"Number of workload clusters on this installation by region, release version, Kubernetes version and node count.",
+		[]string{
+			"region",
+			"release_version",
+			"kubernetes_version",
+			"node_count_bucket",
+		},
+		nil,
+	)
+)
+
+type FleetInventoryConfig struct {
+	CtrlClient  ctrlclient.Client
+	Collections *Collections
+	Logger      micrologger.Logger
+	Location    string
+}
+
+type FleetInventory struct {
+	ctrlClient  ctrlclient.Client
+	collections *Collections
+	logger      micrologger.Logger
+	location    string
+}
+
+type fleetInventoryKey struct {
+	releaseVersion    string
+	kubernetesVersion string
+	nodeCountBucket   string
+}
+
+// NewFleetInventory exposes the number of workload clusters of this installation broken down by release version,
+// Kubernetes version and size, giving a single source of metrics about the shape of the fleet.
+func NewFleetInventory(config FleetInventoryConfig) (*FleetInventory, error) {
+	if config.CtrlClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CtrlClient must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.Location == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Location must not be empty", config)
+	}
+
+	f := &FleetInventory{
+		ctrlClient:  config.CtrlClient,
+		collections: config.Collections,
+		logger:      config.Logger,
+		location:    config.Location,
+	}
+
+	return f, nil
+}
+
+func (f *FleetInventory) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := f.collections.newCollectContext("fleet_inventory")
+	defer cancel()
+
+	clusters := &v1alpha3.ClusterList{}
+	err := f.ctrlClient.List(ctx, clusters, ctrlclient.InNamespace(metav1.NamespaceAll))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	machinePools := &expcapiv1alpha3.MachinePoolList{}
+	err = f.ctrlClient.List(ctx, machinePools, ctrlclient.InNamespace(metav1.NamespaceAll))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	nodeCounts := map[string]int{}
+	for _, machinePool := range machinePools.Items {
+		clusterID := machinePool.Labels[v1alpha3.ClusterLabelName]
+		if clusterID == "" || machinePool.Spec.Replicas == nil {
+			continue
+		}
+
+		nodeCounts[clusterID] += int(*machinePool.Spec.Replicas)
+	}
+
+	// Many clusters share the same release, so we only fetch every Release
+	// CR once.
+	kubernetesVersions := map[string]string{}
+	counts := map[fleetInventoryKey]int{}
+	for _, cluster := range clusters.Items {
+		releaseVersion := cluster.Labels[label.ReleaseVersion]
+
+		kubernetesVersion, ok := kubernetesVersions[releaseVersion]
+		if !ok && releaseVersion != "" {
+			kubernetesVersion, err = f.getKubernetesVersion(ctx, releaseVersion)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
+			kubernetesVersions[releaseVersion] = kubernetesVersion
+		}
+
+		key := fleetInventoryKey{
+			releaseVersion:    releaseVersion,
+			kubernetesVersion: kubernetesVersion,
+			nodeCountBucket:   nodeCountBucket(nodeCounts[cluster.Name]),
+		}
+		counts[key]++
+	}
+
+	for key, count := range counts {
+		ch <- prometheus.MustNewConstMetric(
+			fleetClustersDesc,
+			prometheus.GaugeValue,
+			float64(count),
+			f.location,
+			key.releaseVersion,
+			key.kubernetesVersion,
+			key.nodeCountBucket,
+		)
+	}
+
+	return nil
+}
+
+func (f *FleetInventory) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- fleetClustersDesc
+	return nil
+}
+
+// getKubernetesVersion returns the Kubernetes version of the given release,
+// or an empty string when the Release CR does not exist.
+func (f *FleetInventory) getKubernetesVersion(ctx context.Context, releaseVersion string) (string, error) {
+	release := &releasev1alpha1.Release{}
+	err := f.ctrlClient.Get(ctx, ctrlclient.ObjectKey{Name: "v" + strings.TrimPrefix(releaseVersion, "v")}, release)
+	if apierrors.IsNotFound(err) {
+		f.logger.Debugf(ctx, "Release %#q not found", releaseVersion)
+		return "", nil
+	} else if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	for _, component := range release.Spec.Components {
+		if component.Name == kubernetesComponentName {
+			return component.Version, nil
+		}
+	}
+
+	return "", nil
+}
+
+// nodeCountBucket returns the bucket the given number of nodes falls into,
+// so clusters of a similar size are counted together.
+func nodeCountBucket(nodes int) string {
+	switch {
+	case nodes <= 0:
+		return "0"
+	case nodes <= 5:
+		return "1-5"
+	case nodes <= 20:
+		return "6-20"
+	case nodes <= 50:
+		return "21-50"
+	case nodes <= 100:
+		return "51-100"
+	default:
+		return ">100"
+	}
+}
@@ -0,0 +1,51 @@
+package collector
+
+import (
+	"strconv"
+	"testing"
+)
+
+func Test_nodeCountBucket(t *testing.T) {
+	testCases := []struct {
+		name           string
+		nodes          int
+		expectedBucket string
+	}{
+		{
+			name:           "case 0: no nodes",
+			nodes:          0,
+			expectedBucket: "0",
+		},
+		{
+			name:           "case 1: single node",
+			nodes:          1,
+			expectedBucket: "1-5",
+		},
+		{
+			name:           "case 2: upper bound of a bucket",
+			nodes:          20,
+			expectedBucket: "6-20",
+		},
+		{
+			name:           "case 3: lower bound of a bucket",
+			nodes:          51,
+			expectedBucket: "51-100",
+		},
+		{
+			name:           "case 4: very large cluster",
+			nodes:          250,
+			expectedBucket: ">100",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			bucket := nodeCountBucket(tc.nodes)
+			if bucket != tc.expectedBucket {
+				t.Fatalf("bucket == %#q, want %#q", bucket, tc.expectedBucket)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package collector
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-11-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/service/collector/key"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+var (
+	flowLogEnabledDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "flow_log", "enabled"),
+		"Whether flow logs are enabled for the network security group attached to cluster subnets.",
+		[]string{
+			"cluster_id",
+			"network_security_group",
+			"storage_account",
+		},
+		nil,
+	)
+	flowLogRetentionDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "flow_log", "retention_days"),
+		"Number of days flow logs are retained for the network security group attached to cluster subnets. Zero means logs are retained forever.",
+		[]string{
+			"cluster_id",
+			"network_security_group",
+		},
+		nil,
+	)
+)
+
+type FlowLogConfig struct {
+	CredentialSource *credential.Source
+	Location         string
+	Collections      *Collections
+	Logger           micrologger.Logger
+	GSTenantID       string
+}
+
+type FlowLog struct {
+	credentialSource *credential.Source
+	location         string
+	collections      *Collections
+	logger           micrologger.Logger
+	gsTenantID       string
+}
+
+// NewFlowLog exposes metrics about the Network Watcher flow logs configured for the network security groups
+// attached to the cluster subnets. It uses the Network Watcher of the installation location.
+func NewFlowLog(config FlowLogConfig) (*FlowLog, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Location == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Location must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	f := &FlowLog{
+		credentialSource: config.CredentialSource,
+		location:         config.Location,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		gsTenantID:       config.GSTenantID,
+	}
+
+	return f, nil
+}
+
+func (f *FlowLog) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := f.collections.newCollectContext("flow_log")
+	defer cancel()
+
+	azureClientSets, err := getClientSetsByCluster(ctx, f.credentialSource, f.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	// Flow logs are configured on the Network Watcher of the subscription, so
+	// we only list them once per subscription.
+	flowLogsBySubscription := newPerSubscription()
+
+	forEachCluster(ctx, ch, azureClientSets, func(ctx context.Context, ch chan<- prometheus.Metric, clusterID string, azureClientSet *client.AzureClientSet) error {
+		subscriptionID := azureClientSet.VirtualNetworksClient.SubscriptionID
+
+		v, err := flowLogsBySubscription.get(subscriptionID, func() (interface{}, error) {
+			return f.getFlowLogs(ctx, azureClientSet)
+		})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		flowLogs := v.(map[string]network.FlowLog)
+
+		securityGroupIDs, err := f.getSecurityGroupIDs(ctx, azureClientSet, clusterID)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, securityGroupID := range securityGroupIDs {
+			var enabled float64
+			var retentionDays int32
+			var storageAccount string
+			if flowLog, ok := flowLogs[strings.ToLower(securityGroupID)]; ok && flowLog.FlowLogPropertiesFormat != nil {
+				if to.Bool(flowLog.Enabled) {
+					enabled = 1
+				}
+				if flowLog.RetentionPolicy != nil && to.Bool(flowLog.RetentionPolicy.Enabled) {
+					retentionDays = to.Int32(flowLog.RetentionPolicy.Days)
+				}
+				storageAccount = key.ResourceNameFromID(to.String(flowLog.StorageID))
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				flowLogEnabledDesc,
+				prometheus.GaugeValue,
+				enabled,
+				clusterID,
+				key.ResourceNameFromID(securityGroupID),
+				storageAccount,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				flowLogRetentionDesc,
+				prometheus.GaugeValue,
+				float64(retentionDays),
+				clusterID,
+				key.ResourceNameFromID(securityGroupID),
+			)
+		}
+
+		return nil
+	})
+
+	return nil
+}
+
+func (f *FlowLog) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- flowLogEnabledDesc
+	ch <- flowLogRetentionDesc
+	return nil
+}
+
+// getFlowLogs returns the flow logs configured on the Network Watcher of the
+// installation location indexed by the lower cased target resource ID.
+func (f *FlowLog) getFlowLogs(ctx context.Context, azureClientSet *client.AzureClientSet) (map[string]network.FlowLog, error) {
+	flowLogs := map[string]network.FlowLog{}
+
+	watchers, err := getNetworkWatchers(ctx, azureClientSet.WatchersClient, f.location)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	for _, watcher := range watchers {
+		results, err := azureClientSet.FlowLogsClient.ListComplete(ctx, key.ResourceGroupFromID(to.String(watcher.ID)), to.String(watcher.Name))
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		for results.NotDone() {
+			flowLog := results.Value()
+			if flowLog.FlowLogPropertiesFormat != nil {
+				flowLogs[strings.ToLower(to.String(flowLog.TargetResourceID))] = flowLog
+			}
+
+			if err := results.NextWithContext(ctx); err != nil {
+				return nil, microerror.Mask(err)
+			}
+		}
+	}
+
+	return flowLogs, nil
+}
+
+// getSecurityGroupIDs returns the IDs of the network security groups attached
+// to the subnets of the virtual networks in the cluster resource group.
+func (f *FlowLog) getSecurityGroupIDs(ctx context.Context, azureClientSet *client.AzureClientSet, clusterID string) ([]string, error) {
+	var securityGroupIDs []string
+
+	vnets, err := azureClientSet.VirtualNetworksClient.ListComplete(ctx, clusterID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	for vnets.NotDone() {
+		vnet := vnets.Value()
+		if vnet.VirtualNetworkPropertiesFormat != nil && vnet.Subnets != nil {
+			for _, subnet := range *vnet.Subnets {
+				if subnet.SubnetPropertiesFormat == nil || subnet.NetworkSecurityGroup == nil {
+					continue
+				}
+
+				id := to.String(subnet.NetworkSecurityGroup.ID)
+				if !inArray(securityGroupIDs, id) {
+					securityGroupIDs = append(securityGroupIDs, id)
+				}
+			}
+		}
+
+		if err := vnets.NextWithContext(ctx); err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	return securityGroupIDs, nil
+}
@@ -0,0 +1,239 @@
+package collector
+
+import (
+	"context"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	logQueryAPIVersion = "2018-03-01-preview"
+
+	bytesPerMiB = 1024 * 1024
+
+	// guestDiskUsageQuery selects the latest free space and size of the OS
+	// disk of every node reported by the Azure Monitor agent with VM
+	// insights within the last 15 minutes. Nodes not reporting anymore drop
+	// out, so they can be alerted on.
+	guestDiskUsageQuery = `InsightsMetrics
+| where TimeGenerated > ago(15m)
+| where Origin == "vm.azm.ms" and Namespace == "LogicalDisk" and Name == "FreeSpaceMB"
+| extend Tags = todynamic(Tags)
+| extend MountID = tostring(Tags["vm.azm.ms/mountId"]), SizeMB = todouble(Tags["vm.azm.ms/diskSizeMB"])
+| where MountID in ("/", "C:")
+| summarize arg_max(TimeGenerated, Val, SizeMB) by Computer
+| project Computer, FreeMB = Val, SizeMB, TimeGenerated`
+)
+
+var (
+	guestDiskFreeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "guest_disk", "free_bytes"),
+		"Free space of the OS disk of the node as reported by the Azure Monitor agent.",
+		[]string{
+			"cluster_id",
+			"node",
+		},
+		nil,
+	)
+	guestDiskSizeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "guest_disk", "size_bytes"),
+		"Size of the OS disk of the node as reported by the Azure Monitor agent.",
+		[]string{
+			"cluster_id",
+			"node",
+		},
+		nil,
+	)
+	guestDiskReportedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "guest_disk", "reported_timestamp_seconds"),
+		"Time the disk usage of the node was last reported by the Azure Monitor agent.",
+		[]string{
+			"cluster_id",
+			"node",
+		},
+		nil,
+	)
+)
+
+// logQuery is the request body of a Log Analytics query.
+type logQuery struct {
+	Query string `json:"query"`
+}
+
+// logQueryResult is the response of a Log Analytics query. Rows hold one
+// value per column.
+type logQueryResult struct {
+	Tables []struct {
+		Columns []struct {
+			Name string `json:"name"`
+		} `json:"columns"`
+		Rows [][]interface{} `json:"rows"`
+	} `json:"tables"`
+}
+
+// guestDisk is the OS disk usage of a single node.
+type guestDisk struct {
+	node      string
+	freeBytes float64
+	sizeBytes float64
+	reported  time.Time
+}
+
+type GuestDiskUsageConfig struct {
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+	GSTenantID       string
+}
+
+type GuestDiskUsage struct {
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+	gsTenantID       string
+}
+
+// NewGuestDiskUsage exposes the OS disk usage of the nodes of clusters running
+// the Azure Monitor agent with VM insights, as queried from the InsightsMetrics
+// of the Log Analytics workspace the nodes report to. It is a fallback for when
+// the in-cluster node exporters are down. Clusters without the agent expose no
+// metrics. The service principal has to be allowed to read the logs of the
+// resource group of the cluster, e.g. as Log Analytics Reader.
+func NewGuestDiskUsage(config GuestDiskUsageConfig) (*GuestDiskUsage, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	g := &GuestDiskUsage{
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		gsTenantID:       config.GSTenantID,
+	}
+
+	return g, nil
+}
+
+func (g *GuestDiskUsage) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := g.collections.newCollectContext("guest_disk_usage")
+	defer cancel()
+
+	azureClientSets, err := getClientSetsByCluster(ctx, g.credentialSource, g.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	forEachCluster(ctx, ch, azureClientSets, func(ctx context.Context, ch chan<- prometheus.Metric, clusterID string, azureClientSet *client.AzureClientSet) error {
+		restClient := azureClientSet.RESTClient
+
+		// Queries scoped to the resource group of the cluster, which is
+		// named after the cluster ID, read the logs of its VMs from
+		// whichever workspace they report to.
+		path := "/subscriptions/" + restClient.SubscriptionID + "/resourceGroups/" + clusterID + "/providers/Microsoft.Insights/logs"
+
+		var result logQueryResult
+		err := restClient.PostJSON(ctx, path, logQueryAPIVersion, logQuery{Query: guestDiskUsageQuery}, &result)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, disk := range parseGuestDisks(result) {
+			ch <- prometheus.MustNewConstMetric(
+				guestDiskFreeDesc,
+				prometheus.GaugeValue,
+				disk.freeBytes,
+				clusterID,
+				disk.node,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				guestDiskSizeDesc,
+				prometheus.GaugeValue,
+				disk.sizeBytes,
+				clusterID,
+				disk.node,
+			)
+			if !disk.reported.IsZero() {
+				ch <- prometheus.MustNewConstMetric(
+					guestDiskReportedDesc,
+					prometheus.GaugeValue,
+					float64(disk.reported.Unix()),
+					clusterID,
+					disk.node,
+				)
+			}
+		}
+
+		return nil
+	})
+
+	return nil
+}
+
+func (g *GuestDiskUsage) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- guestDiskFreeDesc
+	ch <- guestDiskSizeDesc
+	ch <- guestDiskReportedDesc
+	return nil
+}
+
+// parseGuestDisks returns the disks found in the result of
+// guestDiskUsageQuery. Rows without a node are skipped.
+func parseGuestDisks(result logQueryResult) []guestDisk {
+	var disks []guestDisk
+	for _, table := range result.Tables {
+		columns := map[string]int{}
+		for i, column := range table.Columns {
+			columns[column.Name] = i
+		}
+
+		value := func(row []interface{}, column string) interface{} {
+			i, ok := columns[column]
+			if !ok || i >= len(row) {
+				return nil
+			}
+			return row[i]
+		}
+
+		for _, row := range table.Rows {
+			node, _ := value(row, "Computer").(string)
+			if node == "" {
+				continue
+			}
+
+			freeMB, _ := value(row, "FreeMB").(float64)
+			sizeMB, _ := value(row, "SizeMB").(float64)
+
+			disk := guestDisk{
+				node:      node,
+				freeBytes: freeMB * bytesPerMiB,
+				sizeBytes: sizeMB * bytesPerMiB,
+			}
+
+			if s, ok := value(row, "TimeGenerated").(string); ok {
+				reported, err := time.Parse(time.RFC3339Nano, s)
+				if err == nil {
+					disk.reported = reported
+				}
+			}
+
+			disks = append(disks, disk)
+		}
+	}
+
+	return disks
+}
@@ -0,0 +1,76 @@
+package collector
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parseGuestDisks(t *testing.T) {
+	testCases := []struct {
+		name          string
+		response      string
+		expectedDisks []guestDisk
+	}{
+		{
+			name:          "case 0: cluster without the Azure Monitor agent",
+			response:      `{"tables": [{"name": "PrimaryResult", "columns": [{"name": "Computer"}, {"name": "FreeMB"}, {"name": "SizeMB"}, {"name": "TimeGenerated"}], "rows": []}]}`,
+			expectedDisks: nil,
+		},
+		{
+			name: "case 1: nodes reporting their disk usage",
+			response: `{"tables": [{"name": "PrimaryResult", "columns": [{"name": "Computer"}, {"name": "FreeMB"}, {"name": "SizeMB"}, {"name": "TimeGenerated"}], "rows": [
+				["nodepool-abc12-000000", 1024, 4096, "2021-05-04T10:11:12.5Z"],
+				["nodepool-abc12-000001", 512, 4096, null]
+			]}]}`,
+			expectedDisks: []guestDisk{
+				{
+					node:      "nodepool-abc12-000000",
+					freeBytes: 1024 * bytesPerMiB,
+					sizeBytes: 4096 * bytesPerMiB,
+					reported:  time.Date(2021, 5, 4, 10, 11, 12, 500000000, time.UTC),
+				},
+				{
+					node:      "nodepool-abc12-000001",
+					freeBytes: 512 * bytesPerMiB,
+					sizeBytes: 4096 * bytesPerMiB,
+				},
+			},
+		},
+		{
+			name: "case 2: columns in any order and rows without node",
+			response: `{"tables": [{"name": "PrimaryResult", "columns": [{"name": "SizeMB"}, {"name": "Computer"}, {"name": "FreeMB"}], "rows": [
+				[2048, "nodepool-abc12-000000", 256],
+				[2048, "", 128]
+			]}]}`,
+			expectedDisks: []guestDisk{
+				{
+					node:      "nodepool-abc12-000000",
+					freeBytes: 256 * bytesPerMiB,
+					sizeBytes: 2048 * bytesPerMiB,
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			var result logQueryResult
+			err := json.Unmarshal([]byte(tc.response), &result)
+			if err != nil {
+				t.Fatalf("expected no error, got %#v", err)
+			}
+
+			disks := parseGuestDisks(result)
+
+			if !cmp.Equal(disks, tc.expectedDisks, cmp.AllowUnexported(guestDisk{})) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedDisks, disks, cmp.AllowUnexported(guestDisk{})))
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package collector
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2019-05-01/resources"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+)
+
+const (
+	// inventoryTTL is how long listed resources are reused. All collectors
+	// run concurrently on every scrape, so resources listed by one of them
+	// are reused by the others within the same scrape.
+	inventoryTTL = time.Minute
+)
+
+var (
+	// inventory caches the resources which are listed by several
+	// collectors, e.g. scale sets and resource groups.
+	inventory = newInventoryCache(inventoryTTL)
+)
+
+// inventoryCache caches the results of list requests by key. Concurrent
+// requests for the same key wait for the first one instead of sending the
+// same list request again. Errors are not cached.
+type inventoryCache struct {
+	mutex   sync.Mutex
+	entries map[string]*inventoryEntry
+	ttl     time.Duration
+}
+
+type inventoryEntry struct {
+	done    chan struct{}
+	expires time.Time
+	value   interface{}
+	err     error
+}
+
+func newInventoryCache(ttl time.Duration) *inventoryCache {
+	return &inventoryCache{
+		entries: map[string]*inventoryEntry{},
+		ttl:     ttl,
+	}
+}
+
+// get returns the cached value of the given key, or the one returned by list
+// when there is none or it expired. Cached values are shared between
+// collectors, so they must not be modified.
+func (c *inventoryCache) get(ctx context.Context, key string, list func() (interface{}, error)) (interface{}, error) {
+	c.mutex.Lock()
+
+	now := time.Now()
+	for k, e := range c.entries {
+		if e.isDone() && !now.Before(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+
+	e, ok := c.entries[key]
+	if ok {
+		c.mutex.Unlock()
+
+		select {
+		case <-e.done:
+			return e.value, e.err
+		case <-ctx.Done():
+			return nil, microerror.Mask(ctx.Err())
+		}
+	}
+
+	e = &inventoryEntry{
+		done: make(chan struct{}),
+	}
+	c.entries[key] = e
+	c.mutex.Unlock()
+
+	e.value, e.err = list()
+	e.expires = time.Now().Add(c.ttl)
+	if e.err != nil {
+		c.mutex.Lock()
+		if c.entries[key] == e {
+			delete(c.entries, key)
+		}
+		c.mutex.Unlock()
+	}
+	close(e.done)
+
+	return e.value, e.err
+}
+
+func (e *inventoryEntry) isDone() bool {
+	select {
+	case <-e.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// listScaleSets returns the scale sets of the given resource group.
+func listScaleSets(ctx context.Context, scaleSetsClient *compute.VirtualMachineScaleSetsClient, resourceGroup string) ([]compute.VirtualMachineScaleSet, error) {
+	key := strings.ToLower("scalesets/" + scaleSetsClient.SubscriptionID + "/" + resourceGroup)
+
+	v, err := inventory.get(ctx, key, func() (interface{}, error) {
+		var result []compute.VirtualMachineScaleSet
+
+		iterator, err := scaleSetsClient.ListComplete(ctx, resourceGroup)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		for iterator.NotDone() {
+			result = append(result, iterator.Value())
+
+			err = iterator.NextWithContext(ctx)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+		}
+
+		return result, nil
+	})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return v.([]compute.VirtualMachineScaleSet), nil
+}
+
+// listAllResourceGroups returns all resource groups of the subscription,
+// including the ones not matching the resource group filter.
+func listAllResourceGroups(ctx context.Context, groupsClient *resources.GroupsClient) ([]resources.Group, error) {
+	key := strings.ToLower("resourcegroups/" + groupsClient.SubscriptionID)
+
+	v, err := inventory.get(ctx, key, func() (interface{}, error) {
+		var result []resources.Group
+
+		iterator, err := groupsClient.ListComplete(ctx, "", nil)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		for iterator.NotDone() {
+			result = append(result, iterator.Value())
+
+			err = iterator.NextWithContext(ctx)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+		}
+
+		return result, nil
+	})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return v.([]resources.Group), nil
+}
+
+// listLoadBalancers returns the load balancers of the subscription of the
+// given client.
+func listLoadBalancers(ctx context.Context, restClient *client.RESTClient) ([]networkArtifact, error) {
+	key := strings.ToLower("loadbalancers/" + restClient.SubscriptionID)
+
+	v, err := inventory.get(ctx, key, func() (interface{}, error) {
+		result, err := listNetworkArtifacts(ctx, restClient, restClient.SubscriptionID, loadBalancerType)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		return result, nil
+	})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return v.([]networkArtifact), nil
+}
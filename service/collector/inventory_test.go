@@ -0,0 +1,63 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_inventoryCache(t *testing.T) {
+	testCases := []struct {
+		name          string
+		ttl           time.Duration
+		results       []error
+		expectedCalls int
+	}{
+		{
+			name:          "case 0: cached value is reused",
+			ttl:           time.Minute,
+			results:       []error{nil, nil, nil},
+			expectedCalls: 1,
+		},
+		{
+			name:          "case 1: expired value is listed again",
+			ttl:           0,
+			results:       []error{nil, nil},
+			expectedCalls: 2,
+		},
+		{
+			name:          "case 2: errors are not cached",
+			ttl:           time.Minute,
+			results:       []error{errors.New("throttled"), nil, nil},
+			expectedCalls: 2,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			c := newInventoryCache(tc.ttl)
+
+			var calls int
+			for _, result := range tc.results {
+				expected := result
+				_, err := c.get(context.Background(), "key", func() (interface{}, error) {
+					calls++
+					return calls, expected
+				})
+				if err != nil && expected == nil {
+					t.Fatalf("error == %#v, want nil", err)
+				}
+			}
+
+			if !cmp.Equal(calls, tc.expectedCalls) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedCalls, calls))
+			}
+		})
+	}
+}
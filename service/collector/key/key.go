@@ -1,6 +1,8 @@
 package key
 
 import (
+	"strings"
+
 	providerv1alpha1 "github.com/giantswarm/apiextensions/v2/pkg/apis/provider/v1alpha1"
 )
 
@@ -13,3 +15,50 @@ func CredentialName(customObject providerv1alpha1.AzureConfig) string {
 func CredentialNamespace(customObject providerv1alpha1.AzureConfig) string {
 	return customObject.Spec.Azure.CredentialSecret.Namespace
 }
+
+// ResourceGroupFromID returns the resource group name found in the given
+// Azure resource ID or an empty string if the ID does not contain one.
+func ResourceGroupFromID(id string) string {
+	return segmentFromID(id, "resourceGroups")
+}
+
+// ResourceNameFromID returns the last segment of the given Azure resource ID,
+// which is the name of the resource.
+func ResourceNameFromID(id string) string {
+	parts := strings.Split(strings.TrimSuffix(id, "/"), "/")
+	return parts[len(parts)-1]
+}
+
+// ResourceTypeFromID returns the top level resource type found in the given
+// Azure resource ID, e.g. "Microsoft.Network/loadBalancers", or an empty string
+// if the ID does not contain one.
+func ResourceTypeFromID(id string) string {
+	parts := strings.Split(id, "/")
+	for i := 0; i < len(parts)-2; i++ {
+		if strings.EqualFold(parts[i], "providers") {
+			return parts[i+1] + "/" + parts[i+2]
+		}
+	}
+
+	return ""
+}
+
+// SubscriptionFromID returns the subscription ID found in the given Azure
+// resource ID or an empty string if the ID does not contain one.
+func SubscriptionFromID(id string) string {
+	return segmentFromID(id, "subscriptions")
+}
+
+// segmentFromID returns the value following the given segment name in an
+// Azure resource ID. Segment names are matched case insensitively, since the
+// Azure APIs are not consistent about their casing.
+func segmentFromID(id, name string) string {
+	parts := strings.Split(id, "/")
+	for i := 0; i < len(parts)-1; i++ {
+		if strings.EqualFold(parts[i], name) {
+			return parts[i+1]
+		}
+	}
+
+	return ""
+}
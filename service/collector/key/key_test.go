@@ -0,0 +1,82 @@
+package key
+
+import (
+	"strconv"
+	"testing"
+)
+
+func Test_ResourceGroupFromID(t *testing.T) {
+	testCases := []struct {
+		name                  string
+		id                    string
+		expectedResourceGroup string
+		expectedName          string
+		expectedSubscription  string
+		expectedType          string
+	}{
+		{
+			name:                  "case 0: virtual network ID",
+			id:                    "/subscriptions/1234/resourceGroups/c4f3e/providers/Microsoft.Network/virtualNetworks/c4f3e-VirtualNetwork",
+			expectedResourceGroup: "c4f3e",
+			expectedName:          "c4f3e-VirtualNetwork",
+			expectedSubscription:  "1234",
+			expectedType:          "Microsoft.Network/virtualNetworks",
+		},
+		{
+			name:                  "case 1: lower case resource group segment",
+			id:                    "/subscriptions/1234/resourcegroups/NetworkWatcherRG/providers/Microsoft.Network/networkWatchers/NetworkWatcher_westeurope",
+			expectedResourceGroup: "NetworkWatcherRG",
+			expectedName:          "NetworkWatcher_westeurope",
+			expectedSubscription:  "1234",
+			expectedType:          "Microsoft.Network/networkWatchers",
+		},
+		{
+			name:                  "case 2: subscription ID only",
+			id:                    "/subscriptions/1234",
+			expectedResourceGroup: "",
+			expectedName:          "1234",
+			expectedSubscription:  "1234",
+		},
+		{
+			name:                  "case 3: empty ID",
+			id:                    "",
+			expectedResourceGroup: "",
+			expectedName:          "",
+			expectedSubscription:  "",
+		},
+		{
+			name:                  "case 4: nested resource ID",
+			id:                    "/subscriptions/1234/resourceGroups/c4f3e/providers/Microsoft.Compute/virtualMachineScaleSets/c4f3e-worker/providers/Microsoft.ResourceHealth/availabilityStatuses/current",
+			expectedResourceGroup: "c4f3e",
+			expectedName:          "current",
+			expectedSubscription:  "1234",
+			expectedType:          "Microsoft.Compute/virtualMachineScaleSets",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			resourceGroup := ResourceGroupFromID(tc.id)
+			if resourceGroup != tc.expectedResourceGroup {
+				t.Fatalf("expected resource group %#q, got %#q", tc.expectedResourceGroup, resourceGroup)
+			}
+
+			name := ResourceNameFromID(tc.id)
+			if name != tc.expectedName {
+				t.Fatalf("expected name %#q, got %#q", tc.expectedName, name)
+			}
+
+			subscription := SubscriptionFromID(tc.id)
+			if subscription != tc.expectedSubscription {
+				t.Fatalf("expected subscription %#q, got %#q", tc.expectedSubscription, subscription)
+			}
+
+			resourceType := ResourceTypeFromID(tc.id)
+			if resourceType != tc.expectedType {
+				t.Fatalf("expected type %#q, got %#q", tc.expectedType, resourceType)
+			}
+		})
+	}
+}
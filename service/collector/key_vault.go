@@ -0,0 +1,124 @@
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/keyvault/v7.0/keyvault"
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2019-05-01/resources"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	keyVaultResourceType = "Microsoft.KeyVault/vaults"
+
+	// keyVaultRotationPolicyAPIVersion is the first key vault data plane API
+	// version supporting key rotation policies, which the SDK version we use
+	// does not know about.
+	keyVaultRotationPolicyAPIVersion = "7.3"
+	keyRotationActionRotate          = "rotate"
+)
+
+// keyRotationPolicy holds the subset of a key rotation policy we need.
+type keyRotationPolicy struct {
+	LifetimeActions []struct {
+		Action struct {
+			Type string `json:"type"`
+		} `json:"action"`
+	} `json:"lifetimeActions"`
+}
+
+// RotatesAutomatically returns true when the policy rotates the key, as
+// opposed to only notifying before it expires.
+func (p keyRotationPolicy) RotatesAutomatically() bool {
+	for _, a := range p.LifetimeActions {
+		if strings.EqualFold(a.Action.Type, keyRotationActionRotate) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getKeyVaults returns the key vaults found in the given resource group.
+func getKeyVaults(ctx context.Context, resourcesClient *resources.Client, resourceGroup string) ([]resources.GenericResourceExpanded, error) {
+	vaults, err := getResourcesByType(ctx, resourcesClient, resourceGroup, keyVaultResourceType)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return vaults, nil
+}
+
+// getKeyRotationPolicy fetches the rotation policy of the given key.
+func getKeyRotationPolicy(ctx context.Context, keyVaultClient *keyvault.BaseClient, vaultBaseURL, keyName string) (keyRotationPolicy, error) {
+	preparer := autorest.CreatePreparer(
+		autorest.AsGet(),
+		autorest.WithBaseURL(vaultBaseURL),
+		autorest.WithPathParameters("/keys/{key-name}/rotationpolicy", map[string]interface{}{
+			"key-name": autorest.Encode("path", keyName),
+		}),
+		autorest.WithQueryParameters(map[string]interface{}{
+			"api-version": keyVaultRotationPolicyAPIVersion,
+		}),
+	)
+
+	req, err := preparer.Prepare((&http.Request{}).WithContext(ctx))
+	if err != nil {
+		return keyRotationPolicy{}, microerror.Mask(err)
+	}
+
+	resp, err := keyVaultClient.Send(req, autorest.DoRetryForStatusCodes(keyVaultClient.RetryAttempts, keyVaultClient.RetryDuration, autorest.StatusCodesForRetry...))
+	if err != nil {
+		return keyRotationPolicy{}, microerror.Mask(autorest.NewErrorWithError(err, "collector", "getKeyRotationPolicy", resp, "Failure sending request"))
+	}
+
+	var policy keyRotationPolicy
+	err = autorest.Respond(
+		resp,
+		azure.WithErrorUnlessStatusCode(http.StatusOK),
+		autorest.ByUnmarshallingJSON(&policy),
+		autorest.ByClosing(),
+	)
+	if err != nil {
+		return keyRotationPolicy{}, microerror.Mask(autorest.NewErrorWithError(err, "collector", "getKeyRotationPolicy", resp, "Failure responding to request"))
+	}
+
+	return policy, nil
+}
+
+// keyVaultBaseURL returns the data plane URL of the key vault with the given
+// name in the cloud the clients of the given source are configured for.
+func keyVaultBaseURL(source *credential.Source, name string) string {
+	return source.ClientFactory().KeyVaultBaseURL(name)
+}
+
+// parseKeyVaultObjectURL splits URLs like
+// "https://myvault.vault.azure.net/keys/mykey/0123" into the vault name, the
+// object name and the version, which is empty for unversioned URLs.
+func parseKeyVaultObjectURL(objectURL string) (vaultName, objectName, version string, err error) {
+	u, err := url.Parse(objectURL)
+	if err != nil {
+		return "", "", "", microerror.Mask(err)
+	}
+
+	host := strings.SplitN(u.Host, ".", 2)
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if host[0] == "" || len(parts) < 2 || len(parts) > 3 || parts[1] == "" {
+		return "", "", "", microerror.Maskf(invalidKeyVaultURLError, "%#q", objectURL)
+	}
+
+	vaultName = host[0]
+	objectName = parts[1]
+	if len(parts) == 3 {
+		version = parts[2]
+	}
+
+	return vaultName, objectName, version, nil
+}
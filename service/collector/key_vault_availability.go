@@ -0,0 +1,169 @@
+package collector
+
+import (
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	keyVaultAvailabilityMetric = "Availability"
+	keyVaultAPIHitMetric       = "ServiceApiHit"
+	keyVaultAPIResultMetric    = "ServiceApiResult"
+	keyVaultStatusCodeFilter   = "StatusCode eq '429'"
+)
+
+var (
+	keyVaultAvailabilityDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "key_vault", "availability_percent"),
+		"Availability of the key vault as reported by Azure Monitor.",
+		[]string{
+			"resource_group",
+			"key_vault",
+		},
+		nil,
+	)
+	keyVaultRequestsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "key_vault", "requests"),
+		"Number of requests to the key vault during the last five minutes.",
+		[]string{
+			"resource_group",
+			"key_vault",
+		},
+		nil,
+	)
+	keyVaultThrottledRequestsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "key_vault", "throttled_requests"),
+		"Number of requests to the key vault which were throttled during the last five minutes.",
+		[]string{
+			"resource_group",
+			"key_vault",
+		},
+		nil,
+	)
+)
+
+type KeyVaultAvailabilityConfig struct {
+	CredentialSource          *credential.Source
+	Collections               *Collections
+	Logger                    micrologger.Logger
+	ControlPlaneResourceGroup string
+	GSTenantID                string
+}
+
+type KeyVaultAvailability struct {
+	credentialSource          *credential.Source
+	collections               *Collections
+	logger                    micrologger.Logger
+	controlPlaneResourceGroup string
+	gsTenantID                string
+}
+
+// NewKeyVaultAvailability exposes the availability, request count and throttled request count of the key vaults in
+// the managed resource groups. Throttled key vaults show up as node bootstrap failures which are hard to track down.
+func NewKeyVaultAvailability(config KeyVaultAvailabilityConfig) (*KeyVaultAvailability, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.ControlPlaneResourceGroup == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ControlPlaneResourceGroup must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	k := &KeyVaultAvailability{
+		credentialSource:          config.CredentialSource,
+		collections:               config.Collections,
+		logger:                    config.Logger,
+		controlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+		gsTenantID:                config.GSTenantID,
+	}
+
+	return k, nil
+}
+
+func (k *KeyVaultAvailability) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := k.collections.newCollectContext("key_vault_availability")
+	defer cancel()
+
+	resourceGroups, err := getManagedResourceGroups(ctx, k.credentialSource, k.gsTenantID, k.controlPlaneResourceGroup)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, resourceGroup := range resourceGroups {
+		vaults, err := getKeyVaults(ctx, resourceGroup.AzureClientSet.ResourcesClient, resourceGroup.Name)
+		if IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, vault := range vaults {
+			vaultID := to.String(vault.ID)
+			vaultName := to.String(vault.Name)
+			metricsClient := resourceGroup.AzureClientSet.MetricsClient
+
+			values, err := getMonitorMetricValues(ctx, metricsClient, vaultID, []string{keyVaultAvailabilityMetric}, aggregationAverage, "")
+			if err != nil {
+				k.logger.Errorf(ctx, err, "an error occurred fetching the availability of key vault %#q", vaultName)
+			}
+			for _, v := range values {
+				ch <- prometheus.MustNewConstMetric(
+					keyVaultAvailabilityDesc,
+					prometheus.GaugeValue,
+					v.Value,
+					resourceGroup.Name,
+					vaultName,
+				)
+			}
+
+			values, err = getMonitorMetricValues(ctx, metricsClient, vaultID, []string{keyVaultAPIHitMetric}, aggregationTotal, "")
+			if err != nil {
+				k.logger.Errorf(ctx, err, "an error occurred fetching the requests of key vault %#q", vaultName)
+			} else {
+				ch <- prometheus.MustNewConstMetric(
+					keyVaultRequestsDesc,
+					prometheus.GaugeValue,
+					sumMonitorMetricValues(values),
+					resourceGroup.Name,
+					vaultName,
+				)
+			}
+
+			values, err = getMonitorMetricValues(ctx, metricsClient, vaultID, []string{keyVaultAPIResultMetric}, aggregationTotal, keyVaultStatusCodeFilter)
+			if err != nil {
+				k.logger.Errorf(ctx, err, "an error occurred fetching the throttled requests of key vault %#q", vaultName)
+			} else {
+				// Azure Monitor returns no time series at all when there
+				// were no throttled requests.
+				ch <- prometheus.MustNewConstMetric(
+					keyVaultThrottledRequestsDesc,
+					prometheus.GaugeValue,
+					sumMonitorMetricValues(values),
+					resourceGroup.Name,
+					vaultName,
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (k *KeyVaultAvailability) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- keyVaultAvailabilityDesc
+	ch <- keyVaultRequestsDesc
+	ch <- keyVaultThrottledRequestsDesc
+	return nil
+}
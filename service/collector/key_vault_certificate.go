@@ -0,0 +1,156 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/service/collector/key"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+var (
+	keyVaultCertificateNotAfterDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "key_vault_certificate", "not_after_timestamp_seconds"),
+		"Unix timestamp after which the certificate stored in the key vault is no longer valid.",
+		[]string{
+			"resource_group",
+			"key_vault",
+			"certificate",
+		},
+		nil,
+	)
+	keyVaultCertificateExpiryDaysDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "key_vault_certificate", "days_until_expiry"),
+		"Number of days until the certificate stored in the key vault expires. Negative values mean it already expired.",
+		[]string{
+			"resource_group",
+			"key_vault",
+			"certificate",
+		},
+		nil,
+	)
+)
+
+type KeyVaultCertificateConfig struct {
+	CredentialSource          *credential.Source
+	Collections               *Collections
+	Logger                    micrologger.Logger
+	ControlPlaneResourceGroup string
+	GSTenantID                string
+}
+
+type KeyVaultCertificate struct {
+	credentialSource          *credential.Source
+	collections               *Collections
+	logger                    micrologger.Logger
+	controlPlaneResourceGroup string
+	gsTenantID                string
+}
+
+// NewKeyVaultCertificate exposes the expiration of the certificates stored in the key vaults of the managed resource
+// groups, e.g. API server and ingress certificates, so they can be renewed before they expire.
+func NewKeyVaultCertificate(config KeyVaultCertificateConfig) (*KeyVaultCertificate, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.ControlPlaneResourceGroup == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ControlPlaneResourceGroup must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	k := &KeyVaultCertificate{
+		credentialSource:          config.CredentialSource,
+		collections:               config.Collections,
+		logger:                    config.Logger,
+		controlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+		gsTenantID:                config.GSTenantID,
+	}
+
+	return k, nil
+}
+
+func (k *KeyVaultCertificate) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := k.collections.newCollectContext("key_vault_certificate")
+	defer cancel()
+
+	resourceGroups, err := getManagedResourceGroups(ctx, k.credentialSource, k.gsTenantID, k.controlPlaneResourceGroup)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	now := time.Now()
+	for _, resourceGroup := range resourceGroups {
+		azureClientSet := resourceGroup.AzureClientSet
+
+		vaults, err := getKeyVaults(ctx, azureClientSet.ResourcesClient, resourceGroup.Name)
+		if IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, vault := range vaults {
+			vaultName := to.String(vault.Name)
+
+			certificates, err := azureClientSet.KeyVaultClient.GetCertificatesComplete(ctx, keyVaultBaseURL(k.credentialSource, vaultName), nil, nil)
+			if err != nil {
+				// Missing data plane permissions on a single vault should not
+				// prevent us from collecting the other ones.
+				k.logger.Errorf(ctx, err, "an error occurred listing the certificates of key vault %#q", vaultName)
+				continue
+			}
+
+			for certificates.NotDone() {
+				certificate := certificates.Value()
+
+				if certificate.Attributes != nil && certificate.Attributes.Expires != nil {
+					notAfter := time.Time(*certificate.Attributes.Expires)
+					certificateName := key.ResourceNameFromID(to.String(certificate.ID))
+
+					ch <- prometheus.MustNewConstMetric(
+						keyVaultCertificateNotAfterDesc,
+						prometheus.GaugeValue,
+						float64(notAfter.Unix()),
+						resourceGroup.Name,
+						vaultName,
+						certificateName,
+					)
+					ch <- prometheus.MustNewConstMetric(
+						keyVaultCertificateExpiryDaysDesc,
+						prometheus.GaugeValue,
+						notAfter.Sub(now).Hours()/24,
+						resourceGroup.Name,
+						vaultName,
+						certificateName,
+					)
+				}
+
+				err = certificates.NextWithContext(ctx)
+				if err != nil {
+					k.logger.Errorf(ctx, err, "an error occurred listing the certificates of key vault %#q", vaultName)
+					break
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (k *KeyVaultCertificate) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- keyVaultCertificateNotAfterDesc
+	ch <- keyVaultCertificateExpiryDaysDesc
+	return nil
+}
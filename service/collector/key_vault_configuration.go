@@ -0,0 +1,196 @@
+package collector
+
+import (
+	"strings"
+
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	keyVaultAPIVersion = "2019-09-01"
+
+	keyVaultAuthorizationRBAC           = "rbac"
+	keyVaultAuthorizationAccessPolicies = "access_policies"
+
+	keyVaultCheckRBACAuthorization   = "rbac_authorization_enabled"
+	keyVaultCheckNetworkDefaultDeny  = "network_default_action_deny"
+	keyVaultCheckPurgeProtection     = "purge_protection_enabled"
+	keyVaultCheckSoftDelete          = "soft_delete_enabled"
+	keyVaultNetworkDefaultActionDeny = "Deny"
+
+	// Key vaults without network ACLs accept requests from all networks.
+	keyVaultNetworkDefaultActionAllow = "Allow"
+)
+
+// keyVaultProperties holds the subset of the key vault properties we need.
+type keyVaultProperties struct {
+	Properties struct {
+		EnablePurgeProtection   *bool `json:"enablePurgeProtection"`
+		EnableRbacAuthorization *bool `json:"enableRbacAuthorization"`
+		EnableSoftDelete        *bool `json:"enableSoftDelete"`
+		NetworkAcls             *struct {
+			DefaultAction string `json:"defaultAction"`
+		} `json:"networkAcls"`
+	} `json:"properties"`
+}
+
+var (
+	keyVaultConfigurationInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "key_vault", "configuration_info"),
+		"Access configuration of the key vault.",
+		[]string{
+			"resource_group",
+			"key_vault",
+			"authorization",
+			"network_default_action",
+		},
+		nil,
+	)
+	keyVaultConfigurationCompliantDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "key_vault", "configuration_compliant"),
+		"Whether the key vault configuration matches the expected baseline for the given check.",
+		[]string{
+			"resource_group",
+			"key_vault",
+			"check",
+		},
+		nil,
+	)
+)
+
+type KeyVaultConfigurationConfig struct {
+	CredentialSource          *credential.Source
+	Collections               *Collections
+	Logger                    micrologger.Logger
+	ControlPlaneResourceGroup string
+	GSTenantID                string
+}
+
+type KeyVaultConfiguration struct {
+	credentialSource          *credential.Source
+	collections               *Collections
+	logger                    micrologger.Logger
+	controlPlaneResourceGroup string
+	gsTenantID                string
+}
+
+// NewKeyVaultConfiguration exposes the access configuration of the key vaults in the managed resource groups and
+// whether it drifted from the expected baseline: RBAC authorization, network access denied by default, purge
+// protection and soft delete enabled.
+func NewKeyVaultConfiguration(config KeyVaultConfigurationConfig) (*KeyVaultConfiguration, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.ControlPlaneResourceGroup == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ControlPlaneResourceGroup must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	k := &KeyVaultConfiguration{
+		credentialSource:          config.CredentialSource,
+		collections:               config.Collections,
+		logger:                    config.Logger,
+		controlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+		gsTenantID:                config.GSTenantID,
+	}
+
+	return k, nil
+}
+
+func (k *KeyVaultConfiguration) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := k.collections.newCollectContext("key_vault_configuration")
+	defer cancel()
+
+	resourceGroups, err := getManagedResourceGroups(ctx, k.credentialSource, k.gsTenantID, k.controlPlaneResourceGroup)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, resourceGroup := range resourceGroups {
+		vaults, err := getKeyVaults(ctx, resourceGroup.AzureClientSet.ResourcesClient, resourceGroup.Name)
+		if IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, vault := range vaults {
+			vaultName := to.String(vault.Name)
+
+			// The SDK version we use does not know about RBAC authorization
+			// of key vaults.
+			var properties keyVaultProperties
+			err := resourceGroup.AzureClientSet.RESTClient.GetJSON(ctx, to.String(vault.ID), keyVaultAPIVersion, &properties)
+			if err != nil {
+				k.logger.Errorf(ctx, err, "an error occurred fetching the properties of key vault %#q", vaultName)
+				continue
+			}
+
+			p := properties.Properties
+
+			authorization := keyVaultAuthorizationAccessPolicies
+			if to.Bool(p.EnableRbacAuthorization) {
+				authorization = keyVaultAuthorizationRBAC
+			}
+
+			networkDefaultAction := keyVaultNetworkDefaultActionAllow
+			if p.NetworkAcls != nil && p.NetworkAcls.DefaultAction != "" {
+				networkDefaultAction = p.NetworkAcls.DefaultAction
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				keyVaultConfigurationInfoDesc,
+				prometheus.GaugeValue,
+				gaugeValue,
+				resourceGroup.Name,
+				vaultName,
+				authorization,
+				networkDefaultAction,
+			)
+
+			checks := map[string]bool{
+				keyVaultCheckRBACAuthorization:  authorization == keyVaultAuthorizationRBAC,
+				keyVaultCheckNetworkDefaultDeny: strings.EqualFold(networkDefaultAction, keyVaultNetworkDefaultActionDeny),
+				keyVaultCheckPurgeProtection:    to.Bool(p.EnablePurgeProtection),
+				keyVaultCheckSoftDelete:         to.Bool(p.EnableSoftDelete),
+			}
+
+			for check, compliant := range checks {
+				var v float64
+				if compliant {
+					v = 1
+				}
+
+				ch <- prometheus.MustNewConstMetric(
+					keyVaultConfigurationCompliantDesc,
+					prometheus.GaugeValue,
+					v,
+					resourceGroup.Name,
+					vaultName,
+					check,
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (k *KeyVaultConfiguration) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- keyVaultConfigurationInfoDesc
+	ch <- keyVaultConfigurationCompliantDesc
+	return nil
+}
@@ -0,0 +1,241 @@
+package collector
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/keyvault/v7.0/keyvault"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+var (
+	keyVaultKeyAgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "key_vault_key", "age_seconds"),
+		"Time since the current version of the key stored in the key vault was created, i.e. since its last rotation.",
+		[]string{
+			"resource_group",
+			"key_vault",
+			"key",
+			"disk_encryption_set",
+		},
+		nil,
+	)
+	keyVaultKeyRotationPolicyDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "key_vault_key", "rotation_policy_enabled"),
+		"Whether the key stored in the key vault has a rotation policy which rotates it automatically.",
+		[]string{
+			"resource_group",
+			"key_vault",
+			"key",
+			"disk_encryption_set",
+		},
+		nil,
+	)
+)
+
+type KeyVaultKeyConfig struct {
+	CredentialSource          *credential.Source
+	Collections               *Collections
+	Logger                    micrologger.Logger
+	ControlPlaneResourceGroup string
+	GSTenantID                string
+}
+
+type KeyVaultKey struct {
+	credentialSource          *credential.Source
+	collections               *Collections
+	logger                    micrologger.Logger
+	controlPlaneResourceGroup string
+	gsTenantID                string
+}
+
+// NewKeyVaultKey exposes the age since the last rotation of the keys stored in the key vaults of the managed resource
+// groups and whether they are rotated automatically. Keys backing the disk encryption sets of the managed resource
+// groups are covered as well, even when they are stored in other key vaults.
+func NewKeyVaultKey(config KeyVaultKeyConfig) (*KeyVaultKey, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.ControlPlaneResourceGroup == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ControlPlaneResourceGroup must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	k := &KeyVaultKey{
+		credentialSource:          config.CredentialSource,
+		collections:               config.Collections,
+		logger:                    config.Logger,
+		controlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+		gsTenantID:                config.GSTenantID,
+	}
+
+	return k, nil
+}
+
+func (k *KeyVaultKey) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := k.collections.newCollectContext("key_vault_key")
+	defer cancel()
+
+	resourceGroups, err := getManagedResourceGroups(ctx, k.credentialSource, k.gsTenantID, k.controlPlaneResourceGroup)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, resourceGroup := range resourceGroups {
+		azureClientSet := resourceGroup.AzureClientSet
+
+		// Maps the unversioned key URLs to the disk encryption set using
+		// them.
+		encryptionSetKeys, err := k.getEncryptionSetKeys(ctx, azureClientSet, resourceGroup.Name)
+		if IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		vaults, err := getKeyVaults(ctx, azureClientSet.ResourcesClient, resourceGroup.Name)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, vault := range vaults {
+			vaultName := to.String(vault.Name)
+
+			keys, err := azureClientSet.KeyVaultClient.GetKeysComplete(ctx, keyVaultBaseURL(k.credentialSource, vaultName), nil)
+			if err != nil {
+				k.logger.Errorf(ctx, err, "an error occurred listing the keys of key vault %#q", vaultName)
+				continue
+			}
+
+			for keys.NotDone() {
+				item := keys.Value()
+				keyURL := strings.ToLower(to.String(item.Kid))
+
+				k.collectKey(ctx, ch, azureClientSet, resourceGroup.Name, to.String(item.Kid), item.Attributes, encryptionSetKeys[keyURL])
+				delete(encryptionSetKeys, keyURL)
+
+				err = keys.NextWithContext(ctx)
+				if err != nil {
+					k.logger.Errorf(ctx, err, "an error occurred listing the keys of key vault %#q", vaultName)
+					break
+				}
+			}
+		}
+
+		// The remaining keys of the disk encryption sets are stored in key
+		// vaults outside of the resource group.
+		for keyURL, encryptionSet := range encryptionSetKeys {
+			vaultName, keyName, _, err := parseKeyVaultObjectURL(keyURL)
+			if err != nil {
+				k.logger.Errorf(ctx, err, "an error occurred parsing the key URL of disk encryption set %#q", encryptionSet)
+				continue
+			}
+
+			bundle, err := azureClientSet.KeyVaultClient.GetKey(ctx, keyVaultBaseURL(k.credentialSource, vaultName), keyName, "")
+			if err != nil {
+				k.logger.Errorf(ctx, err, "an error occurred fetching the key of disk encryption set %#q", encryptionSet)
+				continue
+			}
+
+			k.collectKey(ctx, ch, azureClientSet, resourceGroup.Name, keyURL, bundle.Attributes, encryptionSet)
+		}
+	}
+
+	return nil
+}
+
+func (k *KeyVaultKey) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- keyVaultKeyAgeDesc
+	ch <- keyVaultKeyRotationPolicyDesc
+	return nil
+}
+
+func (k *KeyVaultKey) collectKey(ctx context.Context, ch chan<- prometheus.Metric, azureClientSet *client.AzureClientSet, resourceGroup, keyURL string, attributes *keyvault.KeyAttributes, encryptionSet string) {
+	vaultName, keyName, _, err := parseKeyVaultObjectURL(keyURL)
+	if err != nil {
+		k.logger.Errorf(ctx, err, "an error occurred parsing key URL %#q", keyURL)
+		return
+	}
+
+	if attributes != nil && attributes.Created != nil {
+		ch <- prometheus.MustNewConstMetric(
+			keyVaultKeyAgeDesc,
+			prometheus.GaugeValue,
+			time.Since(time.Time(*attributes.Created)).Seconds(),
+			resourceGroup,
+			vaultName,
+			keyName,
+			encryptionSet,
+		)
+	}
+
+	policy, err := getKeyRotationPolicy(ctx, azureClientSet.KeyVaultClient, keyVaultBaseURL(k.credentialSource, vaultName), keyName)
+	if IsNotFound(err) {
+		// There is no rotation policy.
+	} else if err != nil {
+		k.logger.Errorf(ctx, err, "an error occurred fetching the rotation policy of key %#q in key vault %#q", keyName, vaultName)
+		return
+	}
+
+	var enabled float64
+	if policy.RotatesAutomatically() {
+		enabled = 1
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		keyVaultKeyRotationPolicyDesc,
+		prometheus.GaugeValue,
+		enabled,
+		resourceGroup,
+		vaultName,
+		keyName,
+		encryptionSet,
+	)
+}
+
+func (k *KeyVaultKey) getEncryptionSetKeys(ctx context.Context, azureClientSet *client.AzureClientSet, resourceGroup string) (map[string]string, error) {
+	encryptionSetKeys := map[string]string{}
+
+	iterator, err := azureClientSet.DiskEncryptionSetsClient.ListByResourceGroupComplete(ctx, resourceGroup)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	for iterator.NotDone() {
+		encryptionSet := iterator.Value()
+
+		if encryptionSet.EncryptionSetProperties != nil && encryptionSet.ActiveKey != nil {
+			vaultName, keyName, _, err := parseKeyVaultObjectURL(to.String(encryptionSet.ActiveKey.KeyURL))
+			if err != nil {
+				k.logger.Errorf(ctx, err, "an error occurred parsing the key URL of disk encryption set %#q", to.String(encryptionSet.Name))
+			} else {
+				// The key vault API returns unversioned key URLs when
+				// listing keys.
+				keyURL := strings.ToLower(keyVaultBaseURL(k.credentialSource, vaultName) + "/keys/" + keyName)
+				encryptionSetKeys[keyURL] = to.String(encryptionSet.Name)
+			}
+		}
+
+		err = iterator.NextWithContext(ctx)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	return encryptionSetKeys, nil
+}
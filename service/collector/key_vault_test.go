@@ -0,0 +1,76 @@
+package collector
+
+import (
+	"strconv"
+	"testing"
+)
+
+func Test_parseKeyVaultObjectURL(t *testing.T) {
+	testCases := []struct {
+		name               string
+		objectURL          string
+		expectedVaultName  string
+		expectedObjectName string
+		expectedVersion    string
+		errorMatcher       func(error) bool
+	}{
+		{
+			name:               "case 0: versioned key URL",
+			objectURL:          "https://myvault.vault.azure.net/keys/mykey/0123456789abcdef",
+			expectedVaultName:  "myvault",
+			expectedObjectName: "mykey",
+			expectedVersion:    "0123456789abcdef",
+		},
+		{
+			name:               "case 1: unversioned certificate URL",
+			objectURL:          "https://myvault.vault.azure.net/certificates/ingress",
+			expectedVaultName:  "myvault",
+			expectedObjectName: "ingress",
+		},
+		{
+			name:               "case 2: trailing slash",
+			objectURL:          "https://myvault.vault.azure.net/keys/mykey/",
+			expectedVaultName:  "myvault",
+			expectedObjectName: "mykey",
+		},
+		{
+			name:         "case 3: missing object name",
+			objectURL:    "https://myvault.vault.azure.net/keys",
+			errorMatcher: IsInvalidKeyVaultURL,
+		},
+		{
+			name:         "case 4: empty URL",
+			objectURL:    "",
+			errorMatcher: IsInvalidKeyVaultURL,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			vaultName, objectName, version, err := parseKeyVaultObjectURL(tc.objectURL)
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if vaultName != tc.expectedVaultName {
+				t.Fatalf("expected vault name %#q, got %#q", tc.expectedVaultName, vaultName)
+			}
+			if objectName != tc.expectedObjectName {
+				t.Fatalf("expected object name %#q, got %#q", tc.expectedObjectName, objectName)
+			}
+			if version != tc.expectedVersion {
+				t.Fatalf("expected version %#q, got %#q", tc.expectedVersion, version)
+			}
+		})
+	}
+}
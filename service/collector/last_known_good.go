@@ -0,0 +1,133 @@
+package collector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/giantswarm/exporterkit/collector"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// maxStaleness is how long the metrics of the last complete collection
+	// of a collector are served in place of the ones of collections running
+	// into their deadline. Older metrics are dropped, so Azure resources
+	// which are gone do not linger forever.
+	maxStaleness = time.Hour
+	// maxLastKnownGoodMetrics is the maximum number of metrics of a
+	// collection which are kept. Larger collections are not kept, so a few
+	// large collectors do not hold on to a lot of memory between scrapes.
+	maxLastKnownGoodMetrics = 10000
+)
+
+// lastKnownGoodCollector serves the metrics of the last complete collection
+// of a collector when a collection runs into its deadline, instead of the
+// partial metrics collected by then. The age of the served metrics is exposed
+// by the DataAge collector, so dashboards can tell fresh from stale data.
+type lastKnownGoodCollector struct {
+	collections *Collections
+	collector   collector.Interface
+	name        string
+	maxMetrics  int
+	now         func() time.Time
+
+	mutex     sync.Mutex
+	metrics   []prometheus.Metric
+	collected time.Time
+}
+
+// withLastKnownGood serves the metrics of the last complete collection of the
+// given collectors when a collection runs into its deadline.
+func withLastKnownGood(collectors []collector.Interface, collections *Collections) []collector.Interface {
+	var wrapped []collector.Interface
+	for _, c := range collectors {
+		wrapped = append(wrapped, &lastKnownGoodCollector{
+			collections: collections,
+			collector:   c,
+			name:        collectorName(c),
+			maxMetrics:  maxLastKnownGoodMetrics,
+			now:         time.Now,
+		})
+	}
+
+	return wrapped
+}
+
+func (l *lastKnownGoodCollector) Collect(ch chan<- prometheus.Metric) error {
+	started := l.now()
+	deadline, ok := l.collections.collectDeadline(started, l.collections.getScrape())
+
+	// The metrics are only sent on once it is known whether the collection
+	// completed.
+	var metrics []prometheus.Metric
+	var err error
+	{
+		buffer := make(chan prometheus.Metric)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for m := range buffer {
+				metrics = append(metrics, m)
+			}
+		}()
+
+		func() {
+			defer close(buffer)
+			err = l.collector.Collect(buffer)
+		}()
+		<-done
+	}
+
+	finished := l.now()
+
+	if IsCollectionSkipped(err) {
+		return err
+	}
+
+	if ok && !finished.Before(deadline) {
+		last, found := l.lastKnownGood(finished)
+		if found {
+			for _, m := range last {
+				ch <- m
+			}
+
+			return err
+		}
+	} else if err == nil && len(metrics) <= l.maxMetrics {
+		l.mutex.Lock()
+		l.metrics = metrics
+		l.collected = started
+		l.mutex.Unlock()
+	} else if err == nil {
+		// Collections which are too large to be kept must not leave an
+		// older one to be served later.
+		l.mutex.Lock()
+		l.metrics = nil
+		l.collected = time.Time{}
+		l.mutex.Unlock()
+	}
+
+	for _, m := range metrics {
+		ch <- m
+	}
+
+	return err
+}
+
+func (l *lastKnownGoodCollector) Describe(ch chan<- *prometheus.Desc) error {
+	return l.collector.Describe(ch)
+}
+
+// lastKnownGood returns the metrics of the last complete collection. There
+// are none when there was no complete collection yet or it is older than
+// maxStaleness at the given time.
+func (l *lastKnownGoodCollector) lastKnownGood(now time.Time) ([]prometheus.Metric, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.collected.IsZero() || now.Sub(l.collected) > maxStaleness {
+		return nil, false
+	}
+
+	return l.metrics, true
+}
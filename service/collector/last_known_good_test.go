@@ -0,0 +1,159 @@
+package collector
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/exporterkit/collector"
+	"github.com/giantswarm/microerror"
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+var testLastKnownGoodDesc = prometheus.NewDesc("test_value", "Test value.", nil, nil)
+
+// testClock is a clock which only advances when told to.
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+// testCollection is a collection of testCollector.
+type testCollection struct {
+	values   []float64
+	duration time.Duration
+	err      error
+}
+
+// testCollector sends the values of its collections in order. Every
+// collection advances the clock by its duration.
+type testCollector struct {
+	clock       *testClock
+	collections []testCollection
+}
+
+func (c *testCollector) Collect(ch chan<- prometheus.Metric) error {
+	collection := c.collections[0]
+	c.collections = c.collections[1:]
+
+	for _, v := range collection.values {
+		ch <- prometheus.MustNewConstMetric(testLastKnownGoodDesc, prometheus.GaugeValue, v)
+	}
+	c.clock.now = c.clock.now.Add(collection.duration)
+
+	return collection.err
+}
+
+func (c *testCollector) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- testLastKnownGoodDesc
+	return nil
+}
+
+func Test_lastKnownGoodCollector(t *testing.T) {
+	timeout := time.Minute
+	failed := microerror.Mask(invalidConfigError)
+
+	testCases := []struct {
+		name           string
+		collections    []testCollection
+		maxMetrics     int
+		expectedValues [][]float64
+	}{
+		{
+			name: "case 0: complete collections are served",
+			collections: []testCollection{
+				{values: []float64{1, 2}},
+				{values: []float64{3}},
+			},
+			expectedValues: [][]float64{{1, 2}, {3}},
+		},
+		{
+			name: "case 1: last complete collection is served when a collection runs into its deadline",
+			collections: []testCollection{
+				{values: []float64{1, 2}},
+				{values: []float64{3}, duration: 2 * timeout},
+			},
+			expectedValues: [][]float64{{1, 2}, {1, 2}},
+		},
+		{
+			name: "case 2: partial collection is served without a complete one",
+			collections: []testCollection{
+				{values: []float64{3}, duration: 2 * timeout},
+			},
+			expectedValues: [][]float64{{3}},
+		},
+		{
+			name: "case 3: failed collections are served but not kept",
+			collections: []testCollection{
+				{values: []float64{3}, err: failed},
+				{values: []float64{4}, duration: 2 * timeout},
+			},
+			expectedValues: [][]float64{{3}, {4}},
+		},
+		{
+			name: "case 4: stale collection is not served",
+			collections: []testCollection{
+				{values: []float64{1, 2}},
+				{values: []float64{3}, duration: maxStaleness},
+				{values: []float64{4}, duration: 2 * timeout},
+			},
+			expectedValues: [][]float64{{1, 2}, {1, 2}, {4}},
+		},
+		{
+			name: "case 5: collections too large to be kept are not served",
+			collections: []testCollection{
+				{values: []float64{1}},
+				{values: []float64{1, 2, 3}},
+				{values: []float64{4}, duration: 2 * timeout},
+			},
+			maxMetrics:     2,
+			expectedValues: [][]float64{{1}, {1, 2, 3}, {4}},
+		},
+	}
+
+	collections, err := NewCollections(RuntimeConfig{CollectTimeout: timeout})
+	if err != nil {
+		t.Fatalf("expected no error, got %#v", err)
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			clock := &testClock{now: time.Date(2021, 5, 4, 10, 0, 0, 0, time.UTC)}
+
+			l := withLastKnownGood([]collector.Interface{&testCollector{clock: clock, collections: tc.collections}}, collections)[0].(*lastKnownGoodCollector)
+			l.now = clock.Now
+			if tc.maxMetrics > 0 {
+				l.maxMetrics = tc.maxMetrics
+			}
+
+			var values [][]float64
+			for range tc.collections {
+				ch := make(chan prometheus.Metric, 10)
+				_ = l.Collect(ch)
+				close(ch)
+
+				var collected []float64
+				for m := range ch {
+					var metric dto.Metric
+					err := m.Write(&metric)
+					if err != nil {
+						t.Fatalf("expected no error, got %#v", err)
+					}
+					collected = append(collected, metric.GetGauge().GetValue())
+				}
+				values = append(values, collected)
+			}
+
+			if !cmp.Equal(values, tc.expectedValues) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedValues, values))
+			}
+		})
+	}
+}
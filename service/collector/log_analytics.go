@@ -0,0 +1,225 @@
+package collector
+
+import (
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	logAnalyticsWorkspaceResourceType = "Microsoft.OperationalInsights/workspaces"
+	logAnalyticsAPIVersion            = "2020-08-01"
+
+	// logAnalyticsDataAnalyzedUsage is the usage tracking the data ingested
+	// into the workspace since the last daily reset.
+	logAnalyticsDataAnalyzedUsage = "DataAnalyzed"
+
+	// Workspaces without a daily cap report a negative daily quota.
+	logAnalyticsNoDailyCap = -1
+)
+
+// logAnalyticsWorkspace holds the subset of the workspace properties we need.
+type logAnalyticsWorkspace struct {
+	Properties struct {
+		RetentionInDays  *int32 `json:"retentionInDays"`
+		WorkspaceCapping *struct {
+			DailyQuotaGb        *float64 `json:"dailyQuotaGb"`
+			DataIngestionStatus string   `json:"dataIngestionStatus"`
+		} `json:"workspaceCapping"`
+	} `json:"properties"`
+}
+
+// logAnalyticsUsages holds the usages of a workspace.
+type logAnalyticsUsages struct {
+	Value []struct {
+		Name struct {
+			Value string `json:"value"`
+		} `json:"name"`
+		CurrentValue *float64 `json:"currentValue"`
+	} `json:"value"`
+}
+
+var (
+	logAnalyticsIngestionDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "log_analytics", "daily_ingestion_bytes"),
+		"Data ingested into the Log Analytics workspace since the last daily reset.",
+		[]string{
+			"resource_group",
+			"workspace",
+		},
+		nil,
+	)
+	logAnalyticsDailyCapDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "log_analytics", "daily_cap_bytes"),
+		"Daily ingestion cap of the Log Analytics workspace. Not exposed for workspaces without a cap.",
+		[]string{
+			"resource_group",
+			"workspace",
+		},
+		nil,
+	)
+	logAnalyticsIngestionStatusDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "log_analytics", "ingestion_status"),
+		"Data ingestion status of the Log Analytics workspace, e.g. OverQuota when the daily cap was hit.",
+		[]string{
+			"resource_group",
+			"workspace",
+			"status",
+		},
+		nil,
+	)
+	logAnalyticsRetentionDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "log_analytics", "retention_days"),
+		"Number of days data is retained in the Log Analytics workspace.",
+		[]string{
+			"resource_group",
+			"workspace",
+		},
+		nil,
+	)
+)
+
+type LogAnalyticsConfig struct {
+	CredentialSource          *credential.Source
+	Collections               *Collections
+	Logger                    micrologger.Logger
+	ControlPlaneResourceGroup string
+	GSTenantID                string
+}
+
+type LogAnalytics struct {
+	credentialSource          *credential.Source
+	collections               *Collections
+	logger                    micrologger.Logger
+	controlPlaneResourceGroup string
+	gsTenantID                string
+}
+
+// NewLogAnalytics exposes the daily ingestion, the daily cap and the retention of the Log Analytics workspaces in the
+// managed resource groups. Hitting the daily cap means logs are dropped until the next reset.
+func NewLogAnalytics(config LogAnalyticsConfig) (*LogAnalytics, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.ControlPlaneResourceGroup == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ControlPlaneResourceGroup must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	l := &LogAnalytics{
+		credentialSource:          config.CredentialSource,
+		collections:               config.Collections,
+		logger:                    config.Logger,
+		controlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+		gsTenantID:                config.GSTenantID,
+	}
+
+	return l, nil
+}
+
+func (l *LogAnalytics) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := l.collections.newCollectContext("log_analytics")
+	defer cancel()
+
+	resourceGroups, err := getManagedResourceGroups(ctx, l.credentialSource, l.gsTenantID, l.controlPlaneResourceGroup)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, resourceGroup := range resourceGroups {
+		restClient := resourceGroup.AzureClientSet.RESTClient
+
+		workspaces, err := getResourcesByType(ctx, resourceGroup.AzureClientSet.ResourcesClient, resourceGroup.Name, logAnalyticsWorkspaceResourceType)
+		if IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, w := range workspaces {
+			workspaceID := to.String(w.ID)
+			workspaceName := to.String(w.Name)
+
+			var workspace logAnalyticsWorkspace
+			err := restClient.GetJSON(ctx, workspaceID, logAnalyticsAPIVersion, &workspace)
+			if err != nil {
+				l.logger.Errorf(ctx, err, "an error occurred fetching Log Analytics workspace %#q", workspaceName)
+				continue
+			}
+
+			p := workspace.Properties
+			if p.RetentionInDays != nil {
+				ch <- prometheus.MustNewConstMetric(
+					logAnalyticsRetentionDesc,
+					prometheus.GaugeValue,
+					float64(*p.RetentionInDays),
+					resourceGroup.Name,
+					workspaceName,
+				)
+			}
+			if p.WorkspaceCapping != nil {
+				if p.WorkspaceCapping.DailyQuotaGb != nil && *p.WorkspaceCapping.DailyQuotaGb != logAnalyticsNoDailyCap {
+					ch <- prometheus.MustNewConstMetric(
+						logAnalyticsDailyCapDesc,
+						prometheus.GaugeValue,
+						*p.WorkspaceCapping.DailyQuotaGb*bytesPerGiB,
+						resourceGroup.Name,
+						workspaceName,
+					)
+				}
+				if p.WorkspaceCapping.DataIngestionStatus != "" {
+					ch <- prometheus.MustNewConstMetric(
+						logAnalyticsIngestionStatusDesc,
+						prometheus.GaugeValue,
+						gaugeValue,
+						resourceGroup.Name,
+						workspaceName,
+						p.WorkspaceCapping.DataIngestionStatus,
+					)
+				}
+			}
+
+			var usages logAnalyticsUsages
+			err = restClient.GetJSON(ctx, workspaceID+"/usages", logAnalyticsAPIVersion, &usages)
+			if err != nil {
+				l.logger.Errorf(ctx, err, "an error occurred fetching the usages of Log Analytics workspace %#q", workspaceName)
+				continue
+			}
+
+			for _, usage := range usages.Value {
+				if usage.Name.Value != logAnalyticsDataAnalyzedUsage || usage.CurrentValue == nil {
+					continue
+				}
+
+				ch <- prometheus.MustNewConstMetric(
+					logAnalyticsIngestionDesc,
+					prometheus.GaugeValue,
+					*usage.CurrentValue,
+					resourceGroup.Name,
+					workspaceName,
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (l *LogAnalytics) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- logAnalyticsIngestionDesc
+	ch <- logAnalyticsDailyCapDesc
+	ch <- logAnalyticsIngestionStatusDesc
+	ch <- logAnalyticsRetentionDesc
+	return nil
+}
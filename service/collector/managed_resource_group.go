@@ -0,0 +1,93 @@
+package collector
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2019-05-01/resources"
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+// managedResourceGroup is a resource group managed by this installation
+// together with the Azure clients which have access to it.
+type managedResourceGroup struct {
+	// ClusterID is the ID of the cluster owning the resource group. It is
+	// empty for the control plane resource group.
+	ClusterID      string
+	Name           string
+	AzureClientSet *client.AzureClientSet
+}
+
+// getManagedResourceGroups returns the resource groups of all clusters and
+// the control plane resource group. The control plane resource group is
+// returned once per subscription, because we can't tell which subscription it
+// belongs to. Callers have to ignore 404 responses for it.
+func getManagedResourceGroups(ctx context.Context, source *credential.Source, gsTenantID, controlPlaneResourceGroup string) ([]managedResourceGroup, error) {
+	var resourceGroups []managedResourceGroup
+
+	clusterClientSets, err := getClientSetsByCluster(ctx, source, gsTenantID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	for clusterID, azureClientSet := range clusterClientSets {
+		resourceGroups = append(resourceGroups, managedResourceGroup{
+			ClusterID:      clusterID,
+			Name:           clusterID,
+			AzureClientSet: azureClientSet,
+		})
+	}
+
+	if !source.MatchResourceGroup(controlPlaneResourceGroup) {
+		return resourceGroups, nil
+	}
+
+	subscriptionClientSets, err := credential.GetAzureClientSetsFromCredentialSecretsBySubscription(ctx, source, gsTenantID)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	for _, azureClientSet := range subscriptionClientSets {
+		resourceGroups = append(resourceGroups, managedResourceGroup{
+			Name:           controlPlaneResourceGroup,
+			AzureClientSet: azureClientSet,
+		})
+	}
+
+	return resourceGroups, nil
+}
+
+// getResourcesByType returns the resources of the given type found in the
+// given resource group, e.g. "Microsoft.KeyVault/vaults". The resources are
+// shared with other collectors through the inventory cache.
+func getResourcesByType(ctx context.Context, resourcesClient *resources.Client, resourceGroup, resourceType string) ([]resources.GenericResourceExpanded, error) {
+	key := strings.ToLower("resources/" + resourcesClient.SubscriptionID + "/" + resourceGroup + "/" + resourceType)
+
+	v, err := inventory.get(ctx, key, func() (interface{}, error) {
+		var result []resources.GenericResourceExpanded
+
+		iterator, err := resourcesClient.ListByResourceGroupComplete(ctx, resourceGroup, "resourceType eq '"+resourceType+"'", "", nil)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		for iterator.NotDone() {
+			result = append(result, iterator.Value())
+
+			err = iterator.NextWithContext(ctx)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+		}
+
+		return result, nil
+	})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return v.([]resources.GenericResourceExpanded), nil
+}
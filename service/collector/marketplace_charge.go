@@ -0,0 +1,128 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	costColumnPublisherName = "PublisherName"
+	costColumnPublisherType = "PublisherType"
+
+	costPublisherTypeMarketplace = "Marketplace"
+)
+
+var (
+	marketplaceChargesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "marketplace_charges", "month_to_date"),
+		"Azure Marketplace charges of the subscription since the beginning of the month per publisher.",
+		[]string{
+			"subscription",
+			"publisher",
+			"currency",
+		},
+		nil,
+	)
+)
+
+type MarketplaceChargeConfig struct {
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+
+	GSTenantID string
+}
+
+type MarketplaceCharge struct {
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+	costQuerier      *costQuerier
+
+	gsTenantID string
+}
+
+// NewMarketplaceCharge exposes the Azure Marketplace charges, e.g. for third-party images and extensions, of the
+// subscriptions found in the "credential-*" secrets of the control plane, so unexpected marketplace meters attached to
+// node images are caught early.
+func NewMarketplaceCharge(config MarketplaceChargeConfig) (*MarketplaceCharge, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	m := &MarketplaceCharge{
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		costQuerier:      newCostQuerier(),
+		gsTenantID:       config.GSTenantID,
+	}
+
+	return m, nil
+}
+
+func (m *MarketplaceCharge) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := m.collections.newCollectContext("marketplace_charge")
+	defer cancel()
+	clientSets, err := credential.GetAzureClientSetsFromCredentialSecretsBySubscription(ctx, m.credentialSource, m.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	now := time.Now().UTC()
+	query := actualCostQuery(startOfMonth(now), now, costColumnPublisherName)
+	query.Dataset.Granularity = ""
+	query.Dataset.Filter = &costQueryFilter{
+		Dimensions: &costQueryComparison{
+			Name:     costColumnPublisherType,
+			Operator: "In",
+			Values:   []string{costPublisherTypeMarketplace},
+		},
+	}
+
+	for subscriptionID, azureClientSet := range clientSets {
+		rows, err := m.costQuerier.Query(ctx, azureClientSet.RESTClient, "/subscriptions/"+subscriptionID+"/providers/Microsoft.CostManagement/query", query)
+		if err != nil {
+			m.logger.Errorf(ctx, err, "an error occurred fetching the marketplace charges of subscription %#q", subscriptionID)
+			continue
+		}
+
+		charges := map[[2]string]float64{}
+		for _, row := range rows {
+			charges[[2]string{row.String(costColumnPublisherName), row.String(costColumnCurrency)}] += row.Float(costColumnCost)
+		}
+
+		for k, charge := range charges {
+			ch <- prometheus.MustNewConstMetric(
+				marketplaceChargesDesc,
+				prometheus.GaugeValue,
+				charge,
+				subscriptionID,
+				k[0],
+				k[1],
+			)
+		}
+	}
+
+	return nil
+}
+
+func (m *MarketplaceCharge) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- marketplaceChargesDesc
+	return nil
+}
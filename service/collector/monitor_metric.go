@@ -0,0 +1,158 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/preview/monitor/mgmt/2019-06-01/insights"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+)
+
+const (
+	aggregationAverage = "Average"
+	aggregationCount   = "Count"
+	aggregationMaximum = "Maximum"
+	aggregationMinimum = "Minimum"
+	aggregationTotal   = "Total"
+
+	// monitorMetricTimespan is how far back we look for data points when
+	// querying Azure Monitor. Platform metrics are usually delayed by a few
+	// minutes, so we query a window which is large enough to always contain
+	// at least one complete interval.
+	monitorMetricTimespan = 15 * time.Minute
+	monitorMetricInterval = "PT5M"
+)
+
+// monitorMetricValue is the latest data point of a single Azure Monitor time
+// series.
+type monitorMetricValue struct {
+	// Name is the name of the metric as requested.
+	Name string
+	// Dimensions holds the dimension values of the time series, keyed by
+	// lower cased dimension name. Use Dimension to look values up.
+	Dimensions map[string]string
+	// Value is the aggregated value of the latest data point.
+	Value float64
+}
+
+// Dimension returns the value of the given dimension. Azure Monitor is not
+// consistent about the casing of dimension names, so they are matched case
+// insensitively.
+func (v monitorMetricValue) Dimension(name string) string {
+	return v.Dimensions[strings.ToLower(name)]
+}
+
+// sumMonitorMetricValues returns the sum of the given values, e.g. to
+// aggregate a metric over all its dimensions.
+func sumMonitorMetricValues(values []monitorMetricValue) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v.Value
+	}
+
+	return sum
+}
+
+// getMonitorMetricValues queries Azure Monitor for the given metrics of a
+// single resource and returns the latest data point of every time series.
+// Time series without any data point in the queried timespan are omitted.
+// The filter can be used to split the metrics by dimension, e.g.
+// "TestGroupName eq '*'".
+func getMonitorMetricValues(ctx context.Context, metricsClient *insights.MetricsClient, resourceID string, metricNames []string, aggregation string, filter string) ([]monitorMetricValue, error) {
+	return getMonitorMetricValuesWithTimespan(ctx, metricsClient, resourceID, metricNames, aggregation, filter, monitorMetricTimespan, monitorMetricInterval)
+}
+
+// getMonitorMetricValuesWithTimespan is like getMonitorMetricValues but allows
+// to query metrics which are emitted less often than every few minutes, like
+// the hourly capacity metrics of storage accounts.
+func getMonitorMetricValuesWithTimespan(ctx context.Context, metricsClient *insights.MetricsClient, resourceID string, metricNames []string, aggregation string, filter string, timespan time.Duration, interval string) ([]monitorMetricValue, error) {
+	now := time.Now().UTC()
+	window := fmt.Sprintf("%s/%s", now.Add(-timespan).Format(time.RFC3339), now.Format(time.RFC3339))
+
+	response, err := metricsClient.List(
+		ctx,
+		resourceID,
+		window,
+		to.StringPtr(interval),
+		strings.Join(metricNames, ","),
+		aggregation,
+		nil,
+		"",
+		filter,
+		insights.Data,
+		"",
+	)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var values []monitorMetricValue
+	if response.Value == nil {
+		return values, nil
+	}
+
+	for _, metric := range *response.Value {
+		if metric.Name == nil || metric.Timeseries == nil {
+			continue
+		}
+
+		for _, series := range *metric.Timeseries {
+			value, ok := latestMetricValue(series, aggregation)
+			if !ok {
+				continue
+			}
+
+			dimensions := map[string]string{}
+			if series.Metadatavalues != nil {
+				for _, m := range *series.Metadatavalues {
+					if m.Name == nil {
+						continue
+					}
+					dimensions[strings.ToLower(to.String(m.Name.Value))] = to.String(m.Value)
+				}
+			}
+
+			values = append(values, monitorMetricValue{
+				Name:       to.String(metric.Name.Value),
+				Dimensions: dimensions,
+				Value:      value,
+			})
+		}
+	}
+
+	return values, nil
+}
+
+// latestMetricValue returns the value of the most recent data point of the
+// given time series which has a value for the given aggregation.
+func latestMetricValue(series insights.TimeSeriesElement, aggregation string) (float64, bool) {
+	if series.Data == nil {
+		return 0, false
+	}
+
+	data := *series.Data
+	for i := len(data) - 1; i >= 0; i-- {
+		var v *float64
+		switch aggregation {
+		case aggregationAverage:
+			v = data[i].Average
+		case aggregationCount:
+			v = data[i].Count
+		case aggregationMaximum:
+			v = data[i].Maximum
+		case aggregationMinimum:
+			v = data[i].Minimum
+		case aggregationTotal:
+			v = data[i].Total
+		}
+
+		if v != nil {
+			return *v, true
+		}
+	}
+
+	return 0, false
+}
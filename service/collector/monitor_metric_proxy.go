@@ -0,0 +1,270 @@
+package collector
+
+import (
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/yaml"
+
+	"github.com/giantswarm/azure-collector/v2/service/collector/key"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	monitorMetricProxySubsystem = "monitor"
+)
+
+var (
+	monitorMetricNameRegexp  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	monitorMetricLabelRegexp = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+	monitorMetricAggregations = []string{
+		aggregationAverage,
+		aggregationCount,
+		aggregationMaximum,
+		aggregationMinimum,
+		aggregationTotal,
+	}
+)
+
+// MonitorMetricDefinitions is the content of the YAML file configuring the
+// Azure Monitor metrics which are re-exported by the MonitorMetricProxy
+// collector, e.g.
+//
+//	metrics:
+//	- name: load_balancer_snat_connections
+//	  help: Number of SNAT connections of the load balancer.
+//	  resourceType: Microsoft.Network/loadBalancers
+//	  metric: SnatConnectionCount
+//	  aggregation: Total
+//	  dimensions:
+//	  - ConnectionState
+type MonitorMetricDefinitions struct {
+	Metrics []MonitorMetricDefinition `json:"metrics"`
+}
+
+// MonitorMetricDefinition maps a single Azure Monitor metric of all resources
+// of the given type in the managed resource groups to a Prometheus metric
+// named "azure_operator_monitor_<name>".
+type MonitorMetricDefinition struct {
+	Name         string   `json:"name"`
+	Help         string   `json:"help"`
+	ResourceType string   `json:"resourceType"`
+	Metric       string   `json:"metric"`
+	Aggregation  string   `json:"aggregation"`
+	Dimensions   []string `json:"dimensions"`
+}
+
+type MonitorMetricProxyConfig struct {
+	CredentialSource          *credential.Source
+	Collections               *Collections
+	Logger                    micrologger.Logger
+	ControlPlaneResourceGroup string
+	GSTenantID                string
+
+	Definitions MonitorMetricDefinitions
+}
+
+type MonitorMetricProxy struct {
+	credentialSource          *credential.Source
+	collections               *Collections
+	logger                    micrologger.Logger
+	controlPlaneResourceGroup string
+	gsTenantID                string
+
+	definitions []MonitorMetricDefinition
+	descs       []*prometheus.Desc
+}
+
+// NewMonitorMetricProxy exposes arbitrary Azure Monitor metrics of the resources in the managed resource groups as
+// configured in the given definitions, so new signals do not require code changes.
+func NewMonitorMetricProxy(config MonitorMetricProxyConfig) (*MonitorMetricProxy, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.ControlPlaneResourceGroup == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ControlPlaneResourceGroup must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	var descs []*prometheus.Desc
+	names := map[string]bool{}
+	for i, d := range config.Definitions.Metrics {
+		err := validateMonitorMetricDefinition(d, names)
+		if err != nil {
+			return nil, microerror.Maskf(invalidConfigError, "%T.Definitions.Metrics[%d] is invalid: %s", config, i, err)
+		}
+		names[d.Name] = true
+
+		labels := []string{"resource_group", "resource"}
+		for _, dimension := range d.Dimensions {
+			labels = append(labels, dimensionLabelName(dimension))
+		}
+
+		help := d.Help
+		if help == "" {
+			help = "Azure Monitor metric " + d.Metric + " of " + d.ResourceType + " resources."
+		}
+
+		descs = append(descs, prometheus.NewDesc(
+			prometheus.BuildFQName(MetricsNamespace, monitorMetricProxySubsystem, d.Name),
+			help,
+			labels,
+			nil,
+		))
+	}
+
+	m := &MonitorMetricProxy{
+		credentialSource:          config.CredentialSource,
+		collections:               config.Collections,
+		logger:                    config.Logger,
+		controlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+		gsTenantID:                config.GSTenantID,
+
+		definitions: config.Definitions.Metrics,
+		descs:       descs,
+	}
+
+	return m, nil
+}
+
+func (m *MonitorMetricProxy) Collect(ch chan<- prometheus.Metric) error {
+	if len(m.definitions) == 0 {
+		return nil
+	}
+
+	ctx, cancel := m.collections.newCollectContext("monitor_metric_proxy")
+	defer cancel()
+
+	resourceGroups, err := getManagedResourceGroups(ctx, m.credentialSource, m.gsTenantID, m.controlPlaneResourceGroup)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, resourceGroup := range resourceGroups {
+		// Resources are listed once per type, even when several metrics are
+		// configured for it.
+		resourcesByType := map[string][]string{}
+
+		for i, d := range m.definitions {
+			resourceIDs, ok := resourcesByType[d.ResourceType]
+			if !ok {
+				resources, err := getResourcesByType(ctx, resourceGroup.AzureClientSet.ResourcesClient, resourceGroup.Name, d.ResourceType)
+				if IsNotFound(err) {
+					break
+				} else if err != nil {
+					return microerror.Mask(err)
+				}
+
+				for _, r := range resources {
+					resourceIDs = append(resourceIDs, to.String(r.ID))
+				}
+				resourcesByType[d.ResourceType] = resourceIDs
+			}
+
+			var filters []string
+			for _, dimension := range d.Dimensions {
+				filters = append(filters, dimension+" eq '*'")
+			}
+
+			for _, resourceID := range resourceIDs {
+				values, err := getMonitorMetricValues(ctx, resourceGroup.AzureClientSet.MetricsClient, resourceID, []string{d.Metric}, d.Aggregation, strings.Join(filters, " and "))
+				if err != nil {
+					m.logger.Errorf(ctx, err, "an error occurred fetching Azure Monitor metric %#q of resource %#q", d.Metric, resourceID)
+					continue
+				}
+
+				for _, v := range values {
+					labelValues := []string{resourceGroup.Name, key.ResourceNameFromID(resourceID)}
+					for _, dimension := range d.Dimensions {
+						labelValues = append(labelValues, v.Dimension(dimension))
+					}
+
+					ch <- prometheus.MustNewConstMetric(
+						m.descs[i],
+						prometheus.GaugeValue,
+						v.Value,
+						labelValues...,
+					)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (m *MonitorMetricProxy) Describe(ch chan<- *prometheus.Desc) error {
+	for _, desc := range m.descs {
+		ch <- desc
+	}
+	return nil
+}
+
+// LoadMonitorMetricDefinitions reads the Azure Monitor metric definitions from
+// the YAML file found at the given path.
+func LoadMonitorMetricDefinitions(path string) (MonitorMetricDefinitions, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return MonitorMetricDefinitions{}, microerror.Mask(err)
+	}
+
+	var definitions MonitorMetricDefinitions
+	err = yaml.UnmarshalStrict(b, &definitions)
+	if err != nil {
+		return MonitorMetricDefinitions{}, microerror.Mask(err)
+	}
+
+	return definitions, nil
+}
+
+// dimensionLabelName turns Azure Monitor dimension names like
+// "ConnectionState" or "API Name" into Prometheus label names like
+// "connectionstate" or "api_name".
+func dimensionLabelName(dimension string) string {
+	return strings.ToLower(monitorMetricLabelRegexp.ReplaceAllString(dimension, "_"))
+}
+
+// validateMonitorMetricDefinition validates the given definition. names are
+// the names of the definitions validated before, which it must not reuse.
+func validateMonitorMetricDefinition(d MonitorMetricDefinition, names map[string]bool) error {
+	if !monitorMetricNameRegexp.MatchString(d.Name) {
+		return microerror.Maskf(invalidConfigError, "name %#q is not a valid Prometheus metric name", d.Name)
+	}
+	if names[d.Name] {
+		return microerror.Maskf(invalidConfigError, "name %#q must not be used by more than one metric", d.Name)
+	}
+	if d.ResourceType == "" {
+		return microerror.Maskf(invalidConfigError, "resourceType must not be empty")
+	}
+	if d.Metric == "" {
+		return microerror.Maskf(invalidConfigError, "metric must not be empty")
+	}
+	if !inArray(monitorMetricAggregations, d.Aggregation) {
+		return microerror.Maskf(invalidConfigError, "aggregation must be one of %s", strings.Join(monitorMetricAggregations, ", "))
+	}
+
+	labels := map[string]bool{"resource_group": true, "resource": true}
+	for _, dimension := range d.Dimensions {
+		label := dimensionLabelName(dimension)
+		if label == "" || labels[label] {
+			return microerror.Maskf(invalidConfigError, "dimension %#q results in a duplicate or empty label", dimension)
+		}
+		labels[label] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package collector
+
+import (
+	"strconv"
+	"testing"
+)
+
+func Test_validateMonitorMetricDefinition(t *testing.T) {
+	testCases := []struct {
+		name         string
+		definition   MonitorMetricDefinition
+		names        []string
+		errorMatcher func(error) bool
+	}{
+		{
+			name: "case 0: valid definition",
+			definition: MonitorMetricDefinition{
+				Name:         "load_balancer_snat_connections",
+				ResourceType: "Microsoft.Network/loadBalancers",
+				Metric:       "SnatConnectionCount",
+				Aggregation:  "Total",
+				Dimensions:   []string{"ConnectionState"},
+			},
+		},
+		{
+			name: "case 1: invalid metric name",
+			definition: MonitorMetricDefinition{
+				Name:         "load-balancer",
+				ResourceType: "Microsoft.Network/loadBalancers",
+				Metric:       "SnatConnectionCount",
+				Aggregation:  "Total",
+			},
+			errorMatcher: IsInvalidConfig,
+		},
+		{
+			name: "case 2: missing metric",
+			definition: MonitorMetricDefinition{
+				Name:         "load_balancer_snat_connections",
+				ResourceType: "Microsoft.Network/loadBalancers",
+				Aggregation:  "Total",
+			},
+			errorMatcher: IsInvalidConfig,
+		},
+		{
+			name: "case 3: unknown aggregation",
+			definition: MonitorMetricDefinition{
+				Name:         "load_balancer_snat_connections",
+				ResourceType: "Microsoft.Network/loadBalancers",
+				Metric:       "SnatConnectionCount",
+				Aggregation:  "Sum",
+			},
+			errorMatcher: IsInvalidConfig,
+		},
+		{
+			name: "case 4: dimension clashing with a built-in label",
+			definition: MonitorMetricDefinition{
+				Name:         "load_balancer_snat_connections",
+				ResourceType: "Microsoft.Network/loadBalancers",
+				Metric:       "SnatConnectionCount",
+				Aggregation:  "Total",
+				Dimensions:   []string{"Resource"},
+			},
+			errorMatcher: IsInvalidConfig,
+		},
+		{
+			name: "case 5: name of another definition",
+			definition: MonitorMetricDefinition{
+				Name:         "load_balancer_snat_connections",
+				ResourceType: "Microsoft.Network/loadBalancers",
+				Metric:       "AllocatedSnatPorts",
+				Aggregation:  "Average",
+			},
+			names:        []string{"load_balancer_snat_connections"},
+			errorMatcher: IsInvalidConfig,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			names := map[string]bool{}
+			for _, name := range tc.names {
+				names[name] = true
+			}
+
+			err := validateMonitorMetricDefinition(tc.definition, names)
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package collector
+
+import (
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+var (
+	networkUsageCurrentDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "network_usage", "current"),
+		"Current usage of network resource quotas, e.g. public IP addresses, load balancers, network interfaces, network security groups or route tables.",
+		[]string{
+			"name",
+			"subscription",
+			"location",
+		},
+		nil,
+	)
+	networkUsageLimitDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "network_usage", "limit"),
+		"Limit of network resource quotas, e.g. public IP addresses, load balancers, network interfaces, network security groups or route tables.",
+		[]string{
+			"name",
+			"subscription",
+			"location",
+		},
+		nil,
+	)
+)
+
+type NetworkUsageConfig struct {
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+
+	Location   string
+	GSTenantID string
+}
+
+type NetworkUsage struct {
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+
+	location   string
+	gsTenantID string
+}
+
+// NewNetworkUsage exposes the usage of the network resource quotas in the installation location. They block the
+// creation of clusters just like compute quotas do.
+// It exposes metrics for every subscription found in the "credential-*" secrets of the control plane.
+func NewNetworkUsage(config NetworkUsageConfig) (*NetworkUsage, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.Location == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Location must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	n := &NetworkUsage{
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		location:         config.Location,
+		gsTenantID:       config.GSTenantID,
+	}
+
+	return n, nil
+}
+
+func (n *NetworkUsage) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := n.collections.newCollectContext("network_usage")
+	defer cancel()
+	clientSets, err := credential.GetAzureClientSetsFromCredentialSecretsBySubscription(ctx, n.credentialSource, n.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for subscriptionID, azureClientSet := range clientSets {
+		iterator, err := azureClientSet.NetworkUsagesClient.ListComplete(ctx, n.location)
+		if err != nil {
+			n.logger.Errorf(ctx, err, "an error occurred fetching the network usage of subscription %#q", subscriptionID)
+			continue
+		}
+
+		for iterator.NotDone() {
+			usage := iterator.Value()
+
+			if usage.Name != nil && usage.CurrentValue != nil && usage.Limit != nil {
+				ch <- prometheus.MustNewConstMetric(
+					networkUsageCurrentDesc,
+					prometheus.GaugeValue,
+					float64(*usage.CurrentValue),
+					to.String(usage.Name.Value),
+					subscriptionID,
+					n.location,
+				)
+				ch <- prometheus.MustNewConstMetric(
+					networkUsageLimitDesc,
+					prometheus.GaugeValue,
+					float64(*usage.Limit),
+					to.String(usage.Name.Value),
+					subscriptionID,
+					n.location,
+				)
+			}
+
+			err = iterator.NextWithContext(ctx)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (n *NetworkUsage) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- networkUsageCurrentDesc
+	ch <- networkUsageLimitDesc
+	return nil
+}
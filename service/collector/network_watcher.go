@@ -0,0 +1,33 @@
+package collector
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-11-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+)
+
+// getNetworkWatchers returns the Network Watchers of the subscription which
+// are deployed in the given location. Azure creates one Network Watcher per
+// region, but nothing prevents users from creating more of them.
+func getNetworkWatchers(ctx context.Context, watchersClient *network.WatchersClient, location string) ([]network.Watcher, error) {
+	var watchers []network.Watcher
+
+	result, err := watchersClient.ListAll(ctx)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	if result.Value == nil {
+		return watchers, nil
+	}
+
+	for _, watcher := range *result.Value {
+		if strings.EqualFold(to.String(watcher.Location), location) {
+			watchers = append(watchers, watcher)
+		}
+	}
+
+	return watchers, nil
+}
@@ -0,0 +1,154 @@
+package collector
+
+import (
+	"context"
+
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+type NodePoolCostConfig struct {
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+
+	GSTenantID string
+	// TagLabels are the tags of the scale sets and cluster resource groups
+	// which are exposed as labels.
+	TagLabels []string
+}
+
+type NodePoolCost struct {
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+	retailPricer     *retailPricer
+
+	nodePoolHourlyCostDesc *prometheus.Desc
+	clusterHourlyCostDesc  *prometheus.Desc
+
+	gsTenantID string
+	tagLabels  []string
+}
+
+// NewNodePoolCost exposes the estimated hourly cost of the node pools and clusters, computed from the current number
+// of instances of the scale sets and the Azure retail prices of their VM sizes. Unlike Cost Management data, it follows
+// node count changes right away. Discounts like reservations are not taken into account. The configured tags of the
+// scale sets and cluster resource groups are exposed as labels.
+func NewNodePoolCost(config NodePoolCostConfig) (*NodePoolCost, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	tagLabelNames, err := costTagLabelNames(config.TagLabels)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	n := &NodePoolCost{
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		retailPricer:     newRetailPricer(config.CredentialSource.ClientFactory()),
+
+		nodePoolHourlyCostDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricsNamespace, "node_pool", "estimated_hourly_cost"),
+			"Estimated hourly cost of the node pool based on its current number of instances and the pay-as-you-go retail price of its VM size.",
+			append([]string{"cluster_id", "node_pool", "vm_size", "currency"}, tagLabelNames...),
+			nil,
+		),
+		clusterHourlyCostDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(MetricsNamespace, "cluster", "estimated_hourly_cost"),
+			"Estimated hourly cost of the VMs of the cluster based on their current number and the pay-as-you-go retail prices of their VM sizes.",
+			append([]string{"cluster_id", "currency"}, tagLabelNames...),
+			nil,
+		),
+
+		gsTenantID: config.GSTenantID,
+		tagLabels:  config.TagLabels,
+	}
+
+	return n, nil
+}
+
+func (n *NodePoolCost) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := n.collections.newCollectContext("node_pool_cost")
+	defer cancel()
+
+	clientSets, err := getClientSetsByCluster(ctx, n.credentialSource, n.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	forEachCluster(ctx, ch, clientSets, func(ctx context.Context, ch chan<- prometheus.Metric, clusterID string, azureClientSet *client.AzureClientSet) error {
+		scaleSets, err := listScaleSets(ctx, azureClientSet.VirtualMachineScaleSetsClient, clusterID)
+		if IsNotFound(err) {
+			return nil
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		// The cost is still exposed with empty tag values when the tags
+		// can't be looked up.
+		tagValues, err := getResourceGroupTagLabelValues(ctx, azureClientSet.GroupsClient, clusterID, n.tagLabels)
+		if err != nil {
+			n.logger.Errorf(ctx, err, "an error occurred fetching the tags of cluster %#q", clusterID)
+		}
+
+		clusterCosts := map[string]float64{}
+		for _, vmss := range scaleSets {
+			if vmss.Sku != nil {
+				vmSize := to.String(vmss.Sku.Name)
+
+				price, err := n.retailPricer.HourlyVMPrice(ctx, to.String(vmss.Location), vmSize)
+				if err != nil {
+					n.logger.Errorf(ctx, err, "an error occurred fetching the retail price of VM size %#q", vmSize)
+				} else {
+					cost := price.Amount * float64(to.Int64(vmss.Sku.Capacity))
+					clusterCosts[price.Currency] += cost
+
+					ch <- prometheus.MustNewConstMetric(
+						n.nodePoolHourlyCostDesc,
+						prometheus.GaugeValue,
+						cost,
+						append([]string{clusterID, to.String(vmss.Name), vmSize, price.Currency}, costTagLabelValues(n.tagLabels, vmss.Tags)...)...,
+					)
+				}
+			}
+		}
+
+		for currency, cost := range clusterCosts {
+			ch <- prometheus.MustNewConstMetric(
+				n.clusterHourlyCostDesc,
+				prometheus.GaugeValue,
+				cost,
+				append([]string{clusterID, currency}, tagValues...)...,
+			)
+		}
+
+		return nil
+	})
+
+	return nil
+}
+
+func (n *NodePoolCost) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- n.nodePoolHourlyCostDesc
+	ch <- n.clusterHourlyCostDesc
+	return nil
+}
@@ -0,0 +1,228 @@
+package collector
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	expcapiv1alpha3 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	// azureProviderIDPrefix is the prefix the Azure cloud provider adds to
+	// the resource ID of a VM to build the provider ID of its node.
+	azureProviderIDPrefix = "azure://"
+)
+
+var (
+	nodeVMSSDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "node", "vmss_info"),
+		"Mapping of Kubernetes nodes to the VMSS instances backing them. The provider_id label matches the one of kube_node_info.",
+		[]string{
+			"cluster_id",
+			"node",
+			"provider_id",
+			"vmss",
+			"instance_id",
+			"vm_size",
+			"zone",
+		},
+		nil,
+	)
+	nodeVMSSInstancesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "node", "vmss_instances"),
+		"Number of instances of the VMSS per availability zone.",
+		[]string{
+			"cluster_id",
+			"vmss",
+			"zone",
+		},
+		nil,
+	)
+)
+
+type NodeVMSSConfig struct {
+	CtrlClient       ctrlclient.Client
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+	GSTenantID       string
+}
+
+type NodeVMSS struct {
+	ctrlClient       ctrlclient.Client
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+	gsTenantID       string
+}
+
+// NewNodeVMSS exposes an info metric joining the Kubernetes node names with the VMSS instances backing them, so node
+// metrics can be joined with Azure metrics in PromQL.
+func NewNodeVMSS(config NodeVMSSConfig) (*NodeVMSS, error) {
+	if config.CtrlClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CtrlClient must not be empty", config)
+	}
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	n := &NodeVMSS{
+		ctrlClient:       config.CtrlClient,
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		gsTenantID:       config.GSTenantID,
+	}
+
+	return n, nil
+}
+
+func (n *NodeVMSS) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := n.collections.newCollectContext("node_vmss")
+	defer cancel()
+
+	machinePools := &expcapiv1alpha3.MachinePoolList{}
+	err := n.ctrlClient.List(ctx, machinePools, ctrlclient.InNamespace(metav1.NamespaceAll))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	nodeNames := nodeNamesByProviderID(machinePools.Items)
+
+	azureClientSets, err := getClientSetsByCluster(ctx, n.credentialSource, n.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	forEachCluster(ctx, ch, azureClientSets, func(ctx context.Context, ch chan<- prometheus.Metric, clusterID string, azureClientSet *client.AzureClientSet) error {
+		scaleSets, err := listScaleSets(ctx, azureClientSet.VirtualMachineScaleSetsClient, clusterID)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, vmss := range scaleSets {
+			vmssName := to.String(vmss.Name)
+
+			// Instances are processed page by page and every page is
+			// dropped before the next one is fetched, so only a single page,
+			// the instance counts and the metrics forEachCluster buffers are
+			// held in memory no matter how large the scale set is.
+			instances := map[string]int{}
+			page, err := azureClientSet.VirtualMachineScaleSetVMsClient.List(ctx, clusterID, vmssName, "", "", "")
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
+			for page.NotDone() {
+				for _, vm := range page.Values() {
+					providerID := azureProviderIDPrefix + to.String(vm.ID)
+
+					// Node pool nodes are found in the MachinePool CRs.
+					// The master nodes are not, but the Azure cloud
+					// provider names nodes after the computer name of
+					// their VM.
+					node, ok := nodeNames[strings.ToLower(providerID)]
+					if !ok {
+						node = vmComputerName(vm)
+					}
+
+					var vmSize string
+					if vm.Sku != nil {
+						vmSize = to.String(vm.Sku.Name)
+					}
+
+					var zone string
+					if vm.Zones != nil && len(*vm.Zones) > 0 {
+						zone = (*vm.Zones)[0]
+					}
+					instances[zone]++
+
+					ch <- prometheus.MustNewConstMetric(
+						nodeVMSSDesc,
+						prometheus.GaugeValue,
+						gaugeValue,
+						clusterID,
+						node,
+						providerID,
+						vmssName,
+						to.String(vm.InstanceID),
+						vmSize,
+						zone,
+					)
+				}
+
+				if err := page.NextWithContext(ctx); err != nil {
+					return microerror.Mask(err)
+				}
+			}
+
+			for zone, count := range instances {
+				ch <- prometheus.MustNewConstMetric(
+					nodeVMSSInstancesDesc,
+					prometheus.GaugeValue,
+					float64(count),
+					clusterID,
+					vmssName,
+					zone,
+				)
+			}
+		}
+
+		return nil
+	})
+
+	return nil
+}
+
+func (n *NodeVMSS) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- nodeVMSSDesc
+	ch <- nodeVMSSInstancesDesc
+	return nil
+}
+
+// nodeNamesByProviderID returns the names of the nodes of the given machine
+// pools keyed by their lower cased provider ID. The machine pool controller
+// builds the node references in the order of the provider ID list, so we
+// can only rely on it when all nodes have been found.
+func nodeNamesByProviderID(machinePools []expcapiv1alpha3.MachinePool) map[string]string {
+	nodeNames := map[string]string{}
+
+	for _, machinePool := range machinePools {
+		if len(machinePool.Spec.ProviderIDList) != len(machinePool.Status.NodeRefs) {
+			continue
+		}
+
+		for i, providerID := range machinePool.Spec.ProviderIDList {
+			nodeNames[strings.ToLower(providerID)] = machinePool.Status.NodeRefs[i].Name
+		}
+	}
+
+	return nodeNames
+}
+
+func vmComputerName(vm compute.VirtualMachineScaleSetVM) string {
+	if vm.VirtualMachineScaleSetVMProperties == nil || vm.OsProfile == nil {
+		return ""
+	}
+
+	return strings.ToLower(to.String(vm.OsProfile.ComputerName))
+}
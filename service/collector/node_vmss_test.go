@@ -0,0 +1,79 @@
+package collector
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	expcapiv1alpha3 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
+)
+
+func Test_nodeNamesByProviderID(t *testing.T) {
+	testCases := []struct {
+		name              string
+		machinePools      []expcapiv1alpha3.MachinePool
+		expectedNodeNames map[string]string
+	}{
+		{
+			name:              "case 0: no machine pools",
+			machinePools:      nil,
+			expectedNodeNames: map[string]string{},
+		},
+		{
+			name: "case 1: all nodes found",
+			machinePools: []expcapiv1alpha3.MachinePool{
+				newMachinePool(
+					[]string{
+						"azure:///subscriptions/s/resourceGroups/abc12/providers/Microsoft.Compute/virtualMachineScaleSets/nodepool-p1/virtualMachines/0",
+						"azure:///subscriptions/s/resourceGroups/abc12/providers/Microsoft.Compute/virtualMachineScaleSets/nodepool-p1/virtualMachines/3",
+					},
+					[]string{
+						"nodepool-p1-000000",
+						"nodepool-p1-000003",
+					},
+				),
+			},
+			expectedNodeNames: map[string]string{
+				"azure:///subscriptions/s/resourcegroups/abc12/providers/microsoft.compute/virtualmachinescalesets/nodepool-p1/virtualmachines/0": "nodepool-p1-000000",
+				"azure:///subscriptions/s/resourcegroups/abc12/providers/microsoft.compute/virtualmachinescalesets/nodepool-p1/virtualmachines/3": "nodepool-p1-000003",
+			},
+		},
+		{
+			name: "case 2: node not yet found is skipped",
+			machinePools: []expcapiv1alpha3.MachinePool{
+				newMachinePool(
+					[]string{
+						"azure:///subscriptions/s/resourceGroups/abc12/providers/Microsoft.Compute/virtualMachineScaleSets/nodepool-p1/virtualMachines/0",
+						"azure:///subscriptions/s/resourceGroups/abc12/providers/Microsoft.Compute/virtualMachineScaleSets/nodepool-p1/virtualMachines/1",
+					},
+					[]string{
+						"nodepool-p1-000001",
+					},
+				),
+			},
+			expectedNodeNames: map[string]string{},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			nodeNames := nodeNamesByProviderID(tc.machinePools)
+			if !cmp.Equal(nodeNames, tc.expectedNodeNames) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedNodeNames, nodeNames))
+			}
+		})
+	}
+}
+
+func newMachinePool(providerIDs []string, nodeNames []string) expcapiv1alpha3.MachinePool {
+	machinePool := expcapiv1alpha3.MachinePool{}
+	machinePool.Spec.ProviderIDList = providerIDs
+	for _, nodeName := range nodeNames {
+		machinePool.Status.NodeRefs = append(machinePool.Status.NodeRefs, corev1.ObjectReference{Name: nodeName})
+	}
+
+	return machinePool
+}
@@ -0,0 +1,256 @@
+package collector
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2019-05-01/resources"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/service/collector/key"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	networkAPIVersion = "2022-07-01"
+
+	// orphanedNetworkArtifactMinAge is how old an unattached network
+	// artifact has to be before we consider it orphaned. Artifacts are
+	// unattached for a short time while nodes are created or replaced.
+	orphanedNetworkArtifactMinAge = 24 * time.Hour
+
+	networkInterfaceType = "Microsoft.Network/networkInterfaces"
+	publicIPAddressType  = "Microsoft.Network/publicIPAddresses"
+	loadBalancerType     = "Microsoft.Network/loadBalancers"
+)
+
+// networkArtifactList holds the subset of the properties of network
+// interfaces, public IP addresses and load balancers we need to tell whether
+// they are attached to anything.
+type networkArtifactList struct {
+	NextLink string            `json:"nextLink"`
+	Value    []networkArtifact `json:"value"`
+}
+
+type networkArtifact struct {
+	ID         string `json:"id"`
+	Properties struct {
+		// Network interfaces.
+		VirtualMachine  *subResource `json:"virtualMachine"`
+		PrivateEndpoint *subResource `json:"privateEndpoint"`
+		// Public IP addresses.
+		IPConfiguration *subResource `json:"ipConfiguration"`
+		NatGateway      *subResource `json:"natGateway"`
+		// Load balancers.
+		BackendAddressPools []struct {
+			Properties struct {
+				BackendIPConfigurations []subResource `json:"backendIPConfigurations"`
+			} `json:"properties"`
+		} `json:"backendAddressPools"`
+	} `json:"properties"`
+}
+
+type subResource struct {
+	ID string `json:"id"`
+}
+
+var (
+	orphanedNetworkArtifactsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "network", "orphaned_artifacts"),
+		"Number of network interfaces, public IP addresses and load balancers which are not attached to anything for more than a day.",
+		[]string{
+			"subscription",
+			"type",
+		},
+		nil,
+	)
+)
+
+type OrphanedNetworkConfig struct {
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+
+	GSTenantID string
+}
+
+type OrphanedNetwork struct {
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+
+	gsTenantID string
+}
+
+// NewOrphanedNetwork exposes the number of network interfaces, public IP addresses and load balancers which are not
+// attached to any VM, VMSS or NAT gateway. They are left behind by node deletion bugs and count against the quotas.
+// It exposes metrics for every subscription found in the "credential-*" secrets of the control plane.
+func NewOrphanedNetwork(config OrphanedNetworkConfig) (*OrphanedNetwork, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	o := &OrphanedNetwork{
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		gsTenantID:       config.GSTenantID,
+	}
+
+	return o, nil
+}
+
+func (o *OrphanedNetwork) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := o.collections.newCollectContext("orphaned_network")
+	defer cancel()
+	clientSets, err := credential.GetAzureClientSetsFromCredentialSecretsBySubscription(ctx, o.credentialSource, o.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	now := time.Now()
+
+	for subscriptionID, azureClientSet := range clientSets {
+		for _, resourceType := range []string{networkInterfaceType, publicIPAddressType, loadBalancerType} {
+			// The network API does not return the creation time, so we get
+			// it from the resources API.
+			createdTimes, err := getCreatedTimes(ctx, azureClientSet.ResourcesClient, resourceType)
+			if err != nil {
+				o.logger.Errorf(ctx, err, "an error occurred listing %#q resources of subscription %#q", resourceType, subscriptionID)
+				continue
+			}
+
+			var artifacts []networkArtifact
+			if resourceType == loadBalancerType {
+				artifacts, err = listLoadBalancers(ctx, azureClientSet.RESTClient)
+			} else {
+				artifacts, err = listNetworkArtifacts(ctx, azureClientSet.RESTClient, subscriptionID, resourceType)
+			}
+			if err != nil {
+				o.logger.Errorf(ctx, err, "an error occurred listing %#q resources of subscription %#q", resourceType, subscriptionID)
+				continue
+			}
+
+			var count int
+			for _, a := range artifacts {
+				if !o.credentialSource.MatchResourceGroup(key.ResourceGroupFromID(a.ID)) {
+					continue
+				}
+
+				created, ok := createdTimes[strings.ToLower(a.ID)]
+				if !ok || now.Sub(created) < orphanedNetworkArtifactMinAge {
+					continue
+				}
+
+				if !isNetworkArtifactAttached(a) {
+					count++
+				}
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				orphanedNetworkArtifactsDesc,
+				prometheus.GaugeValue,
+				float64(count),
+				subscriptionID,
+				resourceType,
+			)
+		}
+	}
+
+	return nil
+}
+
+func (o *OrphanedNetwork) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- orphanedNetworkArtifactsDesc
+	return nil
+}
+
+// getCreatedTimes returns the creation time of all resources of the given
+// type in the subscription by their lower case ID.
+func getCreatedTimes(ctx context.Context, resourcesClient *resources.Client, resourceType string) (map[string]time.Time, error) {
+	result := map[string]time.Time{}
+
+	iterator, err := resourcesClient.ListComplete(ctx, "resourceType eq '"+resourceType+"'", "createdTime", nil)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	for iterator.NotDone() {
+		resource := iterator.Value()
+		if resource.CreatedTime != nil {
+			result[strings.ToLower(to.String(resource.ID))] = resource.CreatedTime.Time
+		}
+
+		err = iterator.NextWithContext(ctx)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	return result, nil
+}
+
+// listNetworkArtifacts returns all network resources of the given type in
+// the subscription.
+func listNetworkArtifacts(ctx context.Context, restClient *client.RESTClient, subscriptionID, resourceType string) ([]networkArtifact, error) {
+	var result []networkArtifact
+
+	var artifacts networkArtifactList
+	err := restClient.GetJSON(ctx, "/subscriptions/"+subscriptionID+"/providers/"+resourceType, networkAPIVersion, &artifacts)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	for {
+		result = append(result, artifacts.Value...)
+
+		if artifacts.NextLink == "" {
+			break
+		}
+
+		nextLink := artifacts.NextLink
+		artifacts = networkArtifactList{}
+		err = restClient.GetNextJSON(ctx, nextLink, &artifacts)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	return result, nil
+}
+
+// isNetworkArtifactAttached returns whether the network interface, public IP
+// address or load balancer is in use. Network interfaces of VMSS instances
+// are not listed by the network API, so we only see standalone ones.
+func isNetworkArtifactAttached(a networkArtifact) bool {
+	p := a.Properties
+
+	if p.VirtualMachine != nil || p.PrivateEndpoint != nil {
+		return true
+	}
+	if p.IPConfiguration != nil || p.NatGateway != nil {
+		return true
+	}
+	for _, pool := range p.BackendAddressPools {
+		if len(pool.Properties.BackendIPConfigurations) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
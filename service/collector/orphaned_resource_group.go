@@ -0,0 +1,229 @@
+package collector
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2019-05-01/resources"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	// These tags are set by azure-operator on the resource groups of the
+	// clusters.
+	clusterTagName      = "GiantSwarmCluster"
+	installationTagName = "GiantSwarmInstallation"
+)
+
+var (
+	orphanedResourceGroupDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "resource_group", "orphaned"),
+		"Set to 1 for every resource group tagged as belonging to a cluster of this installation which does not exist anymore.",
+		[]string{
+			"subscription",
+			"resource_group",
+			"cluster_id",
+			labelState,
+		},
+		nil,
+	)
+	missingResourceGroupDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "resource_group", "missing"),
+		"Set to 1 for every cluster whose resource group does not exist.",
+		[]string{
+			"subscription",
+			"cluster_id",
+		},
+		nil,
+	)
+)
+
+type OrphanedResourceGroupConfig struct {
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+
+	GSTenantID       string
+	InstallationName string
+}
+
+type OrphanedResourceGroup struct {
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+
+	gsTenantID       string
+	installationName string
+}
+
+// NewOrphanedResourceGroup exposes the cluster resource groups without a cluster on the management cluster, which
+// are leaked after failed deletions, and the clusters without a resource group.
+func NewOrphanedResourceGroup(config OrphanedResourceGroupConfig) (*OrphanedResourceGroup, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+	if config.InstallationName == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.InstallationName must not be empty", config)
+	}
+
+	o := &OrphanedResourceGroup{
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		gsTenantID:       config.GSTenantID,
+		installationName: config.InstallationName,
+	}
+
+	return o, nil
+}
+
+func (o *OrphanedResourceGroup) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := o.collections.newCollectContext("orphaned_resource_group")
+	defer cancel()
+
+	// The clusters are taken from all AzureConfig CRs, so the resource
+	// groups of clusters whose credentials can't be used are not reported
+	// as orphaned.
+	crs, err := credential.GetAzureConfigs(ctx, o.credentialSource)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	clusters := map[string]bool{}
+	for _, cr := range crs {
+		clusters[cr.GetName()] = true
+	}
+
+	// The client sets of the clusters are only used to look up their
+	// subscriptions.
+	clusterClientSets, err := getClientSetsByCluster(ctx, o.credentialSource, o.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	subscriptionClientSets, err := credential.GetAzureClientSetsFromCredentialSecretsBySubscription(ctx, o.credentialSource, o.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	// The resource groups found per subscription, so we can tell which
+	// clusters are missing one.
+	groupsBySubscription := map[string]map[string]bool{}
+
+	for subscriptionID, azureClientSet := range subscriptionClientSets {
+		groups, err := listResourceGroups(ctx, o.credentialSource, azureClientSet.GroupsClient)
+		if err != nil {
+			o.logger.Errorf(ctx, err, "an error occurred listing the resource groups of subscription %#q", subscriptionID)
+			continue
+		}
+
+		groupsBySubscription[subscriptionID] = map[string]bool{}
+		for _, group := range groups {
+			groupsBySubscription[subscriptionID][to.String(group.Name)] = true
+		}
+
+		for _, group := range findOrphanedResourceGroups(groups, clusters, o.installationName) {
+			ch <- prometheus.MustNewConstMetric(
+				orphanedResourceGroupDesc,
+				prometheus.GaugeValue,
+				gaugeValue,
+				subscriptionID,
+				to.String(group.Name),
+				to.String(group.Tags[clusterTagName]),
+				getState(group),
+			)
+		}
+	}
+
+	for clusterID, azureClientSet := range clusterClientSets {
+		subscriptionID := azureClientSet.RESTClient.SubscriptionID
+
+		groups, ok := groupsBySubscription[subscriptionID]
+		if !ok {
+			// We failed listing the resource groups of the subscription.
+			continue
+		}
+
+		// The resource group of a cluster is named after the cluster ID.
+		if groups[clusterID] {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			missingResourceGroupDesc,
+			prometheus.GaugeValue,
+			gaugeValue,
+			subscriptionID,
+			clusterID,
+		)
+	}
+
+	return nil
+}
+
+func (o *OrphanedResourceGroup) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- orphanedResourceGroupDesc
+	ch <- missingResourceGroupDesc
+	return nil
+}
+
+// listResourceGroups returns the resource groups of the subscription matching
+// the resource group filter of the given source.
+func listResourceGroups(ctx context.Context, source *credential.Source, groupsClient *resources.GroupsClient) ([]resources.Group, error) {
+	var result []resources.Group
+
+	groups, err := listAllResourceGroups(ctx, groupsClient)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	for _, group := range groups {
+		if source.MatchResourceGroup(to.String(group.Name)) {
+			result = append(result, group)
+		}
+	}
+
+	return result, nil
+}
+
+// findOrphanedResourceGroups returns the resource groups tagged with a
+// cluster which is not found in the given clusters. Resource groups tagged
+// with another installation are ignored, as installations can share a
+// subscription.
+func findOrphanedResourceGroups(groups []resources.Group, clusters map[string]bool, installationName string) []resources.Group {
+	var orphaned []resources.Group
+
+	for _, group := range groups {
+		clusterID := to.String(group.Tags[clusterTagName])
+		if clusterID == "" {
+			continue
+		}
+
+		installation := to.String(group.Tags[installationTagName])
+		if installation != "" && installation != installationName {
+			continue
+		}
+
+		if clusters[clusterID] {
+			continue
+		}
+
+		orphaned = append(orphaned, group)
+	}
+
+	return orphaned
+}
@@ -0,0 +1,83 @@
+package collector
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2019-05-01/resources"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_findOrphanedResourceGroups(t *testing.T) {
+	groups := []resources.Group{
+		{
+			Name: to.StringPtr("ab1c2"),
+			Tags: map[string]*string{
+				"GiantSwarmCluster":      to.StringPtr("ab1c2"),
+				"GiantSwarmInstallation": to.StringPtr("godsmack"),
+			},
+		},
+		{
+			Name: to.StringPtr("de3f4"),
+			Tags: map[string]*string{
+				"GiantSwarmCluster":      to.StringPtr("de3f4"),
+				"GiantSwarmInstallation": to.StringPtr("godsmack"),
+			},
+		},
+		{
+			Name: to.StringPtr("gh5i6"),
+			Tags: map[string]*string{
+				"GiantSwarmCluster":      to.StringPtr("gh5i6"),
+				"GiantSwarmInstallation": to.StringPtr("ghost"),
+			},
+		},
+		{
+			Name: to.StringPtr("jk7l8"),
+			Tags: map[string]*string{
+				"GiantSwarmCluster": to.StringPtr("jk7l8"),
+			},
+		},
+		{
+			Name: to.StringPtr("godsmack"),
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		clusters       map[string]bool
+		expectedGroups []string
+	}{
+		{
+			name: "case 0: all clusters exist",
+			clusters: map[string]bool{
+				"ab1c2": true,
+				"de3f4": true,
+				"jk7l8": true,
+			},
+			expectedGroups: nil,
+		},
+		{
+			name: "case 1: clusters were deleted",
+			clusters: map[string]bool{
+				"ab1c2": true,
+			},
+			expectedGroups: []string{"de3f4", "jk7l8"},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			var names []string
+			for _, group := range findOrphanedResourceGroups(groups, tc.clusters, "godsmack") {
+				names = append(names, to.String(group.Name))
+			}
+
+			if !cmp.Equal(names, tc.expectedGroups) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedGroups, names))
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package collector
+
+import (
+	"context"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/service/collector/key"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	policyInsightsAPIVersion = "2019-10-01"
+
+	policyComplianceStateCompliant = "compliant"
+)
+
+// policyStatesSummary holds the subset of the policy states summary we need.
+type policyStatesSummary struct {
+	Value []struct {
+		Results           policyStatesSummaryResults `json:"results"`
+		PolicyAssignments []struct {
+			PolicyAssignmentID string                     `json:"policyAssignmentId"`
+			Results            policyStatesSummaryResults `json:"results"`
+		} `json:"policyAssignments"`
+	} `json:"value"`
+}
+
+type policyStatesSummaryResults struct {
+	NonCompliantResources int `json:"nonCompliantResources"`
+	ResourceDetails       []struct {
+		ComplianceState string `json:"complianceState"`
+		Count           int    `json:"count"`
+	} `json:"resourceDetails"`
+}
+
+var (
+	policyNonCompliantResourcesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "policy", "noncompliant_resources"),
+		"Number of resources of the cluster resource group which are not compliant with the policy assignment.",
+		[]string{
+			"cluster_id",
+			"policy_assignment",
+		},
+		nil,
+	)
+	policyComplianceRatioDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "policy", "compliance_ratio"),
+		"Ratio of resources of the cluster resource group which are compliant with all policy assignments.",
+		[]string{
+			"cluster_id",
+		},
+		nil,
+	)
+)
+
+type PolicyComplianceConfig struct {
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+
+	GSTenantID string
+}
+
+type PolicyCompliance struct {
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+
+	gsTenantID string
+}
+
+// NewPolicyCompliance exposes the number of resources of the clusters which are not compliant with the Azure Policy
+// assignments, so customer policies blocking the provisioning of resources, e.g. tag requirements, become visible.
+func NewPolicyCompliance(config PolicyComplianceConfig) (*PolicyCompliance, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	p := &PolicyCompliance{
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		gsTenantID:       config.GSTenantID,
+	}
+
+	return p, nil
+}
+
+func (p *PolicyCompliance) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := p.collections.newCollectContext("policy_compliance")
+	defer cancel()
+
+	clientSets, err := getClientSetsByCluster(ctx, p.credentialSource, p.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	forEachCluster(ctx, ch, clientSets, func(ctx context.Context, ch chan<- prometheus.Metric, clusterID string, azureClientSet *client.AzureClientSet) error {
+		restClient := azureClientSet.RESTClient
+
+		// The resource group of a cluster is named after the cluster ID.
+		path := "/subscriptions/" + restClient.SubscriptionID + "/resourceGroups/" + clusterID + "/providers/Microsoft.PolicyInsights/policyStates/latest/summarize"
+
+		var summary policyStatesSummary
+		err := restClient.PostJSON(ctx, path, policyInsightsAPIVersion, nil, &summary)
+		if IsNotFound(err) {
+			return nil
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, s := range summary.Value {
+			for _, assignment := range s.PolicyAssignments {
+				ch <- prometheus.MustNewConstMetric(
+					policyNonCompliantResourcesDesc,
+					prometheus.GaugeValue,
+					float64(assignment.Results.NonCompliantResources),
+					clusterID,
+					key.ResourceNameFromID(assignment.PolicyAssignmentID),
+				)
+			}
+
+			ratio, ok := policyComplianceRatio(s.Results)
+			if !ok {
+				continue
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				policyComplianceRatioDesc,
+				prometheus.GaugeValue,
+				ratio,
+				clusterID,
+			)
+		}
+
+		return nil
+	})
+
+	return nil
+}
+
+func (p *PolicyCompliance) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- policyNonCompliantResourcesDesc
+	ch <- policyComplianceRatioDesc
+	return nil
+}
+
+// policyComplianceRatio returns the ratio of compliant resources amongst all
+// evaluated resources. The second return value is false when no resources
+// were evaluated, in which case there is no ratio.
+func policyComplianceRatio(results policyStatesSummaryResults) (float64, bool) {
+	var compliant, total int
+	for _, d := range results.ResourceDetails {
+		if strings.EqualFold(d.ComplianceState, policyComplianceStateCompliant) {
+			compliant += d.Count
+		}
+		total += d.Count
+	}
+
+	if total == 0 {
+		return 0, false
+	}
+
+	return float64(compliant) / float64(total), true
+}
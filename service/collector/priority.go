@@ -0,0 +1,222 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/exporterkit/collector"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// pressureWindow is how long low priority collectors are skipped after
+	// their collection ran into its deadline, and how long their calls to a
+	// subscription are refused after Azure throttled a call to it.
+	pressureWindow = 10 * time.Minute
+)
+
+// priority is the priority class of a collector. Collectors of lower
+// priority are skipped first when the installation is under pressure.
+type priority int
+
+const (
+	// priorityCritical collectors, e.g. rate limits and credentials, are
+	// always collected, even from subscriptions whose ARM call budget is
+	// exhausted.
+	priorityCritical priority = iota
+	// priorityNormal collectors, e.g. VMSS and load balancers, are refused
+	// the calls to subscriptions whose ARM call budget is exhausted, see
+	// criticalCollections.
+	priorityNormal
+	// priorityLow collectors, e.g. cost, are refused those calls as well as
+	// the ones to subscriptions Azure recently throttled, see lowCollections.
+	// They are skipped for a while after their collection ran into its
+	// deadline.
+	priorityLow
+)
+
+func (p priority) String() string {
+	switch p {
+	case priorityCritical:
+		return "critical"
+	case priorityNormal:
+		return "normal"
+	case priorityLow:
+		return "low"
+	default:
+		return "unknown"
+	}
+}
+
+var (
+	collectionsSkippedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: MetricsNamespace,
+		Subsystem: "collection",
+		Name:      "skipped_total",
+		Help:      "Number of collections skipped to relieve the Azure APIs by collector and priority.",
+	}, []string{
+		"collector",
+		"priority",
+	})
+)
+
+// criticalCollections are the names of the collections of the critical
+// collectors making ARM calls, see newCollectContext. Their calls are made
+// even when the call budget of the subscription is exhausted.
+var criticalCollections = map[string]bool{
+	"credential_secret":   true,
+	"credential_validity": true,
+	"rate_limit":          true,
+	"sp_expiration":       true,
+	"vmss_rate_limit":     true,
+}
+
+// lowCollections are the names of the collections of the low priority
+// collectors, see newCollectContext. Their calls to a subscription are refused
+// for pressureWindow after Azure throttled a call to it.
+var lowCollections = map[string]bool{
+	"budget":             true,
+	"cluster_cost":       true,
+	"cost_anomaly":       true,
+	"egress_cost":        true,
+	"fleet_inventory":    true,
+	"guest_disk_usage":   true,
+	"marketplace_charge": true,
+	"node_pool_cost":     true,
+	"policy_compliance":  true,
+	"reservation":        true,
+	"savings_plan":       true,
+	"secure_score":       true,
+	"spending_forecast":  true,
+	"tag_compliance":     true,
+}
+
+func init() {
+	prometheus.MustRegister(collectionsSkippedCounter)
+}
+
+// priorityGuard skips the collections of a collector when its priority is
+// lower than the one collected under the pressure it is under, i.e. when its
+// last collection ran into its deadline. The ARM call budget and throttled
+// subscriptions are enforced per subscription by the Azure clients instead,
+// so collectors keep collecting the subscriptions which are not affected.
+type priorityGuard struct {
+	collections *Collections
+	collector   collector.Interface
+	logger      micrologger.Logger
+	priority    priority
+
+	mutex sync.Mutex
+	// lastTimeout is the time the last collection of the collector which
+	// ran into its deadline started.
+	lastTimeout time.Time
+}
+
+// withPriorities guards the given collectors with their priority. Collectors
+// are of normal priority unless they are found in critical or low.
+func withPriorities(collectors []collector.Interface, critical []collector.Interface, low []collector.Interface, collections *Collections, logger micrologger.Logger) []collector.Interface {
+	priorities := map[collector.Interface]priority{}
+	for _, c := range critical {
+		priorities[c] = priorityCritical
+	}
+	for _, c := range low {
+		priorities[c] = priorityLow
+	}
+
+	var guarded []collector.Interface
+	for _, c := range collectors {
+		p, ok := priorities[c]
+		if !ok {
+			p = priorityNormal
+		}
+
+		// Critical collectors are always collected, so there is no need
+		// to guard them.
+		if p != priorityCritical {
+			c = &priorityGuard{
+				collections: collections,
+				collector:   c,
+				logger:      logger,
+				priority:    p,
+			}
+		}
+
+		guarded = append(guarded, c)
+	}
+
+	return guarded
+}
+
+func (g *priorityGuard) Collect(ch chan<- prometheus.Metric) error {
+	started := time.Now()
+	if !g.collected(started) {
+		name := collectorName(g.collector)
+		collectionsSkippedCounter.WithLabelValues(name, g.priority.String()).Inc()
+
+		g.logger.Debugf(context.Background(), "skipping collection of %s as its collection recently ran into its deadline", name)
+
+		// Skipped collections are not failures, see reportingCollector.
+		return microerror.Mask(collectionSkippedError)
+	}
+
+	deadline, ok := g.collections.collectDeadline(started, g.collections.getScrape())
+
+	err := g.collector.Collect(ch)
+
+	if ok && !time.Now().Before(deadline) {
+		g.mutex.Lock()
+		g.lastTimeout = started
+		g.mutex.Unlock()
+	}
+
+	return err
+}
+
+// collected returns whether the collector is collected at the given time
+// under the pressure it is under.
+func (g *priorityGuard) collected(now time.Time) bool {
+	g.mutex.Lock()
+	lastTimeout := g.lastTimeout
+	g.mutex.Unlock()
+
+	return g.priority <= collectedPriority(now, lastTimeout)
+}
+
+func (g *priorityGuard) Describe(ch chan<- *prometheus.Desc) error {
+	return g.collector.Describe(ch)
+}
+
+// collectorName returns the name of the given collector in metrics and
+// reports, e.g. "collector.Usage".
+func collectorName(c collector.Interface) string {
+	if l, ok := c.(*lastKnownGoodCollector); ok {
+		c = l.collector
+	}
+	if d, ok := c.(*dataAgeCollector); ok {
+		c = d.collector
+	}
+	if d, ok := c.(*disabledGuard); ok {
+		c = d.collector
+	}
+	if g, ok := c.(*priorityGuard); ok {
+		c = g.collector
+	}
+
+	return strings.TrimPrefix(fmt.Sprintf("%T", c), "*")
+}
+
+// collectedPriority returns the lowest priority of a collector which is still
+// collected given the time its last collection ran into its deadline.
+func collectedPriority(now time.Time, lastTimeout time.Time) priority {
+	switch {
+	case now.Sub(lastTimeout) < pressureWindow:
+		return priorityNormal
+	default:
+		return priorityLow
+	}
+}
@@ -0,0 +1,45 @@
+package collector
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_collectedPriority(t *testing.T) {
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name             string
+		lastTimeout      time.Time
+		expectedPriority priority
+	}{
+		{
+			name:             "case 0: no pressure",
+			expectedPriority: priorityLow,
+		},
+		{
+			name:             "case 1: recently timed out",
+			lastTimeout:      now.Add(-time.Minute),
+			expectedPriority: priorityNormal,
+		},
+		{
+			name:             "case 2: timed out long ago",
+			lastTimeout:      now.Add(-time.Hour),
+			expectedPriority: priorityLow,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			p := collectedPriority(now, tc.lastTimeout)
+			if !cmp.Equal(p, tc.expectedPriority) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedPriority, p))
+			}
+		})
+	}
+}
@@ -1,17 +1,14 @@
 package collector
 
 import (
-	"context"
 	"fmt"
 	"strconv"
 
 	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2019-05-01/resources"
 	"github.com/Azure/go-autorest/autorest/to"
-	"github.com/giantswarm/apiextensions/v2/pkg/clientset/versioned"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	"github.com/prometheus/client_golang/prometheus"
-	"k8s.io/client-go/kubernetes"
 
 	"github.com/giantswarm/azure-collector/v2/pkg/project"
 	"github.com/giantswarm/azure-collector/v2/service/credential"
@@ -58,19 +55,19 @@ var (
 )
 
 type RateLimitConfig struct {
-	G8sClient  versioned.Interface
-	K8sClient  kubernetes.Interface
-	Logger     micrologger.Logger
-	Location   string
-	GSTenantID string
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+	Location         string
+	GSTenantID       string
 }
 
 type RateLimit struct {
-	g8sClient  versioned.Interface
-	k8sClient  kubernetes.Interface
-	logger     micrologger.Logger
-	location   string
-	gsTenantID string
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+	location         string
+	gsTenantID       string
 }
 
 func init() {
@@ -82,11 +79,11 @@ func init() {
 // It creates and fetches a resource group. That way it can inspect the Azure API response to find rate limit headers.
 // It uses the credentials found in the "credential-*" secrets of the control plane.
 func NewRateLimit(config RateLimitConfig) (*RateLimit, error) {
-	if config.G8sClient == nil {
-		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
 	}
-	if config.K8sClient == nil {
-		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
 	}
 	if config.Logger == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
@@ -99,20 +96,21 @@ func NewRateLimit(config RateLimitConfig) (*RateLimit, error) {
 	}
 
 	u := &RateLimit{
-		g8sClient:  config.G8sClient,
-		k8sClient:  config.K8sClient,
-		logger:     config.Logger,
-		location:   config.Location,
-		gsTenantID: config.GSTenantID,
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		location:         config.Location,
+		gsTenantID:       config.GSTenantID,
 	}
 
 	return u, nil
 }
 
 func (u *RateLimit) Collect(ch chan<- prometheus.Metric) error {
-	ctx := context.Background()
+	ctx, cancel := u.collections.newCollectContext("rate_limit")
+	defer cancel()
 
-	clientSets, err := credential.GetAzureClientSetsFromCredentialSecrets(ctx, u.k8sClient, u.gsTenantID)
+	clientSets, err := credential.GetAzureClientSetsFromCredentialSecrets(ctx, u.credentialSource, u.gsTenantID)
 	if err != nil {
 		return microerror.Mask(err)
 	}
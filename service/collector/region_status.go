@@ -0,0 +1,255 @@
+package collector
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+)
+
+const (
+	azureStatusFeedURL     = "https://azurestatuscdn.azureedge.net/en-us/status/feed/"
+	azureStatusFeedTimeout = 10 * time.Second
+)
+
+// regionStatusServices maps the services the clusters depend on to the
+// phrases identifying them in the status feed.
+var regionStatusServices = map[string][]string{
+	"compute": {"compute", "virtual machine"},
+	"network": {"network", "load balancer", "vpn gateway", "expressroute", "dns"},
+	"storage": {"storage", "disk"},
+}
+
+// regionQualifiers are the words preceding the display name of a region to
+// name another one, e.g. "North" in "North Central US".
+var regionQualifiers = map[string]bool{
+	"central": true,
+	"east":    true,
+	"north":   true,
+	"south":   true,
+	"west":    true,
+}
+
+// azureStatusFeed is the RSS feed of the Azure status page.
+type azureStatusFeed struct {
+	Channel struct {
+		Items []azureStatusFeedItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type azureStatusFeedItem struct {
+	Title       string `xml:"title"`
+	Description string `xml:"description"`
+}
+
+var (
+	regionStatusIncidentsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "region_status", "incidents"),
+		"Number of ongoing incidents on the Azure status page affecting the service in the region.",
+		[]string{
+			"region",
+			"service",
+		},
+		nil,
+	)
+	regionStatusErrorCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{Namespace: MetricsNamespace, Subsystem: "region_status", Name: "scrape_error",
+			Help: "Total number of times fetching the Azure status feed returned an error.",
+		})
+)
+
+type RegionStatusConfig struct {
+	ClientFactory *client.Factory
+	Collections   *Collections
+	Logger        micrologger.Logger
+
+	Location string
+}
+
+type RegionStatus struct {
+	collections *Collections
+	logger      micrologger.Logger
+
+	httpClient        *http.Client
+	regionStatusError prometheus.Counter
+
+	location string
+}
+
+func init() {
+	prometheus.MustRegister(regionStatusErrorCounter)
+}
+
+// NewRegionStatus exposes the ongoing incidents published on the Azure status page which affect the compute, network
+// and storage services of the installation location, for fast correlation during outages.
+func NewRegionStatus(config RegionStatusConfig) (*RegionStatus, error) {
+	if config.ClientFactory == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ClientFactory must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.Location == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Location must not be empty", config)
+	}
+
+	r := &RegionStatus{
+		collections: config.Collections,
+		logger:      config.Logger,
+
+		httpClient:        config.ClientFactory.NewHTTPClient(azureStatusFeedTimeout),
+		regionStatusError: regionStatusErrorCounter,
+
+		location: config.Location,
+	}
+
+	return r, nil
+}
+
+func (r *RegionStatus) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := r.collections.newCollectContext("region_status")
+	defer cancel()
+
+	items, err := r.getFeedItems(ctx)
+	if err != nil {
+		r.logger.Errorf(ctx, err, "an error occurred fetching the Azure status feed")
+		r.regionStatusError.Inc()
+		return nil
+	}
+
+	for service, incidents := range countRegionIncidents(items, r.location) {
+		ch <- prometheus.MustNewConstMetric(
+			regionStatusIncidentsDesc,
+			prometheus.GaugeValue,
+			float64(incidents),
+			r.location,
+			service,
+		)
+	}
+
+	return nil
+}
+
+func (r *RegionStatus) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- regionStatusIncidentsDesc
+	return nil
+}
+
+func (r *RegionStatus) getFeedItems(ctx context.Context) ([]azureStatusFeedItem, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, azureStatusFeedURL, nil)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, microerror.Maskf(executionFailedError, "expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	items, err := parseAzureStatusFeed(resp.Body)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return items, nil
+}
+
+// parseAzureStatusFeed returns the items of the Azure status RSS feed. The
+// feed only contains ongoing incidents.
+func parseAzureStatusFeed(r io.Reader) ([]azureStatusFeedItem, error) {
+	var feed azureStatusFeed
+	err := xml.NewDecoder(r).Decode(&feed)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return feed.Channel.Items, nil
+}
+
+// countRegionIncidents returns the number of incidents affecting the given
+// location per service. Every service is returned, even without incidents.
+func countRegionIncidents(items []azureStatusFeedItem, location string) map[string]int {
+	counts := map[string]int{}
+	for service := range regionStatusServices {
+		counts[service] = 0
+	}
+
+	for _, item := range items {
+		text := strings.ToLower(item.Title + " " + item.Description)
+		if !mentionsLocation(text, location) {
+			continue
+		}
+
+		for service, phrases := range regionStatusServices {
+			for _, phrase := range phrases {
+				if strings.Contains(text, phrase) {
+					counts[service]++
+					break
+				}
+			}
+		}
+	}
+
+	return counts
+}
+
+// mentionsLocation returns whether the given text mentions the given location
+// by its display name, e.g. "West US" for westus. The words of the display
+// name have to match exactly, so the display names of other regions
+// containing it, e.g. "West US 2" or "North Central US" for centralus, don't
+// count.
+func mentionsLocation(text, location string) bool {
+	location = normalizeLocation(location)
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i := range words {
+		if i > 0 && regionQualifiers[words[i-1]] {
+			continue
+		}
+
+		var name string
+		for j := i; j < len(words) && len(name) < len(location); j++ {
+			name += words[j]
+			if name != location {
+				continue
+			}
+
+			// Regions are numbered when there are several of the
+			// same name, e.g. West US 2.
+			if j+1 < len(words) && isNumber(words[j+1]) {
+				break
+			}
+
+			return true
+		}
+	}
+
+	return false
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
@@ -0,0 +1,85 @@
+package collector
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_countRegionIncidents(t *testing.T) {
+	testCases := []struct {
+		name           string
+		feed           string
+		location       string
+		expectedCounts map[string]int
+	}{
+		{
+			name:     "case 0: empty feed",
+			feed:     `<rss version="2.0"><channel><title>Azure Status</title></channel></rss>`,
+			location: "westeurope",
+			expectedCounts: map[string]int{
+				"compute": 0,
+				"network": 0,
+				"storage": 0,
+			},
+		},
+		{
+			name: "case 1: incidents in several regions",
+			feed: `<rss version="2.0"><channel>
+<item><title>Virtual Machines - West Europe - Investigating</title><description>Customers using Virtual Machines and Managed Disks in West Europe may experience failures.</description></item>
+<item><title>Azure DNS - North Europe</title><description>Customers in North Europe may experience DNS resolution failures.</description></item>
+</channel></rss>`,
+			location: "westeurope",
+			expectedCounts: map[string]int{
+				"compute": 1,
+				"network": 0,
+				"storage": 1,
+			},
+		},
+		{
+			name: "case 2: regions whose display name contains the one of the location",
+			feed: `<rss version="2.0"><channel>
+<item><title>Virtual Machines - West US 2</title><description>Customers using Virtual Machines in West US 2 may experience failures.</description></item>
+<item><title>Storage - North Central US, South Central US</title><description>Customers in North Central US and South Central US may experience storage failures.</description></item>
+<item><title>Load Balancer - Central US</title><description>Customers in Central US may experience connectivity issues.</description></item>
+</channel></rss>`,
+			location: "centralus",
+			expectedCounts: map[string]int{
+				"compute": 0,
+				"network": 1,
+				"storage": 0,
+			},
+		},
+		{
+			name: "case 3: numbered regions",
+			feed: `<rss version="2.0"><channel>
+<item><title>Virtual Machines - West US 2</title><description>Customers using Virtual Machines in West US 2 may experience failures.</description></item>
+<item><title>Azure DNS - West US</title><description>Customers in West US may experience DNS resolution failures.</description></item>
+</channel></rss>`,
+			location: "westus",
+			expectedCounts: map[string]int{
+				"compute": 0,
+				"network": 1,
+				"storage": 0,
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			items, err := parseAzureStatusFeed(strings.NewReader(tc.feed))
+			if err != nil {
+				t.Fatalf("error == %#v, want nil", err)
+			}
+
+			counts := countRegionIncidents(items, tc.location)
+			if !cmp.Equal(counts, tc.expectedCounts) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedCounts, counts))
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package collector
+
+import (
+	"runtime/debug"
+	"time"
+
+	"github.com/giantswarm/exporterkit/collector"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/service/errorreport"
+)
+
+// reportingCollector reports the panics and repeated failures of a collector
+// to the error tracker, if configured, and counts the outcomes of its
+// collections for the success ratios. Skipped collections are neither.
+type reportingCollector struct {
+	collector collector.Interface
+	name      string
+}
+
+// withErrorReports reports the panics and repeated failures of the given
+// collectors.
+func withErrorReports(collectors []collector.Interface) []collector.Interface {
+	var reporting []collector.Interface
+	for _, c := range collectors {
+		reporting = append(reporting, &reportingCollector{
+			collector: c,
+			name:      collectorName(c),
+		})
+	}
+
+	return reporting
+}
+
+func (r *reportingCollector) Collect(ch chan<- prometheus.Metric) error {
+	// Panics are reported and raised again, so they are not hidden.
+	defer func() {
+		if v := recover(); v != nil {
+			errorreport.ReportPanic(r.name, v, debug.Stack())
+			panic(v)
+		}
+	}()
+
+	err := r.collector.Collect(ch)
+	if IsCollectionSkipped(err) {
+		return nil
+	} else if err != nil {
+		collectorOutcomes.record(r.name, false, time.Now())
+		errorreport.ReportFailure(r.name, err)
+		return err
+	}
+
+	collectorOutcomes.record(r.name, true, time.Now())
+	errorreport.ReportSuccess(r.name)
+
+	return nil
+}
+
+func (r *reportingCollector) Describe(ch chan<- *prometheus.Desc) error {
+	return r.collector.Describe(ch)
+}
@@ -0,0 +1,247 @@
+package collector
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/service/collector/key"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	capacityAPIVersion = "2022-11-01"
+)
+
+// reservationOrderList holds the subset of the reservation order properties
+// we need.
+type reservationOrderList struct {
+	NextLink string `json:"nextLink"`
+	Value    []struct {
+		Name string `json:"name"`
+	} `json:"value"`
+}
+
+// reservationSummaryList holds the subset of the reservation summary
+// properties we need.
+type reservationSummaryList struct {
+	Value []struct {
+		Properties struct {
+			ReservationID            string  `json:"reservationId"`
+			SkuName                  string  `json:"skuName"`
+			ReservedHours            float64 `json:"reservedHours"`
+			UsedHours                float64 `json:"usedHours"`
+			AvgUtilizationPercentage float64 `json:"avgUtilizationPercentage"`
+		} `json:"properties"`
+	} `json:"value"`
+}
+
+var (
+	reservationUtilizationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "reservation", "utilization_percent"),
+		"Average utilization of the reserved instance on the previous day.",
+		[]string{
+			"reservation_order",
+			"reservation",
+			"vm_size",
+		},
+		nil,
+	)
+	reservationUnusedHoursDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "reservation", "unused_hours"),
+		"Number of reserved hours of the reserved instance which were not used on the previous day.",
+		[]string{
+			"reservation_order",
+			"reservation",
+			"vm_size",
+		},
+		nil,
+	)
+)
+
+type ReservationConfig struct {
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+
+	GSTenantID string
+}
+
+type Reservation struct {
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+
+	gsTenantID string
+}
+
+// NewReservation exposes the utilization of the reserved instances which apply to the VM sizes used by the clusters,
+// so wasted reservations become visible.
+func NewReservation(config ReservationConfig) (*Reservation, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	r := &Reservation{
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		gsTenantID:       config.GSTenantID,
+	}
+
+	return r, nil
+}
+
+func (r *Reservation) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := r.collections.newCollectContext("reservation")
+	defer cancel()
+
+	clientSets, err := getClientSetsByCluster(ctx, r.credentialSource, r.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	// Reservations are bought per tenant, not per subscription, so we only
+	// need one client per subscription to find all the ones we have access
+	// to.
+	restClients := map[string]*client.RESTClient{}
+	vmSizes := map[string]bool{}
+	var mutex sync.Mutex
+	forEachCluster(ctx, ch, clientSets, func(ctx context.Context, ch chan<- prometheus.Metric, clusterID string, azureClientSet *client.AzureClientSet) error {
+		mutex.Lock()
+		restClients[azureClientSet.RESTClient.SubscriptionID] = azureClientSet.RESTClient
+		mutex.Unlock()
+
+		scaleSets, err := listScaleSets(ctx, azureClientSet.VirtualMachineScaleSetsClient, clusterID)
+		if IsNotFound(err) {
+			return nil
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		for _, vmss := range scaleSets {
+			if vmss.Sku != nil {
+				vmSizes[strings.ToLower(to.String(vmss.Sku.Name))] = true
+			}
+		}
+
+		return nil
+	})
+
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format("2006-01-02")
+	filter := "properties/usageDate ge " + yesterday + " and properties/usageDate le " + yesterday
+
+	seen := map[string]bool{}
+	for subscriptionID, restClient := range restClients {
+		orders, err := getReservationOrders(ctx, restClient)
+		if err != nil {
+			r.logger.Errorf(ctx, err, "an error occurred listing the reservation orders with the credentials of subscription %#q", subscriptionID)
+			continue
+		}
+
+		for _, order := range orders {
+			if seen[order] {
+				continue
+			}
+			seen[order] = true
+
+			parameters := map[string]interface{}{
+				"grain":   "daily",
+				"$filter": autorest.Encode("query", filter),
+			}
+
+			var summaries reservationSummaryList
+			err := restClient.GetJSONWithParameters(ctx, "/providers/Microsoft.Capacity/reservationorders/"+order+"/providers/Microsoft.Consumption/reservationSummaries", consumptionAPIVersion, parameters, &summaries)
+			if err != nil {
+				r.logger.Errorf(ctx, err, "an error occurred fetching the summaries of reservation order %#q", order)
+				continue
+			}
+
+			for _, summary := range summaries.Value {
+				p := summary.Properties
+				if !vmSizes[strings.ToLower(p.SkuName)] {
+					continue
+				}
+
+				reservation := key.ResourceNameFromID(p.ReservationID)
+
+				ch <- prometheus.MustNewConstMetric(
+					reservationUtilizationDesc,
+					prometheus.GaugeValue,
+					p.AvgUtilizationPercentage,
+					order,
+					reservation,
+					p.SkuName,
+				)
+				ch <- prometheus.MustNewConstMetric(
+					reservationUnusedHoursDesc,
+					prometheus.GaugeValue,
+					p.ReservedHours-p.UsedHours,
+					order,
+					reservation,
+					p.SkuName,
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *Reservation) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- reservationUtilizationDesc
+	ch <- reservationUnusedHoursDesc
+	return nil
+}
+
+// getReservationOrders returns the IDs of the reservation orders the client
+// has access to.
+func getReservationOrders(ctx context.Context, restClient *client.RESTClient) ([]string, error) {
+	var result []string
+
+	var orders reservationOrderList
+	err := restClient.GetJSON(ctx, "/providers/Microsoft.Capacity/reservationOrders", capacityAPIVersion, &orders)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	for {
+		for _, order := range orders.Value {
+			result = append(result, order.Name)
+		}
+
+		if orders.NextLink == "" {
+			break
+		}
+
+		nextLink := orders.NextLink
+		orders = reservationOrderList{}
+		err = restClient.GetNextJSON(ctx, nextLink, &orders)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	return result, nil
+}
@@ -2,18 +2,40 @@ package collector
 
 import (
 	"context"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2019-05-01/resources"
+	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/sync/errgroup"
-	"k8s.io/client-go/kubernetes"
 
+	"github.com/giantswarm/azure-collector/v2/client"
 	"github.com/giantswarm/azure-collector/v2/service/credential"
 )
 
+const (
+	// resourceGroupLimit is the maximum number of resource groups per
+	// subscription.
+	resourceGroupLimit = 980
+	// resourceGroupAPIVersion is the first API version returning the
+	// creation time of resource groups.
+	resourceGroupAPIVersion = "2019-08-01"
+)
+
+// resourceGroupList holds the subset of the resource group properties we
+// need which are not supported by the SDK.
+type resourceGroupList struct {
+	NextLink string `json:"nextLink"`
+	Value    []struct {
+		Name        string             `json:"name"`
+		Tags        map[string]*string `json:"tags"`
+		CreatedTime time.Time          `json:"createdTime"`
+	} `json:"value"`
+}
+
 const (
 	labelID        = "id"
 	labelName      = "name"
@@ -35,27 +57,58 @@ var (
 		},
 		nil,
 	)
+	resourceGroupCountDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "resource_group", "count"),
+		"Number of resource groups in the subscription.",
+		[]string{
+			"subscription",
+		},
+		nil,
+	)
+	resourceGroupLimitDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "resource_group", "limit"),
+		"Maximum number of resource groups allowed in the subscription.",
+		[]string{
+			"subscription",
+		},
+		nil,
+	)
+	resourceGroupCreatedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "resource_group", "created"),
+		"Creation date of the cluster resource group in Unix time.",
+		[]string{
+			"subscription",
+			"resource_group",
+			"cluster_id",
+		},
+		nil,
+	)
 
 	gaugeValue float64 = 1
 )
 
 type ResourceGroupConfig struct {
-	K8sClient  kubernetes.Interface
-	Logger     micrologger.Logger
-	GSTenantID string
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+	GSTenantID       string
 }
 
 type ResourceGroup struct {
-	k8sClient  kubernetes.Interface
-	logger     micrologger.Logger
-	gsTenantID string
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+	gsTenantID       string
 }
 
 // NewResourceGroup exposes metrics on the existing resource groups for every subscription.
 // It exposes metrcis about the subscriptions found in the "credential-*" secrets of the control plane.
 func NewResourceGroup(config ResourceGroupConfig) (*ResourceGroup, error) {
-	if config.K8sClient == nil {
-		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
 	}
 	if config.Logger == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
@@ -65,28 +118,36 @@ func NewResourceGroup(config ResourceGroupConfig) (*ResourceGroup, error) {
 	}
 
 	r := &ResourceGroup{
-		k8sClient:  config.K8sClient,
-		logger:     config.Logger,
-		gsTenantID: config.GSTenantID,
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		gsTenantID:       config.GSTenantID,
 	}
 
 	return r, nil
 }
 
 func (r *ResourceGroup) Collect(ch chan<- prometheus.Metric) error {
-	ctx := context.Background()
-	clientSets, err := credential.GetAzureClientSetsFromCredentialSecretsBySubscription(ctx, r.k8sClient, r.gsTenantID)
+	ctx, cancel := r.collections.newCollectContext("resource_group")
+	defer cancel()
+	clientSets, err := credential.GetAzureClientSetsFromCredentialSecretsBySubscription(ctx, r.credentialSource, r.gsTenantID)
 	if err != nil {
 		return microerror.Mask(err)
 	}
 
 	var g errgroup.Group
 
-	for _, item := range clientSets {
+	for id, item := range clientSets {
+		subscriptionID := id
 		clientSet := item
 
 		g.Go(func() error {
-			err := r.collectForClientSet(ctx, ch, clientSet.GroupsClient)
+			err := r.collectForClientSet(ctx, ch, subscriptionID, clientSet.GroupsClient)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+
+			err = r.collectCreatedForClientSet(ctx, ch, clientSet.RESTClient)
 			if err != nil {
 				return microerror.Mask(err)
 			}
@@ -102,26 +163,83 @@ func (r *ResourceGroup) Collect(ch chan<- prometheus.Metric) error {
 	return nil
 }
 
-func (r *ResourceGroup) collectForClientSet(ctx context.Context, ch chan<- prometheus.Metric, client *resources.GroupsClient) error {
-	resultsPage, err := client.ListComplete(context.Background(), "", nil)
+func (r *ResourceGroup) collectForClientSet(ctx context.Context, ch chan<- prometheus.Metric, subscriptionID string, client *resources.GroupsClient) error {
+	groups, err := listAllResourceGroups(ctx, client)
 	if err != nil {
 		return microerror.Mask(err)
 	}
 
-	for resultsPage.NotDone() {
-		group := resultsPage.Value()
-		ch <- prometheus.MustNewConstMetric(
-			resourceGroupDesc,
-			prometheus.GaugeValue,
-			gaugeValue,
-			to.String(group.ID),
-			to.String(group.Name),
-			getState(group),
-			to.String(group.Location),
-			to.String(group.ManagedBy),
-		)
-
-		if err := resultsPage.NextWithContext(ctx); err != nil {
+	// All resource groups count towards the subscription limit, but we only
+	// expose the ones matching the resource group filter.
+	for _, group := range groups {
+		if r.credentialSource.MatchResourceGroup(to.String(group.Name)) {
+			ch <- prometheus.MustNewConstMetric(
+				resourceGroupDesc,
+				prometheus.GaugeValue,
+				gaugeValue,
+				to.String(group.ID),
+				to.String(group.Name),
+				getState(group),
+				to.String(group.Location),
+				to.String(group.ManagedBy),
+			)
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		resourceGroupCountDesc,
+		prometheus.GaugeValue,
+		float64(len(groups)),
+		subscriptionID,
+	)
+	ch <- prometheus.MustNewConstMetric(
+		resourceGroupLimitDesc,
+		prometheus.GaugeValue,
+		resourceGroupLimit,
+		subscriptionID,
+	)
+
+	return nil
+}
+
+// collectCreatedForClientSet exposes the creation date of the cluster
+// resource groups, which are the ones tagged by azure-operator.
+func (r *ResourceGroup) collectCreatedForClientSet(ctx context.Context, ch chan<- prometheus.Metric, restClient *client.RESTClient) error {
+	parameters := map[string]interface{}{
+		"$expand": autorest.Encode("query", "createdTime"),
+	}
+
+	var groups resourceGroupList
+	err := restClient.GetJSONWithParameters(ctx, "/subscriptions/"+restClient.SubscriptionID+"/resourcegroups", resourceGroupAPIVersion, parameters, &groups)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for {
+		for _, group := range groups.Value {
+			clusterID := to.String(group.Tags[clusterTagName])
+			if clusterID == "" || group.CreatedTime.IsZero() || !r.credentialSource.MatchResourceGroup(group.Name) {
+				continue
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				resourceGroupCreatedDesc,
+				prometheus.GaugeValue,
+				float64(group.CreatedTime.Unix()),
+				restClient.SubscriptionID,
+				group.Name,
+				clusterID,
+			)
+		}
+
+		if groups.NextLink == "" {
+			break
+		}
+
+		nextLink := groups.NextLink
+		groups = resourceGroupList{}
+		err = restClient.GetNextJSON(ctx, nextLink, &groups)
+		if err != nil {
 			return microerror.Mask(err)
 		}
 	}
@@ -131,6 +249,9 @@ func (r *ResourceGroup) collectForClientSet(ctx context.Context, ch chan<- prome
 
 func (r *ResourceGroup) Describe(ch chan<- *prometheus.Desc) error {
 	ch <- resourceGroupDesc
+	ch <- resourceGroupCountDesc
+	ch <- resourceGroupLimitDesc
+	ch <- resourceGroupCreatedDesc
 
 	return nil
 }
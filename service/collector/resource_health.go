@@ -0,0 +1,170 @@
+package collector
+
+import (
+	"context"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/service/collector/key"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	resourceHealthAPIVersion = "2020-05-01"
+)
+
+// resourceHealthResourceTypes are the resource types of the clusters we track
+// the health of.
+var resourceHealthResourceTypes = []string{
+	"microsoft.compute/virtualmachinescalesets",
+	"microsoft.network/loadbalancers",
+	"microsoft.network/publicipaddresses",
+}
+
+// resourceHealthStatusList holds the subset of the availability status
+// properties we need.
+type resourceHealthStatusList struct {
+	NextLink string `json:"nextLink"`
+	Value    []struct {
+		ID         string `json:"id"`
+		Properties struct {
+			AvailabilityState string `json:"availabilityState"`
+			HealthEventCause  string `json:"healthEventCause"`
+			ReasonType        string `json:"reasonType"`
+		} `json:"properties"`
+	} `json:"value"`
+}
+
+var (
+	resourceHealthStatusDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "resource_health", "status"),
+		"Resource Health availability status of the cluster resource. The cause tells platform initiated from user initiated problems apart.",
+		[]string{
+			"cluster_id",
+			"resource_type",
+			"resource",
+			"availability_state",
+			"reason_type",
+			"cause",
+		},
+		nil,
+	)
+)
+
+type ResourceHealthConfig struct {
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+
+	GSTenantID string
+}
+
+type ResourceHealth struct {
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+
+	gsTenantID string
+}
+
+// NewResourceHealth exposes the Resource Health availability status of the scale sets, load balancers and public IP
+// addresses of the clusters.
+func NewResourceHealth(config ResourceHealthConfig) (*ResourceHealth, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	r := &ResourceHealth{
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		gsTenantID:       config.GSTenantID,
+	}
+
+	return r, nil
+}
+
+func (r *ResourceHealth) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := r.collections.newCollectContext("resource_health")
+	defer cancel()
+
+	clientSets, err := getClientSetsByCluster(ctx, r.credentialSource, r.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	forEachCluster(ctx, ch, clientSets, func(ctx context.Context, ch chan<- prometheus.Metric, clusterID string, azureClientSet *client.AzureClientSet) error {
+		restClient := azureClientSet.RESTClient
+
+		// The resource group of a cluster is named after the cluster ID.
+		path := "/subscriptions/" + restClient.SubscriptionID + "/resourceGroups/" + clusterID + "/providers/Microsoft.ResourceHealth/availabilityStatuses"
+
+		var statuses resourceHealthStatusList
+		err := restClient.GetJSON(ctx, path, resourceHealthAPIVersion, &statuses)
+		if IsNotFound(err) {
+			return nil
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for {
+			for _, status := range statuses.Value {
+				resourceType := key.ResourceTypeFromID(status.ID)
+				if !inArray(resourceHealthResourceTypes, strings.ToLower(resourceType)) {
+					continue
+				}
+
+				// The ID of the availability status is a child of the ID of
+				// the resource, which we need to get the resource name from.
+				resourceID := status.ID
+				if i := strings.Index(strings.ToLower(resourceID), "/providers/microsoft.resourcehealth/"); i >= 0 {
+					resourceID = resourceID[:i]
+				}
+
+				ch <- prometheus.MustNewConstMetric(
+					resourceHealthStatusDesc,
+					prometheus.GaugeValue,
+					gaugeValue,
+					clusterID,
+					resourceType,
+					key.ResourceNameFromID(resourceID),
+					status.Properties.AvailabilityState,
+					status.Properties.ReasonType,
+					status.Properties.HealthEventCause,
+				)
+			}
+
+			if statuses.NextLink == "" {
+				return nil
+			}
+
+			nextLink := statuses.NextLink
+			statuses = resourceHealthStatusList{}
+			err = restClient.GetNextJSON(ctx, nextLink, &statuses)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		}
+	})
+
+	return nil
+}
+
+func (r *ResourceHealth) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- resourceHealthStatusDesc
+	return nil
+}
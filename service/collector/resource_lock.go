@@ -0,0 +1,175 @@
+package collector
+
+import (
+	"context"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/service/collector/key"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	locksAPIVersion = "2016-09-01"
+	lockIDInfix     = "/providers/Microsoft.Authorization/locks/"
+)
+
+// managementLockList holds the subset of the management lock properties we
+// need.
+type managementLockList struct {
+	NextLink string `json:"nextLink"`
+	Value    []struct {
+		ID         string `json:"id"`
+		Name       string `json:"name"`
+		Properties struct {
+			Level string `json:"level"`
+		} `json:"properties"`
+	} `json:"value"`
+}
+
+var (
+	resourceLockDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "resource_lock", "info"),
+		"Management locks on the managed resource groups and their resources. The resource label is empty for locks on the resource group itself.",
+		[]string{
+			"cluster_id",
+			"resource_group",
+			"resource",
+			"lock",
+			"level",
+		},
+		nil,
+	)
+)
+
+type ResourceLockConfig struct {
+	CredentialSource          *credential.Source
+	Collections               *Collections
+	Logger                    micrologger.Logger
+	ControlPlaneResourceGroup string
+	GSTenantID                string
+}
+
+type ResourceLock struct {
+	credentialSource          *credential.Source
+	collections               *Collections
+	logger                    micrologger.Logger
+	controlPlaneResourceGroup string
+	gsTenantID                string
+}
+
+// NewResourceLock exposes the CanNotDelete and ReadOnly management locks on the managed resource groups and their
+// resources. Locks applied by customers are a common reason for cluster deletions getting stuck.
+func NewResourceLock(config ResourceLockConfig) (*ResourceLock, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.ControlPlaneResourceGroup == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ControlPlaneResourceGroup must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	r := &ResourceLock{
+		credentialSource:          config.CredentialSource,
+		collections:               config.Collections,
+		logger:                    config.Logger,
+		controlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+		gsTenantID:                config.GSTenantID,
+	}
+
+	return r, nil
+}
+
+func (r *ResourceLock) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := r.collections.newCollectContext("resource_lock")
+	defer cancel()
+
+	resourceGroups, err := getManagedResourceGroups(ctx, r.credentialSource, r.gsTenantID, r.controlPlaneResourceGroup)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, resourceGroup := range resourceGroups {
+		locks, err := getManagementLocks(ctx, resourceGroup.AzureClientSet.RESTClient, resourceGroup.Name)
+		if IsNotFound(err) {
+			// The control plane resource group is not part of every
+			// subscription.
+			continue
+		} else if err != nil {
+			r.logger.Errorf(ctx, err, "an error occurred listing the management locks of resource group %#q", resourceGroup.Name)
+			continue
+		}
+
+		for _, lock := range locks.Value {
+			var resource string
+			{
+				scope := lock.ID
+				if i := strings.Index(strings.ToLower(scope), strings.ToLower(lockIDInfix)); i >= 0 {
+					scope = scope[:i]
+				}
+				if key.ResourceTypeFromID(scope) != "" {
+					resource = key.ResourceNameFromID(scope)
+				}
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				resourceLockDesc,
+				prometheus.GaugeValue,
+				gaugeValue,
+				resourceGroup.ClusterID,
+				resourceGroup.Name,
+				resource,
+				lock.Name,
+				lock.Properties.Level,
+			)
+		}
+	}
+
+	return nil
+}
+
+func (r *ResourceLock) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- resourceLockDesc
+	return nil
+}
+
+// getManagementLocks returns the management locks of the resource group,
+// including the ones on its resources.
+func getManagementLocks(ctx context.Context, restClient *client.RESTClient, resourceGroup string) (managementLockList, error) {
+	var result managementLockList
+
+	var locks managementLockList
+	err := restClient.GetJSON(ctx, "/subscriptions/"+restClient.SubscriptionID+"/resourceGroups/"+resourceGroup+"/providers/Microsoft.Authorization/locks", locksAPIVersion, &locks)
+	if err != nil {
+		return managementLockList{}, microerror.Mask(err)
+	}
+
+	for {
+		result.Value = append(result.Value, locks.Value...)
+
+		if locks.NextLink == "" {
+			break
+		}
+
+		nextLink := locks.NextLink
+		locks = managementLockList{}
+		err = restClient.GetNextJSON(ctx, nextLink, &locks)
+		if err != nil {
+			return managementLockList{}, microerror.Mask(err)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,196 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+)
+
+const (
+	retailPricesURL     = "https://prices.azure.com/api/retail/prices"
+	retailPricesTimeout = 30 * time.Second
+
+	// retailPricesRefreshInterval is how long prices are cached. Retail
+	// prices rarely change, while node counts change all the time.
+	retailPricesRefreshInterval = 24 * time.Hour
+)
+
+// retailPriceList is the response of the Azure Retail Prices API.
+type retailPriceList struct {
+	NextPageLink string            `json:"NextPageLink"`
+	Items        []retailPriceItem `json:"Items"`
+}
+
+type retailPriceItem struct {
+	CurrencyCode  string  `json:"currencyCode"`
+	RetailPrice   float64 `json:"retailPrice"`
+	UnitOfMeasure string  `json:"unitOfMeasure"`
+	SkuName       string  `json:"skuName"`
+	ProductName   string  `json:"productName"`
+}
+
+// retailPrice is the price of a single unit of measure, e.g. one hour of a
+// VM or one GB of data transfer.
+type retailPrice struct {
+	Currency string
+	Amount   float64
+}
+
+// retailPricer looks up pay-as-you-go prices from the Azure Retail Prices
+// API, which does not need authentication, and caches them.
+type retailPricer struct {
+	httpClient *http.Client
+
+	mutex   sync.Mutex
+	entries map[string]retailPricerEntry
+}
+
+type retailPricerEntry struct {
+	price   retailPrice
+	expires time.Time
+}
+
+func newRetailPricer(factory *client.Factory) *retailPricer {
+	return &retailPricer{
+		httpClient: factory.NewHTTPClient(retailPricesTimeout),
+		entries:    map[string]retailPricerEntry{},
+	}
+}
+
+// HourlyVMPrice returns the hourly pay-as-you-go price of a Linux VM of the
+// given size in the given location.
+func (p *retailPricer) HourlyVMPrice(ctx context.Context, location, vmSize string) (retailPrice, error) {
+	filter := "serviceName eq 'Virtual Machines' and priceType eq 'Consumption' and armRegionName eq '" + normalizeLocation(location) + "' and armSkuName eq '" + vmSize + "'"
+
+	price, err := p.price(ctx, filter, linuxVMPrice)
+	if err != nil {
+		return retailPrice{}, microerror.Mask(err)
+	}
+
+	return price, nil
+}
+
+// EgressPricePerGB returns the price of one GB of data transfer out to the
+// internet from the given location.
+func (p *retailPricer) EgressPricePerGB(ctx context.Context, location string) (retailPrice, error) {
+	filter := "serviceName eq 'Bandwidth' and priceType eq 'Consumption' and armRegionName eq '" + normalizeLocation(location) + "' and meterName eq 'Standard Data Transfer Out'"
+
+	price, err := p.price(ctx, filter, egressPrice)
+	if err != nil {
+		return retailPrice{}, microerror.Mask(err)
+	}
+
+	return price, nil
+}
+
+// price returns the price picked out of the price items matching the given
+// filter, unless a price for the filter is cached.
+func (p *retailPricer) price(ctx context.Context, filter string, pick func([]retailPriceItem) (retailPrice, bool)) (retailPrice, error) {
+	p.mutex.Lock()
+	entry, ok := p.entries[filter]
+	p.mutex.Unlock()
+
+	if ok && time.Now().Before(entry.expires) {
+		return entry.price, nil
+	}
+
+	var items []retailPriceItem
+	nextLink := retailPricesURL + "?" + url.Values{"$filter": []string{filter}}.Encode()
+	for nextLink != "" {
+		var prices retailPriceList
+		err := p.getJSON(ctx, nextLink, &prices)
+		if err != nil {
+			return retailPrice{}, microerror.Mask(err)
+		}
+
+		items = append(items, prices.Items...)
+		nextLink = prices.NextPageLink
+	}
+
+	price, ok := pick(items)
+	if !ok {
+		return retailPrice{}, microerror.Maskf(priceNotFoundError, "no retail price found for %#q", filter)
+	}
+
+	p.mutex.Lock()
+	p.entries[filter] = retailPricerEntry{
+		price:   price,
+		expires: time.Now().Add(retailPricesRefreshInterval),
+	}
+	p.mutex.Unlock()
+
+	return price, nil
+}
+
+func (p *retailPricer) getJSON(ctx context.Context, u string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return microerror.Maskf(executionFailedError, "expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(result)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// linuxVMPrice picks the hourly Linux pay-as-you-go price out of the price
+// items of a VM size. The API returns Windows, Spot and Low Priority prices
+// for the same VM size as well.
+func linuxVMPrice(items []retailPriceItem) (retailPrice, bool) {
+	for _, item := range items {
+		if item.UnitOfMeasure != "1 Hour" {
+			continue
+		}
+		if strings.Contains(item.ProductName, "Windows") {
+			continue
+		}
+		if strings.Contains(item.SkuName, "Spot") || strings.Contains(item.SkuName, "Low Priority") {
+			continue
+		}
+
+		return retailPrice{Currency: item.CurrencyCode, Amount: item.RetailPrice}, true
+	}
+
+	return retailPrice{}, false
+}
+
+// egressPrice picks the price per GB out of the tiered data transfer prices.
+// The first GBs of every month are free and the price drops with volume, so
+// we use the highest tier price to not underestimate cost.
+func egressPrice(items []retailPriceItem) (retailPrice, bool) {
+	var price retailPrice
+	var ok bool
+	for _, item := range items {
+		if item.UnitOfMeasure != "1 GB" {
+			continue
+		}
+
+		if !ok || item.RetailPrice > price.Amount {
+			price = retailPrice{Currency: item.CurrencyCode, Amount: item.RetailPrice}
+			ok = true
+		}
+	}
+
+	return price, ok
+}
@@ -0,0 +1,95 @@
+package collector
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_linuxVMPrice(t *testing.T) {
+	testCases := []struct {
+		name          string
+		items         []retailPriceItem
+		expectedPrice retailPrice
+		expectedOK    bool
+	}{
+		{
+			name:       "case 0: no prices",
+			items:      nil,
+			expectedOK: false,
+		},
+		{
+			name: "case 1: Windows, Spot and Linux prices",
+			items: []retailPriceItem{
+				{CurrencyCode: "USD", RetailPrice: 0.376, UnitOfMeasure: "1 Hour", SkuName: "D4s v3", ProductName: "Virtual Machines DSv3 Series Windows"},
+				{CurrencyCode: "USD", RetailPrice: 0.0384, UnitOfMeasure: "1 Hour", SkuName: "D4s v3 Spot", ProductName: "Virtual Machines DSv3 Series"},
+				{CurrencyCode: "USD", RetailPrice: 0.192, UnitOfMeasure: "1 Hour", SkuName: "D4s v3", ProductName: "Virtual Machines DSv3 Series"},
+			},
+			expectedPrice: retailPrice{Currency: "USD", Amount: 0.192},
+			expectedOK:    true,
+		},
+		{
+			name: "case 2: only Low Priority prices",
+			items: []retailPriceItem{
+				{CurrencyCode: "USD", RetailPrice: 0.0384, UnitOfMeasure: "1 Hour", SkuName: "D4s v3 Low Priority", ProductName: "Virtual Machines DSv3 Series"},
+			},
+			expectedOK: false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			price, ok := linuxVMPrice(tc.items)
+			if ok != tc.expectedOK {
+				t.Fatalf("ok == %t, want %t", ok, tc.expectedOK)
+			}
+
+			if !cmp.Equal(price, tc.expectedPrice) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedPrice, price))
+			}
+		})
+	}
+}
+
+func Test_egressPrice(t *testing.T) {
+	testCases := []struct {
+		name          string
+		items         []retailPriceItem
+		expectedPrice retailPrice
+		expectedOK    bool
+	}{
+		{
+			name:       "case 0: no prices",
+			items:      nil,
+			expectedOK: false,
+		},
+		{
+			name: "case 1: tiered prices",
+			items: []retailPriceItem{
+				{CurrencyCode: "USD", RetailPrice: 0, UnitOfMeasure: "1 GB"},
+				{CurrencyCode: "USD", RetailPrice: 0.087, UnitOfMeasure: "1 GB"},
+				{CurrencyCode: "USD", RetailPrice: 0.083, UnitOfMeasure: "1 GB"},
+			},
+			expectedPrice: retailPrice{Currency: "USD", Amount: 0.087},
+			expectedOK:    true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			price, ok := egressPrice(tc.items)
+			if ok != tc.expectedOK {
+				t.Fatalf("ok == %t, want %t", ok, tc.expectedOK)
+			}
+
+			if !cmp.Equal(price, tc.expectedPrice) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedPrice, price))
+			}
+		})
+	}
+}
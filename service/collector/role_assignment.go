@@ -0,0 +1,171 @@
+package collector
+
+import (
+	"context"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	authorizationAPIVersion = "2022-04-01"
+)
+
+// roleAssignmentList holds the subset of the role assignment properties we
+// need.
+type roleAssignmentList struct {
+	NextLink string `json:"nextLink"`
+	Value    []struct {
+		Properties struct {
+			Scope string `json:"scope"`
+		} `json:"properties"`
+	} `json:"value"`
+}
+
+var (
+	roleAssignmentCountDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "role_assignments", "current"),
+		"Number of role assignments in the subscription, including the ones of its resource groups and resources.",
+		[]string{
+			"subscription",
+		},
+		nil,
+	)
+	roleAssignmentLimitDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "role_assignments", "limit"),
+		"Maximum number of role assignments allowed in the subscription.",
+		[]string{
+			"subscription",
+		},
+		nil,
+	)
+)
+
+type RoleAssignmentConfig struct {
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+
+	GSTenantID string
+	// Limit is the maximum number of role assignments per subscription.
+	Limit int
+}
+
+type RoleAssignment struct {
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+
+	gsTenantID string
+	limit      int
+}
+
+// NewRoleAssignment exposes the number of role assignments per subscription against the limit. Every cluster adds
+// role assignments and reaching the limit breaks provisioning for the whole subscription.
+// It exposes metrics for every subscription found in the "credential-*" secrets of the control plane.
+func NewRoleAssignment(config RoleAssignmentConfig) (*RoleAssignment, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+	if config.Limit <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Limit must be greater than zero", config)
+	}
+
+	r := &RoleAssignment{
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		gsTenantID:       config.GSTenantID,
+		limit:            config.Limit,
+	}
+
+	return r, nil
+}
+
+func (r *RoleAssignment) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := r.collections.newCollectContext("role_assignment")
+	defer cancel()
+	clientSets, err := credential.GetAzureClientSetsFromCredentialSecretsBySubscription(ctx, r.credentialSource, r.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for subscriptionID, azureClientSet := range clientSets {
+		count, err := countRoleAssignments(ctx, azureClientSet.RESTClient, subscriptionID)
+		if err != nil {
+			r.logger.Errorf(ctx, err, "an error occurred counting the role assignments of subscription %#q", subscriptionID)
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			roleAssignmentCountDesc,
+			prometheus.GaugeValue,
+			float64(count),
+			subscriptionID,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			roleAssignmentLimitDesc,
+			prometheus.GaugeValue,
+			float64(r.limit),
+			subscriptionID,
+		)
+	}
+
+	return nil
+}
+
+func (r *RoleAssignment) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- roleAssignmentCountDesc
+	ch <- roleAssignmentLimitDesc
+	return nil
+}
+
+// countRoleAssignments returns the number of role assignments which count
+// against the limit of the subscription. The list contains assignments
+// inherited from management groups as well, which don't count.
+func countRoleAssignments(ctx context.Context, restClient *client.RESTClient, subscriptionID string) (int, error) {
+	prefix := strings.ToLower("/subscriptions/" + subscriptionID)
+
+	var assignments roleAssignmentList
+	err := restClient.GetJSON(ctx, "/subscriptions/"+subscriptionID+"/providers/Microsoft.Authorization/roleAssignments", authorizationAPIVersion, &assignments)
+	if err != nil {
+		return 0, microerror.Mask(err)
+	}
+
+	var count int
+	for {
+		for _, a := range assignments.Value {
+			if strings.HasPrefix(strings.ToLower(a.Properties.Scope), prefix) {
+				count++
+			}
+		}
+
+		if assignments.NextLink == "" {
+			break
+		}
+
+		nextLink := assignments.NextLink
+		assignments = roleAssignmentList{}
+		err = restClient.GetNextJSON(ctx, nextLink, &assignments)
+		if err != nil {
+			return 0, microerror.Mask(err)
+		}
+	}
+
+	return count, nil
+}
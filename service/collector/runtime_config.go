@@ -0,0 +1,54 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/giantswarm/exporterkit/collector"
+	"github.com/giantswarm/microerror"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RuntimeConfig are the settings of the collectors which can be changed while
+// the collector is running.
+type RuntimeConfig struct {
+	// CollectTimeout is the deadline of a single collection of every
+	// collector. Collections have no deadline when it is zero.
+	CollectTimeout time.Duration
+	// Disabled are the names of the collectors which are not collected,
+	// e.g. "collector.CostAnomaly".
+	Disabled []string
+	// StartSpread is the window the starts of the collections of a scrape
+	// are spread over. Collections start right away when it is zero.
+	StartSpread time.Duration
+}
+
+// disabledGuard skips the collections of a collector while it is disabled.
+type disabledGuard struct {
+	collections *Collections
+	collector   collector.Interface
+}
+
+// withDisabledGuards guards the given collectors, so they can be disabled
+// while the collector is running.
+func withDisabledGuards(collectors []collector.Interface, collections *Collections) []collector.Interface {
+	var guarded []collector.Interface
+	for _, c := range collectors {
+		guarded = append(guarded, &disabledGuard{collections: collections, collector: c})
+	}
+
+	return guarded
+}
+
+func (g *disabledGuard) Collect(ch chan<- prometheus.Metric) error {
+	name := collectorName(g.collector)
+	if g.collections.isDisabled(name) {
+		// Skipped collections are not failures, see reportingCollector.
+		return microerror.Maskf(collectionSkippedError, "collector %s is disabled", name)
+	}
+
+	return g.collector.Collect(ch)
+}
+
+func (g *disabledGuard) Describe(ch chan<- *prometheus.Desc) error {
+	return g.collector.Describe(ch)
+}
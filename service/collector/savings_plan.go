@@ -0,0 +1,190 @@
+package collector
+
+import (
+	"strings"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	costColumnMeterSubCategory  = "MeterSubCategory"
+	costColumnPricingModel      = "PricingModel"
+	costColumnResourceGroupName = "ResourceGroupName"
+
+	costMeterCategoryVirtualMachines = "Virtual Machines"
+	costPricingModelSavingsPlan      = "SavingsPlan"
+)
+
+var (
+	clusterComputeCostDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "cluster_compute_cost", "month_to_date"),
+		"Amortized cost of the virtual machines of all clusters in the subscription since the beginning of the month per VM family and pricing model, e.g. OnDemand, Reservation or SavingsPlan.",
+		[]string{
+			"subscription",
+			"vm_family",
+			"pricing_model",
+			"currency",
+		},
+		nil,
+	)
+	savingsPlanCoverageDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "savings_plan", "coverage_ratio"),
+		"Share of the amortized cost of the virtual machines of all clusters in the subscription which is covered by savings plans since the beginning of the month per VM family and currency.",
+		[]string{
+			"subscription",
+			"vm_family",
+			"currency",
+		},
+		nil,
+	)
+)
+
+type SavingsPlanConfig struct {
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+
+	GSTenantID string
+}
+
+type SavingsPlan struct {
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+	costQuerier      *costQuerier
+
+	gsTenantID string
+}
+
+// NewSavingsPlan exposes which share of the compute spend of the clusters is covered by savings plans in comparison
+// to pay-as-you-go, per subscription and VM family, to drive purchasing decisions.
+func NewSavingsPlan(config SavingsPlanConfig) (*SavingsPlan, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	s := &SavingsPlan{
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		costQuerier:      newCostQuerier(),
+		gsTenantID:       config.GSTenantID,
+	}
+
+	return s, nil
+}
+
+func (s *SavingsPlan) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := s.collections.newCollectContext("savings_plan")
+	defer cancel()
+
+	// We only count the cost of the resource groups of clusters, which are
+	// named after the cluster IDs.
+	crs, err := credential.GetAzureConfigs(ctx, s.credentialSource)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	clusterResourceGroups := map[string]bool{}
+	for _, cr := range crs {
+		clusterResourceGroups[strings.ToLower(cr.GetName())] = true
+	}
+
+	clientSets, err := credential.GetAzureClientSetsFromCredentialSecretsBySubscription(ctx, s.credentialSource, s.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	// Amortized cost spreads reservation and savings plan purchases across
+	// the resources which benefit from them, which is what coverage is about.
+	now := time.Now().UTC()
+	query := actualCostQuery(startOfMonth(now), now, costColumnResourceGroupName, costColumnMeterSubCategory, costColumnPricingModel)
+	query.Type = costTypeAmortized
+	query.Dataset.Granularity = ""
+	query.Dataset.Filter = &costQueryFilter{
+		Dimensions: &costQueryComparison{
+			Name:     costColumnMeterCategory,
+			Operator: "In",
+			Values:   []string{costMeterCategoryVirtualMachines},
+		},
+	}
+
+	for subscriptionID, azureClientSet := range clientSets {
+		rows, err := s.costQuerier.Query(ctx, azureClientSet.RESTClient, "/subscriptions/"+subscriptionID+"/providers/Microsoft.CostManagement/query", query)
+		if err != nil {
+			s.logger.Errorf(ctx, err, "an error occurred fetching the compute cost of subscription %#q", subscriptionID)
+			continue
+		}
+
+		// Costs in different currencies can't be added up, so coverage
+		// is computed per VM family and currency.
+		costs := map[[3]string]float64{}
+		totals := map[[2]string]float64{}
+		covered := map[[2]string]float64{}
+		for _, row := range rows {
+			if !clusterResourceGroups[strings.ToLower(row.String(costColumnResourceGroupName))] {
+				continue
+			}
+
+			family := row.String(costColumnMeterSubCategory)
+			pricingModel := row.String(costColumnPricingModel)
+			currency := row.String(costColumnCurrency)
+			cost := row.Float(costColumnCost)
+
+			costs[[3]string{family, pricingModel, currency}] += cost
+			totals[[2]string{family, currency}] += cost
+			if strings.EqualFold(pricingModel, costPricingModelSavingsPlan) {
+				covered[[2]string{family, currency}] += cost
+			}
+		}
+
+		for k, cost := range costs {
+			ch <- prometheus.MustNewConstMetric(
+				clusterComputeCostDesc,
+				prometheus.GaugeValue,
+				cost,
+				subscriptionID,
+				k[0],
+				k[1],
+				k[2],
+			)
+		}
+		for k, total := range totals {
+			if total == 0 {
+				continue
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				savingsPlanCoverageDesc,
+				prometheus.GaugeValue,
+				covered[k]/total,
+				subscriptionID,
+				k[0],
+				k[1],
+			)
+		}
+	}
+
+	return nil
+}
+
+func (s *SavingsPlan) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- clusterComputeCostDesc
+	ch <- savingsPlanCoverageDesc
+	return nil
+}
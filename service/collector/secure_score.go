@@ -0,0 +1,241 @@
+package collector
+
+import (
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	securityAPIVersion = "2020-01-01"
+
+	// secureScoreName is the name of the overall Defender for Cloud secure
+	// score of a subscription.
+	secureScoreName = "ascScore"
+
+	securityAssessmentStatusUnhealthy = "unhealthy"
+)
+
+// securityRecommendationSeverities are the severities of the recommendations
+// we count. Low severity recommendations are left out to keep noise down.
+var securityRecommendationSeverities = []string{
+	"high",
+	"medium",
+}
+
+// secureScore holds the subset of the secure score properties we need.
+type secureScore struct {
+	Properties struct {
+		Score struct {
+			Current float64 `json:"current"`
+			Max     float64 `json:"max"`
+		} `json:"score"`
+	} `json:"properties"`
+}
+
+// securityAssessmentList holds the subset of the security assessment
+// properties we need.
+type securityAssessmentList struct {
+	NextLink string `json:"nextLink"`
+	Value    []struct {
+		Properties struct {
+			Status struct {
+				Code string `json:"code"`
+			} `json:"status"`
+			Metadata struct {
+				Severity string `json:"severity"`
+			} `json:"metadata"`
+		} `json:"properties"`
+	} `json:"value"`
+}
+
+var (
+	secureScoreCurrentDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "secure_score", "current"),
+		"Current Defender for Cloud secure score of the subscription.",
+		[]string{
+			"subscription",
+		},
+		nil,
+	)
+	secureScoreMaxDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "secure_score", "max"),
+		"Maximum Defender for Cloud secure score the subscription can reach.",
+		[]string{
+			"subscription",
+		},
+		nil,
+	)
+	securityRecommendationsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "security", "recommendations"),
+		"Number of unhealthy Defender for Cloud assessments of resources in the resource group.",
+		[]string{
+			"subscription",
+			"resource_group",
+			"severity",
+		},
+		nil,
+	)
+)
+
+type SecureScoreConfig struct {
+	CredentialSource          *credential.Source
+	Collections               *Collections
+	Logger                    micrologger.Logger
+	ControlPlaneResourceGroup string
+	GSTenantID                string
+}
+
+type SecureScore struct {
+	credentialSource          *credential.Source
+	collections               *Collections
+	logger                    micrologger.Logger
+	controlPlaneResourceGroup string
+	gsTenantID                string
+}
+
+// NewSecureScore exposes the Defender for Cloud secure score of the subscriptions and the number of high and medium
+// severity recommendations for the managed resource groups.
+func NewSecureScore(config SecureScoreConfig) (*SecureScore, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.ControlPlaneResourceGroup == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ControlPlaneResourceGroup must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	s := &SecureScore{
+		credentialSource:          config.CredentialSource,
+		collections:               config.Collections,
+		logger:                    config.Logger,
+		controlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+		gsTenantID:                config.GSTenantID,
+	}
+
+	return s, nil
+}
+
+func (s *SecureScore) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := s.collections.newCollectContext("secure_score")
+	defer cancel()
+
+	resourceGroups, err := getManagedResourceGroups(ctx, s.credentialSource, s.gsTenantID, s.controlPlaneResourceGroup)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	subscriptions := map[string]bool{}
+	for _, resourceGroup := range resourceGroups {
+		restClient := resourceGroup.AzureClientSet.RESTClient
+
+		if !subscriptions[restClient.SubscriptionID] {
+			subscriptions[restClient.SubscriptionID] = true
+
+			var score secureScore
+			err := restClient.GetJSON(ctx, "/subscriptions/"+restClient.SubscriptionID+"/providers/Microsoft.Security/secureScores/"+secureScoreName, securityAPIVersion, &score)
+			if IsNotFound(err) {
+				// Defender for Cloud is not enabled for the subscription.
+			} else if err != nil {
+				s.logger.Errorf(ctx, err, "an error occurred fetching the secure score of subscription %#q", restClient.SubscriptionID)
+			} else {
+				ch <- prometheus.MustNewConstMetric(
+					secureScoreCurrentDesc,
+					prometheus.GaugeValue,
+					score.Properties.Score.Current,
+					restClient.SubscriptionID,
+				)
+				ch <- prometheus.MustNewConstMetric(
+					secureScoreMaxDesc,
+					prometheus.GaugeValue,
+					score.Properties.Score.Max,
+					restClient.SubscriptionID,
+				)
+			}
+		}
+
+		path := "/subscriptions/" + restClient.SubscriptionID + "/resourceGroups/" + resourceGroup.Name + "/providers/Microsoft.Security/assessments"
+		parameters := map[string]interface{}{
+			// The severity is part of the metadata, which is only returned
+			// when asked for.
+			"$expand": "metadata",
+		}
+
+		var assessments securityAssessmentList
+		err := restClient.GetJSONWithParameters(ctx, path, securityAPIVersion, parameters, &assessments)
+		if IsNotFound(err) {
+			continue
+		} else if err != nil {
+			s.logger.Errorf(ctx, err, "an error occurred fetching the security assessments of resource group %#q", resourceGroup.Name)
+			continue
+		}
+
+		counts := map[string]int{}
+		for _, severity := range securityRecommendationSeverities {
+			counts[severity] = 0
+		}
+
+		for {
+			for _, assessment := range assessments.Value {
+				if !strings.EqualFold(assessment.Properties.Status.Code, securityAssessmentStatusUnhealthy) {
+					continue
+				}
+
+				severity := strings.ToLower(assessment.Properties.Metadata.Severity)
+				if _, ok := counts[severity]; ok {
+					counts[severity]++
+				}
+			}
+
+			if assessments.NextLink == "" {
+				break
+			}
+
+			nextLink := assessments.NextLink
+			assessments = securityAssessmentList{}
+			err = restClient.GetNextJSON(ctx, nextLink, &assessments)
+			if err != nil {
+				s.logger.Errorf(ctx, err, "an error occurred fetching the security assessments of resource group %#q", resourceGroup.Name)
+				break
+			}
+		}
+
+		// Counts are only exposed when all pages were read, so they never
+		// drop because of a failing request.
+		if err != nil {
+			continue
+		}
+
+		for severity, count := range counts {
+			ch <- prometheus.MustNewConstMetric(
+				securityRecommendationsDesc,
+				prometheus.GaugeValue,
+				float64(count),
+				restClient.SubscriptionID,
+				resourceGroup.Name,
+				severity,
+			)
+		}
+	}
+
+	return nil
+}
+
+func (s *SecureScore) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- secureScoreCurrentDesc
+	ch <- secureScoreMaxDesc
+	ch <- securityRecommendationsDesc
+	return nil
+}
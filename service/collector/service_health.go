@@ -0,0 +1,193 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	serviceHealthAPIVersion = "2022-10-01"
+
+	// serviceHealthGlobalRegion is the region of events affecting services
+	// which are not bound to a region, like Azure Active Directory.
+	serviceHealthGlobalRegion = "global"
+)
+
+// serviceHealthEventList holds the subset of the Service Health event
+// properties we need.
+type serviceHealthEventList struct {
+	NextLink string `json:"nextLink"`
+	Value    []struct {
+		Name       string `json:"name"`
+		Properties struct {
+			EventType            string     `json:"eventType"`
+			Status               string     `json:"status"`
+			Title                string     `json:"title"`
+			ImpactStartTime      *time.Time `json:"impactStartTime"`
+			ImpactMitigationTime *time.Time `json:"impactMitigationTime"`
+			Impact               []struct {
+				ImpactedService string `json:"impactedService"`
+				ImpactedRegions []struct {
+					ImpactedRegion string `json:"impactedRegion"`
+				} `json:"impactedRegions"`
+			} `json:"impact"`
+		} `json:"properties"`
+	} `json:"value"`
+}
+
+var (
+	serviceHealthEventDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "service_health", "event"),
+		"Active or upcoming Service Health event affecting a service in the installation location.",
+		[]string{
+			"subscription",
+			"tracking_id",
+			"event_type",
+			"status",
+			"service",
+			"title",
+			"start",
+			"end",
+		},
+		nil,
+	)
+)
+
+type ServiceHealthConfig struct {
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+
+	Location   string
+	GSTenantID string
+}
+
+type ServiceHealth struct {
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+
+	location   string
+	gsTenantID string
+}
+
+// NewServiceHealth exposes the active and upcoming Service Health events, like planned maintenance, incidents and
+// health advisories, affecting the installation location. It exposes events for every subscription found in the
+// "credential-*" secrets of the control plane.
+func NewServiceHealth(config ServiceHealthConfig) (*ServiceHealth, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.Location == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Location must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	s := &ServiceHealth{
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		location:         config.Location,
+		gsTenantID:       config.GSTenantID,
+	}
+
+	return s, nil
+}
+
+func (s *ServiceHealth) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := s.collections.newCollectContext("service_health")
+	defer cancel()
+	clientSets, err := credential.GetAzureClientSetsFromCredentialSecretsBySubscription(ctx, s.credentialSource, s.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	location := normalizeLocation(s.location)
+	for subscriptionID, azureClientSet := range clientSets {
+		restClient := azureClientSet.RESTClient
+
+		// Without filter only active events are returned, which includes
+		// upcoming planned maintenance.
+		var events serviceHealthEventList
+		err := restClient.GetJSON(ctx, "/subscriptions/"+subscriptionID+"/providers/Microsoft.ResourceHealth/events", serviceHealthAPIVersion, &events)
+		if err != nil {
+			s.logger.Errorf(ctx, err, "an error occurred fetching the Service Health events of subscription %#q", subscriptionID)
+			continue
+		}
+
+		for {
+			for _, event := range events.Value {
+				p := event.Properties
+
+				var start, end string
+				if p.ImpactStartTime != nil {
+					start = p.ImpactStartTime.UTC().Format(time.RFC3339)
+				}
+				if p.ImpactMitigationTime != nil {
+					end = p.ImpactMitigationTime.UTC().Format(time.RFC3339)
+				}
+
+				for _, impact := range p.Impact {
+					var affected bool
+					for _, r := range impact.ImpactedRegions {
+						region := normalizeLocation(r.ImpactedRegion)
+						if region == location || region == serviceHealthGlobalRegion {
+							affected = true
+							break
+						}
+					}
+					if !affected {
+						continue
+					}
+
+					ch <- prometheus.MustNewConstMetric(
+						serviceHealthEventDesc,
+						prometheus.GaugeValue,
+						gaugeValue,
+						subscriptionID,
+						event.Name,
+						p.EventType,
+						p.Status,
+						impact.ImpactedService,
+						p.Title,
+						start,
+						end,
+					)
+				}
+			}
+
+			if events.NextLink == "" {
+				break
+			}
+
+			nextLink := events.NextLink
+			events = serviceHealthEventList{}
+			err = restClient.GetNextJSON(ctx, nextLink, &events)
+			if err != nil {
+				s.logger.Errorf(ctx, err, "an error occurred fetching the Service Health events of subscription %#q", subscriptionID)
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *ServiceHealth) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- serviceHealthEventDesc
+	return nil
+}
@@ -1,12 +1,16 @@
 package collector
 
 import (
+	"time"
+
 	"github.com/giantswarm/exporterkit/collector"
 	"github.com/giantswarm/k8sclient/v4/pkg/k8sclient"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 
+	"github.com/giantswarm/azure-collector/v2/pkg/featuregate"
 	"github.com/giantswarm/azure-collector/v2/service/collector/cluster"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
 )
 
 const (
@@ -14,11 +18,43 @@ const (
 )
 
 type SetConfig struct {
-	K8sClient                 k8sclient.Interface
+	CredentialSource          *credential.Source
 	Location                  string
 	Logger                    micrologger.Logger
 	ControlPlaneResourceGroup string
 	GSTenantID                string
+
+	// K8sClient is the client of the management cluster. The collectors
+	// reading other resources of it than the clusters and their credentials
+	// are left out when it is nil, e.g. in standalone mode.
+	K8sClient k8sclient.Interface
+	// CollectTimeout is the deadline of a single collection of every
+	// collector. Collections have no deadline when it is zero.
+	CollectTimeout time.Duration
+	// CostTagLabels are the Azure resource tags which are exposed as labels
+	// of the cost metrics.
+	CostTagLabels []string
+	// DiagnosticSettingsDestination is the resource ID diagnostic settings
+	// are expected to send to. Any destination is accepted when empty.
+	DiagnosticSettingsDestination string
+	// DiagnosticSettingsResourceTypes are the resource types expected to
+	// have diagnostic settings configured.
+	DiagnosticSettingsResourceTypes []string
+	// MonitorMetricsConfigFile is the path of the YAML file defining the
+	// Azure Monitor metrics to expose. No such metrics are exposed when empty.
+	MonitorMetricsConfigFile string
+	// RoleAssignmentsLimit is the maximum number of role assignments per
+	// subscription.
+	RoleAssignmentsLimit int
+	// StartSpread is the window the starts of the collections of a scrape
+	// are spread over. Collections start right away when it is zero.
+	StartSpread time.Duration
+	// SuccessRatioWindows are the rolling windows the success ratios of the
+	// collectors are computed over.
+	SuccessRatioWindows []time.Duration
+	// TagComplianceRequiredTags are the Azure resource tags every managed
+	// resource group and its resources are expected to have.
+	TagComplianceRequiredTags []string
 }
 
 // Set is basically only a wrapper for the operator's collector implementations.
@@ -26,29 +62,57 @@ type SetConfig struct {
 // have to alias packages.
 type Set struct {
 	*collector.Set
+
+	// collections creates the contexts of the collections of the
+	// collectors.
+	collections *Collections
+	// collectors are the collectors of the set guarded by their priority,
+	// without the wrappers serving their last known good metrics and
+	// reporting their errors.
+	collectors []collector.Interface
 }
 
 func NewSet(config SetConfig) (*Set, error) {
 	var err error
 
-	var clusterCollectors *cluster.Collectors
+	collectorOutcomes.setWindows(config.SuccessRatioWindows)
+
+	var collections *Collections
 	{
-		clusterCollectors, err = cluster.NewCollectors(config.K8sClient.CtrlClient(), config.Logger)
+		c := RuntimeConfig{
+			CollectTimeout: config.CollectTimeout,
+			StartSpread:    config.StartSpread,
+		}
+
+		collections, err = NewCollections(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var clusterCollectors *cluster.Collectors
+	if config.K8sClient != nil {
+		clusterCollectors, err = cluster.NewCollectors(config.K8sClient.CtrlClient(), config.CredentialSource, config.Logger.With("collector", "cluster"))
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		conditions, err := cluster.NewConditions(config.K8sClient.CtrlClient(), config.Logger.With("collector", "cluster"))
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
 
-		conditions, err := cluster.NewConditions(config.K8sClient.CtrlClient(), config.Logger)
+		releases, err := cluster.NewReleases(config.K8sClient.CtrlClient(), config.Logger.With("collector", "cluster"))
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
 
-		releases, err := cluster.NewReleases(config.K8sClient.CtrlClient(), config.Logger)
+		transition, err := cluster.NewTransitionTime(config.K8sClient.CtrlClient(), config.Logger.With("collector", "cluster"))
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
 
-		transition, err := cluster.NewTransitionTime(config.K8sClient.CtrlClient(), config.Logger)
+		conditionAge, err := cluster.NewConditionAge(config.K8sClient.CtrlClient(), config.Logger.With("collector", "cluster"))
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
@@ -56,15 +120,16 @@ func NewSet(config SetConfig) (*Set, error) {
 		clusterCollectors.Add(conditions)
 		clusterCollectors.Add(releases)
 		clusterCollectors.Add(transition)
+		clusterCollectors.Add(conditionAge)
 	}
 
 	var deploymentCollector *Deployment
 	{
 		c := DeploymentConfig{
-			G8sClient:  config.K8sClient.G8sClient(),
-			K8sClient:  config.K8sClient.K8sClient(),
-			Logger:     config.Logger,
-			GSTenantID: config.GSTenantID,
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "deployment"),
+			GSTenantID:       config.GSTenantID,
 		}
 
 		deploymentCollector, err = NewDeployment(c)
@@ -76,9 +141,10 @@ func NewSet(config SetConfig) (*Set, error) {
 	var resourceGroupCollector *ResourceGroup
 	{
 		c := ResourceGroupConfig{
-			K8sClient:  config.K8sClient.K8sClient(),
-			Logger:     config.Logger,
-			GSTenantID: config.GSTenantID,
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "resource_group"),
+			GSTenantID:       config.GSTenantID,
 		}
 
 		resourceGroupCollector, err = NewResourceGroup(c)
@@ -90,11 +156,11 @@ func NewSet(config SetConfig) (*Set, error) {
 	var usageCollector *Usage
 	{
 		c := UsageConfig{
-			G8sClient:  config.K8sClient.G8sClient(),
-			K8sClient:  config.K8sClient.K8sClient(),
-			Logger:     config.Logger,
-			Location:   config.Location,
-			GSTenantID: config.GSTenantID,
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "usage"),
+			Location:         config.Location,
+			GSTenantID:       config.GSTenantID,
 		}
 
 		usageCollector, err = NewUsage(c)
@@ -106,11 +172,11 @@ func NewSet(config SetConfig) (*Set, error) {
 	var rateLimitCollector *RateLimit
 	{
 		c := RateLimitConfig{
-			G8sClient:  config.K8sClient.G8sClient(),
-			K8sClient:  config.K8sClient.K8sClient(),
-			Location:   config.Location,
-			Logger:     config.Logger,
-			GSTenantID: config.GSTenantID,
+			CredentialSource: config.CredentialSource,
+			Location:         config.Location,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "rate_limit"),
+			GSTenantID:       config.GSTenantID,
 		}
 
 		rateLimitCollector, err = NewRateLimit(c)
@@ -122,9 +188,10 @@ func NewSet(config SetConfig) (*Set, error) {
 	var spExpirationCollector *SPExpiration
 	{
 		c := SPExpirationConfig{
-			K8sClient:  config.K8sClient.K8sClient(),
-			Logger:     config.Logger,
-			GSTenantID: config.GSTenantID,
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "sp_expiration"),
+			GSTenantID:       config.GSTenantID,
 		}
 
 		spExpirationCollector, err = NewSPExpiration(c)
@@ -134,11 +201,13 @@ func NewSet(config SetConfig) (*Set, error) {
 	}
 
 	var vmssRateLimitCollector *VMSSRateLimit
-	{
+	if config.K8sClient != nil {
 		c := VMSSRateLimitConfig{
-			CtrlClient: config.K8sClient.CtrlClient(),
-			Logger:     config.Logger,
-			GSTenantID: config.GSTenantID,
+			CredentialSource: config.CredentialSource,
+			CtrlClient:       config.K8sClient.CtrlClient(),
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "vmss_rate_limit"),
+			GSTenantID:       config.GSTenantID,
 		}
 
 		vmssRateLimitCollector, err = NewVMSSRateLimit(c)
@@ -150,10 +219,10 @@ func NewSet(config SetConfig) (*Set, error) {
 	var vpnConnectionCollector *VPNConnection
 	{
 		c := VPNConnectionConfig{
-			G8sClient:        config.K8sClient.G8sClient(),
 			InstallationName: config.ControlPlaneResourceGroup,
-			K8sClient:        config.K8sClient.K8sClient(),
-			Logger:           config.Logger,
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "vpn_connection"),
 			GSTenantID:       config.GSTenantID,
 		}
 
@@ -163,31 +232,1087 @@ func NewSet(config SetConfig) (*Set, error) {
 		}
 	}
 
-	var collectorSet *collector.Set
+	var ddosProtectionCollector *DDoSProtection
 	{
-		c := collector.SetConfig{
-			Collectors: []collector.Interface{
-				clusterCollectors,
-				deploymentCollector,
-				resourceGroupCollector,
-				rateLimitCollector,
-				spExpirationCollector,
-				usageCollector,
-				vmssRateLimitCollector,
-				vpnConnectionCollector,
-			},
-			Logger: config.Logger,
+		c := DDoSProtectionConfig{
+			InstallationName: config.ControlPlaneResourceGroup,
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "ddos_protection"),
+			GSTenantID:       config.GSTenantID,
 		}
 
-		collectorSet, err = collector.NewSet(c)
+		ddosProtectionCollector, err = NewDDoSProtection(c)
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
 	}
 
-	s := &Set{
-		Set: collectorSet,
+	var flowLogCollector *FlowLog
+	{
+		c := FlowLogConfig{
+			CredentialSource: config.CredentialSource,
+			Location:         config.Location,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "flow_log"),
+			GSTenantID:       config.GSTenantID,
+		}
+
+		flowLogCollector, err = NewFlowLog(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
 	}
 
-	return s, nil
+	var connectionMonitorCollector *ConnectionMonitor
+	{
+		c := ConnectionMonitorConfig{
+			CredentialSource: config.CredentialSource,
+			Location:         config.Location,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "connection_monitor"),
+			GSTenantID:       config.GSTenantID,
+		}
+
+		connectionMonitorCollector, err = NewConnectionMonitor(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var acceleratedNetworkingCollector *AcceleratedNetworking
+	{
+		c := AcceleratedNetworkingConfig{
+			CredentialSource: config.CredentialSource,
+			Location:         config.Location,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "accelerated_networking"),
+			GSTenantID:       config.GSTenantID,
+		}
+
+		acceleratedNetworkingCollector, err = NewAcceleratedNetworking(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var subnetIPConfigurationCollector *SubnetIPConfiguration
+	{
+		c := SubnetIPConfigurationConfig{
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "subnet_ip_configuration"),
+			GSTenantID:       config.GSTenantID,
+		}
+
+		subnetIPConfigurationCollector, err = NewSubnetIPConfiguration(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var bastionHostCollector *BastionHost
+	{
+		c := BastionHostConfig{
+			CredentialSource:          config.CredentialSource,
+			Collections:               collections,
+			Logger:                    config.Logger.With("collector", "bastion_host"),
+			ControlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+			GSTenantID:                config.GSTenantID,
+		}
+
+		bastionHostCollector, err = NewBastionHost(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var storageAccountCollector *StorageAccount
+	{
+		c := StorageAccountConfig{
+			CredentialSource:          config.CredentialSource,
+			Collections:               collections,
+			Logger:                    config.Logger.With("collector", "storage_account"),
+			ControlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+			GSTenantID:                config.GSTenantID,
+		}
+
+		storageAccountCollector, err = NewStorageAccount(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var storageAccountKeyCollector *StorageAccountKey
+	{
+		c := StorageAccountKeyConfig{
+			CredentialSource:          config.CredentialSource,
+			Collections:               collections,
+			Logger:                    config.Logger.With("collector", "storage_account_key"),
+			ControlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+			GSTenantID:                config.GSTenantID,
+		}
+
+		storageAccountKeyCollector, err = NewStorageAccountKey(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var storageAccountSecurityCollector *StorageAccountSecurity
+	{
+		c := StorageAccountSecurityConfig{
+			CredentialSource:          config.CredentialSource,
+			Collections:               collections,
+			Logger:                    config.Logger.With("collector", "storage_account_security"),
+			ControlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+			GSTenantID:                config.GSTenantID,
+		}
+
+		storageAccountSecurityCollector, err = NewStorageAccountSecurity(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var blobDataProtectionCollector *BlobDataProtection
+	{
+		c := BlobDataProtectionConfig{
+			CredentialSource:          config.CredentialSource,
+			Collections:               collections,
+			Logger:                    config.Logger.With("collector", "blob_data_protection"),
+			ControlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+			GSTenantID:                config.GSTenantID,
+		}
+
+		blobDataProtectionCollector, err = NewBlobDataProtection(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var fileShareCollector *FileShare
+	{
+		c := FileShareConfig{
+			CredentialSource:          config.CredentialSource,
+			Collections:               collections,
+			Logger:                    config.Logger.With("collector", "file_share"),
+			ControlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+			GSTenantID:                config.GSTenantID,
+		}
+
+		fileShareCollector, err = NewFileShare(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var storageAccountQuotaCollector *StorageAccountQuota
+	{
+		c := StorageAccountQuotaConfig{
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "storage_account_quota"),
+			Location:         config.Location,
+			GSTenantID:       config.GSTenantID,
+		}
+
+		storageAccountQuotaCollector, err = NewStorageAccountQuota(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var keyVaultCertificateCollector *KeyVaultCertificate
+	{
+		c := KeyVaultCertificateConfig{
+			CredentialSource:          config.CredentialSource,
+			Collections:               collections,
+			Logger:                    config.Logger.With("collector", "key_vault_certificate"),
+			ControlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+			GSTenantID:                config.GSTenantID,
+		}
+
+		keyVaultCertificateCollector, err = NewKeyVaultCertificate(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var keyVaultKeyCollector *KeyVaultKey
+	{
+		c := KeyVaultKeyConfig{
+			CredentialSource:          config.CredentialSource,
+			Collections:               collections,
+			Logger:                    config.Logger.With("collector", "key_vault_key"),
+			ControlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+			GSTenantID:                config.GSTenantID,
+		}
+
+		keyVaultKeyCollector, err = NewKeyVaultKey(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var keyVaultConfigurationCollector *KeyVaultConfiguration
+	{
+		c := KeyVaultConfigurationConfig{
+			CredentialSource:          config.CredentialSource,
+			Collections:               collections,
+			Logger:                    config.Logger.With("collector", "key_vault_configuration"),
+			ControlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+			GSTenantID:                config.GSTenantID,
+		}
+
+		keyVaultConfigurationCollector, err = NewKeyVaultConfiguration(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var keyVaultAvailabilityCollector *KeyVaultAvailability
+	{
+		c := KeyVaultAvailabilityConfig{
+			CredentialSource:          config.CredentialSource,
+			Collections:               collections,
+			Logger:                    config.Logger.With("collector", "key_vault_availability"),
+			ControlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+			GSTenantID:                config.GSTenantID,
+		}
+
+		keyVaultAvailabilityCollector, err = NewKeyVaultAvailability(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var containerRegistryCollector *ContainerRegistry
+	{
+		c := ContainerRegistryConfig{
+			CredentialSource:          config.CredentialSource,
+			Collections:               collections,
+			Logger:                    config.Logger.With("collector", "container_registry"),
+			ControlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+			GSTenantID:                config.GSTenantID,
+		}
+
+		containerRegistryCollector, err = NewContainerRegistry(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var containerRegistryTokenCollector *ContainerRegistryToken
+	{
+		c := ContainerRegistryTokenConfig{
+			CredentialSource:          config.CredentialSource,
+			Collections:               collections,
+			Logger:                    config.Logger.With("collector", "container_registry_token"),
+			ControlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+			GSTenantID:                config.GSTenantID,
+		}
+
+		containerRegistryTokenCollector, err = NewContainerRegistryToken(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var guestDiskUsageCollector *GuestDiskUsage
+	{
+		c := GuestDiskUsageConfig{
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "guest_disk_usage"),
+			GSTenantID:       config.GSTenantID,
+		}
+
+		guestDiskUsageCollector, err = NewGuestDiskUsage(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var logAnalyticsCollector *LogAnalytics
+	{
+		c := LogAnalyticsConfig{
+			CredentialSource:          config.CredentialSource,
+			Collections:               collections,
+			Logger:                    config.Logger.With("collector", "log_analytics"),
+			ControlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+			GSTenantID:                config.GSTenantID,
+		}
+
+		logAnalyticsCollector, err = NewLogAnalytics(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var diagnosticSettingsCollector *DiagnosticSettings
+	{
+		c := DiagnosticSettingsConfig{
+			CredentialSource:          config.CredentialSource,
+			Collections:               collections,
+			Logger:                    config.Logger.With("collector", "diagnostic_settings"),
+			ControlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+			GSTenantID:                config.GSTenantID,
+
+			Destination:   config.DiagnosticSettingsDestination,
+			ResourceTypes: config.DiagnosticSettingsResourceTypes,
+		}
+
+		diagnosticSettingsCollector, err = NewDiagnosticSettings(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var backupJobCollector *BackupJob
+	{
+		c := BackupJobConfig{
+			CredentialSource:          config.CredentialSource,
+			Collections:               collections,
+			Logger:                    config.Logger.With("collector", "backup_job"),
+			ControlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+			GSTenantID:                config.GSTenantID,
+		}
+
+		backupJobCollector, err = NewBackupJob(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var monitorMetricProxyCollector *MonitorMetricProxy
+	{
+		var definitions MonitorMetricDefinitions
+		if config.MonitorMetricsConfigFile != "" {
+			definitions, err = LoadMonitorMetricDefinitions(config.MonitorMetricsConfigFile)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+		}
+
+		c := MonitorMetricProxyConfig{
+			CredentialSource:          config.CredentialSource,
+			Collections:               collections,
+			Logger:                    config.Logger.With("collector", "monitor_metric_proxy"),
+			ControlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+			GSTenantID:                config.GSTenantID,
+
+			Definitions: definitions,
+		}
+
+		monitorMetricProxyCollector, err = NewMonitorMetricProxy(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var activityLogCollector *ActivityLog
+	{
+		c := ActivityLogConfig{
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "activity_log"),
+			GSTenantID:       config.GSTenantID,
+		}
+
+		activityLogCollector, err = NewActivityLog(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var resourceHealthCollector *ResourceHealth
+	{
+		c := ResourceHealthConfig{
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "resource_health"),
+			GSTenantID:       config.GSTenantID,
+		}
+
+		resourceHealthCollector, err = NewResourceHealth(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var serviceHealthCollector *ServiceHealth
+	{
+		c := ServiceHealthConfig{
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "service_health"),
+			Location:         config.Location,
+			GSTenantID:       config.GSTenantID,
+		}
+
+		serviceHealthCollector, err = NewServiceHealth(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var regionStatusCollector *RegionStatus
+	{
+		c := RegionStatusConfig{
+			ClientFactory: config.CredentialSource.ClientFactory(),
+			Collections:   collections,
+			Logger:        config.Logger.With("collector", "region_status"),
+			Location:      config.Location,
+		}
+
+		regionStatusCollector, err = NewRegionStatus(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var policyComplianceCollector *PolicyCompliance
+	{
+		c := PolicyComplianceConfig{
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "policy_compliance"),
+			GSTenantID:       config.GSTenantID,
+		}
+
+		policyComplianceCollector, err = NewPolicyCompliance(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var secureScoreCollector *SecureScore
+	{
+		c := SecureScoreConfig{
+			CredentialSource:          config.CredentialSource,
+			Collections:               collections,
+			Logger:                    config.Logger.With("collector", "secure_score"),
+			ControlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+			GSTenantID:                config.GSTenantID,
+		}
+
+		secureScoreCollector, err = NewSecureScore(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var subscriptionCollector *Subscription
+	{
+		c := SubscriptionConfig{
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "subscription"),
+			GSTenantID:       config.GSTenantID,
+		}
+
+		subscriptionCollector, err = NewSubscription(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var spendingForecastCollector *SpendingForecast
+	{
+		c := SpendingForecastConfig{
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "spending_forecast"),
+			GSTenantID:       config.GSTenantID,
+		}
+
+		spendingForecastCollector, err = NewSpendingForecast(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var clusterCostCollector *ClusterCost
+	{
+		c := ClusterCostConfig{
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "cluster_cost"),
+			GSTenantID:       config.GSTenantID,
+			TagLabels:        config.CostTagLabels,
+		}
+
+		clusterCostCollector, err = NewClusterCost(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var budgetCollector *Budget
+	{
+		c := BudgetConfig{
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "budget"),
+			GSTenantID:       config.GSTenantID,
+		}
+
+		budgetCollector, err = NewBudget(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var costAnomalyCollector *CostAnomaly
+	{
+		c := CostAnomalyConfig{
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "cost_anomaly"),
+			GSTenantID:       config.GSTenantID,
+		}
+
+		costAnomalyCollector, err = NewCostAnomaly(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var reservationCollector *Reservation
+	{
+		c := ReservationConfig{
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "reservation"),
+			GSTenantID:       config.GSTenantID,
+		}
+
+		reservationCollector, err = NewReservation(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var savingsPlanCollector *SavingsPlan
+	{
+		c := SavingsPlanConfig{
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "savings_plan"),
+			GSTenantID:       config.GSTenantID,
+		}
+
+		savingsPlanCollector, err = NewSavingsPlan(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var marketplaceChargeCollector *MarketplaceCharge
+	{
+		c := MarketplaceChargeConfig{
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "marketplace_charge"),
+			GSTenantID:       config.GSTenantID,
+		}
+
+		marketplaceChargeCollector, err = NewMarketplaceCharge(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var nodePoolCostCollector *NodePoolCost
+	{
+		c := NodePoolCostConfig{
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "node_pool_cost"),
+			GSTenantID:       config.GSTenantID,
+			TagLabels:        config.CostTagLabels,
+		}
+
+		nodePoolCostCollector, err = NewNodePoolCost(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var egressCostCollector *EgressCost
+	{
+		c := EgressCostConfig{
+			CredentialSource: config.CredentialSource,
+			Location:         config.Location,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "egress_cost"),
+			GSTenantID:       config.GSTenantID,
+			TagLabels:        config.CostTagLabels,
+		}
+
+		egressCostCollector, err = NewEgressCost(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var networkUsageCollector *NetworkUsage
+	{
+		c := NetworkUsageConfig{
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "network_usage"),
+			Location:         config.Location,
+			GSTenantID:       config.GSTenantID,
+		}
+
+		networkUsageCollector, err = NewNetworkUsage(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var roleAssignmentCollector *RoleAssignment
+	{
+		c := RoleAssignmentConfig{
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "role_assignment"),
+			GSTenantID:       config.GSTenantID,
+			Limit:            config.RoleAssignmentsLimit,
+		}
+
+		roleAssignmentCollector, err = NewRoleAssignment(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var spPermissionCollector *SPPermission
+	{
+		c := SPPermissionConfig{
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "sp_permission"),
+			GSTenantID:       config.GSTenantID,
+		}
+
+		spPermissionCollector, err = NewSPPermission(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var federatedCredentialCollector *FederatedCredential
+	{
+		c := FederatedCredentialConfig{
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "federated_credential"),
+			GSTenantID:       config.GSTenantID,
+		}
+
+		federatedCredentialCollector, err = NewFederatedCredential(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var sharedSPCollector *SharedSP
+	{
+		c := SharedSPConfig{
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "shared_sp"),
+		}
+
+		sharedSPCollector, err = NewSharedSP(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var credentialSecretCollector *CredentialSecret
+	{
+		c := CredentialSecretConfig{
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "credential_secret"),
+		}
+
+		credentialSecretCollector, err = NewCredentialSecret(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var credentialValidityCollector *CredentialValidity
+	{
+		c := CredentialValidityConfig{
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "credential_validity"),
+			GSTenantID:       config.GSTenantID,
+		}
+
+		credentialValidityCollector, err = NewCredentialValidity(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var orphanedResourceGroupCollector *OrphanedResourceGroup
+	{
+		c := OrphanedResourceGroupConfig{
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "orphaned_resource_group"),
+			GSTenantID:       config.GSTenantID,
+			InstallationName: config.ControlPlaneResourceGroup,
+		}
+
+		orphanedResourceGroupCollector, err = NewOrphanedResourceGroup(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var orphanedNetworkCollector *OrphanedNetwork
+	{
+		c := OrphanedNetworkConfig{
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "orphaned_network"),
+			GSTenantID:       config.GSTenantID,
+		}
+
+		orphanedNetworkCollector, err = NewOrphanedNetwork(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+	}
+
+	var resourceLockCollector *ResourceLock
+	{
+		c := ResourceLockConfig{
+			CredentialSource:          config.CredentialSource,
+			Collections:               collections,
+			Logger:                    config.Logger.With("collector", "resource_lock"),
+			ControlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+			GSTenantID:                config.GSTenantID,
+		}
+
+		resourceLockCollector, err = NewResourceLock(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var tagComplianceCollector *TagCompliance
+	{
+		c := TagComplianceConfig{
+			CredentialSource:          config.CredentialSource,
+			Collections:               collections,
+			Logger:                    config.Logger.With("collector", "tag_compliance"),
+			ControlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+			GSTenantID:                config.GSTenantID,
+			RequiredTags:              config.TagComplianceRequiredTags,
+		}
+
+		tagComplianceCollector, err = NewTagCompliance(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var stuckDeletionCollector *StuckDeletion
+	if config.K8sClient != nil {
+		c := StuckDeletionConfig{
+			CtrlClient:  config.K8sClient.CtrlClient(),
+			Collections: collections,
+			Logger:      config.Logger.With("collector", "stuck_deletion"),
+		}
+
+		stuckDeletionCollector, err = NewStuckDeletion(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var fleetInventoryCollector *FleetInventory
+	if config.K8sClient != nil {
+		c := FleetInventoryConfig{
+			CtrlClient:  config.K8sClient.CtrlClient(),
+			Collections: collections,
+			Logger:      config.Logger.With("collector", "fleet_inventory"),
+			Location:    config.Location,
+		}
+
+		fleetInventoryCollector, err = NewFleetInventory(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var nodeVMSSCollector *NodeVMSS
+	if config.K8sClient != nil {
+		c := NodeVMSSConfig{
+			CtrlClient:       config.K8sClient.CtrlClient(),
+			CredentialSource: config.CredentialSource,
+			Collections:      collections,
+			Logger:           config.Logger.With("collector", "node_vmss"),
+			GSTenantID:       config.GSTenantID,
+		}
+
+		nodeVMSSCollector, err = NewNodeVMSS(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var armBudgetCollector *ARMBudget
+	{
+		c := ARMBudgetConfig{
+			ClientFactory: config.CredentialSource.ClientFactory(),
+			Logger:        config.Logger.With("collector", "arm_budget"),
+		}
+
+		armBudgetCollector, err = NewARMBudget(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var clientCacheCollector *ClientCache
+	{
+		c := ClientCacheConfig{
+			ClientFactory: config.CredentialSource.ClientFactory(),
+			Logger:        config.Logger.With("collector", "client_cache"),
+		}
+
+		clientCacheCollector, err = NewClientCache(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var clusterErrorCollector *ClusterError
+	{
+		c := ClusterErrorConfig{
+			Logger: config.Logger.With("collector", "cluster_error"),
+		}
+
+		clusterErrorCollector, err = NewClusterError(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var successRatioCollector *SuccessRatio
+	{
+		c := SuccessRatioConfig{
+			Logger: config.Logger.With("collector", "success_ratio"),
+		}
+
+		successRatioCollector, err = NewSuccessRatio(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var dryRunCollector *DryRun
+	{
+		c := DryRunConfig{
+			Logger: config.Logger.With("collector", "dry_run"),
+		}
+
+		dryRunCollector, err = NewDryRun(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var dataAgeCollector *DataAge
+	{
+		c := DataAgeConfig{
+			Logger: config.Logger.With("collector", "data_age"),
+		}
+
+		dataAgeCollector, err = NewDataAge(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var collectors []collector.Interface
+	var collectorSet *collector.Set
+	{
+		c := collector.SetConfig{
+			Collectors: []collector.Interface{
+				acceleratedNetworkingCollector,
+				activityLogCollector,
+				armBudgetCollector,
+				backupJobCollector,
+				bastionHostCollector,
+				blobDataProtectionCollector,
+				budgetCollector,
+				clientCacheCollector,
+				clusterCollectors,
+				clusterCostCollector,
+				clusterErrorCollector,
+				connectionMonitorCollector,
+				containerRegistryCollector,
+				containerRegistryTokenCollector,
+				costAnomalyCollector,
+				credentialSecretCollector,
+				credentialValidityCollector,
+				dataAgeCollector,
+				ddosProtectionCollector,
+				deploymentCollector,
+				diagnosticSettingsCollector,
+				dryRunCollector,
+				egressCostCollector,
+				federatedCredentialCollector,
+				fileShareCollector,
+				fleetInventoryCollector,
+				flowLogCollector,
+				guestDiskUsageCollector,
+				keyVaultAvailabilityCollector,
+				keyVaultCertificateCollector,
+				keyVaultConfigurationCollector,
+				keyVaultKeyCollector,
+				logAnalyticsCollector,
+				marketplaceChargeCollector,
+				monitorMetricProxyCollector,
+				networkUsageCollector,
+				nodePoolCostCollector,
+				nodeVMSSCollector,
+				orphanedNetworkCollector,
+				orphanedResourceGroupCollector,
+				policyComplianceCollector,
+				regionStatusCollector,
+				reservationCollector,
+				resourceGroupCollector,
+				rateLimitCollector,
+				resourceHealthCollector,
+				resourceLockCollector,
+				roleAssignmentCollector,
+				savingsPlanCollector,
+				secureScoreCollector,
+				serviceHealthCollector,
+				sharedSPCollector,
+				spendingForecastCollector,
+				spExpirationCollector,
+				spPermissionCollector,
+				storageAccountCollector,
+				storageAccountKeyCollector,
+				storageAccountQuotaCollector,
+				storageAccountSecurityCollector,
+				stuckDeletionCollector,
+				subnetIPConfigurationCollector,
+				subscriptionCollector,
+				successRatioCollector,
+				tagComplianceCollector,
+				usageCollector,
+				vmssRateLimitCollector,
+				vpnConnectionCollector,
+			},
+			Logger: config.Logger,
+		}
+
+		// Critical collectors are always collected, low priority ones are
+		// skipped first when the Azure APIs are under pressure. All other
+		// collectors are of normal priority.
+		criticalCollectors := []collector.Interface{
+			armBudgetCollector,
+			clientCacheCollector,
+			clusterCollectors,
+			clusterErrorCollector,
+			credentialSecretCollector,
+			credentialValidityCollector,
+			dataAgeCollector,
+			dryRunCollector,
+			rateLimitCollector,
+			spExpirationCollector,
+			successRatioCollector,
+			vmssRateLimitCollector,
+		}
+		lowCollectors := []collector.Interface{
+			budgetCollector,
+			clusterCostCollector,
+			costAnomalyCollector,
+			egressCostCollector,
+			fleetInventoryCollector,
+			guestDiskUsageCollector,
+			marketplaceChargeCollector,
+			nodePoolCostCollector,
+			policyComplianceCollector,
+			reservationCollector,
+			savingsPlanCollector,
+			secureScoreCollector,
+			spendingForecastCollector,
+			tagComplianceCollector,
+		}
+		// Experimental collectors are left out unless their feature is
+		// enabled.
+		c.Collectors = withFeatureGates(c.Collectors, map[collector.Interface]string{
+			guestDiskUsageCollector: featuregate.GuestDiskUsage,
+			nodeVMSSCollector:       featuregate.NodeVMSS,
+		})
+
+		// Collectors reading other resources of the management cluster
+		// than the clusters and their credentials are left out without
+		// one.
+		if config.K8sClient == nil {
+			c.Collectors = withoutCollectors(c.Collectors, []collector.Interface{
+				clusterCollectors,
+				fleetInventoryCollector,
+				nodeVMSSCollector,
+				stuckDeletionCollector,
+				vmssRateLimitCollector,
+			})
+		}
+
+		c.Collectors = withPriorities(c.Collectors, criticalCollectors, lowCollectors, collections, config.Logger)
+		collectors = c.Collectors
+
+		// Collectors can be disabled while the collector is running.
+		c.Collectors = withDisabledGuards(c.Collectors, collections)
+
+		// The age of the data of every collector is exposed, whether or
+		// not the last complete collections are served.
+		c.Collectors = withDataAges(c.Collectors, collections)
+
+		// Collections running into their deadline serve the metrics of
+		// the last complete collection instead of partial ones.
+		if featuregate.Enabled(featuregate.LastKnownGood) {
+			c.Collectors = withLastKnownGood(c.Collectors, collections)
+		}
+
+		// Panics and repeated failures of all collectors are reported to
+		// the error tracker, if configured.
+		c.Collectors = withErrorReports(c.Collectors)
+
+		collectorSet, err = collector.NewSet(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	s := &Set{
+		Set: collectorSet,
+
+		collections: collections,
+		collectors:  collectors,
+	}
+
+	return s, nil
+}
+
+// Collections returns the collections of the collectors of the set, which
+// the scrapes bound and the shutdown drains.
+func (s *Set) Collections() *Collections {
+	return s.collections
 }
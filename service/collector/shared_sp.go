@@ -0,0 +1,113 @@
+package collector
+
+import (
+	"strconv"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+var (
+	sharedSPClustersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "service_principal", "clusters"),
+		"Number of clusters configured with the service principal.",
+		[]string{
+			labelClientId,
+		},
+		nil,
+	)
+	sharedSPDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "service_principal", "shared"),
+		"Set to 1 for every cluster configured with a service principal which is used by other clusters too.",
+		[]string{
+			"cluster_id",
+			labelClientId,
+			"cluster_count",
+		},
+		nil,
+	)
+)
+
+type SharedSPConfig struct {
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+}
+
+type SharedSP struct {
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+}
+
+// NewSharedSP exposes which clusters share the same service principal. Clusters sharing a service principal share its
+// rate limits too, and rotating its credentials affects all of them at once.
+func NewSharedSP(config SharedSPConfig) (*SharedSP, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	s := &SharedSP{
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+	}
+
+	return s, nil
+}
+
+func (s *SharedSP) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := s.collections.newCollectContext("shared_sp")
+	defer cancel()
+
+	clientIDs, err := credential.GetClientIDsByCluster(ctx, s.credentialSource)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	clustersByClientID := map[string][]string{}
+	for clusterID, clientID := range clientIDs {
+		clustersByClientID[clientID] = append(clustersByClientID[clientID], clusterID)
+	}
+
+	for clientID, clusterIDs := range clustersByClientID {
+		ch <- prometheus.MustNewConstMetric(
+			sharedSPClustersDesc,
+			prometheus.GaugeValue,
+			float64(len(clusterIDs)),
+			clientID,
+		)
+
+		if len(clusterIDs) < 2 {
+			continue
+		}
+
+		for _, clusterID := range clusterIDs {
+			ch <- prometheus.MustNewConstMetric(
+				sharedSPDesc,
+				prometheus.GaugeValue,
+				gaugeValue,
+				clusterID,
+				clientID,
+				strconv.Itoa(len(clusterIDs)),
+			)
+		}
+	}
+
+	return nil
+}
+
+func (s *SharedSP) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- sharedSPClustersDesc
+	ch <- sharedSPDesc
+	return nil
+}
@@ -3,11 +3,13 @@ package collector
 import (
 	"context"
 	"fmt"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
 
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	"github.com/prometheus/client_golang/prometheus"
-	"k8s.io/client-go/kubernetes"
 
 	"github.com/giantswarm/azure-collector/v2/client"
 	"github.com/giantswarm/azure-collector/v2/service/credential"
@@ -20,8 +22,35 @@ const (
 	labelApplicationId   = "application_id"
 	labelApplicationName = "application_name"
 	labelSecretKeyID     = "secret_key_id"
+	labelCredentialType  = "credential_type"
+	labelCredentialName  = "credential_name"
+)
+
+const (
+	credentialTypePassword    = "password"
+	credentialTypeCertificate = "certificate"
 )
 
+// applicationList holds the subset of the Microsoft Graph application
+// properties we need.
+type applicationList struct {
+	NextLink string        `json:"@odata.nextLink"`
+	Value    []application `json:"value"`
+}
+
+type application struct {
+	AppID               string                  `json:"appId"`
+	DisplayName         string                  `json:"displayName"`
+	KeyCredentials      []applicationCredential `json:"keyCredentials"`
+	PasswordCredentials []applicationCredential `json:"passwordCredentials"`
+}
+
+type applicationCredential struct {
+	DisplayName string    `json:"displayName"`
+	EndDateTime time.Time `json:"endDateTime"`
+	KeyID       string    `json:"keyId"`
+}
+
 var (
 	spExpirationDesc = prometheus.NewDesc(
 		prometheus.BuildFQName(MetricsNamespace, "service_principal_token", "expiration"),
@@ -33,6 +62,8 @@ var (
 			labelApplicationId,
 			labelApplicationName,
 			labelSecretKeyID,
+			labelCredentialType,
+			labelCredentialName,
 		},
 		nil,
 	)
@@ -50,22 +81,27 @@ var (
 )
 
 type SPExpirationConfig struct {
-	K8sClient  kubernetes.Interface
-	Logger     micrologger.Logger
-	GSTenantID string
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+	GSTenantID       string
 }
 
 type SPExpiration struct {
-	k8sClient  kubernetes.Interface
-	logger     micrologger.Logger
-	gsTenantID string
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+	gsTenantID       string
 }
 
 // NewSPExpiration exposes metrics about the expiration date of Azure Service Principals.
 // It exposes metrcis about the Service Principals found in the "credential-*" secrets of the control plane.
 func NewSPExpiration(config SPExpirationConfig) (*SPExpiration, error) {
-	if config.K8sClient == nil {
-		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
 	}
 	if config.Logger == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
@@ -75,18 +111,20 @@ func NewSPExpiration(config SPExpirationConfig) (*SPExpiration, error) {
 	}
 
 	v := &SPExpiration{
-		k8sClient:  config.K8sClient,
-		logger:     config.Logger,
-		gsTenantID: config.GSTenantID,
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		gsTenantID:       config.GSTenantID,
 	}
 
 	return v, nil
 }
 
 func (v *SPExpiration) Collect(ch chan<- prometheus.Metric) error {
-	ctx := context.Background()
+	ctx, cancel := v.collections.newCollectContext("sp_expiration")
+	defer cancel()
 
-	azureClientSets, err := credential.GetAzureClientSetsFromCredentialSecrets(ctx, v.k8sClient, v.gsTenantID)
+	azureClientSets, err := credential.GetAzureClientSetsFromCredentialSecrets(ctx, v.credentialSource, v.gsTenantID)
 	if err != nil {
 		return microerror.Mask(err)
 	}
@@ -100,7 +138,7 @@ func (v *SPExpiration) Collect(ch chan<- prometheus.Metric) error {
 
 	// Use one arbitrary client set (we don't care which one) and use it to list all service principals on the GiantSwarm Active Directory.
 	for azureClientSetConfig, clientSet := range azureClientSets {
-		apps, err := clientSet.ApplicationsClient.ListComplete(ctx, "")
+		apps, err := listApplications(ctx, clientSet.GraphClient)
 		if err != nil {
 			// Ignore but log
 			v.logger.LogCtx(ctx, "level", "warning", "message", fmt.Sprintf("Unable to list applications using client %#q", azureClientSetConfig.ClientID), "stack", microerror.JSON(err), "gsTenantID", v.gsTenantID)
@@ -108,24 +146,28 @@ func (v *SPExpiration) Collect(ch chan<- prometheus.Metric) error {
 			continue
 		}
 
-		for apps.NotDone() {
-			app := apps.Value()
-			for _, pc := range *app.PasswordCredentials {
-				ch <- prometheus.MustNewConstMetric(
-					spExpirationDesc,
-					prometheus.GaugeValue,
-					float64(pc.EndDate.Unix()),
-					azureClientSetConfig.ClientID,
-					azureClientSetConfig.SubscriptionID,
-					azureClientSetConfig.TenantID,
-					*app.AppID,
-					*app.DisplayName,
-					*pc.KeyID,
-				)
+		for _, app := range apps {
+			credentials := map[string][]applicationCredential{
+				credentialTypePassword:    app.PasswordCredentials,
+				credentialTypeCertificate: app.KeyCredentials,
 			}
 
-			if err := apps.NextWithContext(ctx); err != nil {
-				return microerror.Mask(err)
+			for credentialType, cs := range credentials {
+				for _, c := range cs {
+					ch <- prometheus.MustNewConstMetric(
+						spExpirationDesc,
+						prometheus.GaugeValue,
+						float64(c.EndDateTime.Unix()),
+						azureClientSetConfig.ClientID,
+						azureClientSetConfig.SubscriptionID,
+						azureClientSetConfig.TenantID,
+						app.AppID,
+						app.DisplayName,
+						c.KeyID,
+						credentialType,
+						c.DisplayName,
+					)
+				}
 			}
 		}
 
@@ -153,3 +195,35 @@ func (v *SPExpiration) Describe(ch chan<- *prometheus.Desc) error {
 	ch <- spExpirationFailedScrapeDesc
 	return nil
 }
+
+// listApplications returns all applications of the tenant using Microsoft
+// Graph, including their password and certificate credentials.
+func listApplications(ctx context.Context, graphClient *client.GraphClient) ([]application, error) {
+	parameters := map[string]interface{}{
+		"$select": autorest.Encode("query", "appId,displayName,keyCredentials,passwordCredentials"),
+	}
+
+	var apps applicationList
+	err := graphClient.GetJSON(ctx, "/applications", parameters, &apps)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var result []application
+	for {
+		result = append(result, apps.Value...)
+
+		if apps.NextLink == "" {
+			break
+		}
+
+		nextLink := apps.NextLink
+		apps = applicationList{}
+		err = graphClient.GetNextJSON(ctx, nextLink, &apps)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,241 @@
+package collector
+
+import (
+	"context"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	spPermissionScopeSubscription  = "subscription"
+	spPermissionScopeResourceGroup = "resource_group"
+)
+
+// spPermissionActions are the actions the credentials of a cluster must be
+// allowed to do per scope, so the operator can manage the cluster.
+var spPermissionActions = map[string][]string{
+	spPermissionScopeSubscription: {
+		"Microsoft.Resources/subscriptions/resourceGroups/write",
+		"Microsoft.Network/virtualNetworks/read",
+	},
+	spPermissionScopeResourceGroup: {
+		"Microsoft.Resources/deployments/write",
+		"Microsoft.Compute/virtualMachineScaleSets/write",
+		"Microsoft.Network/loadBalancers/write",
+		"Microsoft.Network/virtualNetworks/write",
+		"Microsoft.Storage/storageAccounts/write",
+	},
+}
+
+// permissionList holds the effective permissions of the caller on a scope.
+type permissionList struct {
+	NextLink string       `json:"nextLink"`
+	Value    []permission `json:"value"`
+}
+
+type permission struct {
+	Actions    []string `json:"actions"`
+	NotActions []string `json:"notActions"`
+}
+
+var (
+	spPermissionMissingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "sp_permission", "missing"),
+		"Whether the credentials of the cluster are missing the permission for the action on the scope.",
+		[]string{
+			"cluster_id",
+			"scope",
+			"action",
+		},
+		nil,
+	)
+)
+
+type SPPermissionConfig struct {
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+
+	GSTenantID string
+}
+
+type SPPermission struct {
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+
+	gsTenantID string
+}
+
+// NewSPPermission verifies that the credentials of every cluster hold the permissions the operator needs on the
+// subscription and on the cluster resource group. A missing permission otherwise only shows up as 403 responses in the
+// operator logs.
+func NewSPPermission(config SPPermissionConfig) (*SPPermission, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	s := &SPPermission{
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		gsTenantID:       config.GSTenantID,
+	}
+
+	return s, nil
+}
+
+func (s *SPPermission) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := s.collections.newCollectContext("sp_permission")
+	defer cancel()
+
+	clientSets, err := getClientSetsByCluster(ctx, s.credentialSource, s.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	forEachCluster(ctx, ch, clientSets, func(ctx context.Context, ch chan<- prometheus.Metric, clusterID string, azureClientSet *client.AzureClientSet) error {
+		restClient := azureClientSet.RESTClient
+
+		scopes := map[string]string{
+			spPermissionScopeSubscription: "/subscriptions/" + restClient.SubscriptionID,
+			// The resource group of a cluster is named after the cluster ID.
+			spPermissionScopeResourceGroup: "/subscriptions/" + restClient.SubscriptionID + "/resourceGroups/" + clusterID,
+		}
+
+		for scope, path := range scopes {
+			permissions, err := getPermissions(ctx, restClient, path)
+			if IsNotFound(err) {
+				// The resource group does not exist yet.
+				continue
+			} else if err != nil {
+				return microerror.Mask(err)
+			}
+
+			for _, action := range spPermissionActions[scope] {
+				var missing float64
+				if !isActionAllowed(permissions, action) {
+					missing = 1
+				}
+
+				ch <- prometheus.MustNewConstMetric(
+					spPermissionMissingDesc,
+					prometheus.GaugeValue,
+					missing,
+					clusterID,
+					scope,
+					action,
+				)
+			}
+		}
+
+		return nil
+	})
+
+	return nil
+}
+
+func (s *SPPermission) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- spPermissionMissingDesc
+	return nil
+}
+
+// getPermissions returns the effective permissions of the client on the
+// given scope.
+func getPermissions(ctx context.Context, restClient *client.RESTClient, scope string) ([]permission, error) {
+	var result []permission
+
+	var permissions permissionList
+	err := restClient.GetJSON(ctx, scope+"/providers/Microsoft.Authorization/permissions", authorizationAPIVersion, &permissions)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	for {
+		result = append(result, permissions.Value...)
+
+		if permissions.NextLink == "" {
+			break
+		}
+
+		nextLink := permissions.NextLink
+		permissions = permissionList{}
+		err = restClient.GetNextJSON(ctx, nextLink, &permissions)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	return result, nil
+}
+
+// isActionAllowed returns whether any of the given permissions allows the
+// action. Like in Azure RBAC, a permission allows an action when one of its
+// actions matches and none of its not actions does.
+func isActionAllowed(permissions []permission, action string) bool {
+	for _, p := range permissions {
+		var allowed bool
+		for _, a := range p.Actions {
+			if matchesActionPattern(a, action) {
+				allowed = true
+				break
+			}
+		}
+		for _, a := range p.NotActions {
+			if matchesActionPattern(a, action) {
+				allowed = false
+				break
+			}
+		}
+
+		if allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesActionPattern returns whether the action matches the given pattern,
+// e.g. "Microsoft.Compute/*" or "*/read". Wildcards match any number of
+// characters, including slashes, and actions are case insensitive.
+func matchesActionPattern(pattern, action string) bool {
+	pattern = strings.ToLower(pattern)
+	action = strings.ToLower(action)
+
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == action
+	}
+
+	if !strings.HasPrefix(action, parts[0]) {
+		return false
+	}
+	action = action[len(parts[0]):]
+
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(action, part)
+		if i < 0 {
+			return false
+		}
+		action = action[i+len(part):]
+	}
+
+	return strings.HasSuffix(action, parts[len(parts)-1])
+}
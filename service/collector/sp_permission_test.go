@@ -0,0 +1,78 @@
+package collector
+
+import (
+	"strconv"
+	"testing"
+)
+
+func Test_isActionAllowed(t *testing.T) {
+	testCases := []struct {
+		name            string
+		permissions     []permission
+		action          string
+		expectedAllowed bool
+	}{
+		{
+			name:            "case 0: no permissions",
+			permissions:     nil,
+			action:          "Microsoft.Compute/virtualMachineScaleSets/write",
+			expectedAllowed: false,
+		},
+		{
+			name: "case 1: contributor",
+			permissions: []permission{
+				{
+					Actions:    []string{"*"},
+					NotActions: []string{"Microsoft.Authorization/*/Delete", "Microsoft.Authorization/*/Write"},
+				},
+			},
+			action:          "Microsoft.Compute/virtualMachineScaleSets/write",
+			expectedAllowed: true,
+		},
+		{
+			name: "case 2: contributor is not allowed to write role assignments",
+			permissions: []permission{
+				{
+					Actions:    []string{"*"},
+					NotActions: []string{"Microsoft.Authorization/*/Delete", "Microsoft.Authorization/*/Write"},
+				},
+			},
+			action:          "Microsoft.Authorization/roleAssignments/write",
+			expectedAllowed: false,
+		},
+		{
+			name: "case 3: reader",
+			permissions: []permission{
+				{
+					Actions: []string{"*/read"},
+				},
+			},
+			action:          "Microsoft.Network/virtualNetworks/write",
+			expectedAllowed: false,
+		},
+		{
+			name: "case 4: reader and network contributor",
+			permissions: []permission{
+				{
+					Actions: []string{"*/read"},
+				},
+				{
+					Actions: []string{"Microsoft.Network/*"},
+				},
+			},
+			action:          "Microsoft.Network/virtualNetworks/write",
+			expectedAllowed: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			allowed := isActionAllowed(tc.permissions, tc.action)
+			if allowed != tc.expectedAllowed {
+				t.Fatalf("allowed == %t, want %t", allowed, tc.expectedAllowed)
+			}
+		})
+	}
+}
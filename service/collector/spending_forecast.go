@@ -0,0 +1,115 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+var (
+	spendingForecastDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "subscription", "spending_forecast"),
+		"Projected spend of the subscription at the end of the current month, made of the actual cost so far and the Cost Management forecast for the remaining days.",
+		[]string{
+			"subscription",
+			"currency",
+		},
+		nil,
+	)
+)
+
+type SpendingForecastConfig struct {
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+
+	GSTenantID string
+}
+
+type SpendingForecast struct {
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+	costQuerier      *costQuerier
+
+	gsTenantID string
+}
+
+// NewSpendingForecast exposes the projected month-end spend of the subscriptions found in the "credential-*" secrets
+// of the control plane, so we can alert before a spending limit or credit is exhausted.
+func NewSpendingForecast(config SpendingForecastConfig) (*SpendingForecast, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	s := &SpendingForecast{
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		costQuerier:      newCostQuerier(),
+		gsTenantID:       config.GSTenantID,
+	}
+
+	return s, nil
+}
+
+func (s *SpendingForecast) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := s.collections.newCollectContext("spending_forecast")
+	defer cancel()
+	clientSets, err := credential.GetAzureClientSetsFromCredentialSecretsBySubscription(ctx, s.credentialSource, s.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	from := startOfMonth(time.Now())
+	query := actualCostQuery(from, from.AddDate(0, 1, 0).Add(-time.Second))
+	query.IncludeActualCost = to.BoolPtr(true)
+	query.IncludeFreshPartialCost = to.BoolPtr(false)
+
+	for subscriptionID, azureClientSet := range clientSets {
+		rows, err := s.costQuerier.Query(ctx, azureClientSet.RESTClient, "/subscriptions/"+subscriptionID+"/providers/Microsoft.CostManagement/forecast", query)
+		if err != nil {
+			s.logger.Errorf(ctx, err, "an error occurred fetching the spending forecast of subscription %#q", subscriptionID)
+			continue
+		}
+
+		// The forecast holds the actual cost for the past days and the
+		// forecasted cost for the remaining days of the month.
+		forecasts := map[string]float64{}
+		for _, row := range rows {
+			forecasts[row.String(costColumnCurrency)] += row.Float(costColumnCost)
+		}
+
+		for currency, forecast := range forecasts {
+			ch <- prometheus.MustNewConstMetric(
+				spendingForecastDesc,
+				prometheus.GaugeValue,
+				forecast,
+				subscriptionID,
+				currency,
+			)
+		}
+	}
+
+	return nil
+}
+
+func (s *SpendingForecast) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- spendingForecastDesc
+	return nil
+}
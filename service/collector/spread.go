@@ -0,0 +1,74 @@
+package collector
+
+import (
+	"context"
+	"hash/fnv"
+	"time"
+)
+
+const (
+	// startJitter is the fraction of the start spread collections are
+	// delayed by at random in addition to the phase of their collector.
+	startJitter = 0.1
+	// maxStartDelayFraction is the maximum fraction of the time left until
+	// the deadline of a collection its start is delayed by, so delayed
+	// collections still have most of their time for the Azure APIs.
+	maxStartDelayFraction = 0.25
+)
+
+// waitForStart delays the start of a collection of the given collector by its
+// start delay, so the collectors of a scrape, including the first one after
+// booting, do not send their Azure calls all at once and trip the throttling
+// of the subscriptions. It returns early once the given context is done.
+func (c *Collections) waitForStart(ctx context.Context, collector string, deadline time.Time, hasDeadline bool) {
+	c.settingsMutex.RLock()
+	spread := c.startSpread
+	c.settingsMutex.RUnlock()
+
+	if spread <= 0 {
+		return
+	}
+
+	c.randMutex.Lock()
+	jitter := c.random.Float64()
+	c.randMutex.Unlock()
+
+	delay := startDelay(collector, spread, jitter, time.Now(), deadline, hasDeadline)
+	if delay <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// startDelay returns how long a collection of the given collector started at
+// the given time is delayed. Collectors are spread over the given window by a
+// phase derived from their name, so they start at the same offset on every
+// scrape, plus the given jitter between 0 and 1 of startJitter of the window.
+// The delay is bounded by maxStartDelayFraction of the time left until the
+// deadline of the collection, if any.
+func startDelay(collector string, spread time.Duration, jitter float64, now, deadline time.Time, hasDeadline bool) time.Duration {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(collector))
+
+	phase := time.Duration(float64(h.Sum32()) / (1 << 32) * float64(spread))
+	delay := phase + time.Duration(jitter*startJitter*float64(spread))
+
+	if hasDeadline {
+		max := time.Duration(maxStartDelayFraction * float64(deadline.Sub(now)))
+		if max < 0 {
+			max = 0
+		}
+		if delay > max {
+			delay = max
+		}
+	}
+
+	return delay
+}
@@ -0,0 +1,74 @@
+package collector
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_startDelay(t *testing.T) {
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	spread := 10 * time.Second
+
+	phase := startDelay("resource_group", spread, 0, now, time.Time{}, false)
+	if phase < 0 || phase >= spread {
+		t.Fatalf("expected phase within [0, %s), got %s", spread, phase)
+	}
+
+	testCases := []struct {
+		name          string
+		collector     string
+		jitter        float64
+		deadline      time.Time
+		hasDeadline   bool
+		expectedDelay time.Duration
+	}{
+		{
+			name:          "case 0: collectors start at their phase",
+			collector:     "resource_group",
+			expectedDelay: phase,
+		},
+		{
+			name:          "case 1: jitter is added to the phase",
+			collector:     "resource_group",
+			jitter:        0.5,
+			expectedDelay: phase + 500*time.Millisecond,
+		},
+		{
+			name:          "case 2: delay is bounded by the time left until the deadline",
+			collector:     "resource_group",
+			jitter:        1,
+			deadline:      now.Add(2 * time.Second),
+			hasDeadline:   true,
+			expectedDelay: 500 * time.Millisecond,
+		},
+		{
+			name:          "case 3: collections past their deadline start right away",
+			collector:     "resource_group",
+			deadline:      now.Add(-time.Second),
+			hasDeadline:   true,
+			expectedDelay: 0,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			delay := startDelay(tc.collector, spread, tc.jitter, now, tc.deadline, tc.hasDeadline)
+			if delay != tc.expectedDelay {
+				t.Fatalf("expected delay %s, got %s", tc.expectedDelay, delay)
+			}
+		})
+	}
+
+	// Collectors are spread over the window rather than starting at the
+	// same time.
+	phases := map[time.Duration]bool{}
+	for _, collector := range []string{"deployment", "node_vmss", "resource_group", "usage", "vpn_connection"} {
+		phases[startDelay(collector, spread, 0, now, time.Time{}, false)] = true
+	}
+	if len(phases) < 2 {
+		t.Fatalf("expected collectors spread over different phases, got %v", phases)
+	}
+}
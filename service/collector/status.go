@@ -0,0 +1,52 @@
+package collector
+
+import (
+	"sort"
+	"time"
+
+	"github.com/giantswarm/microerror"
+)
+
+// Status is the status of a collector of the set along with the metrics it
+// exports.
+type Status struct {
+	Name     string
+	Priority string
+	// Collected is whether the collector is collected under the pressure it
+	// is under. Low priority collectors are skipped for a while after their
+	// collection ran into its deadline.
+	Collected bool
+	Metrics   []Descriptor
+}
+
+// Statuses returns the statuses of all collectors of the set ordered by
+// name.
+func (s *Set) Statuses() ([]Status, error) {
+	now := time.Now()
+
+	var statuses []Status
+	for _, c := range s.collectors {
+		p := priorityCritical
+		collected := true
+		if g, ok := c.(*priorityGuard); ok {
+			p = g.priority
+			collected = g.collected(now)
+		}
+
+		metrics, err := describeCollector(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		statuses = append(statuses, Status{
+			Name:      collectorName(c),
+			Priority:  p.String(),
+			Collected: collected && !s.collections.isDisabled(collectorName(c)),
+			Metrics:   metrics,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+
+	return statuses, nil
+}
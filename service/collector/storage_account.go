@@ -0,0 +1,228 @@
+package collector
+
+import (
+	"context"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	storageUsedCapacityMetric    = "UsedCapacity"
+	storageTransactionsMetric    = "Transactions"
+	storageResponseTypeDimension = "ResponseType"
+
+	// storageAccountAPIVersion is the storage API version used to fetch
+	// properties which are missing from the SDK version we use, like the
+	// creation time of the access keys.
+	storageAccountAPIVersion = "2021-04-01"
+
+	// Capacity metrics of storage accounts are only emitted once per hour.
+	storageCapacityTimespan = 3 * time.Hour
+	storageCapacityInterval = "PT1H"
+)
+
+// storageServiceObjectMetrics maps the storage services to the Azure Monitor
+// metric counting their objects. Azure Monitor exposes them on the service
+// sub-resources of the storage account.
+var storageServiceObjectMetrics = map[string]string{
+	"blobServices":  "BlobCount",
+	"fileServices":  "FileCount",
+	"tableServices": "TableEntityCount",
+}
+
+// storageAccountProperties holds the subset of the storage account
+// properties we need, which is missing from the SDK version we use.
+type storageAccountProperties struct {
+	Properties struct {
+		AllowBlobPublicAccess *bool `json:"allowBlobPublicAccess"`
+		AllowSharedKeyAccess  *bool `json:"allowSharedKeyAccess"`
+		Encryption            *struct {
+			RequireInfrastructureEncryption *bool `json:"requireInfrastructureEncryption"`
+		} `json:"encryption"`
+		KeyCreationTime map[string]*time.Time `json:"keyCreationTime"`
+		KeyPolicy       *struct {
+			KeyExpirationPeriodInDays int32 `json:"keyExpirationPeriodInDays"`
+		} `json:"keyPolicy"`
+		MinimumTLSVersion string `json:"minimumTlsVersion"`
+	} `json:"properties"`
+}
+
+var (
+	storageAccountUsedCapacityDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "storage_account", "used_capacity_bytes"),
+		"Used capacity of the storage account in bytes.",
+		[]string{
+			"resource_group",
+			"storage_account",
+		},
+		nil,
+	)
+	storageAccountObjectsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "storage_account", "objects"),
+		"Number of blobs, files or table entities in the storage account.",
+		[]string{
+			"resource_group",
+			"storage_account",
+			"service",
+		},
+		nil,
+	)
+	storageAccountTransactionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "storage_account", "transactions"),
+		"Number of transactions on the storage account during the last five minutes by response type.",
+		[]string{
+			"resource_group",
+			"storage_account",
+			"response_type",
+		},
+		nil,
+	)
+)
+
+type StorageAccountConfig struct {
+	CredentialSource          *credential.Source
+	Collections               *Collections
+	Logger                    micrologger.Logger
+	ControlPlaneResourceGroup string
+	GSTenantID                string
+}
+
+type StorageAccount struct {
+	credentialSource          *credential.Source
+	collections               *Collections
+	logger                    micrologger.Logger
+	controlPlaneResourceGroup string
+	gsTenantID                string
+}
+
+// NewStorageAccount exposes capacity and transaction metrics of the storage accounts in the managed resource groups,
+// e.g. the accounts holding the ignition data of the nodes or the etcd backups.
+func NewStorageAccount(config StorageAccountConfig) (*StorageAccount, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.ControlPlaneResourceGroup == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ControlPlaneResourceGroup must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	s := &StorageAccount{
+		credentialSource:          config.CredentialSource,
+		collections:               config.Collections,
+		logger:                    config.Logger,
+		controlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+		gsTenantID:                config.GSTenantID,
+	}
+
+	return s, nil
+}
+
+func (s *StorageAccount) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := s.collections.newCollectContext("storage_account")
+	defer cancel()
+
+	resourceGroups, err := getManagedResourceGroups(ctx, s.credentialSource, s.gsTenantID, s.controlPlaneResourceGroup)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, resourceGroup := range resourceGroups {
+		accounts, err := resourceGroup.AzureClientSet.StorageAccountsClient.ListByResourceGroup(ctx, resourceGroup.Name)
+		if IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+		if accounts.Value == nil {
+			continue
+		}
+
+		for _, account := range *accounts.Value {
+			accountID := to.String(account.ID)
+			accountName := to.String(account.Name)
+			metricsClient := resourceGroup.AzureClientSet.MetricsClient
+
+			values, err := getMonitorMetricValuesWithTimespan(ctx, metricsClient, accountID, []string{storageUsedCapacityMetric}, aggregationAverage, "", storageCapacityTimespan, storageCapacityInterval)
+			if err != nil {
+				s.logger.Errorf(ctx, err, "an error occurred fetching the used capacity of storage account %#q", accountName)
+			}
+			for _, v := range values {
+				ch <- prometheus.MustNewConstMetric(
+					storageAccountUsedCapacityDesc,
+					prometheus.GaugeValue,
+					v.Value,
+					resourceGroup.Name,
+					accountName,
+				)
+			}
+
+			values, err = getMonitorMetricValues(ctx, metricsClient, accountID, []string{storageTransactionsMetric}, aggregationTotal, storageResponseTypeDimension+" eq '*'")
+			if err != nil {
+				s.logger.Errorf(ctx, err, "an error occurred fetching the transactions of storage account %#q", accountName)
+			}
+			for _, v := range values {
+				ch <- prometheus.MustNewConstMetric(
+					storageAccountTransactionsDesc,
+					prometheus.GaugeValue,
+					v.Value,
+					resourceGroup.Name,
+					accountName,
+					v.Dimension(storageResponseTypeDimension),
+				)
+			}
+
+			for service, metricName := range storageServiceObjectMetrics {
+				values, err := getMonitorMetricValuesWithTimespan(ctx, metricsClient, accountID+"/"+service+"/default", []string{metricName}, aggregationAverage, "", storageCapacityTimespan, storageCapacityInterval)
+				if err != nil {
+					s.logger.Errorf(ctx, err, "an error occurred fetching the %#q metric of storage account %#q", metricName, accountName)
+					continue
+				}
+				for _, v := range values {
+					ch <- prometheus.MustNewConstMetric(
+						storageAccountObjectsDesc,
+						prometheus.GaugeValue,
+						v.Value,
+						resourceGroup.Name,
+						accountName,
+						service,
+					)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *StorageAccount) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- storageAccountUsedCapacityDesc
+	ch <- storageAccountObjectsDesc
+	ch <- storageAccountTransactionsDesc
+	return nil
+}
+
+func getStorageAccountProperties(ctx context.Context, restClient *client.RESTClient, accountID string) (storageAccountProperties, error) {
+	var properties storageAccountProperties
+
+	err := restClient.GetJSON(ctx, accountID, storageAccountAPIVersion, &properties)
+	if err != nil {
+		return storageAccountProperties{}, microerror.Mask(err)
+	}
+
+	return properties, nil
+}
@@ -0,0 +1,148 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+var (
+	storageAccountKeyAgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "storage_account_key", "age_seconds"),
+		"Time since the storage account access key was created or last rotated.",
+		[]string{
+			"resource_group",
+			"storage_account",
+			"key",
+		},
+		nil,
+	)
+	storageAccountKeyExpirationPeriodDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "storage_account_key", "expiration_period_days"),
+		"Key expiration period in days configured by the storage account key policy.",
+		[]string{
+			"resource_group",
+			"storage_account",
+		},
+		nil,
+	)
+)
+
+type StorageAccountKeyConfig struct {
+	CredentialSource          *credential.Source
+	Collections               *Collections
+	Logger                    micrologger.Logger
+	ControlPlaneResourceGroup string
+	GSTenantID                string
+}
+
+type StorageAccountKey struct {
+	credentialSource          *credential.Source
+	collections               *Collections
+	logger                    micrologger.Logger
+	controlPlaneResourceGroup string
+	gsTenantID                string
+}
+
+// NewStorageAccountKey exposes the age of the access keys of the storage accounts in the managed resource groups,
+// so key rotation SLAs can be enforced via alerts.
+func NewStorageAccountKey(config StorageAccountKeyConfig) (*StorageAccountKey, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.ControlPlaneResourceGroup == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ControlPlaneResourceGroup must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	s := &StorageAccountKey{
+		credentialSource:          config.CredentialSource,
+		collections:               config.Collections,
+		logger:                    config.Logger,
+		controlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+		gsTenantID:                config.GSTenantID,
+	}
+
+	return s, nil
+}
+
+func (s *StorageAccountKey) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := s.collections.newCollectContext("storage_account_key")
+	defer cancel()
+
+	resourceGroups, err := getManagedResourceGroups(ctx, s.credentialSource, s.gsTenantID, s.controlPlaneResourceGroup)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	now := time.Now()
+	for _, resourceGroup := range resourceGroups {
+		accounts, err := resourceGroup.AzureClientSet.StorageAccountsClient.ListByResourceGroup(ctx, resourceGroup.Name)
+		if IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+		if accounts.Value == nil {
+			continue
+		}
+
+		for _, account := range *accounts.Value {
+			accountName := to.String(account.Name)
+
+			properties, err := getStorageAccountProperties(ctx, resourceGroup.AzureClientSet.RESTClient, to.String(account.ID))
+			if err != nil {
+				s.logger.Errorf(ctx, err, "an error occurred fetching the key properties of storage account %#q", accountName)
+				continue
+			}
+
+			// The creation time is only tracked for keys which were created
+			// or rotated after Azure introduced the property.
+			for keyName, created := range properties.Properties.KeyCreationTime {
+				if created == nil {
+					continue
+				}
+
+				ch <- prometheus.MustNewConstMetric(
+					storageAccountKeyAgeDesc,
+					prometheus.GaugeValue,
+					now.Sub(*created).Seconds(),
+					resourceGroup.Name,
+					accountName,
+					keyName,
+				)
+			}
+
+			if properties.Properties.KeyPolicy != nil {
+				ch <- prometheus.MustNewConstMetric(
+					storageAccountKeyExpirationPeriodDesc,
+					prometheus.GaugeValue,
+					float64(properties.Properties.KeyPolicy.KeyExpirationPeriodInDays),
+					resourceGroup.Name,
+					accountName,
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *StorageAccountKey) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- storageAccountKeyAgeDesc
+	ch <- storageAccountKeyExpirationPeriodDesc
+	return nil
+}
@@ -0,0 +1,179 @@
+package collector
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2019-06-01/storage"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	storageAccountsUsageName = "StorageAccounts"
+
+	// defaultStorageAccountLimit is the number of storage accounts allowed
+	// per region and subscription, unless a quota increase was requested.
+	defaultStorageAccountLimit = 250
+)
+
+var (
+	storageAccountCountDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "storage_account_quota", "current"),
+		"Number of storage accounts in the subscription and region.",
+		[]string{
+			"subscription",
+			"location",
+		},
+		nil,
+	)
+	storageAccountLimitDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "storage_account_quota", "limit"),
+		"Maximum number of storage accounts allowed in the subscription and region.",
+		[]string{
+			"subscription",
+			"location",
+		},
+		nil,
+	)
+)
+
+type StorageAccountQuotaConfig struct {
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+
+	Location   string
+	GSTenantID string
+}
+
+type StorageAccountQuota struct {
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+
+	location   string
+	gsTenantID string
+}
+
+// NewStorageAccountQuota exposes the number of storage accounts per region against the per region limit of the
+// subscription. Reaching the limit silently blocks the creation of new clusters.
+// It exposes metrics for every subscription found in the "credential-*" secrets of the control plane.
+func NewStorageAccountQuota(config StorageAccountQuotaConfig) (*StorageAccountQuota, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.Location == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Location must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	s := &StorageAccountQuota{
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		location:         config.Location,
+		gsTenantID:       config.GSTenantID,
+	}
+
+	return s, nil
+}
+
+func (s *StorageAccountQuota) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := s.collections.newCollectContext("storage_account_quota")
+	defer cancel()
+	clientSets, err := credential.GetAzureClientSetsFromCredentialSecretsBySubscription(ctx, s.credentialSource, s.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for subscriptionID, azureClientSet := range clientSets {
+		// We always report the installation location, even when the
+		// subscription has no storage accounts there yet.
+		counts := map[string]int{
+			normalizeLocation(s.location): 0,
+		}
+
+		iterator, err := azureClientSet.StorageAccountsClient.ListComplete(ctx)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for iterator.NotDone() {
+			location := normalizeLocation(to.String(iterator.Value().Location))
+			counts[location]++
+
+			err = iterator.NextWithContext(ctx)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		}
+
+		for location, count := range counts {
+			limit, err := s.getStorageAccountLimit(ctx, azureClientSet.StorageUsagesClient, location)
+			if err != nil {
+				s.logger.Errorf(ctx, err, "an error occurred fetching the storage account limit of subscription %#q in location %#q", subscriptionID, location)
+				limit = defaultStorageAccountLimit
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				storageAccountCountDesc,
+				prometheus.GaugeValue,
+				float64(count),
+				subscriptionID,
+				location,
+			)
+			ch <- prometheus.MustNewConstMetric(
+				storageAccountLimitDesc,
+				prometheus.GaugeValue,
+				float64(limit),
+				subscriptionID,
+				location,
+			)
+		}
+	}
+
+	return nil
+}
+
+func (s *StorageAccountQuota) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- storageAccountCountDesc
+	ch <- storageAccountLimitDesc
+	return nil
+}
+
+func (s *StorageAccountQuota) getStorageAccountLimit(ctx context.Context, usagesClient *storage.UsagesClient, location string) (int32, error) {
+	usages, err := usagesClient.ListByLocation(ctx, location)
+	if err != nil {
+		return 0, microerror.Mask(err)
+	}
+
+	if usages.Value != nil {
+		for _, usage := range *usages.Value {
+			if usage.Name != nil && to.String(usage.Name.Value) == storageAccountsUsageName && usage.Limit != nil {
+				return *usage.Limit, nil
+			}
+		}
+	}
+
+	return defaultStorageAccountLimit, nil
+}
+
+// normalizeLocation turns display names like "West Europe" into location
+// names like "westeurope".
+func normalizeLocation(location string) string {
+	return strings.ToLower(strings.ReplaceAll(location, " ", ""))
+}
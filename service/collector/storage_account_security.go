@@ -0,0 +1,164 @@
+package collector
+
+import (
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	storageSecurityCheckMinimumTLSVersion        = "minimum_tls_version"
+	storageSecurityCheckPublicBlobAccess         = "public_blob_access_disabled"
+	storageSecurityCheckSharedKeyAccess          = "shared_key_access_disabled"
+	storageSecurityCheckInfrastructureEncryption = "infrastructure_encryption_enabled"
+
+	storageRequiredTLSVersion = "TLS1_2"
+)
+
+var (
+	storageAccountSecurityDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "storage_account", "security_compliant"),
+		"Whether the storage account complies with the given security check.",
+		[]string{
+			"resource_group",
+			"storage_account",
+			"check",
+		},
+		nil,
+	)
+	storageAccountTLSVersionDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "storage_account", "minimum_tls_version_info"),
+		"Minimum TLS version accepted by the storage account.",
+		[]string{
+			"resource_group",
+			"storage_account",
+			"version",
+		},
+		nil,
+	)
+)
+
+type StorageAccountSecurityConfig struct {
+	CredentialSource          *credential.Source
+	Collections               *Collections
+	Logger                    micrologger.Logger
+	ControlPlaneResourceGroup string
+	GSTenantID                string
+}
+
+type StorageAccountSecurity struct {
+	credentialSource          *credential.Source
+	collections               *Collections
+	logger                    micrologger.Logger
+	controlPlaneResourceGroup string
+	gsTenantID                string
+}
+
+// NewStorageAccountSecurity exposes the security settings of the storage accounts in the managed resource groups
+// as boolean gauges which drive security compliance dashboards.
+func NewStorageAccountSecurity(config StorageAccountSecurityConfig) (*StorageAccountSecurity, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.ControlPlaneResourceGroup == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ControlPlaneResourceGroup must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	s := &StorageAccountSecurity{
+		credentialSource:          config.CredentialSource,
+		collections:               config.Collections,
+		logger:                    config.Logger,
+		controlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+		gsTenantID:                config.GSTenantID,
+	}
+
+	return s, nil
+}
+
+func (s *StorageAccountSecurity) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := s.collections.newCollectContext("storage_account_security")
+	defer cancel()
+
+	resourceGroups, err := getManagedResourceGroups(ctx, s.credentialSource, s.gsTenantID, s.controlPlaneResourceGroup)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, resourceGroup := range resourceGroups {
+		accounts, err := resourceGroup.AzureClientSet.StorageAccountsClient.ListByResourceGroup(ctx, resourceGroup.Name)
+		if IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return microerror.Mask(err)
+		}
+		if accounts.Value == nil {
+			continue
+		}
+
+		for _, account := range *accounts.Value {
+			accountName := to.String(account.Name)
+
+			properties, err := getStorageAccountProperties(ctx, resourceGroup.AzureClientSet.RESTClient, to.String(account.ID))
+			if err != nil {
+				s.logger.Errorf(ctx, err, "an error occurred fetching the security properties of storage account %#q", accountName)
+				continue
+			}
+
+			p := properties.Properties
+
+			// Public blob access and shared key access are allowed unless
+			// explicitly disabled.
+			checks := map[string]bool{
+				storageSecurityCheckMinimumTLSVersion:        p.MinimumTLSVersion == storageRequiredTLSVersion,
+				storageSecurityCheckPublicBlobAccess:         p.AllowBlobPublicAccess != nil && !*p.AllowBlobPublicAccess,
+				storageSecurityCheckSharedKeyAccess:          p.AllowSharedKeyAccess != nil && !*p.AllowSharedKeyAccess,
+				storageSecurityCheckInfrastructureEncryption: p.Encryption != nil && to.Bool(p.Encryption.RequireInfrastructureEncryption),
+			}
+
+			for check, compliant := range checks {
+				var v float64
+				if compliant {
+					v = 1
+				}
+
+				ch <- prometheus.MustNewConstMetric(
+					storageAccountSecurityDesc,
+					prometheus.GaugeValue,
+					v,
+					resourceGroup.Name,
+					accountName,
+					check,
+				)
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				storageAccountTLSVersionDesc,
+				prometheus.GaugeValue,
+				gaugeValue,
+				resourceGroup.Name,
+				accountName,
+				p.MinimumTLSVersion,
+			)
+		}
+	}
+
+	return nil
+}
+
+func (s *StorageAccountSecurity) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- storageAccountSecurityDesc
+	ch <- storageAccountTLSVersionDesc
+	return nil
+}
@@ -0,0 +1,129 @@
+package collector
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	providerv1alpha1 "github.com/giantswarm/apiextensions/v2/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/api/v1alpha3"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// stuckDeletionThreshold is how long a cluster CR has to be deleted
+	// before we consider its deletion stuck. Deleting a cluster takes a
+	// while, as all its Azure resources are deleted first.
+	stuckDeletionThreshold = 1 * time.Hour
+)
+
+var (
+	stuckDeletionDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "deletion", "stuck"),
+		"Deletion date in Unix time of cluster CRs which are deleted for more than an hour, labeled with the finalizers blocking their deletion.",
+		[]string{
+			"kind",
+			"namespace",
+			"name",
+			"finalizers",
+		},
+		nil,
+	)
+)
+
+type StuckDeletionConfig struct {
+	CtrlClient  ctrlclient.Client
+	Collections *Collections
+	Logger      micrologger.Logger
+}
+
+type StuckDeletion struct {
+	ctrlClient  ctrlclient.Client
+	collections *Collections
+	logger      micrologger.Logger
+}
+
+// NewStuckDeletion exposes the AzureConfig and Cluster CRs whose deletion is blocked by finalizers for more than an
+// hour, so clusters which are never deleted completely are noticed.
+func NewStuckDeletion(config StuckDeletionConfig) (*StuckDeletion, error) {
+	if config.CtrlClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CtrlClient must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	s := &StuckDeletion{
+		ctrlClient:  config.CtrlClient,
+		collections: config.Collections,
+		logger:      config.Logger,
+	}
+
+	return s, nil
+}
+
+func (s *StuckDeletion) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := s.collections.newCollectContext("stuck_deletion")
+	defer cancel()
+
+	objects := map[string][]metav1.ObjectMeta{}
+	{
+		azureConfigs := &providerv1alpha1.AzureConfigList{}
+		err := s.ctrlClient.List(ctx, azureConfigs, ctrlclient.InNamespace(metav1.NamespaceAll))
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, azureConfig := range azureConfigs.Items {
+			objects["AzureConfig"] = append(objects["AzureConfig"], azureConfig.ObjectMeta)
+		}
+	}
+	{
+		clusters := &v1alpha3.ClusterList{}
+		err := s.ctrlClient.List(ctx, clusters, ctrlclient.InNamespace(metav1.NamespaceAll))
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, cluster := range clusters.Items {
+			objects["Cluster"] = append(objects["Cluster"], cluster.ObjectMeta)
+		}
+	}
+
+	now := time.Now()
+
+	for kind, metas := range objects {
+		for _, meta := range metas {
+			if meta.DeletionTimestamp == nil || now.Sub(meta.DeletionTimestamp.Time) < stuckDeletionThreshold {
+				continue
+			}
+
+			finalizers := append([]string{}, meta.Finalizers...)
+			sort.Strings(finalizers)
+
+			ch <- prometheus.MustNewConstMetric(
+				stuckDeletionDesc,
+				prometheus.GaugeValue,
+				float64(meta.DeletionTimestamp.Unix()),
+				kind,
+				meta.Namespace,
+				meta.Name,
+				strings.Join(finalizers, ","),
+			)
+		}
+	}
+
+	return nil
+}
+
+func (s *StuckDeletion) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- stuckDeletionDesc
+	return nil
+}
@@ -0,0 +1,202 @@
+package collector
+
+import (
+	"context"
+	"net"
+
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-11-01/network"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	// azureReservedSubnetAddresses is the number of addresses Azure reserves
+	// in every subnet: the network address, the default gateway, two
+	// addresses for Azure DNS and the broadcast address.
+	azureReservedSubnetAddresses = 5
+)
+
+var (
+	subnetIPConfigurationsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "subnet", "ip_configurations"),
+		"Number of NIC IP configurations in the subnet.",
+		[]string{
+			"cluster_id",
+			"vnet",
+			"subnet",
+		},
+		nil,
+	)
+	subnetIPCapacityDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "subnet", "ip_capacity"),
+		"Number of usable IP addresses in the subnet.",
+		[]string{
+			"cluster_id",
+			"vnet",
+			"subnet",
+		},
+		nil,
+	)
+)
+
+type SubnetIPConfigurationConfig struct {
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+	GSTenantID       string
+}
+
+type SubnetIPConfiguration struct {
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+	gsTenantID       string
+}
+
+// NewSubnetIPConfiguration exposes metrics about the number of NIC IP configurations per cluster subnet relative to its size.
+// With Azure CNI every pod gets an IP configuration, so this is the real constraint for clusters with many pods per node.
+func NewSubnetIPConfiguration(config SubnetIPConfigurationConfig) (*SubnetIPConfiguration, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	s := &SubnetIPConfiguration{
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		gsTenantID:       config.GSTenantID,
+	}
+
+	return s, nil
+}
+
+func (s *SubnetIPConfiguration) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := s.collections.newCollectContext("subnet_ip_configuration")
+	defer cancel()
+
+	azureClientSets, err := getClientSetsByCluster(ctx, s.credentialSource, s.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	forEachCluster(ctx, ch, azureClientSets, func(ctx context.Context, ch chan<- prometheus.Metric, clusterID string, azureClientSet *client.AzureClientSet) error {
+		vnets, err := azureClientSet.VirtualNetworksClient.ListComplete(ctx, clusterID)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for vnets.NotDone() {
+			vnet := vnets.Value()
+
+			if vnet.VirtualNetworkPropertiesFormat != nil && vnet.Subnets != nil {
+				for _, subnet := range *vnet.Subnets {
+					var ipConfigurations int
+					if subnet.SubnetPropertiesFormat != nil && subnet.IPConfigurations != nil {
+						ipConfigurations = len(*subnet.IPConfigurations)
+					}
+
+					ch <- prometheus.MustNewConstMetric(
+						subnetIPConfigurationsDesc,
+						prometheus.GaugeValue,
+						float64(ipConfigurations),
+						clusterID,
+						to.String(vnet.Name),
+						to.String(subnet.Name),
+					)
+
+					capacity, err := subnetCapacity(subnet)
+					if err != nil {
+						s.logger.Errorf(ctx, err, "an error occurred computing the capacity of subnet %#q", to.String(subnet.Name))
+						continue
+					}
+
+					ch <- prometheus.MustNewConstMetric(
+						subnetIPCapacityDesc,
+						prometheus.GaugeValue,
+						float64(capacity),
+						clusterID,
+						to.String(vnet.Name),
+						to.String(subnet.Name),
+					)
+				}
+			}
+
+			if err := vnets.NextWithContext(ctx); err != nil {
+				return microerror.Mask(err)
+			}
+		}
+
+		return nil
+	})
+
+	return nil
+}
+
+func (s *SubnetIPConfiguration) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- subnetIPConfigurationsDesc
+	ch <- subnetIPCapacityDesc
+	return nil
+}
+
+// subnetCapacity returns the number of usable IPv4 addresses of the given
+// subnet across all of its address prefixes.
+func subnetCapacity(subnet network.Subnet) (int64, error) {
+	if subnet.SubnetPropertiesFormat == nil {
+		return 0, nil
+	}
+
+	var prefixes []string
+	if subnet.AddressPrefix != nil {
+		prefixes = append(prefixes, *subnet.AddressPrefix)
+	}
+	if subnet.AddressPrefixes != nil {
+		prefixes = append(prefixes, *subnet.AddressPrefixes...)
+	}
+
+	var capacity int64
+	for _, prefix := range prefixes {
+		c, err := cidrCapacity(prefix)
+		if err != nil {
+			return 0, microerror.Mask(err)
+		}
+		capacity += c
+	}
+
+	return capacity, nil
+}
+
+// cidrCapacity returns the number of addresses in the given IPv4 CIDR which
+// can be assigned to IP configurations.
+func cidrCapacity(cidr string) (int64, error) {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return 0, microerror.Mask(err)
+	}
+
+	ones, bits := ipNet.Mask.Size()
+	if bits != 32 {
+		// Only IPv4 subnets constrain the number of IP configurations.
+		return 0, nil
+	}
+
+	capacity := int64(1)<<uint(bits-ones) - azureReservedSubnetAddresses
+	if capacity < 0 {
+		return 0, nil
+	}
+
+	return capacity, nil
+}
@@ -0,0 +1,69 @@
+package collector
+
+import (
+	"strconv"
+	"testing"
+)
+
+func Test_cidrCapacity(t *testing.T) {
+	testCases := []struct {
+		name             string
+		cidr             string
+		expectedCapacity int64
+		errorMatcher     func(error) bool
+	}{
+		{
+			name:             "case 0: /24 subnet",
+			cidr:             "10.1.0.0/24",
+			expectedCapacity: 251,
+		},
+		{
+			name:             "case 1: /16 subnet",
+			cidr:             "10.0.0.0/16",
+			expectedCapacity: 65531,
+		},
+		{
+			name:             "case 2: /29 is the smallest subnet allowed by Azure",
+			cidr:             "10.1.0.0/29",
+			expectedCapacity: 3,
+		},
+		{
+			name:             "case 3: /32 has no usable addresses",
+			cidr:             "10.1.0.1/32",
+			expectedCapacity: 0,
+		},
+		{
+			name:             "case 4: IPv6 subnets are ignored",
+			cidr:             "fd00::/64",
+			expectedCapacity: 0,
+		},
+		{
+			name:         "case 5: invalid CIDR",
+			cidr:         "10.1.0.0",
+			errorMatcher: func(err error) bool { return err != nil },
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			capacity, err := cidrCapacity(tc.cidr)
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if capacity != tc.expectedCapacity {
+				t.Fatalf("expected capacity %d, got %d", tc.expectedCapacity, capacity)
+			}
+		})
+	}
+}
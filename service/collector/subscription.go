@@ -0,0 +1,120 @@
+package collector
+
+import (
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	subscriptionAPIVersion = "2020-01-01"
+)
+
+// subscriptionDetails holds the subset of the subscription properties we
+// need.
+type subscriptionDetails struct {
+	DisplayName          string `json:"displayName"`
+	State                string `json:"state"`
+	SubscriptionPolicies struct {
+		// QuotaID identifies the offer of the subscription, e.g.
+		// "PayAsYouGo_2014-09-01" or "MSDN_2014-09-01".
+		QuotaID       string `json:"quotaId"`
+		SpendingLimit string `json:"spendingLimit"`
+	} `json:"subscriptionPolicies"`
+}
+
+var (
+	subscriptionStateDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "subscription", "state"),
+		"State of the subscription, e.g. Enabled, Warned, PastDue or Disabled, together with its offer type and spending limit.",
+		[]string{
+			"subscription",
+			"display_name",
+			"state",
+			"offer_type",
+			"spending_limit",
+		},
+		nil,
+	)
+)
+
+type SubscriptionConfig struct {
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
+
+	GSTenantID string
+}
+
+type Subscription struct {
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
+
+	gsTenantID string
+}
+
+// NewSubscription exposes the state of the subscriptions found in the "credential-*" secrets of the control plane. A
+// disabled subscription stops all the clusters in it, so it should never go unnoticed.
+func NewSubscription(config SubscriptionConfig) (*Subscription, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	s := &Subscription{
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		gsTenantID:       config.GSTenantID,
+	}
+
+	return s, nil
+}
+
+func (s *Subscription) Collect(ch chan<- prometheus.Metric) error {
+	ctx, cancel := s.collections.newCollectContext("subscription")
+	defer cancel()
+	clientSets, err := credential.GetAzureClientSetsFromCredentialSecretsBySubscription(ctx, s.credentialSource, s.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for subscriptionID, azureClientSet := range clientSets {
+		var details subscriptionDetails
+		err := azureClientSet.RESTClient.GetJSON(ctx, "/subscriptions/"+subscriptionID, subscriptionAPIVersion, &details)
+		if err != nil {
+			s.logger.Errorf(ctx, err, "an error occurred fetching the details of subscription %#q", subscriptionID)
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			subscriptionStateDesc,
+			prometheus.GaugeValue,
+			gaugeValue,
+			subscriptionID,
+			details.DisplayName,
+			details.State,
+			details.SubscriptionPolicies.QuotaID,
+			details.SubscriptionPolicies.SpendingLimit,
+		)
+	}
+
+	return nil
+}
+
+func (s *Subscription) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- subscriptionStateDesc
+	return nil
+}
@@ -0,0 +1,234 @@
+package collector
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// successBuckets is the number of buckets the outcomes of collections
+	// are counted in per window. Windows are rounded to the width of their
+	// buckets, e.g. a minute for an hour.
+	successBuckets = 60
+	// minSuccessRatioWindow is the shortest window success ratios can be
+	// computed over.
+	minSuccessRatioWindow = time.Minute
+)
+
+var (
+	collectorSuccessRatioDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "collector", "success_ratio"),
+		"Ratio of the successful collections of the collector within the rolling window. Skipped collections are not counted.",
+		[]string{
+			"collector",
+			"window",
+		},
+		nil,
+	)
+	collectorCollectionsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "collector", "collections"),
+		"Number of collections of the collector within the rolling window. Skipped collections are not counted.",
+		[]string{
+			"collector",
+			"window",
+		},
+		nil,
+	)
+
+	collectorOutcomes = newOutcomeTracker()
+)
+
+// outcomeBucket counts the outcomes of the collections started within a
+// bucket.
+type outcomeBucket struct {
+	start   time.Time
+	success int
+	total   int
+}
+
+// outcomeTracker counts the outcomes of the collections of every collector
+// within the configured windows.
+type outcomeTracker struct {
+	mutex sync.Mutex
+	// buckets are the buckets of every collector and window, the oldest
+	// first.
+	buckets map[string]map[time.Duration][]outcomeBucket
+	windows []time.Duration
+}
+
+// outcomeCount is the number of successful and all collections of a
+// collector within a window.
+type outcomeCount struct {
+	collector string
+	window    time.Duration
+	success   int
+	total     int
+}
+
+func newOutcomeTracker() *outcomeTracker {
+	return &outcomeTracker{
+		buckets: map[string]map[time.Duration][]outcomeBucket{},
+	}
+}
+
+// setWindows sets the windows the success ratios are computed over.
+func (t *outcomeTracker) setWindows(windows []time.Duration) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.windows = append([]time.Duration{}, windows...)
+	sort.Slice(t.windows, func(i, j int) bool { return t.windows[i] < t.windows[j] })
+	t.buckets = map[string]map[time.Duration][]outcomeBucket{}
+}
+
+// record counts the outcome of a collection of the given collector.
+func (t *outcomeTracker) record(collector string, success bool, now time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.buckets[collector] == nil {
+		t.buckets[collector] = map[time.Duration][]outcomeBucket{}
+	}
+
+	for _, window := range t.windows {
+		start := now.Truncate(window / successBuckets)
+
+		buckets := t.buckets[collector][window]
+		if len(buckets) == 0 || buckets[len(buckets)-1].start.Before(start) {
+			buckets = append(buckets, outcomeBucket{start: start})
+		}
+
+		b := &buckets[len(buckets)-1]
+		b.total++
+		if success {
+			b.success++
+		}
+
+		// Buckets which fell out of the window are not needed anymore.
+		var i int
+		for i < len(buckets) && !buckets[i].start.After(start.Add(-window)) {
+			i++
+		}
+
+		t.buckets[collector][window] = buckets[i:]
+	}
+}
+
+// counts returns the number of successful and all collections of every
+// collector within every window. Collectors without collections within a
+// window are skipped.
+func (t *outcomeTracker) counts(now time.Time) []outcomeCount {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	var counts []outcomeCount
+	for collector, windows := range t.buckets {
+		for window, buckets := range windows {
+			oldest := now.Truncate(window / successBuckets).Add(-window)
+
+			c := outcomeCount{
+				collector: collector,
+				window:    window,
+			}
+			for _, b := range buckets {
+				if b.start.After(oldest) {
+					c.success += b.success
+					c.total += b.total
+				}
+			}
+
+			if c.total > 0 {
+				counts = append(counts, c)
+			}
+		}
+	}
+
+	return counts
+}
+
+// ParseSuccessRatioWindows parses the windows success ratios are computed
+// over, e.g. "1h", as passed on the command line.
+func ParseSuccessRatioWindows(values []string) ([]time.Duration, error) {
+	var windows []time.Duration
+	for _, value := range values {
+		window, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, microerror.Maskf(invalidConfigError, "invalid success ratio window %#q: %s", value, err)
+		}
+		if window < minSuccessRatioWindow {
+			return nil, microerror.Maskf(invalidConfigError, "success ratio window %#q must be at least %s", value, minSuccessRatioWindow)
+		}
+
+		windows = append(windows, window)
+	}
+
+	return windows, nil
+}
+
+// formatWindow formats the given window as label value, e.g. "1h" or "30m".
+func formatWindow(window time.Duration) string {
+	switch {
+	case window%time.Hour == 0:
+		return fmt.Sprintf("%dh", window/time.Hour)
+	case window%time.Minute == 0:
+		return fmt.Sprintf("%dm", window/time.Minute)
+	default:
+		return window.String()
+	}
+}
+
+type SuccessRatioConfig struct {
+	Logger micrologger.Logger
+}
+
+type SuccessRatio struct {
+	logger micrologger.Logger
+}
+
+// NewSuccessRatio exposes the ratio of successful collections of every
+// collector over rolling windows, so SLOs of the collector itself can be
+// defined without recording rules.
+func NewSuccessRatio(config SuccessRatioConfig) (*SuccessRatio, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	s := &SuccessRatio{
+		logger: config.Logger,
+	}
+
+	return s, nil
+}
+
+func (s *SuccessRatio) Collect(ch chan<- prometheus.Metric) error {
+	for _, c := range collectorOutcomes.counts(time.Now()) {
+		ch <- prometheus.MustNewConstMetric(
+			collectorSuccessRatioDesc,
+			prometheus.GaugeValue,
+			float64(c.success)/float64(c.total),
+			c.collector,
+			formatWindow(c.window),
+		)
+		ch <- prometheus.MustNewConstMetric(
+			collectorCollectionsDesc,
+			prometheus.GaugeValue,
+			float64(c.total),
+			c.collector,
+			formatWindow(c.window),
+		)
+	}
+
+	return nil
+}
+
+func (s *SuccessRatio) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- collectorSuccessRatioDesc
+	ch <- collectorCollectionsDesc
+	return nil
+}
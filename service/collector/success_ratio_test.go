@@ -0,0 +1,108 @@
+package collector
+
+import (
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_outcomeTracker(t *testing.T) {
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	type outcome struct {
+		collector string
+		success   bool
+		age       time.Duration
+	}
+
+	testCases := []struct {
+		name           string
+		windows        []time.Duration
+		outcomes       []outcome
+		expectedCounts []outcomeCount
+	}{
+		{
+			name:    "case 0: outcomes are counted per collector and window",
+			windows: []time.Duration{time.Hour, 24 * time.Hour},
+			outcomes: []outcome{
+				{collector: "collector.Usage", success: true, age: 2 * time.Hour},
+				{collector: "collector.Usage", success: false, age: 30 * time.Minute},
+				{collector: "collector.Usage", success: true, age: time.Minute},
+				{collector: "collector.Deployment", success: true, age: 3 * time.Hour},
+			},
+			expectedCounts: []outcomeCount{
+				{collector: "collector.Deployment", window: 24 * time.Hour, success: 1, total: 1},
+				{collector: "collector.Usage", window: time.Hour, success: 1, total: 2},
+				{collector: "collector.Usage", window: 24 * time.Hour, success: 2, total: 3},
+			},
+		},
+		{
+			name:    "case 1: outcomes older than the window are dropped",
+			windows: []time.Duration{time.Hour},
+			outcomes: []outcome{
+				{collector: "collector.Usage", success: false, age: 3 * time.Hour},
+				{collector: "collector.Usage", success: true, age: 0},
+			},
+			expectedCounts: []outcomeCount{
+				{collector: "collector.Usage", window: time.Hour, success: 1, total: 1},
+			},
+		},
+		{
+			name: "case 2: nothing is counted without windows",
+			outcomes: []outcome{
+				{collector: "collector.Usage", success: true},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			tracker := newOutcomeTracker()
+			tracker.setWindows(tc.windows)
+
+			// Outcomes are recorded in the order they happened.
+			sort.SliceStable(tc.outcomes, func(i, j int) bool { return tc.outcomes[i].age > tc.outcomes[j].age })
+			for _, o := range tc.outcomes {
+				tracker.record(o.collector, o.success, now.Add(-o.age))
+			}
+
+			counts := tracker.counts(now)
+			sort.Slice(counts, func(i, j int) bool {
+				if counts[i].collector != counts[j].collector {
+					return counts[i].collector < counts[j].collector
+				}
+				return counts[i].window < counts[j].window
+			})
+
+			if !cmp.Equal(counts, tc.expectedCounts, cmp.AllowUnexported(outcomeCount{})) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedCounts, counts, cmp.AllowUnexported(outcomeCount{})))
+			}
+		})
+	}
+}
+
+func Test_formatWindow(t *testing.T) {
+	testCases := []struct {
+		window   time.Duration
+		expected string
+	}{
+		{window: time.Hour, expected: "1h"},
+		{window: 168 * time.Hour, expected: "168h"},
+		{window: 30 * time.Minute, expected: "30m"},
+		{window: 90 * time.Second, expected: "1m30s"},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			window := formatWindow(tc.window)
+			if window != tc.expected {
+				t.Fatalf("window = %#q, want %#q", window, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package collector
+
+import (
+	"strings"
+
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+var (
+	tagComplianceResourceGroupDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "tag_compliance", "resource_group"),
+		"Whether the resource group has the required tag.",
+		[]string{
+			"cluster_id",
+			"resource_group",
+			"tag",
+		},
+		nil,
+	)
+	tagComplianceUntaggedResourcesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "tag_compliance", "untagged_resources"),
+		"Number of resources in the resource group missing the required tag.",
+		[]string{
+			"cluster_id",
+			"resource_group",
+			"tag",
+		},
+		nil,
+	)
+)
+
+type TagComplianceConfig struct {
+	CredentialSource          *credential.Source
+	Collections               *Collections
+	Logger                    micrologger.Logger
+	ControlPlaneResourceGroup string
+	GSTenantID                string
+	// RequiredTags are the tags every managed resource group and its
+	// resources are expected to have. Nothing is exposed when empty.
+	RequiredTags []string
+}
+
+type TagCompliance struct {
+	credentialSource          *credential.Source
+	collections               *Collections
+	logger                    micrologger.Logger
+	controlPlaneResourceGroup string
+	gsTenantID                string
+	requiredTags              []string
+}
+
+// NewTagCompliance exposes whether the managed resource groups and their resources have the required tags, so the
+// tagging policies cost allocation relies on can be enforced by alerts.
+func NewTagCompliance(config TagComplianceConfig) (*TagCompliance, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+	if config.ControlPlaneResourceGroup == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ControlPlaneResourceGroup must not be empty", config)
+	}
+	if config.GSTenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GSTenantID must not be empty", config)
+	}
+
+	t := &TagCompliance{
+		credentialSource:          config.CredentialSource,
+		collections:               config.Collections,
+		logger:                    config.Logger,
+		controlPlaneResourceGroup: config.ControlPlaneResourceGroup,
+		gsTenantID:                config.GSTenantID,
+		requiredTags:              config.RequiredTags,
+	}
+
+	return t, nil
+}
+
+func (t *TagCompliance) Collect(ch chan<- prometheus.Metric) error {
+	if len(t.requiredTags) == 0 {
+		return nil
+	}
+
+	ctx, cancel := t.collections.newCollectContext("tag_compliance")
+	defer cancel()
+
+	resourceGroups, err := getManagedResourceGroups(ctx, t.credentialSource, t.gsTenantID, t.controlPlaneResourceGroup)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, resourceGroup := range resourceGroups {
+		group, err := resourceGroup.AzureClientSet.GroupsClient.Get(ctx, resourceGroup.Name)
+		if IsNotFound(err) {
+			// The control plane resource group is not part of every
+			// subscription.
+			continue
+		} else if err != nil {
+			t.logger.Errorf(ctx, err, "an error occurred fetching resource group %#q", resourceGroup.Name)
+			continue
+		}
+
+		missing := missingTags(t.requiredTags, group.Tags)
+		for _, tag := range t.requiredTags {
+			var value float64
+			if !missing[tag] {
+				value = 1
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				tagComplianceResourceGroupDesc,
+				prometheus.GaugeValue,
+				value,
+				resourceGroup.ClusterID,
+				resourceGroup.Name,
+				tag,
+			)
+		}
+
+		iterator, err := resourceGroup.AzureClientSet.ResourcesClient.ListByResourceGroupComplete(ctx, resourceGroup.Name, "", "", nil)
+		if err != nil {
+			t.logger.Errorf(ctx, err, "an error occurred listing the resources of resource group %#q", resourceGroup.Name)
+			continue
+		}
+
+		untagged := map[string]int{}
+		for iterator.NotDone() {
+			for tag := range missingTags(t.requiredTags, iterator.Value().Tags) {
+				untagged[tag]++
+			}
+
+			err = iterator.NextWithContext(ctx)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+		}
+
+		for _, tag := range t.requiredTags {
+			ch <- prometheus.MustNewConstMetric(
+				tagComplianceUntaggedResourcesDesc,
+				prometheus.GaugeValue,
+				float64(untagged[tag]),
+				resourceGroup.ClusterID,
+				resourceGroup.Name,
+				tag,
+			)
+		}
+	}
+
+	return nil
+}
+
+func (t *TagCompliance) Describe(ch chan<- *prometheus.Desc) error {
+	ch <- tagComplianceResourceGroupDesc
+	ch <- tagComplianceUntaggedResourcesDesc
+	return nil
+}
+
+// missingTags returns the required tags which are not set or empty. Azure
+// treats tag names case insensitively, so we do too.
+func missingTags(required []string, tags map[string]*string) map[string]bool {
+	missing := map[string]bool{}
+	for _, r := range required {
+		var found bool
+		for k, v := range tags {
+			if strings.EqualFold(k, r) && to.String(v) != "" {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			missing[r] = true
+		}
+	}
+
+	return missing
+}
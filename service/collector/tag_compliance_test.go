@@ -0,0 +1,66 @@
+package collector
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_missingTags(t *testing.T) {
+	testCases := []struct {
+		name            string
+		required        []string
+		tags            map[string]*string
+		expectedMissing map[string]bool
+	}{
+		{
+			name:     "case 0: all tags set",
+			required: []string{"cost-center", "owner"},
+			tags: map[string]*string{
+				"cost-center": to.StringPtr("1234"),
+				"owner":       to.StringPtr("team-a"),
+			},
+			expectedMissing: map[string]bool{},
+		},
+		{
+			name:     "case 1: tag names are case insensitive",
+			required: []string{"cost-center"},
+			tags: map[string]*string{
+				"Cost-Center": to.StringPtr("1234"),
+			},
+			expectedMissing: map[string]bool{},
+		},
+		{
+			name:     "case 2: empty tags are missing",
+			required: []string{"cost-center", "owner"},
+			tags: map[string]*string{
+				"cost-center": to.StringPtr(""),
+			},
+			expectedMissing: map[string]bool{
+				"cost-center": true,
+				"owner":       true,
+			},
+		},
+		{
+			name:     "case 3: no tags",
+			required: []string{"owner"},
+			tags:     nil,
+			expectedMissing: map[string]bool{
+				"owner": true,
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			missing := missingTags(tc.required, tc.tags)
+			if !cmp.Equal(missing, tc.expectedMissing) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedMissing, missing))
+			}
+		})
+	}
+}
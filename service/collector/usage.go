@@ -1,13 +1,12 @@
 package collector
 
 import (
-	"context"
+	"strings"
 
-	"github.com/giantswarm/apiextensions/v2/pkg/clientset/versioned"
+	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	"github.com/prometheus/client_golang/prometheus"
-	"k8s.io/client-go/kubernetes"
 
 	"github.com/giantswarm/azure-collector/v2/service/credential"
 )
@@ -31,6 +30,26 @@ var (
 		},
 		nil,
 	)
+	usageVMFamilyCurrentDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "usage", "vm_family_vcpus_current"),
+		"Number of vCPUs of the VM family in use in the region.",
+		[]string{
+			"subscription",
+			"region",
+			"vm_family",
+		},
+		nil,
+	)
+	usageVMFamilyLimitDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(MetricsNamespace, "usage", "vm_family_vcpus_limit"),
+		"Quota of vCPUs of the VM family in the region.",
+		[]string{
+			"subscription",
+			"region",
+			"vm_family",
+		},
+		nil,
+	)
 	scrapeErrorCounter = prometheus.NewCounter(
 		prometheus.CounterOpts{Namespace: MetricsNamespace, Subsystem: "usage", Name: "scrape_error",
 			Help: "Total number of times compute resource usage information scraping returned an error.",
@@ -38,18 +57,18 @@ var (
 )
 
 type UsageConfig struct {
-	G8sClient versioned.Interface
-	K8sClient kubernetes.Interface
-	Logger    micrologger.Logger
+	CredentialSource *credential.Source
+	Collections      *Collections
+	Logger           micrologger.Logger
 
 	Location   string
 	GSTenantID string
 }
 
 type Usage struct {
-	g8sClient versioned.Interface
-	k8sClient kubernetes.Interface
-	logger    micrologger.Logger
+	credentialSource *credential.Source
+	collections      *Collections
+	logger           micrologger.Logger
 
 	usageScrapeError prometheus.Counter
 
@@ -64,11 +83,11 @@ func init() {
 // NewUsage exposes metrics about the quota usage on Azure so we can alert when we are reaching the quota limits.
 // It exposes quota metrics for every subscription found in the "credential-*" secrets of the control plane.
 func NewUsage(config UsageConfig) (*Usage, error) {
-	if config.G8sClient == nil {
-		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
 	}
-	if config.K8sClient == nil {
-		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
 	}
 	if config.Logger == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
@@ -82,8 +101,8 @@ func NewUsage(config UsageConfig) (*Usage, error) {
 	}
 
 	u := &Usage{
-		g8sClient:        config.G8sClient,
-		k8sClient:        config.K8sClient,
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
 		logger:           config.Logger,
 		usageScrapeError: scrapeErrorCounter,
 		location:         config.Location,
@@ -94,8 +113,9 @@ func NewUsage(config UsageConfig) (*Usage, error) {
 }
 
 func (u *Usage) Collect(ch chan<- prometheus.Metric) error {
-	ctx := context.Background()
-	clientSets, err := credential.GetAzureClientSetsFromCredentialSecretsBySubscription(ctx, u.k8sClient, u.gsTenantID)
+	ctx, cancel := u.collections.newCollectContext("usage")
+	defer cancel()
+	clientSets, err := credential.GetAzureClientSetsFromCredentialSecretsBySubscription(ctx, u.credentialSource, u.gsTenantID)
 	if err != nil {
 		return microerror.Mask(err)
 	}
@@ -124,6 +144,28 @@ func (u *Usage) Collect(ch chan<- prometheus.Metric) error {
 						*v.Name.LocalizedValue,
 						subscriptionID,
 					)
+
+					// VM family quotas are exposed separately as well, so
+					// alerts can select them without matching names.
+					family, ok := vmFamilyFromUsageName(to.String(v.Name.Value))
+					if ok {
+						ch <- prometheus.MustNewConstMetric(
+							usageVMFamilyCurrentDesc,
+							prometheus.GaugeValue,
+							float64(*v.CurrentValue),
+							subscriptionID,
+							u.location,
+							family,
+						)
+						ch <- prometheus.MustNewConstMetric(
+							usageVMFamilyLimitDesc,
+							prometheus.GaugeValue,
+							float64(*v.Limit),
+							subscriptionID,
+							u.location,
+							family,
+						)
+					}
 				}
 
 				err := r.NextWithContext(ctx)
@@ -140,5 +182,25 @@ func (u *Usage) Collect(ch chan<- prometheus.Metric) error {
 func (u *Usage) Describe(ch chan<- *prometheus.Desc) error {
 	ch <- usageCurrentDesc
 	ch <- usageLimitDesc
+	ch <- usageVMFamilyCurrentDesc
+	ch <- usageVMFamilyLimitDesc
 	return nil
 }
+
+// vmFamilyFromUsageName returns the VM family of the given compute usage
+// name, e.g. "DSv3" for "standardDSv3Family". The second return value is
+// false for usages which are not about a VM family, like "cores".
+func vmFamilyFromUsageName(name string) (string, bool) {
+	const prefix, suffix = "standard", "Family"
+
+	if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+		return "", false
+	}
+
+	family := strings.TrimSuffix(strings.TrimPrefix(name, prefix), suffix)
+	if family == "" {
+		return "", false
+	}
+
+	return family, true
+}
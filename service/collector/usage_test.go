@@ -0,0 +1,48 @@
+package collector
+
+import (
+	"strconv"
+	"testing"
+)
+
+func Test_vmFamilyFromUsageName(t *testing.T) {
+	testCases := []struct {
+		name           string
+		usageName      string
+		expectedFamily string
+		expectedOK     bool
+	}{
+		{
+			name:           "case 0: VM family",
+			usageName:      "standardDSv3Family",
+			expectedFamily: "DSv3",
+			expectedOK:     true,
+		},
+		{
+			name:           "case 1: regional vCPUs",
+			usageName:      "cores",
+			expectedFamily: "",
+			expectedOK:     false,
+		},
+		{
+			name:           "case 2: low priority vCPUs",
+			usageName:      "lowPriorityCores",
+			expectedFamily: "",
+			expectedOK:     false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			family, ok := vmFamilyFromUsageName(tc.usageName)
+			if ok != tc.expectedOK {
+				t.Fatalf("ok == %t, want %t", ok, tc.expectedOK)
+			}
+			if family != tc.expectedFamily {
+				t.Fatalf("family == %#q, want %#q", family, tc.expectedFamily)
+			}
+		})
+	}
+}
@@ -72,15 +72,19 @@ var (
 )
 
 type VMSSRateLimitConfig struct {
-	CtrlClient ctrlclient.Client
-	Logger     micrologger.Logger
-	GSTenantID string
+	CredentialSource *credential.Source
+	CtrlClient       ctrlclient.Client
+	Collections      *Collections
+	Logger           micrologger.Logger
+	GSTenantID       string
 }
 
 type VMSSRateLimit struct {
-	ctrlClient ctrlclient.Client
-	logger     micrologger.Logger
-	gsTenantID string
+	credentialSource *credential.Source
+	ctrlClient       ctrlclient.Client
+	collections      *Collections
+	logger           micrologger.Logger
+	gsTenantID       string
 }
 
 func init() {
@@ -88,9 +92,15 @@ func init() {
 }
 
 func NewVMSSRateLimit(config VMSSRateLimitConfig) (*VMSSRateLimit, error) {
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
 	if config.CtrlClient == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.CtrlClient must not be empty", config)
 	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
+	}
 	if config.Logger == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
 	}
@@ -99,16 +109,19 @@ func NewVMSSRateLimit(config VMSSRateLimitConfig) (*VMSSRateLimit, error) {
 	}
 
 	u := &VMSSRateLimit{
-		ctrlClient: config.CtrlClient,
-		logger:     config.Logger,
-		gsTenantID: config.GSTenantID,
+		credentialSource: config.CredentialSource,
+		ctrlClient:       config.CtrlClient,
+		collections:      config.Collections,
+		logger:           config.Logger,
+		gsTenantID:       config.GSTenantID,
 	}
 
 	return u, nil
 }
 
 func (u *VMSSRateLimit) Collect(ch chan<- prometheus.Metric) error {
-	ctx := context.Background()
+	ctx, cancel := u.collections.newCollectContext("vmss_rate_limit")
+	defer cancel()
 
 	// Remove 429 from the retriable error codes.
 	original := autorest.StatusCodesForRetry
@@ -130,7 +143,7 @@ func (u *VMSSRateLimit) Collect(ch chan<- prometheus.Metric) error {
 
 	var doneSubscriptions []string
 	for cluster, secret := range clustersSecret {
-		config, err := credential.GetAzureConfigFromSecret(secret, u.gsTenantID)
+		config, err := credential.GetAzureConfigFromSecret(u.credentialSource, secret, u.gsTenantID)
 		if err != nil {
 			return microerror.Mask(err)
 		}
@@ -141,7 +154,7 @@ func (u *VMSSRateLimit) Collect(ch chan<- prometheus.Metric) error {
 			continue
 		}
 
-		azureClients, err := client.NewAzureClientSet(*config)
+		azureClients, err := client.GetAzureClientSet(*config)
 		if err != nil {
 			return microerror.Mask(err)
 		}
@@ -215,6 +228,10 @@ func (u *VMSSRateLimit) getClusters(ctx context.Context) (map[string]*v1.Secret,
 		}
 	}
 	for _, azureConfig := range azureConfigs.Items {
+		if !u.credentialSource.MatchResourceGroup(azureConfig.Name) {
+			continue
+		}
+
 		secret := &v1.Secret{}
 		err := u.ctrlClient.Get(ctx, ctrlclient.ObjectKey{Namespace: key.CredentialNamespace(azureConfig), Name: key.CredentialName(azureConfig)}, secret)
 		if err != nil {
@@ -232,6 +249,10 @@ func (u *VMSSRateLimit) getClusters(ctx context.Context) (map[string]*v1.Secret,
 		}
 	}
 	for _, cluster := range clusters.Items {
+		if !u.credentialSource.MatchResourceGroup(cluster.Name) {
+			continue
+		}
+
 		credentialSecret, err := u.getOrganizationCredentialSecret(ctx, cluster.ObjectMeta)
 		if IsCredentialsNotFoundError(err) {
 			credentialSecret, err = u.getLegacyCredentialSecret(ctx, cluster.ObjectMeta)
@@ -244,9 +265,11 @@ func (u *VMSSRateLimit) getClusters(ctx context.Context) (map[string]*v1.Secret,
 				}
 			} else if err != nil {
 				u.logger.Errorf(ctx, err, "Skipping Cluster %#q", cluster.Name)
+				continue
 			}
 		} else if err != nil {
 			u.logger.Errorf(ctx, err, "Skipping Cluster %#q", cluster.Name)
+			continue
 		}
 
 		clustersSecret[cluster.Name] = credentialSecret
@@ -4,13 +4,12 @@ import (
 	"context"
 
 	"github.com/Azure/go-autorest/autorest/to"
-	"github.com/giantswarm/apiextensions/v2/pkg/clientset/versioned"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/sync/errgroup"
-	"k8s.io/client-go/kubernetes"
 
+	"github.com/giantswarm/azure-collector/v2/client"
 	"github.com/giantswarm/azure-collector/v2/service/credential"
 )
 
@@ -31,30 +30,30 @@ var (
 )
 
 type VPNConnectionConfig struct {
-	G8sClient        versioned.Interface
 	InstallationName string
-	K8sClient        kubernetes.Interface
+	CredentialSource *credential.Source
+	Collections      *Collections
 	Logger           micrologger.Logger
 	GSTenantID       string
 }
 
 type VPNConnection struct {
-	g8sClient        versioned.Interface
 	installationName string
-	k8sClient        kubernetes.Interface
+	credentialSource *credential.Source
+	collections      *Collections
 	logger           micrologger.Logger
 	gsTenantID       string
 }
 
 func NewVPNConnection(config VPNConnectionConfig) (*VPNConnection, error) {
-	if config.G8sClient == nil {
-		return nil, microerror.Maskf(invalidConfigError, "%T.G8sClient must not be empty", config)
-	}
 	if config.InstallationName == "" {
 		return nil, microerror.Maskf(invalidConfigError, "%T.InstallationName must not be empty", config)
 	}
-	if config.K8sClient == nil {
-		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	if config.CredentialSource == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.CredentialSource must not be empty", config)
+	}
+	if config.Collections == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Collections must not be empty", config)
 	}
 	if config.Logger == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
@@ -64,9 +63,9 @@ func NewVPNConnection(config VPNConnectionConfig) (*VPNConnection, error) {
 	}
 
 	v := &VPNConnection{
-		g8sClient:        config.G8sClient,
 		installationName: config.InstallationName,
-		k8sClient:        config.K8sClient,
+		credentialSource: config.CredentialSource,
+		collections:      config.Collections,
 		logger:           config.Logger,
 		gsTenantID:       config.GSTenantID,
 	}
@@ -75,14 +74,15 @@ func NewVPNConnection(config VPNConnectionConfig) (*VPNConnection, error) {
 }
 
 func (v *VPNConnection) Collect(ch chan<- prometheus.Metric) error {
-	ctx := context.Background()
+	ctx, cancel := v.collections.newCollectContext("vpn_connection")
+	defer cancel()
 
-	azureClientSets, err := credential.GetAzureClientSetsByCluster(ctx, v.k8sClient, v.g8sClient, v.gsTenantID)
+	azureClientSets, err := getClientSetsByCluster(ctx, v.credentialSource, v.gsTenantID)
 	if err != nil {
 		return microerror.Mask(err)
 	}
 
-	for clusterID, azureClientSet := range azureClientSets {
+	forEachCluster(ctx, ch, azureClientSets, func(ctx context.Context, ch chan<- prometheus.Metric, clusterID string, azureClientSet *client.AzureClientSet) error {
 		connections, err := azureClientSet.VirtualNetworkGatewayConnectionsClient.ListComplete(ctx, clusterID)
 		if err != nil {
 			return microerror.Mask(err)
@@ -131,7 +131,9 @@ func (v *VPNConnection) Collect(ch chan<- prometheus.Metric) error {
 		if err := g.Wait(); err != nil {
 			return microerror.Mask(err)
 		}
-	}
+
+		return nil
+	})
 
 	return nil
 }
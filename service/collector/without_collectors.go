@@ -0,0 +1,25 @@
+package collector
+
+import (
+	"github.com/giantswarm/exporterkit/collector"
+)
+
+// withoutCollectors returns the given collectors without the excluded ones.
+// Collectors which were not created are excluded by their nil pointers.
+func withoutCollectors(collectors []collector.Interface, excluded []collector.Interface) []collector.Interface {
+	skip := map[collector.Interface]bool{}
+	for _, c := range excluded {
+		skip[c] = true
+	}
+
+	var kept []collector.Interface
+	for _, c := range collectors {
+		if skip[c] {
+			continue
+		}
+
+		kept = append(kept, c)
+	}
+
+	return kept
+}
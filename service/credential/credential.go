@@ -3,40 +3,44 @@ package credential
 import (
 	"context"
 
-	"github.com/Azure/go-autorest/autorest/azure/auth"
 	providerv1alpha1 "github.com/giantswarm/apiextensions/v2/pkg/apis/provider/v1alpha1"
-	"github.com/giantswarm/apiextensions/v2/pkg/clientset/versioned"
 	"github.com/giantswarm/microerror"
 	v1 "k8s.io/api/core/v1"
-	apismetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
 
 	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/pkg/logging"
 	"github.com/giantswarm/azure-collector/v2/service/collector/key"
 )
 
 const (
-	ClientIDKey         = "azure.azureoperator.clientid"
-	ClientSecretKey     = "azure.azureoperator.clientsecret"
-	SubscriptionIDKey   = "azure.azureoperator.subscriptionid"
-	TenantIDKey         = "azure.azureoperator.tenantid"
-	PartnerIDKey        = "azure.azureoperator.partnerid"
-	SecretLabel         = "giantswarm.io/managed-by=credentiald"
-	CredentialNamespace = "giantswarm"
-	CredentialDefault   = "credential-default"
-	SingleTenantSP      = "giantswarm.io/single-tenant-service-principal"
+	ClientIDKey          = "azure.azureoperator.clientid"
+	ClientSecretKey      = "azure.azureoperator.clientsecret"
+	ClientCertificateKey = "azure.azureoperator.clientcertificate"
+	SubscriptionIDKey    = "azure.azureoperator.subscriptionid"
+	TenantIDKey          = "azure.azureoperator.tenantid"
+	PartnerIDKey         = "azure.azureoperator.partnerid"
+	SecretLabel          = "giantswarm.io/managed-by=credentiald"
+	CredentialNamespace  = "giantswarm"
+	CredentialDefault    = "credential-default"
+	SingleTenantSP       = "giantswarm.io/single-tenant-service-principal"
 )
 
-func GetAzureConfigFromSecretName(ctx context.Context, k8sClient kubernetes.Interface, name, namespace, gsTenantID string) (*client.AzureClientSetConfig, error) {
-	credential, err := k8sClient.CoreV1().Secrets(namespace).Get(ctx, name, apismetav1.GetOptions{})
+const (
+	AuthTypeSecret          = "secret"
+	AuthTypeCertificate     = "certificate"
+	AuthTypeManagedIdentity = "msi"
+)
+
+func GetAzureConfigFromSecretName(ctx context.Context, source *Source, name, namespace, gsTenantID string) (*client.AzureClientSetConfig, error) {
+	credential, err := source.CredentialSecret(ctx, name, namespace)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 
-	return GetAzureConfigFromSecret(credential, gsTenantID)
+	return GetAzureConfigFromSecret(source, credential, gsTenantID)
 }
 
-func GetAzureConfigFromSecret(credential *v1.Secret, gsTenantID string) (*client.AzureClientSetConfig, error) {
+func GetAzureConfigFromSecret(source *Source, credential *v1.Secret, gsTenantID string) (*client.AzureClientSetConfig, error) {
 	clientID, err := valueFromSecret(credential, ClientIDKey)
 	if err != nil {
 		return nil, microerror.Mask(err)
@@ -47,6 +51,10 @@ func GetAzureConfigFromSecret(credential *v1.Secret, gsTenantID string) (*client
 		return nil, microerror.Mask(err)
 	}
 
+	// Client secrets must never end up in the logs, e.g. as part of a
+	// failed token request.
+	logging.AddSecret(clientSecret)
+
 	subscriptionID, err := valueFromSecret(credential, SubscriptionIDKey)
 	if err != nil {
 		return nil, microerror.Mask(err)
@@ -64,20 +72,22 @@ func GetAzureConfigFromSecret(credential *v1.Secret, gsTenantID string) (*client
 
 	// By default we assume that the tenant cluster resources will belong to a subscription that belongs to a different Tenant ID than the one used for authentication.
 	// Typically this means we are using a Service Principal from the GiantSwarm Tenant ID.
-	credentials := auth.NewClientCredentialsConfig(clientID, clientSecret, tenantID)
+	factory := source.ClientFactory()
+	credentials := factory.NewClientCredentialsConfig(clientID, clientSecret, tenantID)
 	credentials.AuxTenants = append(credentials.AuxTenants, gsTenantID)
 	if _, exists := credential.GetLabels()[SingleTenantSP]; exists || tenantID == gsTenantID {
 		// In this case the tenant cluster resources will belong to a subscription that belongs to the same Tenant ID used for authentication.
 		// Typically this means we are using a Service Principal from the customer Tenant ID.
-		credentials = auth.NewClientCredentialsConfig(clientID, clientSecret, tenantID)
+		credentials = factory.NewClientCredentialsConfig(clientID, clientSecret, tenantID)
 	}
 
-	authorizer, err := credentials.Authorizer()
+	authorizer, err := factory.GetAuthorizer(credentials)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 
 	azureClientSetConfig, err := client.NewAzureClientSetConfig(
+		factory,
 		authorizer,
 		clientID,
 		clientSecret,
@@ -93,21 +103,21 @@ func GetAzureConfigFromSecret(credential *v1.Secret, gsTenantID string) (*client
 	return &azureClientSetConfig, nil
 }
 
-func GetAzureClientSetsFromCredentialSecrets(ctx context.Context, k8sclient kubernetes.Interface, gsTenantID string) (map[*client.AzureClientSetConfig]*client.AzureClientSet, error) {
+func GetAzureClientSetsFromCredentialSecrets(ctx context.Context, source *Source, gsTenantID string) (map[*client.AzureClientSetConfig]*client.AzureClientSet, error) {
 	azureClientSets := map[*client.AzureClientSetConfig]*client.AzureClientSet{}
 
-	secrets, err := GetCredentialSecrets(ctx, k8sclient)
+	secrets, err := GetCredentialSecrets(ctx, source)
 	if err != nil {
 		return azureClientSets, microerror.Mask(err)
 	}
 
 	for _, secret := range secrets {
-		azureClientSetConfig, err := GetAzureConfigFromSecret(&secret, gsTenantID)
+		azureClientSetConfig, err := GetAzureConfigFromSecret(source, &secret, gsTenantID)
 		if err != nil {
 			return azureClientSets, microerror.Mask(err)
 		}
 
-		clientSet, err := client.NewAzureClientSet(*azureClientSetConfig)
+		clientSet, err := client.GetAzureClientSet(*azureClientSetConfig)
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
@@ -118,10 +128,10 @@ func GetAzureClientSetsFromCredentialSecrets(ctx context.Context, k8sclient kube
 	return azureClientSets, nil
 }
 
-func GetAzureClientSetsFromCredentialSecretsBySubscription(ctx context.Context, k8sclient kubernetes.Interface, gsTenantID string) (map[string]*client.AzureClientSet, error) {
+func GetAzureClientSetsFromCredentialSecretsBySubscription(ctx context.Context, source *Source, gsTenantID string) (map[string]*client.AzureClientSet, error) {
 	azureClientSets := map[string]*client.AzureClientSet{}
 
-	rawAzureClientSets, err := GetAzureClientSetsFromCredentialSecrets(ctx, k8sclient, gsTenantID)
+	rawAzureClientSets, err := GetAzureClientSetsFromCredentialSecrets(ctx, source, gsTenantID)
 	if err != nil {
 		return azureClientSets, microerror.Mask(err)
 	}
@@ -133,65 +143,123 @@ func GetAzureClientSetsFromCredentialSecretsBySubscription(ctx context.Context,
 	return azureClientSets, nil
 }
 
-func GetAzureClientSetsByCluster(ctx context.Context, k8sclient kubernetes.Interface, g8sclient versioned.Interface, gsTenantID string) (map[string]*client.AzureClientSet, error) {
+// GetAzureClientSetsByCluster returns the Azure client sets of the clusters
+// by cluster ID. Clusters whose credentials can't be used, e.g. because their
+// secret got deleted, are returned with their error instead, so the other
+// clusters can still be collected.
+func GetAzureClientSetsByCluster(ctx context.Context, source *Source, gsTenantID string) (map[string]*client.AzureClientSet, map[string]error, error) {
 	azureClientSets := map[string]*client.AzureClientSet{}
-	var crs []providerv1alpha1.AzureConfig
-	{
-		mark := ""
-		page := 0
-		for page == 0 || len(mark) > 0 {
-			opts := apismetav1.ListOptions{
-				Continue: mark,
-			}
-			list, err := g8sclient.ProviderV1alpha1().AzureConfigs(apismetav1.NamespaceAll).List(ctx, opts)
-			if err != nil {
-				return azureClientSets, microerror.Mask(err)
-			}
-
-			crs = append(crs, list.Items...)
-
-			mark = list.Continue
-			page++
-		}
+	failures := map[string]error{}
+
+	crs, err := GetAzureConfigs(ctx, source)
+	if err != nil {
+		return azureClientSets, failures, microerror.Mask(err)
 	}
 
 	for _, cr := range crs {
-		config, err := GetAzureConfigFromSecretName(ctx, k8sclient, key.CredentialName(cr), key.CredentialNamespace(cr), gsTenantID)
+		config, err := GetAzureConfigFromSecretName(ctx, source, key.CredentialName(cr), key.CredentialNamespace(cr), gsTenantID)
 		if err != nil {
-			return nil, microerror.Mask(err)
+			failures[cr.GetName()] = microerror.Mask(err)
+			continue
 		}
-		azureClients, err := client.NewAzureClientSet(*config)
+		azureClients, err := client.GetAzureClientSet(*config)
 		if err != nil {
-			return nil, microerror.Mask(err)
+			failures[cr.GetName()] = microerror.Mask(err)
+			continue
 		}
 		azureClientSets[cr.GetName()] = azureClients
 	}
 
-	return azureClientSets, nil
+	return azureClientSets, failures, nil
 }
 
-func GetCredentialSecrets(ctx context.Context, k8sClient kubernetes.Interface) (secrets []v1.Secret, err error) {
-	mark := ""
-	page := 0
-	for page == 0 || len(mark) > 0 {
-		opts := apismetav1.ListOptions{
-			Continue:      mark,
-			LabelSelector: SecretLabel,
+// GetClientIDsByCluster returns the client ID of the credentials of every
+// cluster without creating any Azure client.
+func GetClientIDsByCluster(ctx context.Context, source *Source) (map[string]string, error) {
+	clientIDs := map[string]string{}
+
+	crs, err := GetAzureConfigs(ctx, source)
+	if err != nil {
+		return clientIDs, microerror.Mask(err)
+	}
+
+	for _, cr := range crs {
+		secret, err := source.CredentialSecret(ctx, key.CredentialName(cr), key.CredentialNamespace(cr))
+		if err != nil {
+			return nil, microerror.Mask(err)
 		}
-		list, err := k8sClient.CoreV1().Secrets(CredentialNamespace).List(ctx, opts)
+
+		clientID, err := valueFromSecret(secret, ClientIDKey)
 		if err != nil {
-			return secrets, microerror.Mask(err)
+			return nil, microerror.Mask(err)
+		}
+
+		clientIDs[cr.GetName()] = clientID
+	}
+
+	return clientIDs, nil
+}
+
+// GetAzureConfigs returns the AzureConfig CRs of the clusters whose resource
+// groups are collected.
+func GetAzureConfigs(ctx context.Context, source *Source) ([]providerv1alpha1.AzureConfig, error) {
+	all, err := source.AzureConfigs(ctx)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var crs []providerv1alpha1.AzureConfig
+	for _, cr := range all {
+		// The resource group of a cluster is named after it.
+		if source.MatchResourceGroup(cr.GetName()) {
+			crs = append(crs, cr)
 		}
+	}
 
-		secrets = append(secrets, list.Items...)
+	return crs, nil
+}
 
-		mark = list.Continue
-		page++
+func GetCredentialSecrets(ctx context.Context, source *Source) ([]v1.Secret, error) {
+	secrets, err := source.CredentialSecrets(ctx)
+	if err != nil {
+		return nil, microerror.Mask(err)
 	}
 
 	return secrets, nil
 }
 
+// GetAuthType returns how the credential secret authenticates against Azure.
+// Secrets without a client secret or certificate rely on the managed identity
+// of the node.
+func GetAuthType(secret *v1.Secret) string {
+	if _, ok := secret.Data[ClientSecretKey]; ok {
+		return AuthTypeSecret
+	}
+	if _, ok := secret.Data[ClientCertificateKey]; ok {
+		return AuthTypeCertificate
+	}
+
+	return AuthTypeManagedIdentity
+}
+
+// ValidateSecret returns an error if the credential secret is missing any of
+// the values required for its auth type.
+func ValidateSecret(secret *v1.Secret) error {
+	keys := []string{ClientIDKey, SubscriptionIDKey, TenantIDKey}
+
+	for _, k := range keys {
+		v, err := valueFromSecret(secret, k)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		if v == "" {
+			return microerror.Maskf(missingValueError, k)
+		}
+	}
+
+	return nil
+}
+
 func valueFromSecret(secret *v1.Secret, key string) (string, error) {
 	v, ok := secret.Data[key]
 	if !ok {
@@ -0,0 +1,361 @@
+package credential
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+)
+
+const (
+	resourceGraphAPIVersion = "2021-03-01"
+
+	// resourceGroupTagsRefreshInterval is how often the resource groups
+	// carrying the configured tags are looked up again.
+	resourceGroupTagsRefreshInterval = 5 * time.Minute
+)
+
+// resourceGraphQuery is the request body of a Resource Graph query.
+type resourceGraphQuery struct {
+	Subscriptions []string                  `json:"subscriptions"`
+	Query         string                    `json:"query"`
+	Options       resourceGraphQueryOptions `json:"options"`
+}
+
+type resourceGraphQueryOptions struct {
+	SkipToken string `json:"$skipToken,omitempty"`
+}
+
+// resourceGraphResourceGroups is the response of the Resource Graph query
+// returning the names of the tagged resource groups.
+type resourceGraphResourceGroups struct {
+	SkipToken string `json:"$skipToken"`
+	Data      []struct {
+		Name string `json:"name"`
+	} `json:"data"`
+}
+
+type ResourceGroupFilterConfig struct {
+	CredentialSource *Source
+	Logger           micrologger.Logger
+	GSTenantID       string
+
+	// Exclude are regular expressions matching the names of the resource
+	// groups which are not collected. They take precedence over Include.
+	Exclude []string
+	// Include are regular expressions matching the names of the resource
+	// groups which are collected. All resource groups match when empty.
+	Include []string
+	// Tags are the tags the collected resource groups have to carry, in the
+	// form "key=value" or "key" for any value.
+	Tags []string
+}
+
+// ResourceGroupFilter decides which resource groups are collected based on
+// regular expressions matching their names and the tags they carry. It allows
+// pointing the collector at subscriptions shared with unrelated resources.
+type ResourceGroupFilter struct {
+	credentialSource *Source
+	logger           micrologger.Logger
+	gsTenantID       string
+
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+	tags    map[string]string
+
+	taggedMutex sync.RWMutex
+	// tagged holds the lower cased names of the resource groups carrying
+	// the configured tags. It is nil until they have been looked up once.
+	tagged map[string]bool
+}
+
+// NewResourceGroupFilter returns a filter matching the resource groups whose
+// name matches any of the include expressions, or all of them when there is
+// none, and none of the exclude expressions. Expressions have to match the
+// whole name and are case insensitive like resource group names.
+func NewResourceGroupFilter(config ResourceGroupFilterConfig) (*ResourceGroupFilter, error) {
+	f := &ResourceGroupFilter{
+		credentialSource: config.CredentialSource,
+		logger:           config.Logger,
+		gsTenantID:       config.GSTenantID,
+
+		tags: map[string]string{},
+	}
+
+	for _, expr := range config.Include {
+		r, err := compileResourceGroupExpr(expr)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		f.include = append(f.include, r)
+	}
+	for _, expr := range config.Exclude {
+		r, err := compileResourceGroupExpr(expr)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		f.exclude = append(f.exclude, r)
+	}
+	for _, tag := range config.Tags {
+		parts := strings.SplitN(tag, "=", 2)
+		if parts[0] == "" {
+			return nil, microerror.Maskf(invalidConfig, "%T.Tags must be in the form key=value or key, got %#q", config, tag)
+		}
+
+		var value string
+		if len(parts) == 2 {
+			value = parts[1]
+		}
+		f.tags[parts[0]] = value
+	}
+
+	if len(f.tags) > 0 {
+		if config.CredentialSource == nil {
+			return nil, microerror.Maskf(invalidConfig, "%T.CredentialSource must not be empty when filtering by tags", config)
+		}
+		if config.Logger == nil {
+			return nil, microerror.Maskf(invalidConfig, "%T.Logger must not be empty when filtering by tags", config)
+		}
+		if config.GSTenantID == "" {
+			return nil, microerror.Maskf(invalidConfig, "%T.GSTenantID must not be empty when filtering by tags", config)
+		}
+	}
+
+	return f, nil
+}
+
+// Boot periodically looks up the resource groups carrying the configured
+// tags. It does nothing when no tags are configured. The first look up is
+// skipped when the tagged resource groups are known already.
+func (f *ResourceGroupFilter) Boot(ctx context.Context) {
+	if f == nil || len(f.tags) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(resourceGroupTagsRefreshInterval)
+	defer ticker.Stop()
+
+	f.taggedMutex.RLock()
+	known := f.tagged != nil
+	f.taggedMutex.RUnlock()
+
+	for {
+		if !known {
+			err := f.refreshTagged(ctx)
+			if err != nil {
+				f.logger.Errorf(ctx, err, "failed to look up tagged resource groups")
+			}
+		}
+		known = false
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Prepare makes the filter ready to replace the given previous filter, so
+// that resource groups are not skipped in between. The tagged resource groups
+// of the previous filter are taken over when it filters by the same tags.
+// Otherwise they are looked up once.
+func (f *ResourceGroupFilter) Prepare(ctx context.Context, previous *ResourceGroupFilter) error {
+	if f == nil || len(f.tags) == 0 {
+		return nil
+	}
+
+	if previous != nil && equalTags(f.tags, previous.tags) {
+		previous.taggedMutex.RLock()
+		tagged := previous.tagged
+		previous.taggedMutex.RUnlock()
+
+		if tagged != nil {
+			f.taggedMutex.Lock()
+			f.tagged = tagged
+			f.taggedMutex.Unlock()
+
+			return nil
+		}
+	}
+
+	err := f.refreshTagged(ctx)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// Match returns whether the given resource group has to be collected. A nil
+// filter matches all resource groups. When filtering by tags, no resource
+// group matches until the tagged ones have been looked up.
+func (f *ResourceGroupFilter) Match(name string) bool {
+	if f == nil {
+		return true
+	}
+
+	for _, r := range f.exclude {
+		if r.MatchString(name) {
+			return false
+		}
+	}
+
+	if len(f.tags) > 0 {
+		f.taggedMutex.RLock()
+		tagged := f.tagged[strings.ToLower(name)]
+		f.taggedMutex.RUnlock()
+
+		if !tagged {
+			return false
+		}
+	}
+
+	if len(f.include) == 0 {
+		return true
+	}
+
+	for _, r := range f.include {
+		if r.MatchString(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// refreshTagged looks up the resource groups carrying the configured tags in
+// all subscriptions using Resource Graph, which avoids listing all resource
+// groups of shared subscriptions.
+func (f *ResourceGroupFilter) refreshTagged(ctx context.Context) error {
+	clientSets, err := GetAzureClientSetsFromCredentialSecretsBySubscription(ctx, f.credentialSource, f.gsTenantID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	tagged := map[string]bool{}
+	for subscriptionID, clientSet := range clientSets {
+		names, err := f.getTaggedResourceGroups(ctx, clientSet.RESTClient, subscriptionID)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		for _, name := range names {
+			tagged[strings.ToLower(name)] = true
+		}
+	}
+
+	f.taggedMutex.Lock()
+	f.tagged = tagged
+	f.taggedMutex.Unlock()
+
+	return nil
+}
+
+func (f *ResourceGroupFilter) getTaggedResourceGroups(ctx context.Context, restClient *client.RESTClient, subscriptionID string) ([]string, error) {
+	query := resourceGraphQuery{
+		Subscriptions: []string{subscriptionID},
+		Query:         resourceGroupTagsQuery(f.tags),
+	}
+
+	var names []string
+	for {
+		var result resourceGraphResourceGroups
+		err := restClient.PostJSON(ctx, "/providers/Microsoft.ResourceGraph/resources", resourceGraphAPIVersion, query, &result)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		for _, row := range result.Data {
+			names = append(names, row.Name)
+		}
+
+		if result.SkipToken == "" {
+			break
+		}
+
+		query.Options.SkipToken = result.SkipToken
+	}
+
+	return names, nil
+}
+
+// SetResourceGroupFilter configures the filter applied to the resource groups
+// of all collectors using the source. It is called on startup and whenever
+// the runtime configuration changes the filter.
+func (s *Source) SetResourceGroupFilter(f *ResourceGroupFilter) {
+	s.resourceGroupFilterMutex.Lock()
+	defer s.resourceGroupFilterMutex.Unlock()
+
+	s.resourceGroupFilter = f
+}
+
+// MatchResourceGroup returns whether the given resource group has to be
+// collected according to the configured filter.
+func (s *Source) MatchResourceGroup(name string) bool {
+	s.resourceGroupFilterMutex.RLock()
+	defer s.resourceGroupFilterMutex.RUnlock()
+
+	return s.resourceGroupFilter.Match(name)
+}
+
+func compileResourceGroupExpr(expr string) (*regexp.Regexp, error) {
+	r, err := regexp.Compile("(?i)^(?:" + expr + ")$")
+	if err != nil {
+		return nil, microerror.Maskf(invalidConfig, "invalid resource group expression %#q: %s", expr, err)
+	}
+
+	return r, nil
+}
+
+// resourceGroupTagsQuery returns the Resource Graph query selecting the names
+// of the resource groups carrying all given tags. Tags without value only
+// have to be present.
+func resourceGroupTagsQuery(tags map[string]string) string {
+	var keys []string
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	query := "resourcecontainers | where type =~ 'microsoft.resources/subscriptions/resourcegroups'"
+	for _, k := range keys {
+		if tags[k] == "" {
+			query += fmt.Sprintf(" | where isnotempty(tags[%s])", kqlString(k))
+		} else {
+			query += fmt.Sprintf(" | where tags[%s] =~ %s", kqlString(k), kqlString(tags[k]))
+		}
+	}
+
+	return query + " | project name"
+}
+
+// kqlString returns the given value as a quoted KQL string literal.
+func kqlString(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+
+	return "'" + value + "'"
+}
+
+func equalTags(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		w, ok := b[k]
+		if !ok || v != w {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,127 @@
+package credential
+
+import (
+	"context"
+	"strconv"
+	"testing"
+)
+
+func Test_ResourceGroupFilter_Match(t *testing.T) {
+	testCases := []struct {
+		name          string
+		include       []string
+		exclude       []string
+		resourceGroup string
+		expectedMatch bool
+	}{
+		{
+			name:          "case 0: no expressions",
+			resourceGroup: "abc12",
+			expectedMatch: true,
+		},
+		{
+			name:          "case 1: included",
+			include:       []string{"[a-z0-9]{5}", "godsmack"},
+			resourceGroup: "godsmack",
+			expectedMatch: true,
+		},
+		{
+			name:          "case 2: not included as expressions match the whole name",
+			include:       []string{"[a-z0-9]{5}"},
+			resourceGroup: "customer-shared",
+			expectedMatch: false,
+		},
+		{
+			name:          "case 3: exclude takes precedence",
+			include:       []string{".*"},
+			exclude:       []string{"customer-.*"},
+			resourceGroup: "Customer-Shared",
+			expectedMatch: false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			c := ResourceGroupFilterConfig{
+				Exclude: tc.exclude,
+				Include: tc.include,
+			}
+
+			f, err := NewResourceGroupFilter(c)
+			if err != nil {
+				t.Fatalf("err == %v, want nil", err)
+			}
+
+			match := f.Match(tc.resourceGroup)
+			if match != tc.expectedMatch {
+				t.Fatalf("match == %t, want %t", match, tc.expectedMatch)
+			}
+		})
+	}
+}
+
+func Test_NewResourceGroupFilter_Invalid(t *testing.T) {
+	_, err := NewResourceGroupFilter(ResourceGroupFilterConfig{Include: []string{"("}})
+	if !IsInvalidConfigFoundError(err) {
+		t.Fatalf("err == %v, want invalidConfig", err)
+	}
+}
+
+func Test_ResourceGroupFilter_MatchTagged(t *testing.T) {
+	f := &ResourceGroupFilter{
+		tags: map[string]string{"giantswarm.io/installation": "godsmack"},
+	}
+
+	if f.Match("abc12") {
+		t.Fatalf("match == true before the tagged resource groups are looked up, want false")
+	}
+
+	f.tagged = map[string]bool{"abc12": true}
+
+	if !f.Match("ABC12") {
+		t.Fatalf("match == false for a tagged resource group, want true")
+	}
+	if f.Match("def34") {
+		t.Fatalf("match == true for an untagged resource group, want false")
+	}
+}
+
+func Test_ResourceGroupFilter_PrepareTakesOverTagged(t *testing.T) {
+	previous := &ResourceGroupFilter{
+		tags:   map[string]string{"giantswarm.io/installation": "godsmack"},
+		tagged: map[string]bool{"abc12": true},
+	}
+	f := &ResourceGroupFilter{
+		tags: map[string]string{"giantswarm.io/installation": "godsmack"},
+	}
+
+	err := f.Prepare(context.Background(), previous)
+	if err != nil {
+		t.Fatalf("err == %v, want nil", err)
+	}
+
+	if !f.Match("abc12") {
+		t.Fatalf("match == false for a tagged resource group of the previous filter, want true")
+	}
+}
+
+func Test_resourceGroupTagsQuery(t *testing.T) {
+	tags := map[string]string{
+		"giantswarm.io/installation": "godsmack",
+		"owner":                      "",
+		"team":                       "o'neil",
+	}
+
+	expected := "resourcecontainers | where type =~ 'microsoft.resources/subscriptions/resourcegroups'" +
+		" | where tags['giantswarm.io/installation'] =~ 'godsmack'" +
+		" | where isnotempty(tags['owner'])" +
+		" | where tags['team'] =~ 'o\\'neil'" +
+		" | project name"
+
+	query := resourceGroupTagsQuery(tags)
+	if query != expected {
+		t.Fatalf("query == %#q, want %#q", query, expected)
+	}
+}
@@ -0,0 +1,170 @@
+package credential
+
+import (
+	"context"
+	"sync"
+
+	providerv1alpha1 "github.com/giantswarm/apiextensions/v2/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/apiextensions/v2/pkg/clientset/versioned"
+	"github.com/giantswarm/microerror"
+	v1 "k8s.io/api/core/v1"
+	apismetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+)
+
+// Backend provides the AzureConfig CRs of the clusters and the credential
+// secrets of their subscriptions the collectors create their Azure clients
+// from. They are read from the Kubernetes API of the management cluster, or
+// given by the configuration in standalone mode.
+type Backend interface {
+	// AzureConfigs returns the AzureConfig CRs of all clusters.
+	AzureConfigs(ctx context.Context) ([]providerv1alpha1.AzureConfig, error)
+	// CredentialSecret returns the credential secret of the given name and
+	// namespace. The cause of the returned error is a not found error of the
+	// Kubernetes API when it does not exist.
+	CredentialSecret(ctx context.Context, name, namespace string) (*v1.Secret, error)
+	// CredentialSecrets returns the credential secrets of all
+	// subscriptions.
+	CredentialSecrets(ctx context.Context) ([]v1.Secret, error)
+}
+
+type SourceConfig struct {
+	Backend       Backend
+	ClientFactory *client.Factory
+}
+
+// Source provides the collectors with the AzureConfig CRs and credential
+// secrets of its backend, and the factory they create their Azure clients
+// with. It holds the filter applied to the resource groups of all
+// collectors.
+type Source struct {
+	backend       Backend
+	clientFactory *client.Factory
+
+	resourceGroupFilterMutex sync.RWMutex
+	resourceGroupFilter      *ResourceGroupFilter
+}
+
+func NewSource(config SourceConfig) (*Source, error) {
+	if config.Backend == nil {
+		return nil, microerror.Maskf(invalidConfig, "%T.Backend must not be empty", config)
+	}
+	if config.ClientFactory == nil {
+		return nil, microerror.Maskf(invalidConfig, "%T.ClientFactory must not be empty", config)
+	}
+
+	s := &Source{
+		backend:       config.Backend,
+		clientFactory: config.ClientFactory,
+	}
+
+	return s, nil
+}
+
+// AzureConfigs returns the AzureConfig CRs of all clusters.
+func (s *Source) AzureConfigs(ctx context.Context) ([]providerv1alpha1.AzureConfig, error) {
+	crs, err := s.backend.AzureConfigs(ctx)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return crs, nil
+}
+
+// CredentialSecret returns the credential secret of the given name and
+// namespace, see Backend.
+func (s *Source) CredentialSecret(ctx context.Context, name, namespace string) (*v1.Secret, error) {
+	secret, err := s.backend.CredentialSecret(ctx, name, namespace)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return secret, nil
+}
+
+// CredentialSecrets returns the credential secrets of all subscriptions.
+func (s *Source) CredentialSecrets(ctx context.Context) ([]v1.Secret, error) {
+	secrets, err := s.backend.CredentialSecrets(ctx)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return secrets, nil
+}
+
+// ClientFactory returns the factory the Azure clients are created with.
+func (s *Source) ClientFactory() *client.Factory {
+	return s.clientFactory
+}
+
+type kubernetesBackend struct {
+	g8sClient versioned.Interface
+	k8sClient kubernetes.Interface
+}
+
+// NewKubernetesBackend returns the backend reading the AzureConfig CRs and the
+// credential secrets managed by credentiald from the Kubernetes API.
+func NewKubernetesBackend(k8sClient kubernetes.Interface, g8sClient versioned.Interface) Backend {
+	return &kubernetesBackend{
+		g8sClient: g8sClient,
+		k8sClient: k8sClient,
+	}
+}
+
+func (s *kubernetesBackend) AzureConfigs(ctx context.Context) ([]providerv1alpha1.AzureConfig, error) {
+	var crs []providerv1alpha1.AzureConfig
+
+	mark := ""
+	page := 0
+	for page == 0 || len(mark) > 0 {
+		opts := apismetav1.ListOptions{
+			Continue: mark,
+		}
+		list, err := s.g8sClient.ProviderV1alpha1().AzureConfigs(apismetav1.NamespaceAll).List(ctx, opts)
+		if err != nil {
+			return crs, microerror.Mask(err)
+		}
+
+		crs = append(crs, list.Items...)
+
+		mark = list.Continue
+		page++
+	}
+
+	return crs, nil
+}
+
+func (s *kubernetesBackend) CredentialSecret(ctx context.Context, name, namespace string) (*v1.Secret, error) {
+	secret, err := s.k8sClient.CoreV1().Secrets(namespace).Get(ctx, name, apismetav1.GetOptions{})
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return secret, nil
+}
+
+func (s *kubernetesBackend) CredentialSecrets(ctx context.Context) ([]v1.Secret, error) {
+	var secrets []v1.Secret
+
+	mark := ""
+	page := 0
+	for page == 0 || len(mark) > 0 {
+		opts := apismetav1.ListOptions{
+			Continue:      mark,
+			LabelSelector: SecretLabel,
+		}
+		list, err := s.k8sClient.CoreV1().Secrets(CredentialNamespace).List(ctx, opts)
+		if err != nil {
+			return secrets, microerror.Mask(err)
+		}
+
+		secrets = append(secrets, list.Items...)
+
+		mark = list.Continue
+		page++
+	}
+
+	return secrets, nil
+}
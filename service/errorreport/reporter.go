@@ -0,0 +1,297 @@
+// Package errorreport reports panics and repeated collector failures to a
+// Sentry compatible error tracker.
+package errorreport
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+	"github.com/giantswarm/azure-collector/v2/pkg/logging"
+)
+
+const (
+	// sendTimeout is the timeout of sending a single event.
+	sendTimeout = 10 * time.Second
+	// sentryVersion is the version of the Sentry protocol events are sent
+	// with.
+	sentryVersion = "7"
+)
+
+var (
+	reporterMutex sync.Mutex
+	reporter      *Reporter
+)
+
+// Config configures the reporting of errors.
+type Config struct {
+	// ClientFactory creates the HTTP client events are sent with, so they
+	// are sent through the same proxy as the requests to the Azure APIs.
+	ClientFactory *client.Factory
+	// DSN is the Sentry compatible DSN events are sent to, e.g.
+	// "https://<key>@sentry.example.com/<project>". Nothing is reported when
+	// it is empty.
+	DSN string
+	// Environment is the environment events are tagged with, e.g. the name
+	// of the installation.
+	Environment string
+	// FailureThreshold is the number of consecutive failed collections of a
+	// collector after which the failure is reported. It is reported once per
+	// streak of failures.
+	FailureThreshold int
+	// Release is the version of the collector events are tagged with.
+	Release string
+}
+
+// Reporter sends events to a Sentry compatible error tracker.
+type Reporter struct {
+	httpClient *http.Client
+	key        string
+	storeURL   string
+
+	environment      string
+	failureThreshold int
+	release          string
+
+	mutex    sync.Mutex
+	failures map[string]int
+}
+
+// Configure configures the reporter errors are reported with. It is meant to
+// be called once on startup.
+func Configure(config Config) error {
+	if config.DSN == "" {
+		return nil
+	}
+
+	r, err := New(config)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	reporterMutex.Lock()
+	defer reporterMutex.Unlock()
+
+	reporter = r
+
+	return nil
+}
+
+// New returns a reporter sending events to the given DSN.
+func New(config Config) (*Reporter, error) {
+	if config.ClientFactory == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ClientFactory must not be empty", config)
+	}
+	if config.FailureThreshold <= 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.FailureThreshold must be greater than zero", config)
+	}
+
+	storeURL, key, err := parseDSN(config.DSN)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	r := &Reporter{
+		httpClient: config.ClientFactory.NewHTTPClient(sendTimeout),
+		key:        key,
+		storeURL:   storeURL,
+
+		environment:      config.Environment,
+		failureThreshold: config.FailureThreshold,
+		release:          config.Release,
+
+		failures: map[string]int{},
+	}
+
+	return r, nil
+}
+
+// ReportFailure counts a failed collection of the given collector, and
+// reports it once the collector failed as many times in a row as configured.
+// It is reported in the background to not delay the collection. Nothing is
+// reported when no reporter is configured.
+func ReportFailure(collector string, err error) {
+	r := getReporter()
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	r.failures[collector]++
+	failures := r.failures[collector]
+	r.mutex.Unlock()
+
+	if failures != r.failureThreshold {
+		return
+	}
+
+	e := r.newEvent("error", collector, "collector failure", err.Error())
+	e.Extra["failures"] = failures
+	e.Extra["stack"] = logging.Redact(microerror.JSON(err))
+	if subscriptionID := client.SubscriptionFromError(err); subscriptionID != "" {
+		e.Tags["subscription"] = subscriptionID
+	}
+	if clientRequestID, requestID := client.RequestIDsFromError(err); clientRequestID != "" || requestID != "" {
+		e.Extra["client_request_id"] = clientRequestID
+		e.Extra["request_id"] = requestID
+	}
+
+	go r.send(context.Background(), e) // nolint: errcheck
+}
+
+// ReportSuccess resets the failures of the given collector.
+func ReportSuccess(collector string) {
+	r := getReporter()
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.failures, collector)
+}
+
+// ReportPanic reports the given value recovered from a panic of the given
+// collector along with the stack of the panicking goroutine. It waits for the
+// event to be sent, as the process is about to crash. Nothing is reported
+// when no reporter is configured.
+func ReportPanic(collector string, recovered interface{}, stack []byte) {
+	r := getReporter()
+	if r == nil {
+		return
+	}
+
+	e := r.newEvent("fatal", collector, "panic", fmt.Sprint(recovered))
+	e.Extra["stack"] = logging.Redact(string(stack))
+
+	_ = r.send(context.Background(), e)
+}
+
+func getReporter() *Reporter {
+	reporterMutex.Lock()
+	defer reporterMutex.Unlock()
+
+	return reporter
+}
+
+// event is an event of the Sentry store API.
+type event struct {
+	EventID     string                 `json:"event_id"`
+	Environment string                 `json:"environment,omitempty"`
+	Exception   []exception            `json:"exception"`
+	Extra       map[string]interface{} `json:"extra"`
+	Fingerprint []string               `json:"fingerprint"`
+	Level       string                 `json:"level"`
+	Logger      string                 `json:"logger"`
+	Platform    string                 `json:"platform"`
+	Release     string                 `json:"release,omitempty"`
+	Tags        map[string]string      `json:"tags"`
+	Timestamp   string                 `json:"timestamp"`
+}
+
+type exception struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// newEvent returns an event of the given collector. Events are grouped by
+// their type and collector, so the same failure of different clusters is
+// tracked as one issue. Secrets are scrubbed from the message.
+func (r *Reporter) newEvent(level string, collector string, eventType string, message string) event {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	return event{
+		EventID:     hex.EncodeToString(id),
+		Environment: r.environment,
+		Exception: []exception{
+			{
+				Type:  eventType,
+				Value: logging.Redact(message),
+			},
+		},
+		Extra:       map[string]interface{}{},
+		Fingerprint: []string{eventType, collector},
+		Level:       level,
+		Logger:      "azure-collector",
+		Platform:    "go",
+		Release:     r.release,
+		Tags: map[string]string{
+			"collector": collector,
+		},
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+func (r *Reporter) send(ctx context.Context, e event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.storeURL, bytes.NewReader(body))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", fmt.Sprintf("Sentry sentry_version=%s, sentry_client=azure-collector, sentry_key=%s", sentryVersion, r.key))
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return microerror.Maskf(executionFailedError, "sending event failed with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// parseDSN returns the URL of the store API and the key of the given DSN in
+// the form "https://<key>@<host>[/<path>]/<project>".
+func parseDSN(dsn string) (string, string, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", "", microerror.Maskf(invalidConfigError, "DSN must be a URL")
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", "", microerror.Maskf(invalidConfigError, "DSN must be an HTTP or HTTPS URL")
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return "", "", microerror.Maskf(invalidConfigError, "DSN must contain a key")
+	}
+
+	path := strings.Trim(u.Path, "/")
+	i := strings.LastIndex(path, "/")
+	prefix, project := "", path
+	if i >= 0 {
+		prefix, project = "/"+path[:i], path[i+1:]
+	}
+	if project == "" {
+		return "", "", microerror.Maskf(invalidConfigError, "DSN must contain a project")
+	}
+
+	storeURL := fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, prefix, project)
+
+	return storeURL, u.User.Username(), nil
+}
@@ -0,0 +1,163 @@
+package errorreport
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/giantswarm/azure-collector/v2/client"
+)
+
+func Test_parseDSN(t *testing.T) {
+	testCases := []struct {
+		name             string
+		dsn              string
+		expectedStoreURL string
+		expectedKey      string
+		errorMatcher     func(error) bool
+	}{
+		{
+			name:             "case 0: DSN of a project",
+			dsn:              "https://abc123@sentry.example.com/42",
+			expectedStoreURL: "https://sentry.example.com/api/42/store/",
+			expectedKey:      "abc123",
+		},
+		{
+			name:             "case 1: DSN with path and port",
+			dsn:              "http://abc123@sentry.example.com:9000/sentry/42",
+			expectedStoreURL: "http://sentry.example.com:9000/sentry/api/42/store/",
+			expectedKey:      "abc123",
+		},
+		{
+			name:         "case 2: DSN without key",
+			dsn:          "https://sentry.example.com/42",
+			errorMatcher: IsInvalidConfig,
+		},
+		{
+			name:         "case 3: DSN without project",
+			dsn:          "https://abc123@sentry.example.com/",
+			errorMatcher: IsInvalidConfig,
+		},
+		{
+			name:         "case 4: DSN of unknown scheme",
+			dsn:          "ftp://abc123@sentry.example.com/42",
+			errorMatcher: IsInvalidConfig,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			storeURL, key, err := parseDSN(tc.dsn)
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if storeURL != tc.expectedStoreURL {
+				t.Fatalf("storeURL == %q, want %q", storeURL, tc.expectedStoreURL)
+			}
+			if key != tc.expectedKey {
+				t.Fatalf("key == %q, want %q", key, tc.expectedKey)
+			}
+		})
+	}
+}
+
+func Test_ReportFailure(t *testing.T) {
+	var mutex sync.Mutex
+	var events []event
+	var auth string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e event
+		err := json.NewDecoder(r.Body).Decode(&e)
+		if err != nil {
+			t.Errorf("error == %#v, want nil", err)
+		}
+
+		mutex.Lock()
+		events = append(events, e)
+		auth = r.Header.Get("X-Sentry-Auth")
+		mutex.Unlock()
+	}))
+	defer server.Close()
+
+	dsn := strings.Replace(server.URL, "://", "://abc123@", 1) + "/42"
+
+	clientFactory, err := client.NewFactory(client.FactoryConfig{})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+
+	err = Configure(Config{
+		ClientFactory:    clientFactory,
+		DSN:              dsn,
+		Environment:      "godsmack",
+		FailureThreshold: 3,
+		Release:          "2.4.1",
+	})
+	if err != nil {
+		t.Fatalf("error == %#v, want nil", err)
+	}
+	defer func() {
+		reporter = nil
+	}()
+
+	failure := errors.New("GET https://foo.blob.core.windows.net/c?sig=secret failed")
+
+	// The failure is reported once it happened three times in a row.
+	ReportFailure("collector.Usage", failure)
+	ReportFailure("collector.Usage", failure)
+	ReportSuccess("collector.Usage")
+	ReportFailure("collector.Usage", failure)
+	ReportFailure("collector.Usage", failure)
+	ReportFailure("collector.Usage", failure)
+	ReportFailure("collector.Usage", failure)
+
+	var reported []event
+	for i := 0; i < 100; i++ {
+		mutex.Lock()
+		reported = events
+		mutex.Unlock()
+
+		if len(reported) > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if len(reported) != 1 {
+		t.Fatalf("len(events) == %d, want 1", len(reported))
+	}
+
+	e := reported[0]
+	if !cmp.Equal(e.Tags, map[string]string{"collector": "collector.Usage"}) {
+		t.Fatalf("\n\n%s\n", cmp.Diff(map[string]string{"collector": "collector.Usage"}, e.Tags))
+	}
+	if e.Exception[0].Value != "GET https://foo.blob.core.windows.net/c?sig=REDACTED failed" {
+		t.Fatalf("exception == %q, want redacted", e.Exception[0].Value)
+	}
+	if e.Environment != "godsmack" || e.Release != "2.4.1" || e.Level != "error" {
+		t.Fatalf("event == %#v, want environment, release and level", e)
+	}
+	if !strings.Contains(auth, "sentry_key=abc123") {
+		t.Fatalf("auth == %q, want key", auth)
+	}
+}
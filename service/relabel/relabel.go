@@ -0,0 +1,239 @@
+// Package relabel provides a Prometheus gatherer dropping, hashing and
+// renaming labels of the exported series, for downstream systems which must
+// not receive raw Azure identifiers, and attaching constant labels and the
+// labels of their cluster to them.
+package relabel
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const (
+	// hashLength is the number of hex characters of the SHA-256 hash a
+	// hashed label value is replaced with. It is long enough to not collide
+	// within an installation while keeping the series small.
+	hashLength = 16
+
+	// clusterIDLabel is the label identifying the cluster of a series.
+	clusterIDLabel = "cluster_id"
+)
+
+type Config struct {
+	Gatherer prometheus.Gatherer
+
+	// ClusterLabels returns the labels attached to the series of the given
+	// cluster, identified by their cluster_id label, e.g. its release
+	// version. They are dropped, hashed and renamed like the labels of the
+	// series. A label of the series with the same name takes precedence.
+	// No labels are attached when it is nil.
+	ClusterLabels func(clusterID string) map[string]string
+	// ConstLabels are the labels attached to every series in the form
+	// "name=value", e.g. the installation name. A label of the series with
+	// the same name takes precedence.
+	ConstLabels []string
+	// DropLabels are the names of the labels removed from every series.
+	DropLabels []string
+	// HashLabels are the names of the labels whose values are replaced by
+	// their hash, so series can still be told apart and joined.
+	HashLabels []string
+	// RenameLabels are the labels to rename in the form "old=new". A renamed
+	// label replaces an existing label of the same name.
+	RenameLabels []string
+}
+
+type Gatherer struct {
+	gatherer      prometheus.Gatherer
+	clusterLabels func(clusterID string) map[string]string
+
+	constLabels  map[string]string
+	dropLabels   map[string]bool
+	hashLabels   map[string]bool
+	renameLabels map[string]string
+}
+
+func New(config Config) (*Gatherer, error) {
+	if config.Gatherer == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Gatherer must not be empty", config)
+	}
+
+	g := &Gatherer{
+		gatherer:      config.Gatherer,
+		clusterLabels: config.ClusterLabels,
+
+		constLabels:  map[string]string{},
+		dropLabels:   map[string]bool{},
+		hashLabels:   map[string]bool{},
+		renameLabels: map[string]string{},
+	}
+
+	for _, label := range config.ConstLabels {
+		parts := strings.SplitN(label, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, microerror.Maskf(invalidConfigError, "%T.ConstLabels must be in the form name=value, got %#q", config, label)
+		}
+
+		g.constLabels[parts[0]] = parts[1]
+	}
+
+	for _, name := range config.DropLabels {
+		g.dropLabels[name] = true
+	}
+	for _, name := range config.HashLabels {
+		g.hashLabels[name] = true
+	}
+	for _, rename := range config.RenameLabels {
+		parts := strings.SplitN(rename, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, microerror.Maskf(invalidConfigError, "%T.RenameLabels must be in the form old=new, got %#q", config, rename)
+		}
+
+		g.renameLabels[parts[0]] = parts[1]
+	}
+
+	return g, nil
+}
+
+// Gather relabels the series of the underlying gatherer. Dropping or renaming
+// labels can make series of the same family identical, in which case only
+// the first one is kept.
+func (g *Gatherer) Gather() ([]*dto.MetricFamily, error) {
+	// The underlying gatherer may return partial results along with an
+	// error, so we relabel what we got and return the error unchanged for
+	// the HTTP handler to deal with it.
+	families, err := g.gatherer.Gather()
+
+	for _, family := range families {
+		seen := map[string]bool{}
+
+		var metrics []*dto.Metric
+		for _, metric := range family.Metric {
+			metric.Label = g.relabel(metric.Label)
+
+			key := labelsKey(metric.Label)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			metrics = append(metrics, metric)
+		}
+
+		family.Metric = metrics
+	}
+
+	return families, err
+}
+
+func (g *Gatherer) relabel(labels []*dto.LabelPair) []*dto.LabelPair {
+	values := map[string]string{}
+	renamed := map[string]string{}
+
+	for _, label := range g.withClusterLabels(labels) {
+		name := label.GetName()
+		value := label.GetValue()
+
+		if g.dropLabels[name] {
+			continue
+		}
+		if g.hashLabels[name] {
+			value = hashLabelValue(value)
+		}
+
+		if newName, ok := g.renameLabels[name]; ok {
+			renamed[newName] = value
+			continue
+		}
+
+		values[name] = value
+	}
+
+	for name, value := range renamed {
+		values[name] = value
+	}
+
+	for name, value := range g.constLabels {
+		if _, ok := values[name]; !ok {
+			values[name] = value
+		}
+	}
+
+	var names []string
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var relabeled []*dto.LabelPair
+	for _, name := range names {
+		n := name
+		v := values[name]
+		relabeled = append(relabeled, &dto.LabelPair{Name: &n, Value: &v})
+	}
+
+	return relabeled
+}
+
+// withClusterLabels returns the given labels along with the labels of the
+// cluster they belong to, if any, which they do not have yet.
+func (g *Gatherer) withClusterLabels(labels []*dto.LabelPair) []*dto.LabelPair {
+	if g.clusterLabels == nil {
+		return labels
+	}
+
+	var clusterID string
+	existing := map[string]bool{}
+	for _, label := range labels {
+		if label.GetName() == clusterIDLabel {
+			clusterID = label.GetValue()
+		}
+		existing[label.GetName()] = true
+	}
+	if clusterID == "" {
+		return labels
+	}
+
+	clusterLabels := g.clusterLabels(clusterID)
+	if len(clusterLabels) == 0 {
+		return labels
+	}
+
+	withClusterLabels := append([]*dto.LabelPair{}, labels...)
+	for name, value := range clusterLabels {
+		if existing[name] {
+			continue
+		}
+
+		n := name
+		v := value
+		withClusterLabels = append(withClusterLabels, &dto.LabelPair{Name: &n, Value: &v})
+	}
+
+	return withClusterLabels
+}
+
+// hashLabelValue returns the truncated SHA-256 hash of the given label value.
+// Empty values are kept, since they mean the label is not set.
+func hashLabelValue(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])[:hashLength]
+}
+
+func labelsKey(labels []*dto.LabelPair) string {
+	var parts []string
+	for _, label := range labels {
+		parts = append(parts, label.GetName()+"="+label.GetValue())
+	}
+
+	return strings.Join(parts, "\xff")
+}
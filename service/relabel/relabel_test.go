@@ -0,0 +1,185 @@
+package relabel
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func Test_Gather(t *testing.T) {
+	testCases := []struct {
+		name           string
+		config         Config
+		series         []map[string]string
+		expectedSeries []string
+	}{
+		{
+			name:   "case 0: nothing configured",
+			config: Config{},
+			series: []map[string]string{
+				{"subscription": "s1", "resource_group": "abc12"},
+			},
+			expectedSeries: []string{
+				"resource_group=abc12,subscription=s1",
+			},
+		},
+		{
+			name: "case 1: dropped label merges identical series",
+			config: Config{
+				DropLabels: []string{"resource_group"},
+			},
+			series: []map[string]string{
+				{"subscription": "s1", "resource_group": "abc12"},
+				{"subscription": "s1", "resource_group": "def34"},
+				{"subscription": "s2", "resource_group": "abc12"},
+			},
+			expectedSeries: []string{
+				"subscription=s1",
+				"subscription=s2",
+			},
+		},
+		{
+			name: "case 2: hashed label",
+			config: Config{
+				HashLabels: []string{"subscription"},
+			},
+			series: []map[string]string{
+				{"subscription": "s1"},
+				{"subscription": ""},
+			},
+			expectedSeries: []string{
+				"subscription=" + hashLabelValue("s1"),
+				"subscription=",
+			},
+		},
+		{
+			name: "case 3: renamed label replaces existing one",
+			config: Config{
+				RenameLabels: []string{"resource_group=group", "cluster_id=cluster"},
+			},
+			series: []map[string]string{
+				{"resource_group": "abc12", "cluster": "old", "cluster_id": "abc12"},
+			},
+			expectedSeries: []string{
+				"cluster=abc12,group=abc12",
+			},
+		},
+		{
+			name: "case 4: constant labels",
+			config: Config{
+				ConstLabels: []string{"installation=godsmack", "location=westeurope"},
+			},
+			series: []map[string]string{
+				{"subscription": "s1"},
+				{"subscription": "s2", "location": "germanywestcentral"},
+			},
+			expectedSeries: []string{
+				"installation=godsmack,location=westeurope,subscription=s1",
+				"installation=godsmack,location=germanywestcentral,subscription=s2",
+			},
+		},
+		{
+			name: "case 5: cluster labels",
+			config: Config{
+				ClusterLabels: func(clusterID string) map[string]string {
+					if clusterID != "abc12" {
+						return nil
+					}
+					return map[string]string{"release_version": "14.1.0", "kubernetes_version": "1.19.9"}
+				},
+				RenameLabels: []string{"kubernetes_version=k8s_version"},
+			},
+			series: []map[string]string{
+				{"cluster_id": "abc12"},
+				{"cluster_id": "abc12", "release_version": "13.0.0"},
+				{"cluster_id": "def34"},
+				{"subscription": "s1"},
+			},
+			expectedSeries: []string{
+				"cluster_id=abc12,k8s_version=1.19.9,release_version=14.1.0",
+				"cluster_id=abc12,k8s_version=1.19.9,release_version=13.0.0",
+				"cluster_id=def34",
+				"subscription=s1",
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			tc.config.Gatherer = prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+				return []*dto.MetricFamily{newMetricFamily(tc.series)}, nil
+			})
+
+			g, err := New(tc.config)
+			if err != nil {
+				t.Fatalf("err == %v, want nil", err)
+			}
+
+			families, err := g.Gather()
+			if err != nil {
+				t.Fatalf("err == %v, want nil", err)
+			}
+
+			var series []string
+			for _, metric := range families[0].Metric {
+				var parts []string
+				for _, label := range metric.Label {
+					parts = append(parts, label.GetName()+"="+label.GetValue())
+				}
+				series = append(series, strings.Join(parts, ","))
+			}
+
+			if !cmp.Equal(series, tc.expectedSeries) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedSeries, series))
+			}
+		})
+	}
+}
+
+func Test_New(t *testing.T) {
+	_, err := New(Config{
+		Gatherer:     prometheus.NewRegistry(),
+		RenameLabels: []string{"resource_group"},
+	})
+	if !IsInvalidConfig(err) {
+		t.Fatalf("err == %v, want invalidConfigError", err)
+	}
+
+	_, err = New(Config{
+		Gatherer:    prometheus.NewRegistry(),
+		ConstLabels: []string{"=godsmack"},
+	})
+	if !IsInvalidConfig(err) {
+		t.Fatalf("err == %v, want invalidConfigError", err)
+	}
+}
+
+func newMetricFamily(series []map[string]string) *dto.MetricFamily {
+	name := "azure_operator_test"
+	family := &dto.MetricFamily{Name: &name}
+
+	for _, labels := range series {
+		var names []string
+		for labelName := range labels {
+			names = append(names, labelName)
+		}
+		sort.Strings(names)
+
+		metric := &dto.Metric{}
+		for _, labelName := range names {
+			n := labelName
+			v := labels[labelName]
+			metric.Label = append(metric.Label, &dto.LabelPair{Name: &n, Value: &v})
+		}
+		family.Metric = append(family.Metric, metric)
+	}
+
+	return family
+}
@@ -0,0 +1,232 @@
+// Package runtimeconfig watches the ConfigMap holding the settings of the
+// collector which can be changed while it is running, e.g. the resource group
+// filter and the disabled collectors, so changing them requires neither a
+// Helm upgrade nor a restart.
+package runtimeconfig
+
+import (
+	"context"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// Key is the key of the ConfigMap data holding the configuration.
+	Key = "config.yaml"
+
+	// retryInterval is how long to wait before watching the ConfigMap again
+	// once the watch ended.
+	retryInterval = 10 * time.Second
+)
+
+// Values is the configuration held by the ConfigMap, e.g.
+//
+//	collector:
+//	  disabled:
+//	  - collector.CostAnomaly
+//	  resourceGroups:
+//	    exclude:
+//	    - ci-.*
+//	  startSpread: 30s
+//	  timeout: 1m
+//
+// Settings which are not given keep the values configured by flags.
+type Values struct {
+	Collector Collector `json:"collector"`
+}
+
+type Collector struct {
+	// Disabled are the names of the collectors which are not collected,
+	// e.g. collector.CostAnomaly.
+	Disabled []string `json:"disabled"`
+	// ResourceGroups replaces the resource group filter as a whole.
+	ResourceGroups *ResourceGroups  `json:"resourceGroups"`
+	StartSpread    *metav1.Duration `json:"startSpread"`
+	Timeout        *metav1.Duration `json:"timeout"`
+}
+
+type ResourceGroups struct {
+	Exclude []string `json:"exclude"`
+	Include []string `json:"include"`
+	Tags    []string `json:"tags"`
+}
+
+type Config struct {
+	K8sClient kubernetes.Interface
+	Logger    micrologger.Logger
+
+	// Name and Namespace identify the ConfigMap holding the configuration.
+	Name      string
+	Namespace string
+
+	// Apply applies the given configuration. It is called once the
+	// ConfigMap has been read and whenever it changes. The former
+	// configuration is kept when it fails.
+	Apply func(ctx context.Context, values Values) error
+}
+
+// Watcher applies the configuration held by a ConfigMap whenever it changes.
+// A ConfigMap which does not exist holds an empty configuration, so deleting
+// it restores the settings configured by flags.
+type Watcher struct {
+	k8sClient kubernetes.Interface
+	logger    micrologger.Logger
+
+	name      string
+	namespace string
+
+	apply func(ctx context.Context, values Values) error
+
+	// applied is whether a configuration has been applied yet and data is
+	// its source.
+	applied bool
+	data    string
+}
+
+func New(config Config) (*Watcher, error) {
+	if config.K8sClient == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.K8sClient must not be empty", config)
+	}
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	if config.Name == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Name must not be empty", config)
+	}
+	if config.Namespace == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Namespace must not be empty", config)
+	}
+	if config.Apply == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Apply must not be empty", config)
+	}
+
+	w := &Watcher{
+		k8sClient: config.K8sClient,
+		logger:    config.Logger,
+
+		name:      config.Name,
+		namespace: config.Namespace,
+
+		apply: config.Apply,
+	}
+
+	return w, nil
+}
+
+// Boot watches the ConfigMap until the given context is canceled.
+func (w *Watcher) Boot(ctx context.Context) {
+	for {
+		err := w.watch(ctx)
+		if err != nil {
+			w.logger.Errorf(ctx, err, "failed to watch runtime configuration %#q", w.namespace+"/"+w.name)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+// watch applies the current configuration and the changes of the ConfigMap
+// until the watch is closed by the API server or the given context is
+// canceled.
+func (w *Watcher) watch(ctx context.Context) error {
+	configMaps := w.k8sClient.CoreV1().ConfigMaps(w.namespace)
+
+	var resourceVersion string
+	{
+		configMap, err := configMaps.Get(ctx, w.name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			w.update(ctx, "")
+		} else if err != nil {
+			return microerror.Mask(err)
+		} else {
+			w.update(ctx, configMap.Data[Key])
+			resourceVersion = configMap.ResourceVersion
+		}
+	}
+
+	options := metav1.ListOptions{
+		FieldSelector:   fields.OneTermEqualSelector("metadata.name", w.name).String(),
+		ResourceVersion: resourceVersion,
+	}
+	watcher, err := configMaps.Watch(ctx, options)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return nil
+			}
+
+			switch event.Type {
+			case watch.Added, watch.Modified:
+				configMap, ok := event.Object.(*corev1.ConfigMap)
+				if !ok {
+					continue
+				}
+				w.update(ctx, configMap.Data[Key])
+			case watch.Deleted:
+				w.update(ctx, "")
+			case watch.Error:
+				return microerror.Maskf(executionFailedError, "%s", apierrors.FromObject(event.Object))
+			}
+		}
+	}
+}
+
+// update applies the given configuration unless it is already applied.
+// Configurations which are invalid or fail to be applied are logged, so they
+// can be fixed, and the former one is kept.
+func (w *Watcher) update(ctx context.Context, data string) {
+	if w.applied && data == w.data {
+		return
+	}
+
+	values, err := parse(data)
+	if err != nil {
+		w.logger.Errorf(ctx, err, "keeping former runtime configuration")
+		return
+	}
+
+	err = w.apply(ctx, values)
+	if err != nil {
+		w.logger.Errorf(ctx, err, "keeping former runtime configuration")
+		return
+	}
+
+	w.applied = true
+	w.data = data
+
+	w.logger.Debugf(ctx, "applied runtime configuration %#q", w.namespace+"/"+w.name)
+}
+
+// parse parses the given configuration. Unknown fields are rejected, so typos
+// do not go unnoticed.
+func parse(data string) (Values, error) {
+	var values Values
+	err := yaml.UnmarshalStrict([]byte(data), &values)
+	if err != nil {
+		return Values{}, microerror.Maskf(invalidConfigError, "runtime configuration must be valid: %s", err)
+	}
+
+	return values, nil
+}
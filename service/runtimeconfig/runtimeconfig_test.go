@@ -0,0 +1,199 @@
+package runtimeconfig
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func Test_parse(t *testing.T) {
+	testCases := []struct {
+		name           string
+		data           string
+		expectedValues Values
+		errorMatcher   func(error) bool
+	}{
+		{
+			name:           "case 0: empty configuration keeps all flags",
+			data:           "",
+			expectedValues: Values{},
+		},
+		{
+			name: "case 1: all settings",
+			data: `
+collector:
+  disabled:
+  - collector.CostAnomaly
+  resourceGroups:
+    exclude:
+    - ci-.*
+    tags:
+    - owner=team-a
+  startSpread: 30s
+  timeout: 1m
+`,
+			expectedValues: Values{
+				Collector: Collector{
+					Disabled: []string{"collector.CostAnomaly"},
+					ResourceGroups: &ResourceGroups{
+						Exclude: []string{"ci-.*"},
+						Tags:    []string{"owner=team-a"},
+					},
+					StartSpread: &metav1.Duration{Duration: 30 * time.Second},
+					Timeout:     &metav1.Duration{Duration: time.Minute},
+				},
+			},
+		},
+		{
+			name: "case 2: empty resource groups replace the filter",
+			data: `
+collector:
+  resourceGroups: {}
+`,
+			expectedValues: Values{
+				Collector: Collector{
+					ResourceGroups: &ResourceGroups{},
+				},
+			},
+		},
+		{
+			name: "case 3: unknown fields are rejected",
+			data: `
+collector:
+  timout: 1m
+`,
+			errorMatcher: IsInvalidConfig,
+		},
+		{
+			name: "case 4: invalid durations are rejected",
+			data: `
+collector:
+  timeout: soon
+`,
+			errorMatcher: IsInvalidConfig,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			values, err := parse(tc.data)
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if !cmp.Equal(values, tc.expectedValues) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedValues, values))
+			}
+		})
+	}
+}
+
+func Test_Watcher(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	k8sClient := fake.NewSimpleClientset()
+
+	// The changes of the ConfigMap are only made once it is watched, so
+	// they are not missed.
+	watching := make(chan struct{})
+	k8sClient.PrependWatchReactor("configmaps", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		w, err := k8sClient.Tracker().Watch(action.GetResource(), action.GetNamespace())
+		close(watching)
+		return true, w, err
+	})
+
+	applied := make(chan Values)
+	var w *Watcher
+	{
+		c := Config{
+			K8sClient: k8sClient,
+			Logger:    microloggertest.New(),
+
+			Name:      "azure-collector-runtime",
+			Namespace: "giantswarm",
+
+			Apply: func(ctx context.Context, values Values) error {
+				applied <- values
+				return nil
+			},
+		}
+
+		var err error
+		w, err = New(c)
+		if err != nil {
+			t.Fatalf("expected no error, got %#v", err)
+		}
+	}
+
+	go func() {
+		_ = w.watch(ctx)
+	}()
+
+	expectApplied := func(expected Values) {
+		t.Helper()
+
+		select {
+		case values := <-applied:
+			if !cmp.Equal(values, expected) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(expected, values))
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected configuration to be applied")
+		}
+	}
+
+	configMaps := k8sClient.CoreV1().ConfigMaps("giantswarm")
+
+	// Without the ConfigMap, the settings configured by flags are kept.
+	expectApplied(Values{})
+	<-watching
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "azure-collector-runtime",
+			Namespace: "giantswarm",
+		},
+		Data: map[string]string{
+			Key: "collector:\n  disabled:\n  - collector.CostAnomaly\n",
+		},
+	}
+	_, err := configMaps.Create(ctx, configMap, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("expected no error, got %#v", err)
+	}
+	expectApplied(Values{Collector: Collector{Disabled: []string{"collector.CostAnomaly"}}})
+
+	configMap.Data[Key] = "collector:\n  timeout: 1m\n"
+	_, err = configMaps.Update(ctx, configMap, metav1.UpdateOptions{})
+	if err != nil {
+		t.Fatalf("expected no error, got %#v", err)
+	}
+	expectApplied(Values{Collector: Collector{Timeout: &metav1.Duration{Duration: time.Minute}}})
+
+	// Deleting the ConfigMap restores the settings configured by flags.
+	err = configMaps.Delete(ctx, configMap.Name, metav1.DeleteOptions{})
+	if err != nil {
+		t.Fatalf("expected no error, got %#v", err)
+	}
+	expectApplied(Values{})
+}
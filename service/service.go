@@ -2,23 +2,37 @@ package service
 
 import (
 	"context"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/giantswarm/apiextensions/v2/pkg/apis/provider/v1alpha1"
+	releasev1alpha1 "github.com/giantswarm/apiextensions/v3/pkg/apis/release/v1alpha1"
+	securityv1alpha1 "github.com/giantswarm/apiextensions/v3/pkg/apis/security/v1alpha1"
 	"github.com/giantswarm/k8sclient/v4/pkg/k8sclient"
 	"github.com/giantswarm/k8sclient/v4/pkg/k8srestconfig"
-	"github.com/giantswarm/microendpoint/service/version"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	"github.com/giantswarm/statusresource/v2"
-	"github.com/giantswarm/versionbundle"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/viper"
 	"k8s.io/client-go/rest"
 	capiv1alpha3 "sigs.k8s.io/cluster-api/api/v1alpha3"
+	expcapiv1alpha3 "sigs.k8s.io/cluster-api/exp/api/v1alpha3"
 
+	"github.com/giantswarm/azure-collector/v2/client"
 	"github.com/giantswarm/azure-collector/v2/flag"
-	"github.com/giantswarm/azure-collector/v2/pkg/project"
+	"github.com/giantswarm/azure-collector/v2/pkg/audit"
+	"github.com/giantswarm/azure-collector/v2/pkg/dryrun"
+	"github.com/giantswarm/azure-collector/v2/pkg/featuregate"
+	"github.com/giantswarm/azure-collector/v2/pkg/tracing"
+	"github.com/giantswarm/azure-collector/v2/service/clusterlabels"
 	"github.com/giantswarm/azure-collector/v2/service/collector"
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+	"github.com/giantswarm/azure-collector/v2/service/errorreport"
+	"github.com/giantswarm/azure-collector/v2/service/relabel"
+	"github.com/giantswarm/azure-collector/v2/service/runtimeconfig"
+	"github.com/giantswarm/azure-collector/v2/service/standalone"
 )
 
 // Config represents the configuration used to create a new service.
@@ -28,21 +42,48 @@ type Config struct {
 	Flag  *flag.Flag
 	Viper *viper.Viper
 
-	Description string
 	GitCommit   string
 	ProjectName string
-	Source      string
 	Version     string
 }
 
 type Service struct {
-	Version *version.Service
+	logger micrologger.Logger
 
 	bootOnce                sync.Once
+	buildInfo               BuildInfo
+	clientFactory           *client.Factory
+	credentialSource        *credential.Source
+	clusterLabelsResolver   *clusterlabels.Resolver
 	operatorCollector       *collector.Set
+	runtimeConfigWatcher    *runtimeconfig.Watcher
+	shutdown                chan struct{}
+	shutdownGracePeriod     time.Duration
+	shutdownOnce            sync.Once
+	shutdownTracing         func(context.Context) error
+	stateFile               string
 	statusResourceCollector *statusresource.CollectorSet
+
+	// collectorConfig and resourceGroupFilterConfig are the settings
+	// configured by flags, which the runtime configuration overrides.
+	collectorConfig           collector.RuntimeConfig
+	resourceGroupFilterConfig credential.ResourceGroupFilterConfig
+
+	resourceGroupFilterMutex sync.Mutex
+	// resourceGroupFilter is the filter currently applied along with its
+	// settings. stopResourceGroupFilter stops looking up its tagged resource
+	// groups.
+	resourceGroupFilter        *credential.ResourceGroupFilter
+	resourceGroupFilterApplied credential.ResourceGroupFilterConfig
+	stopResourceGroupFilter    context.CancelFunc
 }
 
+const (
+	// stateSaveInterval is how often the ARM call budgets and throttled
+	// subscriptions are written to the state file.
+	stateSaveInterval = 30 * time.Second
+)
+
 // New creates a new configured service object.
 func New(config Config) (*Service, error) {
 	if config.Logger == nil {
@@ -55,23 +96,100 @@ func New(config Config) (*Service, error) {
 	if config.Viper == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Viper must not be empty", config)
 	}
-	if config.Description == "" {
-		return nil, microerror.Maskf(invalidConfigError, "%T.Description must not be empty", config)
-	}
 	if config.GitCommit == "" {
 		return nil, microerror.Maskf(invalidConfigError, "%T.GitCommit must not be empty", config)
 	}
 	if config.ProjectName == "" {
 		return nil, microerror.Maskf(invalidConfigError, "%T.ProjectName must not be empty", config)
 	}
-	if config.Source == "" {
-		return nil, microerror.Maskf(invalidConfigError, "%T.Source must not be empty", config)
+	if config.Version == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Version must not be empty", config)
 	}
 
 	var err error
 
-	var k8sClient *k8sclient.Clients
+	gsTenantID := config.Viper.GetString(config.Flag.Service.Azure.SPTenantID)
+
+	// The Azure clients of all collectors are created by the same factory,
+	// so they share its HTTP transport, endpoints and ARM call budgets.
+	var clientFactory *client.Factory
 	{
+		serviceTimeouts, err := client.ParseServiceTimeouts(config.Viper.GetStringSlice(config.Flag.Service.Azure.Timeout.Services))
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		c := client.FactoryConfig{
+			HTTP: client.HTTPConfig{
+				Timeout:         config.Viper.GetDuration(config.Flag.Service.Azure.Timeout.Default),
+				ServiceTimeouts: serviceTimeouts,
+				CAFile:          config.Viper.GetString(config.Flag.Service.Azure.CAFile),
+				HTTPProxy:       config.Viper.GetString(config.Flag.Service.Azure.Proxy.HTTP),
+				HTTPSProxy:      config.Viper.GetString(config.Flag.Service.Azure.Proxy.HTTPS),
+				NoProxy:         config.Viper.GetString(config.Flag.Service.Azure.Proxy.NoProxy),
+				Logger:          config.Logger.With("component", "azure_client"),
+			},
+			Endpoints: client.EndpointConfig{
+				ActiveDirectory:   config.Viper.GetString(config.Flag.Service.Azure.Endpoints.ActiveDirectory),
+				KeyVaultDNSSuffix: config.Viper.GetString(config.Flag.Service.Azure.Endpoints.KeyVaultDNSSuffix),
+				MicrosoftGraph:    config.Viper.GetString(config.Flag.Service.Azure.Endpoints.MicrosoftGraph),
+				ResourceManager:   config.Viper.GetString(config.Flag.Service.Azure.Endpoints.ResourceManager),
+			},
+			CallBudget: config.Viper.GetInt(config.Flag.Service.Azure.CallBudget),
+		}
+
+		clientFactory, err = client.NewFactory(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	// In standalone mode the subscriptions and credentials are taken from
+	// the configuration and no Kubernetes API is talked to, so there is no
+	// Kubernetes client.
+	var credentialBackend credential.Backend
+	var k8sClient k8sclient.Interface
+	if config.Viper.GetBool(config.Flag.Service.Standalone.Enabled) {
+		// Credentials not configured by flags are taken from the
+		// environment or the azure.json file, if any, for local
+		// development.
+		credentials := standalone.Credentials{
+			ClientID:       config.Viper.GetString(config.Flag.Service.Azure.ClientID),
+			ClientSecret:   config.Viper.GetString(config.Flag.Service.Azure.ClientSecret),
+			SubscriptionID: config.Viper.GetString(config.Flag.Service.Azure.SubscriptionID),
+			TenantID:       config.Viper.GetString(config.Flag.Service.Azure.TenantID),
+		}
+
+		credentials, err = standalone.LoadCredentials(credentials, config.Viper.GetString(config.Flag.Service.Standalone.CredentialsFile))
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		subscriptionIDs := config.Viper.GetStringSlice(config.Flag.Service.Standalone.SubscriptionIDs)
+		if len(subscriptionIDs) == 0 && credentials.SubscriptionID != "" {
+			subscriptionIDs = []string{credentials.SubscriptionID}
+		}
+
+		c := standalone.Config{
+			ClientID:        credentials.ClientID,
+			ClientSecret:    credentials.ClientSecret,
+			PartnerID:       config.Viper.GetString(config.Flag.Service.Azure.PartnerID),
+			ResourceGroups:  config.Viper.GetStringSlice(config.Flag.Service.Standalone.ResourceGroups),
+			SubscriptionIDs: subscriptionIDs,
+			TenantID:        credentials.TenantID,
+		}
+
+		credentialBackend, err = standalone.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		// The service principal is a single tenant one, which is the
+		// tenant used for authentication unless configured otherwise.
+		if gsTenantID == "" {
+			gsTenantID = c.TenantID
+		}
+	} else {
 		address := config.Viper.GetString(config.Flag.Service.Kubernetes.Address)
 		inCluster := config.Viper.GetBool(config.Flag.Service.Kubernetes.InCluster)
 		kubeConfigPath := config.Viper.GetString(config.Flag.Service.Kubernetes.KubeConfigPath)
@@ -107,6 +225,9 @@ func New(config Config) (*Service, error) {
 			SchemeBuilder: k8sclient.SchemeBuilder{
 				v1alpha1.AddToScheme,
 				capiv1alpha3.AddToScheme,
+				expcapiv1alpha3.AddToScheme,
+				releasev1alpha1.AddToScheme,
+				securityv1alpha1.AddToScheme,
 			},
 
 			KubeConfigPath: kubeConfigPath,
@@ -117,16 +238,137 @@ func New(config Config) (*Service, error) {
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
+
+		credentialBackend = credential.NewKubernetesBackend(k8sClient.K8sClient(), k8sClient.G8sClient())
+	}
+
+	var credentialSource *credential.Source
+	{
+		c := credential.SourceConfig{
+			Backend:       credentialBackend,
+			ClientFactory: clientFactory,
+		}
+
+		credentialSource, err = credential.NewSource(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	var shutdownTracing func(context.Context) error
+	{
+		c := tracing.Config{
+			Endpoint:    config.Viper.GetString(config.Flag.Service.Tracing.Endpoint),
+			Insecure:    config.Viper.GetBool(config.Flag.Service.Tracing.Insecure),
+			SampleRatio: config.Viper.GetFloat64(config.Flag.Service.Tracing.SampleRatio),
+
+			ServiceName:    config.ProjectName,
+			ServiceVersion: config.Version,
+		}
+
+		shutdownTracing, err = tracing.Configure(context.Background(), c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	// In a dry run no calls are sent to the Azure APIs, including the ones
+	// of the background pollers.
+	dryrun.Configure(config.Viper.GetBool(config.Flag.Service.DryRun))
+
+	// Feature gates must be configured before the collectors are created,
+	// as experimental collectors are left out unless enabled.
+	{
+		err = featuregate.Configure(config.Viper.GetStringSlice(config.Flag.Service.FeatureGates))
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		config.Logger.LogCtx(context.Background(), "level", "info", "message", "configured feature gates", "featuregates", strings.Join(featuregate.Status(), ","))
+	}
+
+	{
+		c := audit.Config{
+			Output:     config.Viper.GetString(config.Flag.Service.Audit.Output),
+			MaxSize:    config.Viper.GetInt64(config.Flag.Service.Audit.MaxSize),
+			MaxBackups: config.Viper.GetInt(config.Flag.Service.Audit.MaxBackups),
+		}
+
+		err = audit.Configure(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	// Errors are reported through the proxy of the client factory.
+	{
+		c := errorreport.Config{
+			ClientFactory: clientFactory,
+
+			DSN:              config.Viper.GetString(config.Flag.Service.ErrorReporting.DSN),
+			Environment:      config.Viper.GetString(config.Flag.Service.ErrorReporting.Environment),
+			FailureThreshold: config.Viper.GetInt(config.Flag.Service.ErrorReporting.FailureThreshold),
+			Release:          config.Version,
+		}
+
+		err = errorreport.Configure(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+	}
+
+	// A state file which can't be read must not keep the collector from
+	// starting, it merely starts without knowing about earlier throttling.
+	if stateFile := config.Viper.GetString(config.Flag.Service.Azure.StateFile); stateFile != "" {
+		err = clientFactory.LoadBudgetState(stateFile)
+		if err != nil {
+			config.Logger.Errorf(context.Background(), err, "failed to load budget state from %#q", stateFile)
+		}
+	}
+
+	resourceGroupFilterConfig := credential.ResourceGroupFilterConfig{
+		CredentialSource: credentialSource,
+		Logger:           config.Logger,
+		GSTenantID:       gsTenantID,
+
+		Exclude: config.Viper.GetStringSlice(config.Flag.Service.Collector.ResourceGroups.Exclude),
+		Include: config.Viper.GetStringSlice(config.Flag.Service.Collector.ResourceGroups.Include),
+		Tags:    config.Viper.GetStringSlice(config.Flag.Service.Collector.ResourceGroups.Tags),
+	}
+
+	var resourceGroupFilter *credential.ResourceGroupFilter
+	{
+		resourceGroupFilter, err = credential.NewResourceGroupFilter(resourceGroupFilterConfig)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		credentialSource.SetResourceGroupFilter(resourceGroupFilter)
 	}
 
 	var operatorCollector *collector.Set
 	{
+		successRatioWindows, err := collector.ParseSuccessRatioWindows(config.Viper.GetStringSlice(config.Flag.Service.Collector.SuccessRatioWindows))
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
 		c := collector.SetConfig{
-			ControlPlaneResourceGroup: config.Viper.GetString(config.Flag.Service.ControlPlaneResourceGroup),
-			Location:                  config.Viper.GetString(config.Flag.Service.Location),
-			Logger:                    config.Logger,
-			K8sClient:                 k8sClient,
-			GSTenantID:                config.Viper.GetString(config.Flag.Service.Azure.SPTenantID),
+			CollectTimeout:                  config.Viper.GetDuration(config.Flag.Service.Collector.Timeout),
+			ControlPlaneResourceGroup:       config.Viper.GetString(config.Flag.Service.ControlPlaneResourceGroup),
+			CostTagLabels:                   config.Viper.GetStringSlice(config.Flag.Service.Collector.Cost.TagLabels),
+			DiagnosticSettingsDestination:   config.Viper.GetString(config.Flag.Service.Collector.DiagnosticSettings.Destination),
+			DiagnosticSettingsResourceTypes: config.Viper.GetStringSlice(config.Flag.Service.Collector.DiagnosticSettings.ResourceTypes),
+			Location:                        config.Viper.GetString(config.Flag.Service.Location),
+			MonitorMetricsConfigFile:        config.Viper.GetString(config.Flag.Service.Collector.MonitorMetrics.ConfigFile),
+			RoleAssignmentsLimit:            config.Viper.GetInt(config.Flag.Service.Collector.RoleAssignments.Limit),
+			StartSpread:                     config.Viper.GetDuration(config.Flag.Service.Collector.StartSpread),
+			SuccessRatioWindows:             successRatioWindows,
+			TagComplianceRequiredTags:       config.Viper.GetStringSlice(config.Flag.Service.Collector.TagCompliance.RequiredTags),
+			CredentialSource:                credentialSource,
+			Logger:                          config.Logger,
+			K8sClient:                       k8sClient,
+			GSTenantID:                      gsTenantID,
 		}
 
 		operatorCollector, err = collector.NewSet(c)
@@ -135,51 +377,319 @@ func New(config Config) (*Service, error) {
 		}
 	}
 
-	var statusResourceCollector *statusresource.CollectorSet
-	{
-		c := statusresource.CollectorSetConfig{
-			Logger:  config.Logger,
-			Watcher: k8sClient.G8sClient().ProviderV1alpha1().AzureConfigs("").Watch,
+	// The series of the workload clusters of a management cluster carry
+	// the release and Kubernetes versions and the organization of the
+	// cluster. There are no clusters in standalone mode.
+	var clusterLabelsResolver *clusterlabels.Resolver
+	if k8sClient != nil {
+		c := clusterlabels.Config{
+			CtrlClient: k8sClient.CtrlClient(),
+			Logger:     config.Logger,
 		}
 
-		statusResourceCollector, err = statusresource.NewCollectorSet(c)
+		clusterLabelsResolver, err = clusterlabels.New(c)
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
 	}
 
-	var versionService *version.Service
 	{
-		c := version.Config{
-			Description:    config.Description,
-			GitCommit:      config.GitCommit,
-			Name:           config.ProjectName,
-			Source:         config.Source,
-			Version:        config.Version,
-			VersionBundles: []versionbundle.Bundle{project.NewVersionBundle()},
+		c := relabel.Config{
+			Gatherer: prometheus.DefaultGatherer,
+
+			ConstLabels:  config.Viper.GetStringSlice(config.Flag.Service.Metrics.ConstLabels),
+			DropLabels:   config.Viper.GetStringSlice(config.Flag.Service.Metrics.DropLabels),
+			HashLabels:   config.Viper.GetStringSlice(config.Flag.Service.Metrics.HashLabels),
+			RenameLabels: config.Viper.GetStringSlice(config.Flag.Service.Metrics.RenameLabels),
+		}
+
+		if clusterLabelsResolver != nil {
+			c.ClusterLabels = clusterLabelsResolver.Labels
 		}
 
-		versionService, err = version.New(c)
+		relabelGatherer, err := relabel.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+
+		// The metrics endpoint of the server serves the default gatherer,
+		// so we replace it to relabel every exported series. It is only
+		// replaced when relabeling is configured to not pay its cost
+		// otherwise.
+		if c.ClusterLabels != nil || len(c.ConstLabels) > 0 || len(c.DropLabels) > 0 || len(c.HashLabels) > 0 || len(c.RenameLabels) > 0 {
+			prometheus.DefaultGatherer = relabelGatherer
+		}
+	}
+
+	// The statuses of the AzureConfig CRs are only watched in a management
+	// cluster.
+	var statusResourceCollector *statusresource.CollectorSet
+	if k8sClient != nil {
+		c := statusresource.CollectorSetConfig{
+			Logger:  config.Logger,
+			Watcher: k8sClient.G8sClient().ProviderV1alpha1().AzureConfigs("").Watch,
+		}
+
+		statusResourceCollector, err = statusresource.NewCollectorSet(c)
 		if err != nil {
 			return nil, microerror.Mask(err)
 		}
 	}
 
 	s := &Service{
-		Version: versionService,
+		logger: config.Logger,
 
 		bootOnce:                sync.Once{},
+		clientFactory:           clientFactory,
+		credentialSource:        credentialSource,
+		buildInfo:               newBuildInfo(config.Version, config.GitCommit, operatorCollector.Names()),
+		clusterLabelsResolver:   clusterLabelsResolver,
 		operatorCollector:       operatorCollector,
+		shutdown:                make(chan struct{}),
+		shutdownGracePeriod:     config.Viper.GetDuration(config.Flag.Service.Collector.ShutdownGracePeriod),
+		shutdownOnce:            sync.Once{},
+		shutdownTracing:         shutdownTracing,
+		stateFile:               config.Viper.GetString(config.Flag.Service.Azure.StateFile),
 		statusResourceCollector: statusResourceCollector,
+
+		collectorConfig: collector.RuntimeConfig{
+			CollectTimeout: config.Viper.GetDuration(config.Flag.Service.Collector.Timeout),
+			StartSpread:    config.Viper.GetDuration(config.Flag.Service.Collector.StartSpread),
+		},
+		resourceGroupFilterConfig: resourceGroupFilterConfig,
+
+		resourceGroupFilter:        resourceGroupFilter,
+		resourceGroupFilterApplied: resourceGroupFilterConfig,
+	}
+
+	// The runtime configuration is applied over the settings configured
+	// by flags whenever its ConfigMap changes. There is no ConfigMap to
+	// watch in standalone mode.
+	if name := config.Viper.GetString(config.Flag.Service.RuntimeConfig.Name); name != "" && k8sClient != nil {
+		c := runtimeconfig.Config{
+			K8sClient: k8sClient.K8sClient(),
+			Logger:    config.Logger,
+
+			Name:      name,
+			Namespace: config.Viper.GetString(config.Flag.Service.RuntimeConfig.Namespace),
+
+			Apply: s.applyRuntimeConfig,
+		}
+
+		s.runtimeConfigWatcher, err = runtimeconfig.New(c)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
 	}
 
 	return s, nil
 }
 
+// BuildInfo returns the build information of the service.
+func (s *Service) BuildInfo() BuildInfo {
+	return s.buildInfo
+}
+
+// Collections returns the collections of all collectors, which the scrapes
+// of the metrics endpoint bound.
+func (s *Service) Collections() *collector.Collections {
+	return s.operatorCollector.Collections()
+}
+
+// CollectorStatuses returns the statuses of all collectors along with the
+// metrics they export.
+func (s *Service) CollectorStatuses() ([]collector.Status, error) {
+	statuses, err := s.operatorCollector.Statuses()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	return statuses, nil
+}
+
 func (s *Service) Boot(ctx context.Context) {
 	s.bootOnce.Do(func() {
-		go s.operatorCollector.Boot(ctx)       // nolint: errcheck
-		go s.statusResourceCollector.Boot(ctx) // nolint: errcheck
+		// Background pollers are stopped on shutdown.
+		ctx, cancel := context.WithCancel(ctx)
+		go func() {
+			<-s.shutdown
+			cancel()
+		}()
+
+		go s.operatorCollector.Boot(ctx) // nolint: errcheck
+		go s.saveState(ctx)
+
+		if s.statusResourceCollector != nil {
+			go s.statusResourceCollector.Boot(ctx) // nolint: errcheck
+		}
+		if s.clusterLabelsResolver != nil {
+			go s.clusterLabelsResolver.Boot(ctx)
+		}
+
+		s.resourceGroupFilterMutex.Lock()
+		s.stopResourceGroupFilter = bootResourceGroupFilter(ctx, s.resourceGroupFilter)
+		s.resourceGroupFilterMutex.Unlock()
+
+		if s.runtimeConfigWatcher != nil {
+			go s.runtimeConfigWatcher.Boot(ctx)
+		}
+	})
+}
+
+// applyRuntimeConfig applies the given runtime configuration over the settings
+// configured by flags. The resource group filter is only replaced when its
+// settings changed, as the tagged resource groups are looked up again then.
+func (s *Service) applyRuntimeConfig(ctx context.Context, values runtimeconfig.Values) error {
+	// Typos in the names of disabled collectors would otherwise leave the
+	// collectors enabled without notice.
+	names := map[string]bool{}
+	for _, name := range s.operatorCollector.Names() {
+		names[name] = true
+	}
+	for _, name := range values.Collector.Disabled {
+		if !names[name] {
+			return microerror.Maskf(invalidConfigError, "disabled collector %#q must be one of %s", name, strings.Join(s.operatorCollector.Names(), ", "))
+		}
+	}
+
+	collectorConfig := s.collectorConfig
+	collectorConfig.Disabled = values.Collector.Disabled
+	if values.Collector.StartSpread != nil {
+		collectorConfig.StartSpread = values.Collector.StartSpread.Duration
+	}
+	if values.Collector.Timeout != nil {
+		collectorConfig.CollectTimeout = values.Collector.Timeout.Duration
+	}
+
+	filterConfig := s.resourceGroupFilterConfig
+	if r := values.Collector.ResourceGroups; r != nil {
+		filterConfig.Exclude = r.Exclude
+		filterConfig.Include = r.Include
+		filterConfig.Tags = r.Tags
+	}
+
+	s.resourceGroupFilterMutex.Lock()
+	defer s.resourceGroupFilterMutex.Unlock()
+
+	if !equalResourceGroupFilterConfigs(filterConfig, s.resourceGroupFilterApplied) {
+		f, err := credential.NewResourceGroupFilter(filterConfig)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		// The tagged resource groups of the new filter are known before it
+		// is applied, as no resource group would match in between.
+		err = f.Prepare(ctx, s.resourceGroupFilter)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+
+		s.credentialSource.SetResourceGroupFilter(f)
+
+		s.stopResourceGroupFilter()
+		s.stopResourceGroupFilter = bootResourceGroupFilter(ctx, f)
+		s.resourceGroupFilter = f
+		s.resourceGroupFilterApplied = filterConfig
+	}
+
+	s.operatorCollector.Collections().Configure(collectorConfig)
+
+	return nil
+}
+
+// bootResourceGroupFilter looks up the tagged resource groups of the given
+// filter until the returned function is called.
+func bootResourceGroupFilter(ctx context.Context, f *credential.ResourceGroupFilter) context.CancelFunc {
+	ctx, cancel := context.WithCancel(ctx)
+	go f.Boot(ctx)
+
+	return cancel
+}
+
+func equalResourceGroupFilterConfigs(a, b credential.ResourceGroupFilterConfig) bool {
+	return equalStrings(a.Exclude, b.Exclude) && equalStrings(a.Include, b.Include) && equalStrings(a.Tags, b.Tags)
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// saveState writes the ARM call budgets and throttled subscriptions to the
+// state file periodically and whenever a throttled call is recorded, until the
+// given context is canceled.
+func (s *Service) saveState(ctx context.Context) {
+	if s.stateFile == "" {
+		return
+	}
+
+	ticker := time.NewTicker(stateSaveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.clientFactory.ThrottleRecorded():
+		}
+
+		err := s.clientFactory.SaveBudgetState(s.stateFile)
+		if err != nil {
+			s.logger.Errorf(ctx, err, "failed to save budget state to %#q", s.stateFile)
+		}
+	}
+}
+
+// Shutdown stops the background pollers and waits for the collections in
+// progress to finish within the configured grace period. The Azure calls of
+// the collections still in progress by then are canceled. The budget state and
+// spans not exported yet are written afterwards.
+func (s *Service) Shutdown() {
+	s.shutdownOnce.Do(func() {
+		close(s.shutdown)
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.shutdownGracePeriod)
+		defer cancel()
+
+		s.logger.Debugf(ctx, "draining collections in progress")
+
+		err := s.operatorCollector.Collections().Drain(ctx)
+		if err != nil {
+			s.logger.Errorf(ctx, err, "failed to drain collections within the grace period of %s", s.shutdownGracePeriod)
+		} else {
+			s.logger.Debugf(ctx, "drained collections in progress")
+		}
+
+		// The calls of the drained collections are counted in the budget
+		// state.
+		if s.stateFile != "" {
+			err = s.clientFactory.SaveBudgetState(s.stateFile)
+			if err != nil {
+				s.logger.Errorf(ctx, err, "failed to save budget state to %#q", s.stateFile)
+			}
+		}
+
+		// The spans of the drained collections are flushed last, so they
+		// are not lost.
+		err = s.shutdownTracing(context.Background())
+		if err != nil {
+			s.logger.Errorf(ctx, err, "failed to flush spans")
+		}
+
+		// No more calls are sent once the collections are drained.
+		err = audit.Close()
+		if err != nil {
+			s.logger.Errorf(ctx, err, "failed to close audit file")
+		}
 	})
 }
 
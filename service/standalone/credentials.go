@@ -0,0 +1,101 @@
+package standalone
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"github.com/giantswarm/microerror"
+)
+
+// Environment variables of the credentials, as used by the Azure SDKs and CLI.
+const (
+	envClientID       = "AZURE_CLIENT_ID"
+	envClientSecret   = "AZURE_CLIENT_SECRET"
+	envSubscriptionID = "AZURE_SUBSCRIPTION_ID"
+	envTenantID       = "AZURE_TENANT_ID"
+)
+
+// Credentials are the credentials of the service principal used for all
+// subscriptions along with the subscription collected by default.
+type Credentials struct {
+	ClientID       string
+	ClientSecret   string
+	SubscriptionID string
+	TenantID       string
+}
+
+// azureJSON is the subset of the azure.json file of the Azure cloud provider
+// holding the credentials.
+type azureJSON struct {
+	AADClientID     string `json:"aadClientId"`
+	AADClientSecret string `json:"aadClientSecret"`
+	SubscriptionID  string `json:"subscriptionId"`
+	TenantID        string `json:"tenantId"`
+}
+
+// LoadCredentials returns the credentials of the first source providing a
+// client ID: the given ones, e.g. configured by flags, the AZURE_CLIENT_ID,
+// AZURE_CLIENT_SECRET and AZURE_TENANT_ID environment variables or the given
+// azure.json file, if it exists. The subscription is taken from the first
+// source providing one, including the AZURE_SUBSCRIPTION_ID environment
+// variable. This way collectors can be run locally with the credentials
+// developers already have at hand.
+func LoadCredentials(credentials Credentials, file string) (Credentials, error) {
+	c, err := loadCredentials(credentials, os.Getenv, file)
+	if err != nil {
+		return Credentials{}, microerror.Mask(err)
+	}
+
+	return c, nil
+}
+
+func loadCredentials(credentials Credentials, getenv func(string) string, file string) (Credentials, error) {
+	sources := []Credentials{
+		credentials,
+		{
+			ClientID:       getenv(envClientID),
+			ClientSecret:   getenv(envClientSecret),
+			SubscriptionID: getenv(envSubscriptionID),
+			TenantID:       getenv(envTenantID),
+		},
+	}
+
+	if file != "" {
+		b, err := ioutil.ReadFile(file)
+		if err != nil && !os.IsNotExist(err) {
+			return Credentials{}, microerror.Mask(err)
+		}
+
+		if err == nil {
+			var a azureJSON
+			err = json.Unmarshal(b, &a)
+			if err != nil {
+				return Credentials{}, microerror.Maskf(invalidConfigError, "credentials file %#q must be an azure.json file: %s", file, err)
+			}
+
+			sources = append(sources, Credentials{
+				ClientID:       a.AADClientID,
+				ClientSecret:   a.AADClientSecret,
+				SubscriptionID: a.SubscriptionID,
+				TenantID:       a.TenantID,
+			})
+		}
+	}
+
+	// Client IDs, secrets and tenants of different sources are never
+	// mixed, as they only work together.
+	var c Credentials
+	for _, s := range sources {
+		if c.ClientID == "" && s.ClientID != "" {
+			c.ClientID = s.ClientID
+			c.ClientSecret = s.ClientSecret
+			c.TenantID = s.TenantID
+		}
+		if c.SubscriptionID == "" {
+			c.SubscriptionID = s.SubscriptionID
+		}
+	}
+
+	return c, nil
+}
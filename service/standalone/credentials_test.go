@@ -0,0 +1,126 @@
+package standalone
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_loadCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "standalone")
+	if err != nil {
+		t.Fatalf("expected no error, got %#v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	azureJSONFile := filepath.Join(dir, "azure.json")
+	err = ioutil.WriteFile(azureJSONFile, []byte(`{"cloud":"AzurePublicCloud","tenantId":"file-tenant","subscriptionId":"file-subscription","aadClientId":"file-client","aadClientSecret":"file-secret","resourceGroup":"rg"}`), 0600)
+	if err != nil {
+		t.Fatalf("expected no error, got %#v", err)
+	}
+
+	invalidFile := filepath.Join(dir, "invalid.json")
+	err = ioutil.WriteFile(invalidFile, []byte(`tenantId: file-tenant`), 0600)
+	if err != nil {
+		t.Fatalf("expected no error, got %#v", err)
+	}
+
+	env := map[string]string{
+		"AZURE_CLIENT_ID":     "env-client",
+		"AZURE_CLIENT_SECRET": "env-secret",
+		"AZURE_TENANT_ID":     "env-tenant",
+	}
+
+	testCases := []struct {
+		name                string
+		credentials         Credentials
+		env                 map[string]string
+		file                string
+		expectedCredentials Credentials
+		errorMatcher        func(error) bool
+	}{
+		{
+			name: "case 0: configured credentials take precedence",
+			credentials: Credentials{
+				ClientID:       "flag-client",
+				ClientSecret:   "flag-secret",
+				SubscriptionID: "flag-subscription",
+				TenantID:       "flag-tenant",
+			},
+			env:  env,
+			file: azureJSONFile,
+			expectedCredentials: Credentials{
+				ClientID:       "flag-client",
+				ClientSecret:   "flag-secret",
+				SubscriptionID: "flag-subscription",
+				TenantID:       "flag-tenant",
+			},
+		},
+		{
+			name:        "case 1: environment variables are used without configured credentials, the subscription is taken from the file",
+			credentials: Credentials{},
+			env:         env,
+			file:        azureJSONFile,
+			expectedCredentials: Credentials{
+				ClientID:       "env-client",
+				ClientSecret:   "env-secret",
+				SubscriptionID: "file-subscription",
+				TenantID:       "env-tenant",
+			},
+		},
+		{
+			name: "case 2: file is used without configured credentials and environment variables",
+			credentials: Credentials{
+				SubscriptionID: "flag-subscription",
+			},
+			file: azureJSONFile,
+			expectedCredentials: Credentials{
+				ClientID:       "file-client",
+				ClientSecret:   "file-secret",
+				SubscriptionID: "flag-subscription",
+				TenantID:       "file-tenant",
+			},
+		},
+		{
+			name:                "case 3: missing file is skipped",
+			file:                filepath.Join(dir, "missing.json"),
+			expectedCredentials: Credentials{},
+		},
+		{
+			name:         "case 4: invalid file is rejected",
+			file:         invalidFile,
+			errorMatcher: IsInvalidConfig,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			getenv := func(key string) string {
+				return tc.env[key]
+			}
+
+			credentials, err := loadCredentials(tc.credentials, getenv, tc.file)
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if !cmp.Equal(credentials, tc.expectedCredentials) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedCredentials, credentials))
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+// Package standalone provides the collectors with the Azure credentials and
+// resource groups given in the configuration instead of the ones found in a
+// Giant Swarm management cluster, so the collector can be run against
+// arbitrary Azure subscriptions. No Kubernetes API is talked to. The
+// collectors read the credential secrets and AzureConfig CRs derived from the
+// configuration from an in-memory source instead, and the collectors needing
+// other resources of the management cluster are not run.
+package standalone
+
+import (
+	"context"
+	"strings"
+
+	providerv1alpha1 "github.com/giantswarm/apiextensions/v2/pkg/apis/provider/v1alpha1"
+	"github.com/giantswarm/microerror"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+const (
+	// namespace is the namespace of the AzureConfig CRs of the resource
+	// groups.
+	namespace = "default"
+)
+
+type Config struct {
+	// ClientID, ClientSecret and TenantID are the credentials of the
+	// service principal used for all subscriptions.
+	ClientID     string
+	ClientSecret string
+	TenantID     string
+	// PartnerID is the ID of the Azure partner program the calls are
+	// attributed to. It is optional.
+	PartnerID string
+	// SubscriptionIDs are the subscriptions which are collected.
+	SubscriptionIDs []string
+	// ResourceGroups are the resource groups which are collected like the
+	// ones of Giant Swarm clusters, in the form subscription/resourceGroup.
+	// The subscription can be omitted when a single one is collected.
+	ResourceGroups []string
+}
+
+// Source provides the collectors with a credential secret per subscription
+// and an AzureConfig CR per resource group. It implements credential.Backend.
+type Source struct {
+	azureConfigs []providerv1alpha1.AzureConfig
+	secrets      []corev1.Secret
+}
+
+func New(config Config) (*Source, error) {
+	if config.ClientID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ClientID must not be empty", config)
+	}
+	if config.ClientSecret == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.ClientSecret must not be empty", config)
+	}
+	if config.TenantID == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.TenantID must not be empty", config)
+	}
+	if len(config.SubscriptionIDs) == 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.SubscriptionIDs must not be empty", config)
+	}
+
+	resourceGroups, err := parseResourceGroups(config.ResourceGroups, config.SubscriptionIDs)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	s := &Source{}
+
+	for _, subscriptionID := range config.SubscriptionIDs {
+		s.secrets = append(s.secrets, newCredentialSecret(config, subscriptionID))
+	}
+
+	for _, r := range resourceGroups {
+		cr := providerv1alpha1.AzureConfig{}
+		cr.Name = r.name
+		cr.Namespace = namespace
+		cr.Spec.Azure.CredentialSecret.Name = credentialName(r.subscriptionID)
+		cr.Spec.Azure.CredentialSecret.Namespace = credential.CredentialNamespace
+
+		s.azureConfigs = append(s.azureConfigs, cr)
+	}
+
+	return s, nil
+}
+
+func (s *Source) AzureConfigs(ctx context.Context) ([]providerv1alpha1.AzureConfig, error) {
+	return s.azureConfigs, nil
+}
+
+func (s *Source) CredentialSecret(ctx context.Context, name, namespace string) (*corev1.Secret, error) {
+	for i := range s.secrets {
+		if s.secrets[i].Name == name && s.secrets[i].Namespace == namespace {
+			return &s.secrets[i], nil
+		}
+	}
+
+	return nil, microerror.Mask(apierrors.NewNotFound(corev1.Resource("secrets"), name))
+}
+
+func (s *Source) CredentialSecrets(ctx context.Context) ([]corev1.Secret, error) {
+	return s.secrets, nil
+}
+
+type resourceGroup struct {
+	subscriptionID string
+	name           string
+}
+
+// parseResourceGroups parses resource groups in the form
+// subscription/resourceGroup, as passed on the command line. The subscription
+// defaults to the only one given. Resource groups are identified by their
+// name like clusters, so they must be unique across subscriptions.
+func parseResourceGroups(values []string, subscriptionIDs []string) ([]resourceGroup, error) {
+	subscriptions := map[string]bool{}
+	for _, subscriptionID := range subscriptionIDs {
+		subscriptions[subscriptionID] = true
+	}
+
+	var resourceGroups []resourceGroup
+	names := map[string]bool{}
+	for _, value := range values {
+		r := resourceGroup{
+			name: value,
+		}
+
+		parts := strings.SplitN(value, "/", 2)
+		switch {
+		case len(parts) == 2:
+			r.subscriptionID = parts[0]
+			r.name = parts[1]
+		case len(subscriptionIDs) == 1:
+			r.subscriptionID = subscriptionIDs[0]
+		default:
+			return nil, microerror.Maskf(invalidConfigError, "resource group %#q must be in the form subscription/resourceGroup when collecting multiple subscriptions", value)
+		}
+
+		if !subscriptions[r.subscriptionID] {
+			return nil, microerror.Maskf(invalidConfigError, "subscription %#q of resource group %#q is not collected", r.subscriptionID, r.name)
+		}
+		if r.name == "" {
+			return nil, microerror.Maskf(invalidConfigError, "resource group %#q must not be empty", value)
+		}
+		if names[strings.ToLower(r.name)] {
+			return nil, microerror.Maskf(invalidConfigError, "resource group %#q must only be given once", r.name)
+		}
+		names[strings.ToLower(r.name)] = true
+
+		resourceGroups = append(resourceGroups, r)
+	}
+
+	return resourceGroups, nil
+}
+
+// newCredentialSecret returns the credential secret of the given subscription
+// like credentiald would create it. The service principal is a single tenant
+// one, so no auxiliary tenant is authorized.
+func newCredentialSecret(config Config, subscriptionID string) corev1.Secret {
+	secret := corev1.Secret{}
+	secret.Name = credentialName(subscriptionID)
+	secret.Namespace = credential.CredentialNamespace
+	secret.Labels = map[string]string{
+		"giantswarm.io/managed-by": "credentiald",
+		credential.SingleTenantSP:  "true",
+	}
+	secret.Data = map[string][]byte{
+		credential.ClientIDKey:       []byte(config.ClientID),
+		credential.ClientSecretKey:   []byte(config.ClientSecret),
+		credential.SubscriptionIDKey: []byte(subscriptionID),
+		credential.TenantIDKey:       []byte(config.TenantID),
+	}
+	if config.PartnerID != "" {
+		secret.Data[credential.PartnerIDKey] = []byte(config.PartnerID)
+	}
+
+	return secret
+}
+
+func credentialName(subscriptionID string) string {
+	return "credential-" + subscriptionID
+}
@@ -0,0 +1,137 @@
+package standalone
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/microerror"
+	"github.com/google/go-cmp/cmp"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/giantswarm/azure-collector/v2/service/credential"
+)
+
+func Test_parseResourceGroups(t *testing.T) {
+	testCases := []struct {
+		name                   string
+		values                 []string
+		subscriptionIDs        []string
+		expectedResourceGroups []resourceGroup
+		errorMatcher           func(error) bool
+	}{
+		{
+			name:            "case 0: subscription defaults to the only one",
+			values:          []string{"rg-a", "s1/rg-b"},
+			subscriptionIDs: []string{"s1"},
+			expectedResourceGroups: []resourceGroup{
+				{subscriptionID: "s1", name: "rg-a"},
+				{subscriptionID: "s1", name: "rg-b"},
+			},
+		},
+		{
+			name:            "case 1: resource groups of multiple subscriptions",
+			values:          []string{"s1/rg-a", "s2/rg-b"},
+			subscriptionIDs: []string{"s1", "s2"},
+			expectedResourceGroups: []resourceGroup{
+				{subscriptionID: "s1", name: "rg-a"},
+				{subscriptionID: "s2", name: "rg-b"},
+			},
+		},
+		{
+			name:            "case 2: subscription is required with multiple subscriptions",
+			values:          []string{"rg-a"},
+			subscriptionIDs: []string{"s1", "s2"},
+			errorMatcher:    IsInvalidConfig,
+		},
+		{
+			name:            "case 3: subscription must be collected",
+			values:          []string{"s3/rg-a"},
+			subscriptionIDs: []string{"s1", "s2"},
+			errorMatcher:    IsInvalidConfig,
+		},
+		{
+			name:            "case 4: resource groups must be unique across subscriptions",
+			values:          []string{"s1/rg-a", "s2/RG-A"},
+			subscriptionIDs: []string{"s1", "s2"},
+			errorMatcher:    IsInvalidConfig,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			t.Log(tc.name)
+
+			resourceGroups, err := parseResourceGroups(tc.values, tc.subscriptionIDs)
+
+			switch {
+			case err == nil && tc.errorMatcher == nil:
+				// correct; carry on
+			case err != nil && tc.errorMatcher == nil:
+				t.Fatalf("error == %#v, want nil", err)
+			case err == nil && tc.errorMatcher != nil:
+				t.Fatalf("error == nil, want non-nil")
+			case !tc.errorMatcher(err):
+				t.Fatalf("error == %#v, want matching", err)
+			}
+
+			if !cmp.Equal(resourceGroups, tc.expectedResourceGroups, cmp.AllowUnexported(resourceGroup{})) {
+				t.Fatalf("\n\n%s\n", cmp.Diff(tc.expectedResourceGroups, resourceGroups, cmp.AllowUnexported(resourceGroup{})))
+			}
+		})
+	}
+}
+
+func Test_Source(t *testing.T) {
+	ctx := context.Background()
+
+	c := Config{
+		ClientID:        "client",
+		ClientSecret:    "secret",
+		TenantID:        "tenant",
+		SubscriptionIDs: []string{"s1", "s2"},
+		ResourceGroups:  []string{"s1/rg-a", "s2/rg-b"},
+	}
+
+	var source credential.Backend
+	{
+		var err error
+		source, err = New(c)
+		if err != nil {
+			t.Fatalf("expected no error, got %#v", err)
+		}
+	}
+
+	secrets, err := source.CredentialSecrets(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %#v", err)
+	}
+	if len(secrets) != 2 {
+		t.Fatalf("expected 2 credential secrets, got %d", len(secrets))
+	}
+
+	crs, err := source.AzureConfigs(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got %#v", err)
+	}
+	if len(crs) != 2 {
+		t.Fatalf("expected 2 AzureConfigs, got %d", len(crs))
+	}
+
+	// Every AzureConfig references the credential secret of its
+	// subscription.
+	for _, cr := range crs {
+		secret, err := source.CredentialSecret(ctx, cr.Spec.Azure.CredentialSecret.Name, cr.Spec.Azure.CredentialSecret.Namespace)
+		if err != nil {
+			t.Fatalf("expected no error, got %#v", err)
+		}
+		if cr.Name == "rg-b" && string(secret.Data[credential.SubscriptionIDKey]) != "s2" {
+			t.Fatalf("expected subscription %#q, got %#q", "s2", secret.Data[credential.SubscriptionIDKey])
+		}
+	}
+
+	_, err = source.CredentialSecret(ctx, "credential-s3", credential.CredentialNamespace)
+	if !apierrors.IsNotFound(microerror.Cause(err)) {
+		t.Fatalf("expected not found error, got %#v", err)
+	}
+}